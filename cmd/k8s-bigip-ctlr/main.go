@@ -26,9 +26,11 @@ import (
 	routeclient "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
 	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
 	"strings"
 	"syscall"
 	"time"
@@ -61,6 +63,8 @@ const (
 	versionPathk8s         = "/version"
 )
 
+var as3VersionRegex = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
 var (
 	// To be set by build
 	version   string
@@ -92,8 +96,52 @@ var (
 
 	trustedCertsCfgmap *string
 
-	CISConfigCR *string
-	httpAddress *string
+	CISConfigCR     *string
+	httpAddress     *string
+	diagnosticsAddr *string
+
+	topologyAwarePoolWeights *bool
+	topologyKey              *string
+	bigipZone                *string
+	zoneWeights              *map[string]int
+	bgpAdvertise             *bool
+	stalenessThreshold       *time.Duration
+	checkpointCfgmap         *string
+	warmStart                *bool
+	tenantDeviceMapping      *map[string]string
+	auditLog                 *bool
+	auditLogPath             *string
+	driftDetectionInterval   *time.Duration
+	driftResync              *bool
+	manageIngress            *bool
+	apmEnabled               *bool
+	certExpiryWarningDays    *int
+	rbacEnabled              *bool
+	bigipSourceIP            *string
+	classVersions            *map[string]string
+	as3Async                 *bool
+	as3AsyncPollInterval     *time.Duration
+	as3AsyncPollMaxInterval  *time.Duration
+	as3RetryBaseDelay        *time.Duration
+	as3RetryMultiplier       *float64
+	as3RetryMaxDelay         *time.Duration
+	as3AsyncTimeout          *time.Duration
+	dryRun                   *bool
+	maxDeclarationSize       *int
+	circuitBreakerThreshold  *int
+	circuitBreakerCooldown   *time.Duration
+	rolloutPause             *bool
+	bigipTargets             *[]string
+	externalMonitors         *bool
+	adminNamespace           *string
+	provisionCheckInterval   *time.Duration
+	otelEndpoint             *string
+	otelServiceName          *string
+	certRenewalLeadDays      *int
+	defaultLogPublisher      *string
+	firewallEnabled          *bool
+	virtualAddressPool       *[]string
+	virtualAddressPoolCfgmap *string
 
 	// package variables
 	clientSets       controller.ClientSets
@@ -134,6 +182,8 @@ func _init() {
 		"Required, specify a CRD that holds additional spec for controller.")
 	httpAddress = globalFlags.String("http-listen-address", "0.0.0.0:8080",
 		"Optional, address to serve http based informations (/metrics and /health).")
+	diagnosticsAddr = globalFlags.String("diagnostics-listen-address", "",
+		"Optional, address to serve the diagnostic informations (/stats/as3) on, e.g. 0.0.0.0:8081. Disabled by default.")
 	globalFlags.Usage = func() {
 		fmt.Fprintf(os.Stderr, "  Global:\n%s\n", globalFlags.FlagUsagesWrapped(width))
 	}
@@ -164,11 +214,97 @@ func _init() {
 	}
 	manageCustomResources = kubeFlags.Bool("manage-custom-resources", true,
 		"Optional, specify whether or not to manage custom resources i.e. transportserver")
-	// setting manageRoutes to false by default
-	tmpval := false
-	manageRoutes = &tmpval
+	manageRoutes = kubeFlags.Bool("manage-routes", false,
+		"Optional, when set to true, watch the route.openshift.io/v1 Routes and translate them into virtuals, the Routes are grouped into virtuals by the routeGroups of the extendedSpec of the global DeployConfig CR.")
 	ipam = kubeFlags.Bool("ipam", false,
 		"Optional, when set to true, enable ipam feature for CRD.")
+	topologyAwarePoolWeights = kubeFlags.Bool("topology-aware-pool-weights", false,
+		"Optional, when set to true, assign higher pool member ratios to endpoints in the same zone as the BIG-IP. "+
+			"The zone of an endpoint is read from the EndpointSlice topology hints, so the Service needs topology aware hints enabled. "+
+			"The ratios take effect only when the pool uses a ratio load balancing method, e.g. ratio-least-connections-member.")
+	topologyKey = kubeFlags.String("topology-key", "",
+		"Optional, node label holding the topology zone of the nodeport pool members, used with topology-aware-pool-weights. Defaults to topology.kubernetes.io/zone.")
+	bigipZone = kubeFlags.String("bigip-zone", "",
+		"Optional, topology zone of the BIG-IP, used with topology-aware-pool-weights.")
+	zoneWeights = kubeFlags.StringToInt("zone-weights", map[string]int{},
+		"Optional, zone to pool member ratio mapping, e.g. zone-a=10,zone-b=1")
+	stalenessThreshold = kubeFlags.Duration("staleness-threshold", 0,
+		"Optional, duration after which a CIS managed tenant without kubernetes resources is removed from BIG-IP, e.g. 30m. Disabled by default.")
+	checkpointCfgmap = kubeFlags.String("declaration-checkpoint-cfgmap", "",
+		"Optional, namespace/name of the ConfigMap to save the AS3 declaration to shortly after the posts, e.g. kube-system/cis-declaration-checkpoint. The tenants which failed to post are left out, so that they are posted again after CIS restarts.")
+	warmStart = kubeFlags.Bool("warm-start", false,
+		"Optional, when set to true, restore the AS3 declaration from the declaration-checkpoint-cfgmap on startup.")
+	tenantDeviceMapping = kubeFlags.StringToString("tenant-device-mapping", map[string]string{},
+		"Optional, tenant to BIG-IP address mapping to post the tenants to different BIG-IPs, e.g. tenant1=10.1.1.1,tenant2=10.1.1.2. Tenants which are not mapped are posted to the default BIG-IP.")
+	auditLog = kubeFlags.Bool("audit-log", false,
+		"Optional, when set to true, write a Kubernetes audit event for every tenant posted to BIG-IP to the audit-log-path.")
+	auditLogPath = kubeFlags.String("audit-log-path", "",
+		"Optional, file to append the audit events to, required with audit-log, e.g. /var/log/cis/audit.log")
+	driftDetectionInterval = kubeFlags.Duration("drift-detection-interval", 0,
+		"Optional, interval at which the AS3 declaration stored on BIG-IP is compared with the declaration posted by CIS to detect the tenants changed by other AS3 clients, e.g. 10m. Changes made with the BIG-IP GUI or tmsh are not detected. Disabled by default.")
+	driftResync = kubeFlags.Bool("drift-resync", false,
+		"Optional, when set to true, post the drifted tenants again to BIG-IP, used with drift-detection-interval.")
+	virtualAddressPool = kubeFlags.StringSlice("virtual-address-pool", []string{},
+		"Optional, CIDRs to allocate the addresses of the virtualservers without virtualServerAddress from in round-robin order, e.g. 10.8.3.0/28,10.8.4.0/28")
+	virtualAddressPoolCfgmap = kubeFlags.String("virtual-address-pool-cfgmap", "",
+		"Optional, namespace/name of the ConfigMap to save the virtual-address-pool allocations to, e.g. kube-system/cis-virtual-address-pool")
+	bgpAdvertise = kubeFlags.Bool("bgp-advertise", false,
+		"Optional, when set to true, create a LoadBalancer service for virtualservers annotated with cis.f5.com/bgp-advertise.")
+	manageIngress = kubeFlags.Bool("manage-ingress", false,
+		"Optional, when set to true, translate the networking.k8s.io/v1 ingresses of the f5 ingress class into virtuals on the address of their virtual-server.f5.com/ip annotation.")
+	apmEnabled = kubeFlags.Bool("apm-enabled", false,
+		"Optional, when set to true, attach the BIG-IP APM access profiles of the cis.f5.com/access-profile annotation to the virtualservers.")
+	certExpiryWarningDays = kubeFlags.Int("cert-expiry-warning-days", 0,
+		"Optional, number of days before the BIG-IP management certificate expiry to warn with a pod event, e.g. 30. Disabled by default.")
+	rbacEnabled = kubeFlags.Bool("tenant-rbac", false,
+		"Optional, when set to true, process a virtualserver only if the cis-tenant-writer ServiceAccount of its namespace is granted the write verb on its tenant in the tenants resource of the cis.f5.com API group.")
+	bigipSourceIP = kubeFlags.String("bigip-source-ip", "",
+		"Optional, source IP of the BIG-IP traffic to the pods, the pool members of the services of other namespaces are skipped when the NetworkPolicies of their namespace do not allow it.")
+	classVersions = kubeFlags.StringToString("as3-class-versions", map[string]string{},
+		"Optional, AS3 class to schema version mapping, the declarations with objects of the classes are posted with the lowest of their versions as schemaVersion, e.g. Service_HTTPS=3.40.0,TLS_Server=3.38.0")
+	as3Async = kubeFlags.Bool("as3-async", false,
+		"Optional, when set to true, post the AS3 declarations asynchronously and poll their task until it completes.")
+	as3AsyncPollInterval = kubeFlags.Duration("as3-async-poll-interval", time.Second,
+		"Optional, interval before the first poll of the task of an asynchronous AS3 declaration, the interval doubles with every poll of the task up to as3-async-poll-max-interval, used with as3-async.")
+	as3AsyncPollMaxInterval = kubeFlags.Duration("as3-async-poll-max-interval", 30*time.Second,
+		"Optional, maximum interval between the polls of the task of an asynchronous AS3 declaration, used with as3-async.")
+	as3AsyncTimeout = kubeFlags.Duration("as3-async-timeout", 10*time.Minute,
+		"Optional, time the task of an asynchronous AS3 declaration is polled for before its tenants are retried, used with as3-async.")
+	as3RetryBaseDelay = kubeFlags.Duration("as3-retry-base-delay", 0,
+		"Optional, delay before the first retry of the tenants which failed to post to BIG-IP, the delay of the consecutive retries grows by as3-retry-multiplier up to as3-retry-max-delay. Half of the delay is random. Disabled by default, the failed tenants are retried every 30s.")
+	as3RetryMultiplier = kubeFlags.Float64("as3-retry-multiplier", 2,
+		"Optional, factor the delay grows by with every consecutive retry of the failed tenants, used with as3-retry-base-delay.")
+	as3RetryMaxDelay = kubeFlags.Duration("as3-retry-max-delay", 5*time.Minute,
+		"Optional, maximum delay before a retry of the failed tenants, used with as3-retry-base-delay.")
+	dryRun = kubeFlags.Bool("dry-run", false,
+		"Optional, when set to true, check the tenants and the classes of the AS3 declarations against the AS3 schema shipped with CIS and log them and the packet filters at info level without posting them to BIG-IP or updating the status of the resources, e.g. to run CIS in shadow mode alongside the production CIS.")
+	maxDeclarationSize = kubeFlags.Int("as3-max-declaration-size", 0,
+		"Optional, maximum size in bytes of the AS3 declarations posted to BIG-IP, the tenants of a larger declaration are failed and logged with their size instead of posting it, e.g. 10485760. Disabled by default.")
+	circuitBreakerThreshold = kubeFlags.Int("circuit-breaker-threshold", 0,
+		"Optional, number of consecutive 503 Service Unavailable responses of BIG-IP after which the posts are rejected for circuit-breaker-cooldown, a single post probes BIG-IP once the cool-down expires. Disabled by default.")
+	circuitBreakerCooldown = kubeFlags.Duration("circuit-breaker-cooldown", time.Minute,
+		"Optional, time the posts to an unavailable BIG-IP are rejected for, used with circuit-breaker-threshold.")
+	bigipTargets = kubeFlags.StringArray("bigip-target", []string{},
+		"Optional, BIG-IP of the DeployConfig which receives the tenants posted to the other BIG-IPs as well, e.g. the standby device of an HA pair. Use <bigip-address> to post all the tenants or <bigip-address>=<tenant1>,<tenant2> to post the listed tenants, the flag can be repeated. The tenants are placed together with the tenant-device-mapping, so every BIG-IP receives one declaration with all its tenants. A tenant is reported as posted only when all the BIG-IPs it's posted to accept it.")
+	rolloutPause = kubeFlags.Bool("rollout-pause", false,
+		"Optional, holds the declarations of the resource updates instead of posting them to BIG-IP, the latest declaration of every BIG-IP is posted once the rollout is resumed. The rollout is also paused while rolloutPause is set in the as3Config of the global DeployConfig CR.")
+	externalMonitors = kubeFlags.Bool("enable-external-monitors", false,
+		"Optional, allows the Services to monitor their pools with the external monitor scripts of the ConfigMaps of the admin-namespace referenced by their cis.f5.com/monitor-external annotation. BIG-IP runs the scripts as root, so only the administrators should be allowed to write the ConfigMaps of the admin-namespace.")
+	adminNamespace = kubeFlags.String("admin-namespace", "",
+		"Optional, namespace of the ConfigMaps holding the external monitor scripts and the monitor library and of the PacketFilters, the ConfigMaps and the PacketFilters of the other namespaces are ignored. Defaults to the namespace of the CIS pod.")
+	provisionCheckInterval = kubeFlags.Duration("provision-check-interval", 0,
+		"Optional, interval at which the provisioned BIG-IP modules are refreshed, the features of the modules which are not provisioned are skipped, e.g. 5m. Disabled by default, the modules are fetched only once.")
+	otelEndpoint = kubeFlags.String("otel-endpoint", "",
+		"Optional, OTLP/HTTP endpoint of the OpenTelemetry collector a span per AS3 declaration post is exported to, e.g. otel-collector:4318. "+
+			"Only the OTLP/HTTP JSON encoding over plain HTTP is supported, the spans are not sampled and are dropped when the export fails.")
+	otelServiceName = kubeFlags.String("otel-service-name", "k8s-bigip-ctlr",
+		"Optional, service name of the traces exported to the otel-endpoint.")
+	certRenewalLeadDays = kubeFlags.Int("cert-renewal-lead-days", 0,
+		"Optional, number of days before the renewal time of the cert-manager Certificates labelled cis.f5.com/managed=true to update the certificates of their TLSProfiles on BIG-IP, 0 disables it.")
+	defaultLogPublisher = kubeFlags.String("default-log-publisher", "",
+		"Optional, BIG-IP Log Publisher the security events of the virtual servers without the cis.f5.com/log-publisher annotation are logged to, e.g. /Common/remote-syslog.")
+	firewallEnabled = kubeFlags.Bool("firewall-enabled", false,
+		"Optional, translate the ipBlocks of the NetworkPolicies selecting the pool pods into BIG-IP AFM firewall policies of the virtual servers.")
 	// MultiCluster Flags
 	multiClusterMode = multiClusterFlags.String("multi-cluster-mode", "",
 		"Optional, determines in multi cluster env cis running as standalone/primary/secondary")
@@ -237,9 +373,82 @@ func verifyArgs() error {
 		log.Infof("[MultiCluster] CIS running with multi-cluster-mode: %s", *multiClusterMode)
 	}
 
+	if len(*checkpointCfgmap) > 0 && len(strings.Split(*checkpointCfgmap, "/")) != 2 {
+		return fmt.Errorf("invalid value provided for --declaration-checkpoint-cfgmap" +
+			"Usage: --declaration-checkpoint-cfgmap=<namespace>/<configmap-name>")
+	}
+	if *warmStart && len(*checkpointCfgmap) == 0 {
+		return fmt.Errorf("--warm-start requires --declaration-checkpoint-cfgmap")
+	}
+	if *auditLog && len(*auditLogPath) == 0 {
+		return fmt.Errorf("--audit-log requires --audit-log-path")
+	}
+	if *driftDetectionInterval < 0 {
+		return fmt.Errorf("invalid value provided for --drift-detection-interval, should not be negative")
+	}
+	if *driftResync && *driftDetectionInterval == 0 {
+		return fmt.Errorf("--drift-resync requires --drift-detection-interval")
+	}
+	if *as3Async && (*as3AsyncPollInterval <= 0 || *as3AsyncTimeout <= 0) {
+		return fmt.Errorf("--as3-async-poll-interval and --as3-async-timeout should be positive")
+	}
+	if *as3Async && *as3AsyncPollMaxInterval < *as3AsyncPollInterval {
+		return fmt.Errorf("--as3-async-poll-max-interval should not be less than --as3-async-poll-interval")
+	}
+	if *as3RetryBaseDelay < 0 ||
+		(*as3RetryBaseDelay > 0 && (*as3RetryMultiplier < 1 || *as3RetryMaxDelay < *as3RetryBaseDelay)) {
+		return fmt.Errorf("invalid AS3 retry backoff, --as3-retry-base-delay should not be negative, " +
+			"--as3-retry-multiplier at least 1 and --as3-retry-max-delay not less than --as3-retry-base-delay")
+	}
+	if *certRenewalLeadDays < 0 {
+		return fmt.Errorf("invalid value provided for --cert-renewal-lead-days, should not be negative")
+	}
+	if *provisionCheckInterval < 0 {
+		return fmt.Errorf("invalid value provided for --provision-check-interval, should not be negative")
+	}
+	if *bigipSourceIP != "" && net.ParseIP(*bigipSourceIP) == nil {
+		return fmt.Errorf("invalid IP address %v provided for --bigip-source-ip", *bigipSourceIP)
+	}
+	for class, version := range *classVersions {
+		if !as3VersionRegex.MatchString(version) {
+			return fmt.Errorf("invalid version %v provided for class %v in --as3-class-versions, e.g. 3.40.0", version, class)
+		}
+	}
+	for _, cidr := range *virtualAddressPool {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid CIDR %v provided for --virtual-address-pool: %v", cidr, err)
+		}
+	}
+	for _, target := range getBIGIPTargets(*bigipTargets) {
+		if target.Address == "" {
+			return fmt.Errorf("invalid value provided for --bigip-target, " +
+				"Usage: --bigip-target=<bigip-address>[=<tenant1>,<tenant2>]")
+		}
+	}
+	if len(*virtualAddressPoolCfgmap) > 0 && len(strings.Split(*virtualAddressPoolCfgmap, "/")) != 2 {
+		return fmt.Errorf("invalid value provided for --virtual-address-pool-cfgmap" +
+			"Usage: --virtual-address-pool-cfgmap=<namespace>/<configmap-name>")
+	}
+
 	return nil
 }
 
+// getBIGIPTargets returns the BIG-IP targets of the bigip-target flags, <bigip-address>[=<tenant1>,<tenant2>]
+func getBIGIPTargets(values []string) []controller.BIGIPTarget {
+	var targets []controller.BIGIPTarget
+	for _, value := range values {
+		address, tenants, _ := strings.Cut(value, "=")
+		target := controller.BIGIPTarget{Address: strings.TrimSpace(address)}
+		for _, tenant := range strings.Split(tenants, ",") {
+			if tenant = strings.TrimSpace(tenant); tenant != "" {
+				target.TenantSelector = append(target.TenantSelector, tenant)
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
 func getCredentials() error {
 	if len(*credsDir) > 0 {
 		var usr, pass, cmCredURL string
@@ -386,19 +595,66 @@ func initController(
 			Config:     config,
 			ClientSets: &clientSets,
 			UserAgent:  userAgentInfo,
+			CISVersion: version,
 			CMConfigDetails: &controller.CMConfig{
 				URL:      *cmURL,
 				UserName: *cmUsername,
 				Password: *cmPassword,
 			},
-			CMTrustedCerts:        getBIGIPTrustedCerts(),
-			CMSSLInsecure:         *sslInsecure,
-			CISConfigCRKey:        *CISConfigCR,
-			HttpAddress:           *httpAddress,
-			ManageCustomResources: *manageCustomResources,
-			UseNodeInternal:       *useNodeInternal,
-			MultiClusterMode:      *multiClusterMode,
-			IPAM:                  *ipam,
+			CMTrustedCerts:              getBIGIPTrustedCerts(),
+			CMSSLInsecure:               *sslInsecure,
+			CISConfigCRKey:              *CISConfigCR,
+			HttpAddress:                 *httpAddress,
+			DiagnosticsAddr:             *diagnosticsAddr,
+			ManageCustomResources:       *manageCustomResources,
+			OpenShiftRoute:              *manageRoutes,
+			UseNodeInternal:             *useNodeInternal,
+			MultiClusterMode:            *multiClusterMode,
+			IPAM:                        *ipam,
+			TopologyAwarePoolWeights:    *topologyAwarePoolWeights,
+			TopologyKey:                 *topologyKey,
+			BIGIPZone:                   *bigipZone,
+			ZoneWeights:                 *zoneWeights,
+			BGPAdvertise:                *bgpAdvertise,
+			StalenessThreshold:          *stalenessThreshold,
+			CheckpointConfigMap:         *checkpointCfgmap,
+			WarmStart:                   *warmStart,
+			TenantToDeviceMapping:       *tenantDeviceMapping,
+			AuditLogEnabled:             *auditLog,
+			AuditLogPath:                *auditLogPath,
+			DriftDetectionInterval:      *driftDetectionInterval,
+			DriftResync:                 *driftResync,
+			VirtualAddressPool:          *virtualAddressPool,
+			VirtualAddressPoolConfigMap: *virtualAddressPoolCfgmap,
+			ManageIngress:               *manageIngress,
+			APMEnabled:                  *apmEnabled,
+			CertExpiryWarningDays:       *certExpiryWarningDays,
+			RBACEnabled:                 *rbacEnabled,
+			BIGIPSourceIP:               *bigipSourceIP,
+			ClassVersions:               *classVersions,
+			AS3AsyncMode:                *as3Async,
+			AS3AsyncPollInterval:        *as3AsyncPollInterval,
+			AS3AsyncPollMaxInterval:     *as3AsyncPollMaxInterval,
+			AS3AsyncTimeout:             *as3AsyncTimeout,
+			ProvisionCheckInterval:      *provisionCheckInterval,
+			OTelEndpoint:                *otelEndpoint,
+			OTelServiceName:             *otelServiceName,
+			CertRenewalLeadDays:         *certRenewalLeadDays,
+			DefaultLogPublisher:         *defaultLogPublisher,
+			FirewallEnabled:             *firewallEnabled,
+			DryRun:                      *dryRun,
+			MaxDeclarationSizeBytes:     *maxDeclarationSize,
+			CircuitBreakerThreshold:     *circuitBreakerThreshold,
+			CircuitBreakerCooldown:      *circuitBreakerCooldown,
+			RolloutPause:                *rolloutPause,
+			BIGIPTargets:                getBIGIPTargets(*bigipTargets),
+			ExternalMonitors:            *externalMonitors,
+			AdminNamespace:              *adminNamespace,
+			AS3RetryBackoff: controller.RetryBackoff{
+				BaseDelay:  *as3RetryBaseDelay,
+				Multiplier: *as3RetryMultiplier,
+				MaxDelay:   *as3RetryMaxDelay,
+			},
 		},
 	)
 
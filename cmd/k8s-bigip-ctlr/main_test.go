@@ -26,6 +26,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	"os"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -48,6 +49,7 @@ var _ = Describe("Main Tests", func() {
 				"--kubeconfig=/tmp/kubeconfig",
 				"--credentials-directory=/tmp/k8s-test-creds",
 				"--log-file=/tmp/k8s-bigip-ctlr.log",
+				"--manage-routes=true",
 			}
 
 			flags.Parse(os.Args)
@@ -64,6 +66,14 @@ var _ = Describe("Main Tests", func() {
 			Expect(*kubeConfig).To(Equal("/tmp/kubeconfig"))
 			Expect(*credsDir).To(Equal("/tmp/k8s-test-creds"))
 			Expect(*logFile).To(Equal("/tmp/k8s-bigip-ctlr.log"))
+			Expect(*manageRoutes).To(BeTrue())
+		})
+		It("parses the BIG-IP targets", func() {
+			targets := getBIGIPTargets([]string{"10.1.1.2", "10.1.1.3=tenant1, tenant2"})
+			Expect(targets).To(Equal([]controller.BIGIPTarget{
+				{Address: "10.1.1.2"},
+				{Address: "10.1.1.3", TenantSelector: []string{"tenant1", "tenant2"}},
+			}))
 		})
 		It("Test empty required args ", func() {
 			defer _init()
@@ -97,7 +107,14 @@ var _ = Describe("Main Tests", func() {
 			flags.Parse(os.Args)
 			argError := verifyArgs()
 			Expect(argError).To(BeNil())
+			// the optional checks and limits are off by default
+			Expect(*maxDeclarationSize).To(BeZero())
+			Expect(*certExpiryWarningDays).To(BeZero())
+			Expect(*provisionCheckInterval).To(BeZero())
+			Expect(*as3RetryBaseDelay).To(BeZero())
 
+			*as3RetryBaseDelay = -time.Second
+			Expect(verifyArgs()).NotTo(BeNil(), "Negative retry base delay allowed")
 		})
 
 		It("verifies with missing --cm-password required CLI parameter", func() {
@@ -50,6 +50,16 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PolicyList{},
 		&DeployConfig{},
 		&DeployConfigList{},
+		&AddressList{},
+		&AddressListList{},
+		&PortList{},
+		&PortListList{},
+		&NATPolicy{},
+		&NATPolicyList{},
+		&PacketFilter{},
+		&PacketFilterList{},
+		&SNATPool{},
+		&SNATPoolList{},
 	)
 
 	scheme.AddKnownTypes(
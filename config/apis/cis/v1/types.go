@@ -21,8 +21,10 @@ type VirtualServer struct {
 
 // VirtualServerStatus is the status of the VirtualServer resource.
 type VirtualServerStatus struct {
-	VSAddress string `json:"vsAddress,omitempty"`
-	StatusOk  string `json:"status,omitempty"`
+	VSAddress       string             `json:"vsAddress,omitempty"`
+	VSAddressSource string             `json:"vsAddressSource,omitempty"`
+	StatusOk        string             `json:"status,omitempty"`
+	Conditions      []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // VirtualServerSpec is the spec of the VirtualServer resource.
@@ -55,6 +57,42 @@ type VirtualServerSpec struct {
 	AllowSourceRange                 []string         `json:"allowSourceRange,omitempty"`
 	HttpMrfRoutingEnabled            *bool            `json:"httpMrfRoutingEnabled,omitempty"`
 	Partition                        string           `json:"partition,omitempty"`
+	URLMap                           []URLRule        `json:"urlMap,omitempty"`
+	TrafficClassification            bool             `json:"trafficClassification,omitempty"`
+	Protocol                         string           `json:"protocol,omitempty"`
+	SIP                              *SIP             `json:"sip,omitempty"`
+	SCTP                             *SCTP            `json:"sctp,omitempty"`
+	WebSocketEnabled                 bool             `json:"webSocketEnabled,omitempty"`
+	WebSocket                        *WebSocket       `json:"webSocket,omitempty"`
+}
+
+// WebSocket defines the WebSocket profile settings of a VirtualServer with webSocketEnabled.
+type WebSocket struct {
+	Compression bool   `json:"compression,omitempty"`
+	Masking     string `json:"masking,omitempty"`
+}
+
+// SIP defines the Session Initiation Protocol settings of a VirtualServer with protocol sip.
+type SIP struct {
+	Port        int32  `json:"port,omitempty"`
+	Transport   string `json:"transport,omitempty"`
+	Secure      bool   `json:"secure,omitempty"`
+	DialogAware bool   `json:"dialogAware,omitempty"`
+}
+
+// SCTP defines the SCTP profile settings of a VirtualServer with protocol sctp.
+type SCTP struct {
+	HeartbeatInterval int32 `json:"heartbeatInterval,omitempty"`
+	MaxInitRetries    int32 `json:"maxInitRetries,omitempty"`
+	CookieExpiry      int32 `json:"cookieExpiry,omitempty"`
+}
+
+// URLRule defines a host/path based routing rule to a pool of the Virtual Server.
+type URLRule struct {
+	Host          string `json:"host,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Pool          string `json:"pool"`
+	RewriteTarget string `json:"rewriteTarget,omitempty"`
 }
 
 // ServiceAddress Service IP address definition (BIG-IP virtual-address).
@@ -167,13 +205,49 @@ type TLSProfile struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec TLSProfileSpec `json:"spec"`
+	Spec   TLSProfileSpec   `json:"spec"`
+	Status TLSProfileStatus `json:"status,omitempty"`
+}
+
+// TLSProfileStatus is the status of the TLSProfile resource.
+type TLSProfileStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// CertExpiry and CertIssuer are sourced from the cert-manager Certificate of the TLS secret
+	CertExpiry string `json:"certExpiry,omitempty"`
+	CertIssuer string `json:"certIssuer,omitempty"`
 }
 
 // TLSProfileSpec is spec for TLSServer
 type TLSProfileSpec struct {
-	Hosts []string `json:"hosts"`
-	TLS   TLS      `json:"tls"`
+	Hosts          []string                    `json:"hosts"`
+	TLS            TLS                         `json:"tls"`
+	OCSPCRLProfile *CertificateValidatorConfig `json:"ocspCRLProfile,omitempty"`
+	CipherGroup    *CipherGroupConfig          `json:"cipherGroup,omitempty"`
+}
+
+// CipherGroupConfig defines the cipher group of the TLS server
+type CipherGroupConfig struct {
+	Name  string       `json:"name"`
+	Rules []CipherRule `json:"rules,omitempty"`
+}
+
+// CipherRule defines the cipher suites allowed and denied by the cipher group
+type CipherRule struct {
+	Name      string   `json:"name"`
+	DenyList  []string `json:"denyList,omitempty"`
+	AllowList []string `json:"allowList,omitempty"`
+	OrderBy   string   `json:"orderBy,omitempty"`
+}
+
+// CertificateValidatorConfig defines the OCSP/CRL revocation checks of client certificates
+type CertificateValidatorConfig struct {
+	TrustCA           string `json:"trustCA"`
+	OCSPEnabled       bool   `json:"ocspEnabled,omitempty"`
+	OCSPResponderURL  string `json:"ocspResponderUrl,omitempty"`
+	DNSResolver       string `json:"dnsResolver,omitempty"`
+	CRLEnabled        bool   `json:"crlEnabled,omitempty"`
+	CRLFile           string `json:"crlFile,omitempty"`
+	RequireOCSPStatus bool   `json:"requireOCSPStatus,omitempty"`
 }
 
 // TLS contains required fields for TLS termination
@@ -445,6 +519,164 @@ type PolicyList struct {
 	Items []Policy `json:"items"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AddressList describes a list of CIDR addresses used for access control.
+type AddressList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AddressListSpec `json:"spec"`
+}
+
+// AddressListSpec defines the addresses and the type of the address list.
+type AddressListSpec struct {
+	Addresses []string `json:"addresses"`
+	// +kubebuilder:validation:Enum=allow;deny
+	Type string `json:"type,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AddressListList is list of AddressList resources
+type AddressListList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []AddressList `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PortList describes a list of ports used for access control.
+type PortList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PortListSpec `json:"spec"`
+}
+
+// PortListSpec defines the ports and port ranges of the port list.
+type PortListSpec struct {
+	Ports      []int       `json:"ports,omitempty"`
+	PortRanges []PortRange `json:"portRanges,omitempty"`
+}
+
+// PortRange defines an inclusive range of ports.
+type PortRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PortListList is list of PortList resources
+type PortListList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PortList `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NATPolicy describes the network address translation rules of the virtual servers.
+type NATPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NATPolicySpec `json:"spec"`
+}
+
+// NATPolicySpec defines the rules of the NAT policy.
+type NATPolicySpec struct {
+	Rules []NATRule `json:"rules"`
+}
+
+// NATRule translates the source of the traffic matching the source and destination addresses and the protocol.
+type NATRule struct {
+	Name        string `json:"name"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Translation string `json:"translation"`
+	Protocol    string `json:"protocol,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NATPolicyList is list of NATPolicy resources
+type NATPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NATPolicy `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SNATPool describes the SNAT addresses a virtual server translates the source of its traffic to.
+type SNATPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SNATPoolSpec `json:"spec"`
+}
+
+// SNATPoolSpec defines the addresses of the SNAT pool.
+type SNATPoolSpec struct {
+	Addresses []string `json:"addresses"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SNATPoolList is list of SNATPool resources
+type SNATPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SNATPool `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PacketFilter describes the packet filter rules applied to the traffic of BIG-IP.
+type PacketFilter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PacketFilterSpec `json:"spec"`
+}
+
+// PacketFilterSpec defines the rules of the packet filter.
+type PacketFilterSpec struct {
+	Rules []PacketFilterRule `json:"rules"`
+}
+
+// PacketFilterRule allows, rejects or discards the packets matching the source and destination addresses
+// and the protocol, the rules are evaluated in the ascending order.
+type PacketFilterRule struct {
+	Order       int    `json:"order"`
+	Action      string `json:"action"`
+	Protocol    string `json:"protocol,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PacketFilterList is list of PacketFilter resources
+type PacketFilterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PacketFilter `json:"items"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:validation:Optional
@@ -499,6 +731,7 @@ type AS3Config struct {
 	DebugAS3     bool `json:"debugAS3,omitempty"`
 	PostDelayAS3 int  `json:"postDelayAS3,omitempty"`
 	DocumentAPI  bool `json:"documentAPI,omitempty"`
+	RolloutPause bool `json:"rolloutPause,omitempty"`
 }
 
 type BigIpConfig struct {
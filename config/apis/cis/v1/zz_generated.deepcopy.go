@@ -60,6 +60,87 @@ func (in *AS3Status) DeepCopy() *AS3Status {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressList) DeepCopyInto(out *AddressList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressList.
+func (in *AddressList) DeepCopy() *AddressList {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AddressList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressListList) DeepCopyInto(out *AddressListList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AddressList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressListList.
+func (in *AddressListList) DeepCopy() *AddressListList {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressListList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AddressListList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressListSpec) DeepCopyInto(out *AddressListSpec) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressListSpec.
+func (in *AddressListSpec) DeepCopy() *AddressListSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressListSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AlternateBackend) DeepCopyInto(out *AlternateBackend) {
 	*out = *in
@@ -207,6 +288,71 @@ func (in *CNIConfigMeta) DeepCopy() *CNIConfigMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateValidatorConfig) DeepCopyInto(out *CertificateValidatorConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateValidatorConfig.
+func (in *CertificateValidatorConfig) DeepCopy() *CertificateValidatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateValidatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CipherGroupConfig) DeepCopyInto(out *CipherGroupConfig) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]CipherRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CipherGroupConfig.
+func (in *CipherGroupConfig) DeepCopy() *CipherGroupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CipherGroupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CipherRule) DeepCopyInto(out *CipherRule) {
+	*out = *in
+	if in.DenyList != nil {
+		in, out := &in.DenyList, &out.DenyList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowList != nil {
+		in, out := &in.AllowList, &out.AllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CipherRule.
+func (in *CipherRule) DeepCopy() *CipherRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CipherRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterDetails) DeepCopyInto(out *ClusterDetails) {
 	*out = *in
@@ -957,6 +1103,103 @@ func (in *MultiPoolPersistence) DeepCopy() *MultiPoolPersistence {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATPolicy) DeepCopyInto(out *NATPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATPolicy.
+func (in *NATPolicy) DeepCopy() *NATPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NATPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NATPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATPolicyList) DeepCopyInto(out *NATPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NATPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATPolicyList.
+func (in *NATPolicyList) DeepCopy() *NATPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NATPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NATPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATPolicySpec) DeepCopyInto(out *NATPolicySpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]NATRule, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATPolicySpec.
+func (in *NATPolicySpec) DeepCopy() *NATPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NATPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATRule) DeepCopyInto(out *NATRule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATRule.
+func (in *NATRule) DeepCopy() *NATRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NATRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkConfig) DeepCopyInto(out *NetworkConfig) {
 	*out = *in
@@ -991,6 +1234,103 @@ func (in *NetworkConfigStatus) DeepCopy() *NetworkConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketFilter) DeepCopyInto(out *PacketFilter) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketFilter.
+func (in *PacketFilter) DeepCopy() *PacketFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketFilter) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketFilterList) DeepCopyInto(out *PacketFilterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PacketFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketFilterList.
+func (in *PacketFilterList) DeepCopy() *PacketFilterList {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketFilterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketFilterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketFilterRule) DeepCopyInto(out *PacketFilterRule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketFilterRule.
+func (in *PacketFilterRule) DeepCopy() *PacketFilterRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketFilterRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketFilterSpec) DeepCopyInto(out *PacketFilterSpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]PacketFilterRule, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketFilterSpec.
+func (in *PacketFilterSpec) DeepCopy() *PacketFilterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketFilterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Policy) DeepCopyInto(out *Policy) {
 	*out = *in
@@ -1095,6 +1435,108 @@ func (in *PoolSettingsSpec) DeepCopy() *PoolSettingsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortList) DeepCopyInto(out *PortList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortList.
+func (in *PortList) DeepCopy() *PortList {
+	if in == nil {
+		return nil
+	}
+	out := new(PortList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PortList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortListList) DeepCopyInto(out *PortListList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PortList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortListList.
+func (in *PortListList) DeepCopy() *PortListList {
+	if in == nil {
+		return nil
+	}
+	out := new(PortListList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PortListList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortListSpec) DeepCopyInto(out *PortListSpec) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.PortRanges != nil {
+		in, out := &in.PortRanges, &out.PortRanges
+		*out = make([]PortRange, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortListSpec.
+func (in *PortListSpec) DeepCopy() *PortListSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PortListSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortRange) DeepCopyInto(out *PortRange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortRange.
+func (in *PortRange) DeepCopy() *PortRange {
+	if in == nil {
+		return nil
+	}
+	out := new(PortRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProfileHTTP2) DeepCopyInto(out *ProfileHTTP2) {
 	*out = *in
@@ -1192,6 +1634,119 @@ func (in *ProfileVSSpec) DeepCopy() *ProfileVSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCTP) DeepCopyInto(out *SCTP) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCTP.
+func (in *SCTP) DeepCopy() *SCTP {
+	if in == nil {
+		return nil
+	}
+	out := new(SCTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SIP) DeepCopyInto(out *SIP) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SIP.
+func (in *SIP) DeepCopy() *SIP {
+	if in == nil {
+		return nil
+	}
+	out := new(SIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNATPool) DeepCopyInto(out *SNATPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNATPool.
+func (in *SNATPool) DeepCopy() *SNATPool {
+	if in == nil {
+		return nil
+	}
+	out := new(SNATPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SNATPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNATPoolList) DeepCopyInto(out *SNATPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SNATPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNATPoolList.
+func (in *SNATPoolList) DeepCopy() *SNATPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(SNATPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SNATPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNATPoolSpec) DeepCopyInto(out *SNATPoolSpec) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNATPoolSpec.
+func (in *SNATPoolSpec) DeepCopy() *SNATPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SNATPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SSLProfiles) DeepCopyInto(out *SSLProfiles) {
 	*out = *in
@@ -1282,6 +1837,7 @@ func (in *TLSProfile) DeepCopyInto(out *TLSProfile) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1345,6 +1901,16 @@ func (in *TLSProfileSpec) DeepCopyInto(out *TLSProfileSpec) {
 		copy(*out, *in)
 	}
 	in.TLS.DeepCopyInto(&out.TLS)
+	if in.OCSPCRLProfile != nil {
+		in, out := &in.OCSPCRLProfile, &out.OCSPCRLProfile
+		*out = new(CertificateValidatorConfig)
+		**out = **in
+	}
+	if in.CipherGroup != nil {
+		in, out := &in.CipherGroup, &out.CipherGroup
+		*out = new(CipherGroupConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1358,6 +1924,29 @@ func (in *TLSProfileSpec) DeepCopy() *TLSProfileSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSProfileStatus) DeepCopyInto(out *TLSProfileStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSProfileStatus.
+func (in *TLSProfileStatus) DeepCopy() *TLSProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TSPool) DeepCopyInto(out *TSPool) {
 	*out = *in
@@ -1504,6 +2093,22 @@ func (in *TransportServerStatus) DeepCopy() *TransportServerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *URLRule) DeepCopyInto(out *URLRule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new URLRule.
+func (in *URLRule) DeepCopy() *URLRule {
+	if in == nil {
+		return nil
+	}
+	out := new(URLRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VSPool) DeepCopyInto(out *VSPool) {
 	*out = *in
@@ -1553,7 +2158,7 @@ func (in *VirtualServer) DeepCopyInto(out *VirtualServer) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1650,6 +2255,26 @@ func (in *VirtualServerSpec) DeepCopyInto(out *VirtualServerSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.URLMap != nil {
+		in, out := &in.URLMap, &out.URLMap
+		*out = make([]URLRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.SIP != nil {
+		in, out := &in.SIP, &out.SIP
+		*out = new(SIP)
+		**out = **in
+	}
+	if in.SCTP != nil {
+		in, out := &in.SCTP, &out.SCTP
+		*out = new(SCTP)
+		**out = **in
+	}
+	if in.WebSocket != nil {
+		in, out := &in.WebSocket, &out.WebSocket
+		*out = new(WebSocket)
+		**out = **in
+	}
 	return
 }
 
@@ -1666,6 +2291,13 @@ func (in *VirtualServerSpec) DeepCopy() *VirtualServerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualServerStatus) DeepCopyInto(out *VirtualServerStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1678,3 +2310,19 @@ func (in *VirtualServerStatus) DeepCopy() *VirtualServerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebSocket) DeepCopyInto(out *WebSocket) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebSocket.
+func (in *WebSocket) DeepCopy() *WebSocket {
+	if in == nil {
+		return nil
+	}
+	out := new(WebSocket)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -26,10 +26,15 @@ import (
 
 type CisV1Interface interface {
 	RESTClient() rest.Interface
+	AddressListsGetter
 	DeployConfigsGetter
 	ExternalDNSesGetter
 	IngressLinksGetter
+	NATPoliciesGetter
 	PoliciesGetter
+	PortListsGetter
+	PacketFiltersGetter
+	SNATPoolsGetter
 	TLSProfilesGetter
 	TransportServersGetter
 	VirtualServersGetter
@@ -40,6 +45,10 @@ type CisV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *CisV1Client) AddressLists(namespace string) AddressListInterface {
+	return newAddressLists(c, namespace)
+}
+
 func (c *CisV1Client) DeployConfigs(namespace string) DeployConfigInterface {
 	return newDeployConfigs(c, namespace)
 }
@@ -52,10 +61,26 @@ func (c *CisV1Client) IngressLinks(namespace string) IngressLinkInterface {
 	return newIngressLinks(c, namespace)
 }
 
+func (c *CisV1Client) NATPolicies(namespace string) NATPolicyInterface {
+	return newNATPolicies(c, namespace)
+}
+
 func (c *CisV1Client) Policies(namespace string) PolicyInterface {
 	return newPolicies(c, namespace)
 }
 
+func (c *CisV1Client) PortLists(namespace string) PortListInterface {
+	return newPortLists(c, namespace)
+}
+
+func (c *CisV1Client) PacketFilters(namespace string) PacketFilterInterface {
+	return newPacketFilters(c, namespace)
+}
+
+func (c *CisV1Client) SNATPools(namespace string) SNATPoolInterface {
+	return newSNATPools(c, namespace)
+}
+
 func (c *CisV1Client) TLSProfiles(namespace string) TLSProfileInterface {
 	return newTLSProfiles(c, namespace)
 }
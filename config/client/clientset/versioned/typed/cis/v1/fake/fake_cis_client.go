@@ -28,6 +28,10 @@ type FakeCisV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeCisV1) AddressLists(namespace string) v1.AddressListInterface {
+	return &FakeAddressLists{c, namespace}
+}
+
 func (c *FakeCisV1) DeployConfigs(namespace string) v1.DeployConfigInterface {
 	return &FakeDeployConfigs{c, namespace}
 }
@@ -40,10 +44,26 @@ func (c *FakeCisV1) IngressLinks(namespace string) v1.IngressLinkInterface {
 	return &FakeIngressLinks{c, namespace}
 }
 
+func (c *FakeCisV1) NATPolicies(namespace string) v1.NATPolicyInterface {
+	return &FakeNATPolicies{c, namespace}
+}
+
 func (c *FakeCisV1) Policies(namespace string) v1.PolicyInterface {
 	return &FakePolicies{c, namespace}
 }
 
+func (c *FakeCisV1) PortLists(namespace string) v1.PortListInterface {
+	return &FakePortLists{c, namespace}
+}
+
+func (c *FakeCisV1) PacketFilters(namespace string) v1.PacketFilterInterface {
+	return &FakePacketFilters{c, namespace}
+}
+
+func (c *FakeCisV1) SNATPools(namespace string) v1.SNATPoolInterface {
+	return &FakeSNATPools{c, namespace}
+}
+
 func (c *FakeCisV1) TLSProfiles(namespace string) v1.TLSProfileInterface {
 	return &FakeTLSProfiles{c, namespace}
 }
@@ -18,14 +18,24 @@ limitations under the License.
 
 package v1
 
+type AddressListExpansion interface{}
+
 type DeployConfigExpansion interface{}
 
 type ExternalDNSExpansion interface{}
 
 type IngressLinkExpansion interface{}
 
+type NATPolicyExpansion interface{}
+
 type PolicyExpansion interface{}
 
+type PortListExpansion interface{}
+
+type PacketFilterExpansion interface{}
+
+type SNATPoolExpansion interface{}
+
 type TLSProfileExpansion interface{}
 
 type TransportServerExpansion interface{}
@@ -24,14 +24,24 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// AddressLists returns a AddressListInformer.
+	AddressLists() AddressListInformer
 	// DeployConfigs returns a DeployConfigInformer.
 	DeployConfigs() DeployConfigInformer
 	// ExternalDNSes returns a ExternalDNSInformer.
 	ExternalDNSes() ExternalDNSInformer
 	// IngressLinks returns a IngressLinkInformer.
 	IngressLinks() IngressLinkInformer
+	// NATPolicies returns a NATPolicyInformer.
+	NATPolicies() NATPolicyInformer
 	// Policies returns a PolicyInformer.
 	Policies() PolicyInformer
+	// PortLists returns a PortListInformer.
+	PortLists() PortListInformer
+	// PacketFilters returns a PacketFilterInformer.
+	PacketFilters() PacketFilterInformer
+	// SNATPools returns a SNATPoolInformer.
+	SNATPools() SNATPoolInformer
 	// TLSProfiles returns a TLSProfileInformer.
 	TLSProfiles() TLSProfileInformer
 	// TransportServers returns a TransportServerInformer.
@@ -51,6 +61,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// AddressLists returns a AddressListInformer.
+func (v *version) AddressLists() AddressListInformer {
+	return &addressListInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // DeployConfigs returns a DeployConfigInformer.
 func (v *version) DeployConfigs() DeployConfigInformer {
 	return &deployConfigInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -66,11 +81,31 @@ func (v *version) IngressLinks() IngressLinkInformer {
 	return &ingressLinkInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// NATPolicies returns a NATPolicyInformer.
+func (v *version) NATPolicies() NATPolicyInformer {
+	return &nATPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Policies returns a PolicyInformer.
 func (v *version) Policies() PolicyInformer {
 	return &policyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// PortLists returns a PortListInformer.
+func (v *version) PortLists() PortListInformer {
+	return &portListInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// PacketFilters returns a PacketFilterInformer.
+func (v *version) PacketFilters() PacketFilterInformer {
+	return &packetFilterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// SNATPools returns a SNATPoolInformer.
+func (v *version) SNATPools() SNATPoolInformer {
+	return &sNATPoolInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // TLSProfiles returns a TLSProfileInformer.
 func (v *version) TLSProfiles() TLSProfileInformer {
 	return &tLSProfileInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
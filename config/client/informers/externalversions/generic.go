@@ -53,14 +53,24 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=cis.f5.com, Version=v1
+	case v1.SchemeGroupVersion.WithResource("addresslists"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().AddressLists().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("deployconfigs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().DeployConfigs().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("externaldnses"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().ExternalDNSes().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("ingresslinks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().IngressLinks().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("natpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().NATPolicies().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("policies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().Policies().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("portlists"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().PortLists().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("packetfilters"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().PacketFilters().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("snatpools"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().SNATPools().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("tlsprofiles"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cis().V1().TLSProfiles().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("transportservers"):
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1
 
+// AddressListListerExpansion allows custom methods to be added to
+// AddressListLister.
+type AddressListListerExpansion interface{}
+
+// AddressListNamespaceListerExpansion allows custom methods to be added to
+// AddressListNamespaceLister.
+type AddressListNamespaceListerExpansion interface{}
+
 // DeployConfigListerExpansion allows custom methods to be added to
 // DeployConfigLister.
 type DeployConfigListerExpansion interface{}
@@ -42,6 +50,14 @@ type IngressLinkListerExpansion interface{}
 // IngressLinkNamespaceLister.
 type IngressLinkNamespaceListerExpansion interface{}
 
+// NATPolicyListerExpansion allows custom methods to be added to
+// NATPolicyLister.
+type NATPolicyListerExpansion interface{}
+
+// NATPolicyNamespaceListerExpansion allows custom methods to be added to
+// NATPolicyNamespaceLister.
+type NATPolicyNamespaceListerExpansion interface{}
+
 // PolicyListerExpansion allows custom methods to be added to
 // PolicyLister.
 type PolicyListerExpansion interface{}
@@ -50,6 +66,30 @@ type PolicyListerExpansion interface{}
 // PolicyNamespaceLister.
 type PolicyNamespaceListerExpansion interface{}
 
+// PortListListerExpansion allows custom methods to be added to
+// PortListLister.
+type PortListListerExpansion interface{}
+
+// PortListNamespaceListerExpansion allows custom methods to be added to
+// PortListNamespaceLister.
+type PortListNamespaceListerExpansion interface{}
+
+// PacketFilterListerExpansion allows custom methods to be added to
+// PacketFilterLister.
+type PacketFilterListerExpansion interface{}
+
+// PacketFilterNamespaceListerExpansion allows custom methods to be added to
+// PacketFilterNamespaceLister.
+type PacketFilterNamespaceListerExpansion interface{}
+
+// SNATPoolListerExpansion allows custom methods to be added to
+// SNATPoolLister.
+type SNATPoolListerExpansion interface{}
+
+// SNATPoolNamespaceListerExpansion allows custom methods to be added to
+// SNATPoolNamespaceLister.
+type SNATPoolNamespaceListerExpansion interface{}
+
 // TLSProfileListerExpansion allows custom methods to be added to
 // TLSProfileLister.
 type TLSProfileListerExpansion interface{}
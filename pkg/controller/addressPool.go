@@ -0,0 +1,234 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewVirtualAddressPool creates the virtual address pool of the CIDRs and restores the allocations
+// from the ConfigMap
+func NewVirtualAddressPool(cidrs []string, configMap string, kubeClient kubernetes.Interface) (*VirtualAddressPool, error) {
+	pool := &VirtualAddressPool{
+		allocations: make(map[string]string),
+		configMap:   configMap,
+		kubeClient:  kubeClient,
+	}
+	for _, cidr := range cidrs {
+		addresses, err := getCIDRAddresses(cidr)
+		if err != nil {
+			return nil, err
+		}
+		pool.addresses = append(pool.addresses, addresses...)
+		if len(pool.addresses) > MaxVirtualAddressPoolSize {
+			return nil, fmt.Errorf("virtual address pool has more than %v addresses", MaxVirtualAddressPoolSize)
+		}
+	}
+	if len(pool.addresses) == 0 {
+		return nil, fmt.Errorf("virtual address pool has no addresses")
+	}
+	pool.restoreAllocations()
+	return pool, nil
+}
+
+// getCIDRAddresses returns the host addresses of the CIDR, the network and broadcast
+// addresses of the IPv4 subnets are excluded
+func getCIDRAddresses(cidr string) ([]string, error) {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %v in virtual address pool: %v", cidr, err)
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones > 16 {
+		return nil, fmt.Errorf("CIDR %v in virtual address pool has more than %v addresses", cidr,
+			MaxVirtualAddressPoolSize)
+	}
+	var addresses []string
+	for ip = ip.Mask(ipNet.Mask); ipNet.Contains(ip); ip = nextIP(ip) {
+		addresses = append(addresses, ip.String())
+	}
+	if ip.To4() != nil && bits-ones > 1 {
+		addresses = addresses[1 : len(addresses)-1]
+	}
+	return addresses, nil
+}
+
+// nextIP returns the IP address following the given address
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// allocate returns the address allocated for the key, a new address is allocated in round-robin
+// order when the key has no allocation
+func (pool *VirtualAddressPool) allocate(key string) (string, error) {
+	pool.Lock()
+	defer pool.Unlock()
+	if ip, ok := pool.allocations[key]; ok {
+		return ip, nil
+	}
+	allocated := make(map[string]struct{}, len(pool.allocations))
+	for _, ip := range pool.allocations {
+		allocated[ip] = struct{}{}
+	}
+	for i := 0; i < len(pool.addresses); i++ {
+		index := (pool.next + i) % len(pool.addresses)
+		ip := pool.addresses[index]
+		if _, ok := allocated[ip]; ok {
+			continue
+		}
+		pool.allocations[key] = ip
+		pool.next = (index + 1) % len(pool.addresses)
+		pool.saveAllocations()
+		log.Infof("Allocated virtual address %v to %v from virtual address pool", ip, key)
+		return ip, nil
+	}
+	return "", fmt.Errorf("no address available in virtual address pool for %v", key)
+}
+
+// release returns the address allocated for the key back to the pool
+func (pool *VirtualAddressPool) release(key string) string {
+	pool.Lock()
+	defer pool.Unlock()
+	ip, ok := pool.allocations[key]
+	if !ok {
+		return ""
+	}
+	delete(pool.allocations, key)
+	pool.saveAllocations()
+	log.Infof("Released virtual address %v of %v to virtual address pool", ip, key)
+	return ip
+}
+
+// getVirtualAddressPoolKey returns the allocation key of the VirtualServer, the virtuals with the same host
+// share the address allocated from the pool
+func getVirtualAddressPoolKey(vs *cisapiv1.VirtualServer) string {
+	return vs.Namespace + "/" + vs.Spec.Host + "_host"
+}
+
+// releaseExplicitVirtualAddress releases the address allocated from the pool for the host of the VirtualServer
+// once it has an explicit virtualServerAddress and none of the virtuals of the host takes the pool address
+func (ctlr *Controller) releaseExplicitVirtualAddress(vs *cisapiv1.VirtualServer, virtuals []*cisapiv1.VirtualServer) {
+	if ctlr.vipPool == nil || vs.Spec.VirtualServerAddress == "" {
+		return
+	}
+	for _, virtual := range virtuals {
+		if virtual.Spec.VirtualServerAddress == "" {
+			return
+		}
+	}
+	ctlr.vipPool.release(getVirtualAddressPoolKey(vs))
+}
+
+// restoreAllocations reads the allocations from the ConfigMap, the allocations of the addresses
+// which are no longer in the pool are dropped
+func (pool *VirtualAddressPool) restoreAllocations() {
+	if pool.configMap == "" || pool.kubeClient == nil {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(pool.configMap)
+	if err != nil || namespace == "" {
+		log.Errorf("Invalid virtual address pool ConfigMap %v, should be namespace/name", pool.configMap)
+		return
+	}
+	cm, err := pool.kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Warningf("Unable to read the virtual address pool allocations from ConfigMap %v: %v", pool.configMap, err)
+		}
+		return
+	}
+	allocations := make(map[string]string)
+	if data, ok := cm.Data[VirtualAddressPoolAllocationsKey]; ok {
+		if err = json.Unmarshal([]byte(data), &allocations); err != nil {
+			log.Errorf("Invalid virtual address pool allocations in ConfigMap %v: %v", pool.configMap, err)
+			return
+		}
+	}
+	index := make(map[string]int, len(pool.addresses))
+	for i, ip := range pool.addresses {
+		index[ip] = i
+	}
+	last := -1
+	for key, ip := range allocations {
+		i, ok := index[ip]
+		if !ok {
+			log.Warningf("Dropping the allocation of %v to %v as the address is not in the virtual address pool", ip, key)
+			continue
+		}
+		pool.allocations[key] = ip
+		if i > last {
+			last = i
+		}
+	}
+	// continue the round-robin after the last address of the pool in use
+	pool.next = (last + 1) % len(pool.addresses)
+	log.Infof("Restored %v virtual address pool allocations from ConfigMap %v", len(pool.allocations), pool.configMap)
+}
+
+// saveAllocations writes the allocations to the ConfigMap, the caller must hold the pool lock
+func (pool *VirtualAddressPool) saveAllocations() {
+	if pool.configMap == "" || pool.kubeClient == nil {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(pool.configMap)
+	if err != nil || namespace == "" {
+		log.Errorf("Invalid virtual address pool ConfigMap %v, should be namespace/name", pool.configMap)
+		return
+	}
+	data, err := json.Marshal(pool.allocations)
+	if err != nil {
+		log.Errorf("Unable to marshal the virtual address pool allocations: %v", err)
+		return
+	}
+	cmClient := pool.kubeClient.CoreV1().ConfigMaps(namespace)
+	cm, err := cmClient.Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string]string{VirtualAddressPoolAllocationsKey: string(data)},
+		}
+		_, err = cmClient.Create(context.TODO(), cm, metav1.CreateOptions{})
+	} else if err == nil {
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[VirtualAddressPoolAllocationsKey] = string(data)
+		_, err = cmClient.Update(context.TODO(), cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		log.Errorf("Unable to save the virtual address pool allocations to ConfigMap %v: %v", pool.configMap, err)
+	}
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/test"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Virtual Address Pool", func() {
+	It("Expand the pool CIDRs", func() {
+		addresses, err := getCIDRAddresses("10.8.3.0/30")
+		Expect(err).To(BeNil())
+		Expect(addresses).To(Equal([]string{"10.8.3.1", "10.8.3.2"}))
+		addresses, err = getCIDRAddresses("10.8.3.7/32")
+		Expect(err).To(BeNil())
+		Expect(addresses).To(Equal([]string{"10.8.3.7"}))
+		_, err = getCIDRAddresses("10.8.3.0")
+		Expect(err).NotTo(BeNil(), "Invalid CIDR accepted")
+		_, err = getCIDRAddresses("10.0.0.0/8")
+		Expect(err).NotTo(BeNil(), "Large CIDR accepted")
+	})
+
+	It("Allocate and release addresses in round-robin order", func() {
+		kubeClient := k8sfake.NewSimpleClientset()
+		pool, err := NewVirtualAddressPool([]string{"10.8.3.0/30", "10.8.4.10/32"}, "default/vip-pool", kubeClient)
+		Expect(err).To(BeNil())
+
+		ip, err := pool.allocate("default/foo.com_host")
+		Expect(err).To(BeNil())
+		Expect(ip).To(Equal("10.8.3.1"))
+		ip, _ = pool.allocate("default/foo.com_host")
+		Expect(ip).To(Equal("10.8.3.1"), "Allocation not reused")
+		ip, _ = pool.allocate("default/bar.com_host")
+		Expect(ip).To(Equal("10.8.3.2"))
+		Expect(pool.release("default/foo.com_host")).To(Equal("10.8.3.1"))
+		Expect(pool.release("default/foo.com_host")).To(BeEmpty())
+		// released addresses are reused only after the rest of the pool
+		ip, _ = pool.allocate("default/baz.com_host")
+		Expect(ip).To(Equal("10.8.4.10"))
+		ip, _ = pool.allocate("default/qux.com_host")
+		Expect(ip).To(Equal("10.8.3.1"))
+		_, err = pool.allocate("default/quux.com_host")
+		Expect(err).NotTo(BeNil(), "Allocated from an exhausted pool")
+
+		// allocations are restored from the ConfigMap
+		cm, err := kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "vip-pool", metav1.GetOptions{})
+		Expect(err).To(BeNil())
+		Expect(cm.Data).To(HaveKey(VirtualAddressPoolAllocationsKey))
+		restored, err := NewVirtualAddressPool([]string{"10.8.3.0/30", "10.8.4.10/32"}, "default/vip-pool", kubeClient)
+		Expect(err).To(BeNil())
+		Expect(restored.allocations).To(Equal(pool.allocations))
+		Expect(restored.release("default/bar.com_host")).To(Equal("10.8.3.2"))
+		ip, _ = restored.allocate("default/quux.com_host")
+		Expect(ip).To(Equal("10.8.3.2"))
+	})
+
+	It("Release the address of a VirtualServer switched to an explicit address", func() {
+		mockCtlr := newMockController()
+		mockCtlr.vipPool, _ = NewVirtualAddressPool([]string{"10.8.3.0/30"}, "", nil)
+		vs1 := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{Host: "foo.com"})
+		vs2 := test.NewVirtualServer("vs2", "default", cisapiv1.VirtualServerSpec{Host: "foo.com"})
+		ip, _ := mockCtlr.vipPool.allocate(getVirtualAddressPoolKey(vs1))
+		Expect(ip).To(Equal("10.8.3.1"))
+
+		vs1.Spec.VirtualServerAddress = "10.1.1.1"
+		mockCtlr.releaseExplicitVirtualAddress(vs1, []*cisapiv1.VirtualServer{vs1, vs2})
+		Expect(mockCtlr.vipPool.allocations).To(HaveKey("default/foo.com_host"),
+			"Address released while another virtual of the host takes it from the pool")
+		vs2.Spec.VirtualServerAddress = "10.1.1.1"
+		mockCtlr.releaseExplicitVirtualAddress(vs1, []*cisapiv1.VirtualServer{vs1, vs2})
+		Expect(mockCtlr.vipPool.allocations).To(BeEmpty(), "Address of the explicit virtuals not released")
+	})
+})
@@ -0,0 +1,162 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	cisv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/prometheus"
+	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/statusmanager"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AS3ErrorCode classifies the failure of an AS3 post
+type AS3ErrorCode string
+
+const (
+	ErrSchemaMismatch      AS3ErrorCode = "SchemaMismatch"
+	ErrNetworkTimeout      AS3ErrorCode = "NetworkTimeout"
+	ErrUnprocessableEntity AS3ErrorCode = "UnprocessableEntity"
+	ErrServiceUnavailable  AS3ErrorCode = "ServiceUnavailable"
+	ErrDeclarationTooLarge AS3ErrorCode = "DeclarationTooLarge"
+	ErrInvalidTenant       AS3ErrorCode = "InvalidTenant"
+	ErrConnectionFailed    AS3ErrorCode = "ConnectionFailed"
+	ErrUnknownResponse     AS3ErrorCode = "UnknownResponse"
+	ErrCircuitOpen         AS3ErrorCode = "CircuitOpen"
+)
+
+// AS3Error is the error of an AS3 post, the tenant is empty when the error applies to the whole declaration
+// and RetryAfter is set when the declaration is posted again after the given duration
+type AS3Error struct {
+	Code       AS3ErrorCode
+	Tenant     string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *AS3Error) Error() string {
+	var msg strings.Builder
+	msg.WriteString(string(e.Code))
+	if e.Tenant != "" {
+		msg.WriteString(fmt.Sprintf(" (tenant %v)", e.Tenant))
+	}
+	msg.WriteString(": " + e.Message)
+	if e.RetryAfter > 0 {
+		msg.WriteString(fmt.Sprintf(", retrying after %v", e.RetryAfter))
+	}
+	return msg.String()
+}
+
+// newAS3Error returns the AS3Error of the response with the given http code
+func newAS3Error(httpCode int, tenant string, message string) *AS3Error {
+	return &AS3Error{
+		Code:    classifyAS3Error(httpCode, message),
+		Tenant:  tenant,
+		Message: message,
+	}
+}
+
+// classifyAS3Error returns the error code of an AS3 response, AS3 reports an unsupported schemaVersion of the
+// declaration with a 422, which is classified as a schema mismatch as it's fixed by upgrading AS3 on BIG-IP
+func classifyAS3Error(httpCode int, message string) AS3ErrorCode {
+	switch {
+	case httpCode == http.StatusRequestEntityTooLarge:
+		return ErrDeclarationTooLarge
+	case httpCode == http.StatusServiceUnavailable:
+		return ErrServiceUnavailable
+	case httpCode == http.StatusRequestTimeout || httpCode == http.StatusGatewayTimeout:
+		return ErrNetworkTimeout
+	case strings.Contains(message, "schemaVersion"):
+		return ErrSchemaMismatch
+	case httpCode == http.StatusUnprocessableEntity:
+		return ErrUnprocessableEntity
+	default:
+		return ErrUnknownResponse
+	}
+}
+
+// classifyRequestError returns the AS3Error of a post, which didn't get a response from BIG-IP
+func classifyRequestError(err error) *AS3Error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &AS3Error{Code: ErrNetworkTimeout, Message: err.Error(), RetryAfter: timeoutMedium}
+	}
+	return &AS3Error{Code: ErrConnectionFailed, Message: err.Error()}
+}
+
+// reportAS3Error counts the error and reports it in the AS3 status of the BIG-IP
+func (postMgr *PostManager) reportAS3Error(cfg *as3Config, httpCode int, as3Err *AS3Error) {
+	prometheus.AS3Errors.WithLabelValues(postMgr.bigIpAddress, string(as3Err.Code)).Inc()
+	log.Errorf("%v[AS3]%v %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, as3Err)
+	message := http.StatusText(httpCode)
+	if message == "" {
+		message = string(as3Err.Code)
+	}
+	postMgr.tokenManager.StatusManager.AddRequest(statusmanager.DeployConfig, "", "", false,
+		&cisv1.BigIPStatus{
+			BigIPAddress: cfg.targetAddress,
+			AS3Status: &cisv1.AS3Status{
+				Message:       message,
+				Error:         as3Err.Error(),
+				LastSubmitted: metav1.Now(),
+			},
+		})
+}
+
+// checkDeclarationSize returns the error of a declaration larger than the size limit, which BIG-IP rejects,
+// the tenants of the declaration are logged with their size, largest first
+func (postMgr *PostManager) checkDeclarationSize(cfg *as3Config) *AS3Error {
+	if postMgr.MaxDeclarationSize <= 0 || len(cfg.data) <= postMgr.MaxDeclarationSize {
+		return nil
+	}
+	type tenantSize struct {
+		tenant string
+		size   int
+	}
+	var sizes []tenantSize
+	for tenant, decl := range cfg.incomingTenantDeclMap {
+		data, _ := json.Marshal(decl)
+		sizes = append(sizes, tenantSize{tenant, len(data)})
+	}
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].size != sizes[j].size {
+			return sizes[i].size > sizes[j].size
+		}
+		return sizes[i].tenant < sizes[j].tenant
+	})
+	tenants := make([]string, 0, len(sizes))
+	for _, ts := range sizes {
+		tenants = append(tenants, fmt.Sprintf("%v=%d", ts.tenant, ts.size))
+	}
+	log.Errorf("%v[AS3]%v declaration size %d bytes exceeds the limit of %d bytes, tenant sizes in bytes: %v",
+		getRequestPrefix(cfg.id), postMgr.postManagerPrefix, len(cfg.data), postMgr.MaxDeclarationSize,
+		strings.Join(tenants, ", "))
+	return &AS3Error{
+		Code: ErrDeclarationTooLarge,
+		Message: fmt.Sprintf("declaration of %d bytes exceeds the limit of %d bytes", len(cfg.data),
+			postMgr.MaxDeclarationSize),
+	}
+}
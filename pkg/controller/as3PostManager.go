@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
@@ -38,10 +40,75 @@ func (postMgr *AS3PostManager) createAS3Declaration(tenantDeclMap map[string]as3
 	if err != nil {
 		log.Debugf("[AS3] Unified declaration: %v\n", err)
 	}
+	// the declaration is pinned as a whole, AS3 objects don't have a schemaVersion of their own
+	if version := postMgr.getPinnedSchemaVersion(decl); version != "" {
+		adc["schemaVersion"] = version
+		if pinned, err := json.Marshal(as3Config); err == nil {
+			decl = pinned
+		}
+	}
 
 	return as3Declaration(decl)
 }
 
+// getPinnedSchemaVersion returns the lowest schema version the classes of the declaration objects are pinned to,
+// when it is lower than the AS3 schema version on BIG-IP, pinning is skipped until that version is known
+func (postMgr *AS3PostManager) getPinnedSchemaVersion(decl []byte) string {
+	currentVersion := postMgr.AS3VersionInfo.as3SchemaVersion
+	if len(postMgr.classVersions) == 0 || currentVersion == "" {
+		return ""
+	}
+	var obj interface{}
+	if err := json.Unmarshal(decl, &obj); err != nil {
+		return ""
+	}
+	pinnedVersion := ""
+	var find func(interface{})
+	find = func(v interface{}) {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if class, ok := val["class"].(string); ok {
+				if version, ok := postMgr.classVersions[class]; ok &&
+					(pinnedVersion == "" || compareAS3Versions(version, pinnedVersion) < 0) {
+					pinnedVersion = version
+				}
+			}
+			for _, child := range val {
+				find(child)
+			}
+		case []interface{}:
+			for _, child := range val {
+				find(child)
+			}
+		}
+	}
+	find(obj)
+	if pinnedVersion == "" || compareAS3Versions(currentVersion, pinnedVersion) <= 0 {
+		return ""
+	}
+	return pinnedVersion
+}
+
+// compareAS3Versions compares the dotted AS3 versions, it returns a negative number, zero or a positive number
+// when the first version is lower than, equal to or higher than the second one
+func compareAS3Versions(first, second string) int {
+	firstParts := strings.Split(first, ".")
+	secondParts := strings.Split(second, ".")
+	for i := 0; i < len(firstParts) || i < len(secondParts); i++ {
+		var a, b int
+		if i < len(firstParts) {
+			a, _ = strconv.Atoi(firstParts[i])
+		}
+		if i < len(secondParts) {
+			b, _ = strconv.Atoi(secondParts[i])
+		}
+		if a != b {
+			return a - b
+		}
+	}
+	return 0
+}
+
 func getDeletedTenantDeclaration(cisLabel string) as3Tenant {
 	return as3Tenant{
 		"class": "Tenant",
@@ -115,6 +182,12 @@ func processResourcesForAS3(cfg *ResourceConfig, app as3Application, shareNodes
 
 	switch cfg.MetaData.ResourceType {
 	case VirtualServer:
+		//Create AS3 address lists, port lists and firewall policy for virtual server
+		createAccessListDecl(cfg, app)
+		//Create AS3 NAT policy for virtual server
+		createNATPolicyDecl(cfg, app)
+		//Create AS3 SNAT pool for virtual server
+		createSNATPoolDecl(cfg, app)
 		//Create AS3 Service for virtual server
 		createServiceDecl(cfg, app, tenant)
 	case TransportServer:
@@ -124,9 +197,196 @@ func processResourcesForAS3(cfg *ResourceConfig, app as3Application, shareNodes
 
 }
 
+// Create AS3 Net_Address_List, Net_Port_List and the firewall policy enforcing them for CRD
+func createAccessListDecl(cfg *ResourceConfig, app as3Application) {
+	if len(cfg.Virtual.AddressLists) == 0 && len(cfg.Virtual.PortLists) == 0 {
+		return
+	}
+	if cfg.Virtual.Firewall != "" {
+		log.Warningf("[AS3] virtualServer: %v, address and port lists are ignored as firewall policy %v is already configured",
+			cfg.Virtual.Name, cfg.Virtual.Firewall)
+		return
+	}
+	// traffic is allowed only to the ports of the port lists
+	var destination *as3FirewallRuleDestination
+	for _, prt := range cfg.Virtual.PortLists {
+		prtName := AS3NameFormatter(prt.Name) + "_port_list"
+		portList := &as3NetPortList{Class: "Net_Port_List"}
+		for _, port := range prt.Ports {
+			portList.Ports = append(portList.Ports, port)
+		}
+		for _, portRange := range prt.PortRanges {
+			portList.Ports = append(portList.Ports, portRange)
+		}
+		app[prtName] = portList
+		if destination == nil {
+			destination = &as3FirewallRuleDestination{}
+		}
+		destination.PortLists = append(destination.PortLists, as3ResourcePointer{Use: prtName})
+	}
+	ruleList := &as3FirewallRuleList{Class: "Firewall_Rule_List"}
+	allowList := destination != nil
+	var allowAddressList bool
+	for _, adl := range cfg.Virtual.AddressLists {
+		adlName := AS3NameFormatter(adl.Name) + "_address_list"
+		app[adlName] = &as3NetAddressList{
+			Class:     "Net_Address_List",
+			Addresses: adl.Addresses,
+		}
+		rule := as3FirewallRule{
+			Name:     AS3NameFormatter(adl.Name),
+			Protocol: "any",
+			Source: &as3FirewallRuleSource{
+				AddressLists: []as3ResourcePointer{{Use: adlName}},
+			},
+		}
+		if adl.Action == AddressListAllow {
+			rule.Action = "accept"
+			rule.Destination = destination
+			allowList = true
+			allowAddressList = true
+		} else {
+			rule.Action = "drop"
+		}
+		ruleList.Rules = append(ruleList.Rules, rule)
+	}
+	// accept the traffic to the port lists from any source when no allow address list is present
+	if destination != nil && !allowAddressList {
+		ruleList.Rules = append(ruleList.Rules, as3FirewallRule{
+			Name:        "allow_port_lists",
+			Action:      "accept",
+			Protocol:    "any",
+			Destination: destination,
+		})
+	}
+	// drop the traffic not matching any of the allow lists
+	if allowList {
+		ruleList.Rules = append(ruleList.Rules, as3FirewallRule{
+			Name:     "default_deny",
+			Action:   "drop",
+			Protocol: "any",
+		})
+	}
+	policyName := getAccessListFirewallPolicyName(cfg.Virtual.Name)
+	app[policyName+"_rules"] = ruleList
+	app[policyName] = &as3FirewallPolicy{
+		Class: "Firewall_Policy",
+		Rules: []as3ResourcePointer{{Use: policyName + "_rules"}},
+	}
+}
+
+// Create AS3 ICAP_Profile, the pool of the ICAP server and the internal virtual forwarding to it, and the
+// Adapt_Profile sending the HTTP requests of the virtual to the internal virtual, returns the Adapt_Profile name
+func createICAPDecl(cfg *ResourceConfig, app as3Application) string {
+	icap := cfg.Virtual.ICAP
+	prefix := cfg.Virtual.Name + "_icap"
+	app[prefix+"_pool"] = &as3Pool{
+		Class: "Pool",
+		Members: []as3PoolMember{{
+			ServerAddresses: []string{icap.Address},
+			ServicePort:     icap.Port,
+		}},
+	}
+	app[prefix+"_profile"] = &as3ICAPProfile{
+		Class: "ICAP_Profile",
+		URI:   "icap://${SERVER_IP}:${SERVER_PORT}/reqmod",
+	}
+	app[prefix+"_service"] = &as3InternalService{
+		Class:       "Service_TCP",
+		VirtualType: "internal",
+		Pool:        prefix + "_pool",
+		ProfileICAP: &as3ResourcePointer{Use: prefix + "_profile"},
+	}
+	adaptName := prefix + "_request_adapt"
+	app[adaptName] = &as3AdaptProfile{
+		Class:             "Adapt_Profile",
+		MessageType:       "request",
+		InternalService:   as3ResourcePointer{Use: prefix + "_service"},
+		AllowHTTP10:       icap.AllowHTTP10,
+		ServiceDownAction: icap.ServiceDownAction,
+	}
+	return adaptName
+}
+
+// Create AS3 NAT_Policy with the inline NAT_Rules and the NAT_Source_Translations of its rules for CRD
+func createNATPolicyDecl(cfg *ResourceConfig, app as3Application) {
+	nat := cfg.Virtual.NATPolicy
+	if nat == nil {
+		return
+	}
+	policyName := getNATPolicyName(cfg.Virtual.Name)
+	policy := &as3NATPolicy{Class: "NAT_Policy"}
+	for _, rule := range nat.Rules {
+		ruleName := policyName + "_" + AS3NameFormatter(rule.Name)
+		natRule := as3NATRule{
+			Name:              AS3NameFormatter(rule.Name),
+			Protocol:          rule.Protocol,
+			SourceTranslation: &as3ResourcePointer{Use: ruleName + "_translation"},
+		}
+		// the address lists of the NAT rules must be firewall address lists
+		if rule.Source != "" {
+			app[ruleName+"_source"] = &as3NetAddressList{Class: "Firewall_Address_List", Addresses: []string{rule.Source}}
+			natRule.Source = &as3FirewallRuleSource{AddressLists: []as3ResourcePointer{{Use: ruleName + "_source"}}}
+		}
+		if rule.Destination != "" {
+			app[ruleName+"_destination"] = &as3NetAddressList{Class: "Firewall_Address_List",
+				Addresses: []string{rule.Destination}}
+			natRule.Destination = &as3FirewallRuleDestination{
+				AddressLists: []as3ResourcePointer{{Use: ruleName + "_destination"}}}
+		}
+		app[ruleName+"_translation"] = &as3NATSourceTranslation{
+			Class:     "NAT_Source_Translation",
+			Type:      "static-nat",
+			Addresses: []string{rule.Translation},
+		}
+		policy.Rules = append(policy.Rules, natRule)
+	}
+	app[policyName] = policy
+}
+
+// Create AS3 SNAT_Pool with the addresses of the SNATPool for CRD
+func createSNATPoolDecl(cfg *ResourceConfig, app as3Application) {
+	if cfg.Virtual.SNATPool == nil {
+		return
+	}
+	app[getSNATPoolName(cfg.Virtual.Name)] = &as3SNATPool{
+		Class:         "SNAT_Pool",
+		SNATAddresses: cfg.Virtual.SNATPool.Addresses,
+	}
+}
+
+// getSNATPoolName returns the name of SNAT pool created for the SNATPool of the virtual
+func getSNATPoolName(vsName string) string {
+	return vsName + "_snat_pool"
+}
+
+// getNATPolicyName returns the name of NAT policy created for the NATPolicy of the virtual
+func getNATPolicyName(vsName string) string {
+	return vsName + "_nat_policy"
+}
+
+// getEndpointStrategyName returns the name of Endpoint_Strategy created for the endpoint policies of the virtual
+func getEndpointStrategyName(vsName string) string {
+	return vsName + "_endpoint_strategy"
+}
+
+// getAccessListFirewallPolicyName returns the name of firewall policy created for the address and port lists
+func getAccessListFirewallPolicyName(vsName string) string {
+	return vsName + "_fw_policy"
+}
+
 // Create policy declaration
 func createPoliciesDecl(cfg *ResourceConfig, app as3Application) {
 	_, port := extractVirtualAddressAndPort(cfg.Virtual.Destination)
+	var customStrategy string
+	if cfg.Virtual.EndpointStrategy != nil && len(cfg.Policies) > 0 {
+		customStrategy = getEndpointStrategyName(cfg.Virtual.Name)
+		app[customStrategy] = &as3EndpointStrategy{
+			Class:       "Endpoint_Strategy",
+			MatchMethod: cfg.Virtual.EndpointStrategy.MatchMethod,
+			Operands:    cfg.Virtual.EndpointStrategy.Operands,
+		}
+	}
 	for _, pl := range cfg.Policies {
 		//Create EndpointPolicy
 		ep := &as3EndpointPolicy{}
@@ -147,6 +407,10 @@ func createPoliciesDecl(cfg *ResourceConfig, app as3Application) {
 
 			ep.Rules = append(ep.Rules, rulesData)
 		}
+		if customStrategy != "" {
+			ep.Strategy = "custom"
+			ep.CustomStrategy = customStrategy
+		}
 		//Setting Endpoint_Policy Name
 		app[pl.Name] = ep
 	}
@@ -192,6 +456,9 @@ func createPoolDecl(cfg *ResourceConfig, app as3Application, shareNodes bool, te
 			if val.ConnectionLimit != 0 {
 				member.ConnectionLimit = val.ConnectionLimit
 			}
+			if val.Ratio > 0 {
+				member.Ratio = val.Ratio
+			}
 			pool.Members = append(pool.Members, member)
 		}
 		for _, val := range v.MonitorNames {
@@ -310,7 +577,27 @@ func createServiceDecl(cfg *ResourceConfig, app as3Application, tenant string) {
 		svc.Pool = &poolPointer
 	}
 
-	if cfg.Virtual.TLSTermination != TLSPassthrough {
+	if cfg.Virtual.Protocol == TCP {
+		svc.Layer4 = TCP
+		svc.Class = "Service_TCP"
+	} else if cfg.Virtual.Protocol == UDP {
+		svc.Layer4 = UDP
+		svc.Class = "Service_UDP"
+	} else if cfg.Virtual.Protocol == SCTP {
+		svc.Layer4 = SCTP
+		svc.Class = "Service_SCTP"
+		svc.ProfileSCTP = &as3ResourcePointer{BigIP: DefaultSCTPProfile}
+		if sctpProfile := newBigIPSCTPProfile(cfg.Virtual.SCTP); sctpProfile != nil {
+			svc.ProfileSCTP.BigIP = JoinBigipPath(sctpProfile.Partition, sctpProfile.Name)
+			svc.sctpProfile = sctpProfile
+		}
+	} else if cfg.Virtual.Protocol == ProtocolOther {
+		svc.Layer4 = cfg.Virtual.IpProtocol
+		svc.Class = "Service_Generic"
+		svc.ProfileIPOther = &as3ResourcePointer{
+			BigIP: DefaultIPOtherProfile,
+		}
+	} else if cfg.Virtual.TLSTermination != TLSPassthrough {
 		svc.Layer4 = cfg.Virtual.IpProtocol
 		svc.Class = "Service_HTTP"
 	} else {
@@ -320,7 +607,74 @@ func createServiceDecl(cfg *ResourceConfig, app as3Application, tenant string) {
 		svc.Class = "Service_TCP"
 	}
 
-	svc.addPersistenceMethod(cfg.Virtual.PersistenceProfile)
+	// SIP virtual is a TCP or UDP service with the SIP profile attached
+	if cfg.Virtual.SIP {
+		sipProfile := cfg.Virtual.Name + "_sip_profile"
+		app[sipProfile] = &as3SIPProfile{Class: "SIP_Profile"}
+		svc.ProfileSIP = &as3ResourcePointer{Use: sipProfile}
+	}
+
+	// Attaching RADIUS profile
+	if radius := cfg.Virtual.Radius; radius != nil {
+		radiusProfile := cfg.Virtual.Name + "_radius_profile"
+		profile := &as3RadiusProfile{
+			Class:                      "Radius_Profile",
+			SubscriberDiscoveryEnabled: radius.SubscriberDiscoveryEnabled,
+		}
+		// the attributes are declared by their RFC 2865 name or their numeric code
+		if code, err := strconv.Atoi(radius.PersistAttribute); err == nil {
+			profile.PersistAttribute = code
+		} else if radius.PersistAttribute != "" {
+			profile.PersistAttribute = radius.PersistAttribute
+		}
+		app[radiusProfile] = profile
+		svc.ProfileRadius = &as3ResourcePointer{Use: radiusProfile}
+	}
+
+	// Attaching HTTP compression profile
+	if compression := cfg.Virtual.HTTPCompression; compression != nil {
+		compressionProfile := cfg.Virtual.Name + "_http_compression_profile"
+		as3Compression := &as3HTTPCompressProfile{
+			Class:               "HTTP_Compress",
+			PreferMethod:        "gzip",
+			ContentTypeIncludes: compression.ContentTypeInclude,
+			MinimumSize:         compression.MinimumSize,
+		}
+		if !compression.Gzip {
+			as3Compression.PreferMethod = "deflate"
+		}
+		app[compressionProfile] = as3Compression
+		svc.ProfileHTTPCompression = &as3ResourcePointer{Use: compressionProfile}
+	}
+
+	// Attaching security log profile logging to the Log Publisher
+	if cfg.Virtual.LogPublisher != "" {
+		logProfile := cfg.Virtual.Name + "_security_log_profile"
+		app[logProfile] = &as3SecurityLogProfile{
+			Class: "Security_Log_Profile",
+			Network: &as3SecurityLogProfileNetwork{
+				Publisher:           as3ResourcePointer{BigIP: cfg.Virtual.LogPublisher},
+				LogRuleMatchAccepts: true,
+				LogRuleMatchRejects: true,
+				LogRuleMatchDrops:   true,
+				LogIpErrors:         true,
+			},
+		}
+		svc.LogProfiles = append(svc.LogProfiles, as3ResourcePointer{Use: logProfile})
+	}
+
+	// MQTT virtual is a TCP service with the MQTT protocol enabled
+	if cfg.Virtual.MQTT {
+		svc.MQTTEnabled = true
+	}
+
+	// SIP persists the dialogs of UDP virtuals as well
+	if cfg.Virtual.Protocol == UDP && !cfg.Virtual.SIP && len(cfg.Virtual.PersistenceProfile) > 0 {
+		log.Warningf("[AS3] virtualServer: %v, persistence profile %v is ignored for UDP virtual", cfg.Virtual.Name,
+			cfg.Virtual.PersistenceProfile)
+	} else {
+		svc.addPersistenceMethod(cfg.Virtual.PersistenceProfile)
+	}
 
 	if len(cfg.Virtual.ProfileDOS) > 0 {
 		log.Warningf("[AS3] virtualServer: %v, ProfileDOS feature is not supported with BIG-IP Next", cfg.Virtual.Name)
@@ -359,7 +713,8 @@ func createServiceDecl(cfg *ResourceConfig, app as3Application, tenant string) {
 		}
 	}
 
-	if len(cfg.Virtual.TCP.Client) > 0 || len(cfg.Virtual.TCP.Server) > 0 {
+	if cfg.Virtual.Protocol != UDP && cfg.Virtual.Protocol != ProtocolOther &&
+		(len(cfg.Virtual.TCP.Client) > 0 || len(cfg.Virtual.TCP.Server) > 0) {
 		if cfg.Virtual.TCP.Client == "" {
 			log.Errorf("[AS3] resetting ProfileTCP as client profile doesnt co-exist with TCP Server Profile, Please include client TCP Profile ")
 		}
@@ -403,6 +758,11 @@ func createServiceDecl(cfg *ResourceConfig, app as3Application, tenant string) {
 		_, name := getPartitionAndName(profile.Name)
 		switch profile.Context {
 		case "http":
+			if isLayer4Protocol(cfg.Virtual.Protocol) {
+				log.Warningf("[AS3] virtualServer: %v, HTTP profile %v is ignored for %v virtual", cfg.Virtual.Name,
+					profile.Name, cfg.Virtual.Protocol)
+				continue
+			}
 			if !profile.BigIPProfile {
 				svc.ProfileHTTP = name
 			} else {
@@ -410,6 +770,74 @@ func createServiceDecl(cfg *ResourceConfig, app as3Application, tenant string) {
 					BigIP: fmt.Sprintf("%v", profile.Name),
 				}
 			}
+		case "udp":
+			if cfg.Virtual.Protocol != UDP {
+				continue
+			}
+			if !profile.BigIPProfile {
+				svc.ProfileUDP = name
+			} else {
+				svc.ProfileUDP = &as3ResourcePointer{
+					BigIP: fmt.Sprintf("%v", profile.Name),
+				}
+			}
+		}
+	}
+
+	// Attaching rate limit configuration
+	if cfg.Virtual.RateLimit.Rate > 0 {
+		createRateLimitDecl(cfg, app, svc)
+	}
+
+	// Attaching URL rewrite profile
+	if cfg.Virtual.Rewrite != (RewriteConfig{}) {
+		createRewriteProfileDecl(cfg, app, svc)
+	}
+
+	// Attaching stream profile
+	if cfg.Virtual.Stream.Profile != "" {
+		createStreamProfileDecl(cfg, app, svc)
+	}
+
+	// Attaching connection multiplexing profile
+	if cfg.Virtual.Multiplex != nil {
+		createMultiplexProfileDecl(cfg, app, svc)
+	}
+
+	// Attaching traffic classification profile
+	if cfg.Virtual.ProfileClassification != "" {
+		svc.ProfileClassification = &as3ResourcePointer{
+			BigIP: cfg.Virtual.ProfileClassification,
+		}
+	}
+
+	// Attaching request and response adapt profiles
+	if cfg.Virtual.ProfileRequestAdapt != "" {
+		svc.ProfileRequestAdapt = &as3ResourcePointer{
+			BigIP: cfg.Virtual.ProfileRequestAdapt,
+		}
+	}
+	if cfg.Virtual.ProfileResponseAdapt != "" {
+		svc.ProfileResponseAdapt = &as3ResourcePointer{
+			BigIP: cfg.Virtual.ProfileResponseAdapt,
+		}
+	}
+
+	// Attaching the request adapt profile forwarding the HTTP requests to the ICAP server
+	if cfg.Virtual.ICAP != nil && cfg.Virtual.ProfileRequestAdapt == "" {
+		svc.ProfileRequestAdapt = &as3ResourcePointer{Use: createICAPDecl(cfg, app)}
+	}
+
+	// Attaching WebSocket profile
+	if ws := cfg.Virtual.WebSocket; ws != nil {
+		profileName := cfg.Virtual.Name + "_websocket"
+		app[profileName] = &as3WebSocketProfile{
+			Class:       "WebSocket_Profile",
+			Compression: ws.Compression,
+			Masking:     ws.Masking,
+		}
+		svc.ProfileWebSocket = &as3ResourcePointer{
+			Use: profileName,
 		}
 	}
 
@@ -452,6 +880,10 @@ func createServiceDecl(cfg *ResourceConfig, app as3Application, tenant string) {
 			}
 			svc.VirtualPort = port
 		}
+		// other IP protocols have no ports, so the virtual listens on any port
+		if cfg.Virtual.Protocol == ProtocolOther {
+			svc.VirtualPort = 0
+		}
 	}
 	if cfg.Virtual.HttpMrfRoutingEnabled != nil {
 		//set HttpMrfRoutingEnabled
@@ -472,9 +904,37 @@ func createServiceDecl(cfg *ResourceConfig, app as3Application, tenant string) {
 		log.Warningf("[AS3] virtualServer: %v, ProfileWebSocket feature is not supported with BIG-IP Next", cfg.Virtual.Name)
 	}
 	processCommonDecl(cfg, svc)
+	// Attaching HTTP profile and iRule for X-Forwarded-For header setting, after the iRules of the virtual
+	if cfg.Virtual.XFFInsert != "" {
+		createXFFHTTPProfileDecl(cfg, app, svc)
+	}
+	if cfg.Virtual.Forwarding != nil {
+		svc = createForwardingServiceDecl(cfg, svc)
+	}
 	app[cfg.Virtual.Name] = svc
 }
 
+// createForwardingServiceDecl returns the Service_Forwarding of a forwarding virtual, which keeps only the
+// listener, the address translation and the security settings of the service as it doesn't terminate the
+// connections, so the pool, the persistence and the L7 profiles are not supported
+func createForwardingServiceDecl(cfg *ResourceConfig, svc *as3Service) *as3Service {
+	fwdSvc := &as3Service{
+		Class:            "Service_Forwarding",
+		Layer4:           "any",
+		ForwardingType:   cfg.Virtual.Forwarding.Type,
+		VirtualAddresses: svc.VirtualAddresses,
+		VirtualPort:      svc.VirtualPort,
+		SNAT:             svc.SNAT,
+		Firewall:         svc.Firewall,
+		LogProfiles:      svc.LogProfiles,
+		PolicyNAT:        svc.PolicyNAT,
+	}
+	if cfg.Virtual.Forwarding.Mirroring {
+		fwdSvc.Mirroring = "L4"
+	}
+	return fwdSvc
+}
+
 // Create AS3 Service Address for Virtual Server Address
 func createServiceAddressDecl(cfg *ResourceConfig, virtualAddress string, app as3Application) string {
 	var name string
@@ -556,6 +1016,19 @@ func createRuleCondition(rl *Rule, rulesData *as3Rule, port int) {
 			if c.Equals {
 				condition.Path.Operand = "equals"
 			}
+		} else if c.SSLExtensionClient {
+			// The server name of the TLS client hello is matched before the TLS handshake is terminated
+			condition.Type = "sslExtension"
+			condition.Event = "ssl-client-hello"
+			condition.ServerName = &as3PolicyCompareString{
+				Values: c.Values,
+			}
+			if c.Equals {
+				condition.ServerName.Operand = "equals"
+			}
+			if c.EndsWith {
+				condition.ServerName.Operand = "ends-with"
+			}
 		} else if c.Tcp {
 			if c.Address && len(c.Values) > 0 {
 				condition.Type = "tcp"
@@ -585,6 +1058,9 @@ func createRuleAction(rl *Rule, rulesData *as3Rule) {
 		if v.Request {
 			action.Event = "request"
 		}
+		if v.SSLClientHello {
+			action.Event = "ssl-client-hello"
+		}
 		if v.Redirect {
 			action.Type = "httpRedirect"
 		}
@@ -645,6 +1121,7 @@ func createRuleAction(rl *Rule, rulesData *as3Rule) {
 	}
 }
 
+// DeepEqualJSON compares two declarations semantically, ignoring the key order and the whitespace
 func DeepEqualJSON(decl1, decl2 as3Declaration) bool {
 	if decl1 == "" && decl2 == "" {
 		return true
@@ -664,6 +1141,98 @@ func DeepEqualJSON(decl1, decl2 as3Declaration) bool {
 	return reflect.DeepEqual(o1, o2)
 }
 
+// DiffAS3Declarations returns the tenants added, removed or modified by the new declaration,
+// with the keys changed in the modified tenants
+func DiffAS3Declarations(old, new as3Declaration) []TenantDiff {
+	oldTenants := declarationTenants(old)
+	newTenants := declarationTenants(new)
+	var diffs []TenantDiff
+	for tenant, after := range newTenants {
+		before, ok := oldTenants[tenant]
+		if !ok {
+			diffs = append(diffs, TenantDiff{Tenant: tenant, Change: TenantAdded, After: jsonSnippet(after)})
+			continue
+		}
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		diff := TenantDiff{Tenant: tenant, Change: TenantModified}
+		diffJSONKeys("", before, after, &diff.Keys)
+		sort.Strings(diff.Keys)
+		// the snippets hold the applications of the tenant which are changed
+		beforeApps := make(map[string]interface{})
+		afterApps := make(map[string]interface{})
+		for _, key := range diff.Keys {
+			app := strings.Split(key, "/")[0]
+			if value, ok := before[app]; ok {
+				beforeApps[app] = value
+			}
+			if value, ok := after[app]; ok {
+				afterApps[app] = value
+			}
+		}
+		diff.Before = jsonSnippet(beforeApps)
+		diff.After = jsonSnippet(afterApps)
+		diffs = append(diffs, diff)
+	}
+	for tenant, before := range oldTenants {
+		if _, ok := newTenants[tenant]; !ok {
+			diffs = append(diffs, TenantDiff{Tenant: tenant, Change: TenantRemoved, Before: jsonSnippet(before)})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Tenant < diffs[j].Tenant
+	})
+	return diffs
+}
+
+// declarationTenants returns the tenants of the declaration by their name
+func declarationTenants(decl as3Declaration) map[string]map[string]interface{} {
+	tenants := make(map[string]map[string]interface{})
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(decl), &obj); err != nil {
+		return tenants
+	}
+	if adc, ok := obj["declaration"].(map[string]interface{}); ok {
+		obj = adc
+	}
+	for name, value := range obj {
+		if tenant, ok := value.(map[string]interface{}); ok && tenant["class"] == "Tenant" {
+			tenants[name] = tenant
+		}
+	}
+	return tenants
+}
+
+// diffJSONKeys appends the paths of the keys which differ between the objects
+func diffJSONKeys(path string, before, after interface{}, keys *[]string) {
+	beforeObj, ok1 := before.(map[string]interface{})
+	afterObj, ok2 := after.(map[string]interface{})
+	if !ok1 || !ok2 {
+		if !reflect.DeepEqual(before, after) {
+			*keys = append(*keys, strings.TrimSuffix(path, "/"))
+		}
+		return
+	}
+	for key, value := range afterObj {
+		if oldValue, ok := beforeObj[key]; ok {
+			diffJSONKeys(path+key+"/", oldValue, value, keys)
+		} else {
+			*keys = append(*keys, path+key)
+		}
+	}
+	for key := range beforeObj {
+		if _, ok := afterObj[key]; !ok {
+			*keys = append(*keys, path+key)
+		}
+	}
+}
+
+func jsonSnippet(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
 func processProfilesForAS3(cfg *ResourceConfig, app as3Application) {
 	if svc, ok := app[cfg.Virtual.Name].(*as3Service); ok {
 		processTLSProfilesForAS3(&cfg.Virtual, svc, cfg.Virtual.Name)
@@ -752,6 +1321,11 @@ func processCustomProfilesForAS3(rsCfg *ResourceConfig, app as3Application, as3V
 		}
 	}
 
+	for svcName := range svcNameMap {
+		createCertificateValidatorDecl(rsCfg, app, svcName)
+		createCipherGroupDecl(rsCfg, app, svcName)
+	}
+
 	// if AS3 version on bigIP is lower than 3.44 then don't enable sniDefault, as it's only supported from AS3 v3.44 onwards
 	if as3Version < 3.44 {
 		return
@@ -770,6 +1344,278 @@ func processCustomProfilesForAS3(rsCfg *ResourceConfig, app as3Application, as3V
 	}
 }
 
+// processBotDefenseForAS3 attaches the bot defense profile to the virtual if supported by the AS3 version on BIG-IP
+func processBotDefenseForAS3(rsCfg *ResourceConfig, app as3Application, as3Version float64) {
+	if rsCfg.MetaData.ResourceType != VirtualServer || rsCfg.Virtual.ProfileBotDefense == "" {
+		return
+	}
+	svc, ok := app[rsCfg.Virtual.Name].(*as3Service)
+	if !ok {
+		return
+	}
+	// unknown AS3 version is validated against the bundled AS3 schema which supports bot defense
+	if as3Version != 0 && as3Version < BotDefenseMinAS3Version {
+		log.Warningf("[AS3] virtualServer: %v, bot defense profile %v is ignored as it is supported from AS3 v%v onwards",
+			rsCfg.Virtual.Name, rsCfg.Virtual.ProfileBotDefense, BotDefenseMinAS3Version)
+		return
+	}
+	svc.ProfileBotDefense = &as3ResourcePointer{
+		BigIP: rsCfg.Virtual.ProfileBotDefense,
+	}
+}
+
+// processDOSProfileForAS3 attaches the DoS profile to the virtual server, the profile is skipped when the
+// AFM module is not licensed or provisioned on BIG-IP
+func processDOSProfileForAS3(rsCfg *ResourceConfig, app as3Application, afmUnavailable bool) {
+	if rsCfg.MetaData.ResourceType != VirtualServer || (rsCfg.Virtual.ProfileDOS == "" && rsCfg.Virtual.DOS == nil) {
+		return
+	}
+	svc, ok := app[rsCfg.Virtual.Name].(*as3Service)
+	if !ok {
+		return
+	}
+	if afmUnavailable {
+		log.Warningf("[AS3] virtualServer: %v, DoS profile is ignored as AFM module is not licensed or provisioned on BIG-IP",
+			rsCfg.Virtual.Name)
+		return
+	}
+	dos := rsCfg.Virtual.DOS
+	if dos == nil {
+		svc.ProfileDOS = &as3ResourcePointer{
+			BigIP: rsCfg.Virtual.ProfileDOS,
+		}
+		return
+	}
+	app[dos.Name] = &as3DOSProfile{
+		Class: "DOS_Profile",
+		Network: &as3DOSNetwork{
+			Vectors: []as3DOSNetworkVector{{
+				Type:                  dos.Vector,
+				State:                 "mitigate",
+				ThresholdMode:         "manual",
+				RateThreshold:         dos.DetectThreshold,
+				RateIncreaseThreshold: dos.RateIncreaseThreshold,
+				RateLimit:             dos.RateLimit,
+			}},
+		},
+	}
+	svc.ProfileDOS = &as3ResourcePointer{
+		Use: dos.Name,
+	}
+}
+
+// processProtocolInspectionForAS3 attaches the protocol inspection profile to the virtual server, the profile is
+// skipped when the AFM module is not licensed or provisioned or the AS3 version on BIG-IP doesn't support it
+func processProtocolInspectionForAS3(rsCfg *ResourceConfig, app as3Application, as3Version float64, afmUnavailable bool) {
+	if rsCfg.MetaData.ResourceType != VirtualServer || rsCfg.Virtual.ProfileProtocolInspection == "" {
+		return
+	}
+	svc, ok := app[rsCfg.Virtual.Name].(*as3Service)
+	if !ok {
+		return
+	}
+	if afmUnavailable {
+		log.Warningf("[AS3] virtualServer: %v, protocol inspection profile is ignored as AFM module is not licensed or provisioned on BIG-IP",
+			rsCfg.Virtual.Name)
+		return
+	}
+	// unknown AS3 version is validated against the bundled AS3 schema which supports protocol inspection
+	if as3Version != 0 && as3Version < ProtocolInspectionMinAS3Version {
+		log.Warningf("[AS3] virtualServer: %v, protocol inspection profile %v is ignored as it is supported from AS3 v%v onwards",
+			rsCfg.Virtual.Name, rsCfg.Virtual.ProfileProtocolInspection, ProtocolInspectionMinAS3Version)
+		return
+	}
+	svc.ProfileInspection = &as3ResourcePointer{
+		BigIP: rsCfg.Virtual.ProfileProtocolInspection,
+	}
+}
+
+// afmUnavailable checks whether the AFM module is known to be unlicensed or unprovisioned on BIG-IP
+func (postMgr *AS3PostManager) afmUnavailable() bool {
+	_, unprovisioned := postMgr.unprovisionedModules[ModuleAFM]
+	return postMgr.afmUnlicensed || unprovisioned
+}
+
+// processHTMLProfileForAS3 attaches the HTML profile to the virtual if supported by the AS3 version on BIG-IP
+func processHTMLProfileForAS3(rsCfg *ResourceConfig, app as3Application, as3Version float64) {
+	html := rsCfg.Virtual.HTML
+	if rsCfg.MetaData.ResourceType != VirtualServer || html.Profile == "" {
+		return
+	}
+	svc, ok := app[rsCfg.Virtual.Name].(*as3Service)
+	if !ok {
+		return
+	}
+	if as3Version != 0 && as3Version < HTMLProfileMinAS3Version {
+		log.Warningf("[AS3] virtualServer: %v, HTML profile %v is ignored as it is supported from AS3 v%v onwards",
+			rsCfg.Virtual.Name, html.Profile, HTMLProfileMinAS3Version)
+		return
+	}
+	if html.BigIPProfile {
+		svc.ProfileHTML = &as3ResourcePointer{
+			BigIP: html.Profile,
+		}
+		return
+	}
+	app[html.Profile] = &as3HTMLProfile{
+		Class:                   "HTML_Profile",
+		ContentDetectionEnabled: html.ContentDetectionEnabled,
+		ContentSelection:        html.ContentSelection,
+	}
+	svc.ProfileHTML = &as3ResourcePointer{
+		Use: html.Profile,
+	}
+}
+
+// processDiameterProfileForAS3 creates the Enforcement_Diameter_Endpoint_Profile of the TCP virtual server,
+// the profile is skipped when the AS3 version on BIG-IP does not support it
+func processDiameterProfileForAS3(rsCfg *ResourceConfig, app as3Application, as3Version float64) {
+	diameter := rsCfg.Virtual.Diameter
+	if rsCfg.MetaData.ResourceType != VirtualServer || diameter == nil {
+		return
+	}
+	svc, ok := app[rsCfg.Virtual.Name].(*as3Service)
+	if !ok {
+		return
+	}
+	if as3Version != 0 && as3Version < DiameterProfileMinAS3Version {
+		log.Warningf("[AS3] virtualServer: %v, Diameter profile is ignored as it is supported from AS3 v%v onwards",
+			rsCfg.Virtual.Name, DiameterProfileMinAS3Version)
+		return
+	}
+	profileName := rsCfg.Virtual.Name + "_diameter"
+	profile := &as3DiameterProfile{
+		Class:            "Enforcement_Diameter_Endpoint_Profile",
+		DestinationHost:  diameter.DestHost,
+		DestinationRealm: diameter.DestRealm,
+		SupportedApps:    diameter.SupportedApps,
+	}
+	if diameter.ParentProfile != "" {
+		profile.ParentProfile = &as3ResourcePointer{BigIP: diameter.ParentProfile}
+	}
+	app[profileName] = profile
+	svc.ProfileDiameterEndpoint = &as3ResourcePointer{
+		Use: profileName,
+	}
+}
+
+// processSCTPServiceForAS3 removes the Service_SCTP of the SCTP virtual server, when the AS3 version on BIG-IP
+// does not support it
+func processSCTPServiceForAS3(rsCfg *ResourceConfig, app as3Application, as3Version float64) {
+	if rsCfg.MetaData.ResourceType != VirtualServer || rsCfg.Virtual.SCTP == nil {
+		return
+	}
+	if as3Version != 0 && as3Version < SCTPServiceMinAS3Version {
+		log.Errorf("[AS3] virtualServer: %v, SCTP service is not created as it is supported from AS3 v%v onwards",
+			rsCfg.Virtual.Name, SCTPServiceMinAS3Version)
+		delete(app, rsCfg.Virtual.Name)
+	}
+}
+
+// processTCPAnalyticsProfileForAS3 creates the Analytics_TCP_Profile of the virtual server,
+// the profile is skipped when the AVR module is not licensed on BIG-IP
+func processTCPAnalyticsProfileForAS3(rsCfg *ResourceConfig, app as3Application, avrUnlicensed bool) {
+	tcpAnalytics := rsCfg.Virtual.AnalyticsProfiles.TCPAnalyticsProfile
+	if rsCfg.MetaData.ResourceType != VirtualServer || tcpAnalytics == nil {
+		return
+	}
+	svc, ok := app[rsCfg.Virtual.Name].(*as3Service)
+	if !ok {
+		return
+	}
+	if avrUnlicensed {
+		log.Warningf("[AS3] virtualServer: %v, TCP analytics profile is ignored as AVR module is not licensed on BIG-IP",
+			rsCfg.Virtual.Name)
+		return
+	}
+	profileName := rsCfg.Virtual.Name + "_tcp_analytics"
+	app[profileName] = &as3AnalyticsTCPProfile{
+		Class:               "Analytics_TCP_Profile",
+		CollectRemoteHostIp: tcpAnalytics.CollectRemoteHostIP,
+		CollectNexthop:      tcpAnalytics.CollectNexthop,
+	}
+	svc.TcpAnalyticsProfile = &as3ResourcePointer{
+		Use: profileName,
+	}
+}
+
+// processAccessProfileForAS3 attaches the APM access profile to the virtual server,
+// the profile is skipped when the APM module is not licensed on BIG-IP
+func processAccessProfileForAS3(rsCfg *ResourceConfig, app as3Application, apmUnlicensed bool) {
+	if rsCfg.MetaData.ResourceType != VirtualServer || rsCfg.Virtual.ProfileAccess == "" {
+		return
+	}
+	svc, ok := app[rsCfg.Virtual.Name].(*as3Service)
+	if !ok {
+		return
+	}
+	if apmUnlicensed {
+		log.Warningf("[AS3] virtualServer: %v, access profile is ignored as APM module is not licensed on BIG-IP",
+			rsCfg.Virtual.Name)
+		return
+	}
+	svc.ProfileAccess = &as3ResourcePointer{
+		BigIP: rsCfg.Virtual.ProfileAccess,
+	}
+}
+
+// processUnprovisionedModulesForAS3 skips the firewall policies, analytics and access profiles of the
+// virtual server when their BIG-IP module is not provisioned
+func processUnprovisionedModulesForAS3(rsCfg *ResourceConfig, app as3Application, unprovisioned map[string]struct{}) {
+	svc, ok := app[rsCfg.Virtual.Name].(*as3Service)
+	if !ok || len(unprovisioned) == 0 {
+		return
+	}
+	if _, ok := unprovisioned[ModuleAFM]; ok && svc.Firewall != nil {
+		log.Warningf("[AS3] virtualServer: %v, firewall policy is ignored as AFM module is not provisioned on BIG-IP",
+			rsCfg.Virtual.Name)
+		svc.Firewall = nil
+		policyName := getAccessListFirewallPolicyName(rsCfg.Virtual.Name)
+		delete(app, policyName)
+		delete(app, policyName+"_rules")
+	}
+	if _, ok := unprovisioned[ModuleAFM]; ok && svc.PolicyNAT != nil {
+		log.Warningf("[AS3] virtualServer: %v, NAT policy is ignored as AFM module is not provisioned on BIG-IP",
+			rsCfg.Virtual.Name)
+		svc.PolicyNAT = nil
+		// the rule lists, address lists and translations of the NAT policy share its name as prefix
+		natPolicyName := getNATPolicyName(rsCfg.Virtual.Name)
+		for name := range app {
+			if strings.HasPrefix(name, natPolicyName) {
+				delete(app, name)
+			}
+		}
+	}
+	if _, ok := unprovisioned[ModuleAVR]; ok && (svc.HttpAnalyticsProfile != nil || svc.TcpAnalyticsProfile != nil) {
+		log.Warningf("[AS3] virtualServer: %v, analytics profiles are ignored as AVR module is not provisioned on BIG-IP",
+			rsCfg.Virtual.Name)
+		svc.HttpAnalyticsProfile = nil
+		svc.TcpAnalyticsProfile = nil
+		delete(app, rsCfg.Virtual.Name+"_tcp_analytics")
+	}
+	if _, ok := unprovisioned[ModuleAPM]; ok && svc.ProfileAccess != nil {
+		log.Warningf("[AS3] virtualServer: %v, access profile is ignored as APM module is not provisioned on BIG-IP",
+			rsCfg.Virtual.Name)
+		svc.ProfileAccess = nil
+	}
+	if _, ok := unprovisioned[ModulePEM]; ok && svc.ProfileDiameterEndpoint != nil {
+		log.Warningf("[AS3] virtualServer: %v, Diameter endpoint profile is ignored as PEM module is not provisioned "+
+			"on BIG-IP", rsCfg.Virtual.Name)
+		svc.ProfileDiameterEndpoint = nil
+		delete(app, rsCfg.Virtual.Name+"_diameter")
+	}
+}
+
+// processIFilesForAS3 creates the iFiles of the virtual in its application, the iRules refer them by name
+func processIFilesForAS3(rsCfg *ResourceConfig, app as3Application) {
+	for _, iFile := range rsCfg.Virtual.IFiles {
+		app[iFile.Name] = &as3IFile{
+			Class: "iFile",
+			IFile: as3IFileSource{Base64: iFile.Base64},
+		}
+	}
+}
+
 // createUpdateTLSServer creates a new TLSServer instance or updates if one exists already
 func createUpdateTLSServer(prof CustomProfile, svcName string, app as3Application) bool {
 	if len(prof.Certificates) > 0 {
@@ -814,6 +1660,158 @@ func createUpdateTLSServer(prof CustomProfile, svcName string, app as3Applicatio
 	return false
 }
 
+// createCertificateValidatorDecl makes the TLS_Server of the virtual require client certificates signed by
+// the trusted CA and checks their revocation status against the OCSP responder or the CRL file
+func createCertificateValidatorDecl(cfg *ResourceConfig, app as3Application, svcName string) {
+	cv := cfg.Virtual.CertificateValidator
+	if !cv.OCSPEnabled && !cv.CRLEnabled {
+		return
+	}
+	tlsServer, ok := app[fmt.Sprintf("%s_tls_server", svcName)].(*as3TLSServer)
+	if !ok {
+		return
+	}
+	tlsServer.AuthenticationMode = "require"
+	tlsServer.AuthenticationTrustCA = &as3ResourcePointer{BigIP: cv.TrustCA}
+	if cv.OCSPEnabled {
+		// OCSP and CRL checks can not be combined on a TLS_Server with all BIG-IP versions, OCSP takes precedence
+		if cv.CRLEnabled {
+			log.Warningf("[AS3] OCSP and CRL are mutually exclusive, ignoring CRL for virtual %v", svcName)
+		}
+		validatorName := fmt.Sprintf("%s_ocsp_validator", svcName)
+		app[validatorName] = &as3CertificateValidatorOCSP{
+			Class:        "Certificate_Validator_OCSP",
+			ResponderURL: cv.OCSPResponderURL,
+			DNSResolver:  &as3ResourcePointer{BigIP: cv.DNSResolver},
+		}
+		tlsServer.C3DOCSP = &as3ResourcePointer{Use: validatorName}
+		// Clients whose certificate status is unknown to the responder are dropped only when required
+		tlsServer.C3DOCSPUnknownStatusAction = "ignore"
+		if cv.RequireOCSPStatus {
+			tlsServer.C3DOCSPUnknownStatusAction = "drop"
+		}
+		return
+	}
+	tlsServer.CRLFile = &as3ResourcePointer{BigIP: cv.CRLFile}
+}
+
+// createCipherGroupDecl creates the Cipher_Rules and the Cipher_Group of the virtual and refers them from its TLS_Server
+func createCipherGroupDecl(cfg *ResourceConfig, app as3Application, svcName string) {
+	cg := cfg.Virtual.CipherGroup
+	if cg == nil {
+		return
+	}
+	tlsServer, ok := app[fmt.Sprintf("%s_tls_server", svcName)].(*as3TLSServer)
+	if !ok {
+		return
+	}
+	groupName := AS3NameFormatter(cg.Name)
+	cipherGroup := &as3CipherGroup{
+		Class: "Cipher_Group",
+		Order: cg.Order,
+	}
+	for _, rule := range cg.Rules {
+		ruleName := AS3NameFormatter(fmt.Sprintf("%s_%s", cg.Name, rule.Name))
+		if len(rule.AllowList) > 0 {
+			app[ruleName] = &as3CipherRule{Class: "Cipher_Rule", CipherSuites: rule.AllowList}
+			cipherGroup.AllowCipherRules = append(cipherGroup.AllowCipherRules, as3ResourcePointer{Use: ruleName})
+		}
+		if len(rule.DenyList) > 0 {
+			denyRuleName := ruleName + "_deny"
+			app[denyRuleName] = &as3CipherRule{Class: "Cipher_Rule", CipherSuites: rule.DenyList}
+			cipherGroup.ExcludeCipherRules = append(cipherGroup.ExcludeCipherRules, as3ResourcePointer{Use: denyRuleName})
+		}
+	}
+	app[groupName] = cipherGroup
+	// ciphers and cipherGroup are mutually exclusive on a TLS_Server
+	tlsServer.Ciphers = ""
+	tlsServer.CipherGroup = &as3ResourcePointer{Use: groupName}
+}
+
+// shareCipherGroupsForAS3 moves the Cipher_Groups of the application to the Shared application of the
+// tenant, cipher groups with the same rules are created once and referred by all the TLS_Servers of the tenant
+func shareCipherGroupsForAS3(app as3Application, sharedApp as3Application, tenant string) {
+	var names []string
+	for name := range app {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	shared := make(map[string]*as3ResourcePointer)
+	for _, name := range names {
+		tlsServer, ok := app[name].(*as3TLSServer)
+		if !ok || tlsServer.CipherGroup == nil || tlsServer.CipherGroup.Use == "" {
+			continue
+		}
+		groupName := tlsServer.CipherGroup.Use
+		if pointer, ok := shared[groupName]; ok {
+			tlsServer.CipherGroup = pointer
+			continue
+		}
+		cipherGroup, ok := app[groupName].(*as3CipherGroup)
+		if !ok {
+			continue
+		}
+		sharedName, sharedGroup := getSharedCipherGroupDecl(app, cipherGroup, sharedApp)
+		if sharedName == "" {
+			continue
+		}
+		sharedApp[sharedName] = sharedGroup
+		// the rules of the group are not referred by other objects of the application
+		for _, rule := range append(cipherGroup.AllowCipherRules, cipherGroup.ExcludeCipherRules...) {
+			delete(app, rule.Use)
+		}
+		delete(app, groupName)
+		shared[groupName] = &as3ResourcePointer{Use: fmt.Sprintf("/%v/%v/%v", tenant, SharedApplication, sharedName)}
+		tlsServer.CipherGroup = shared[groupName]
+	}
+}
+
+// getSharedCipherGroupDecl creates the rules of the shared cipher group in the shared application, the group is
+// named after the hash of its content, so that the cipher groups with the same rules share the same name
+func getSharedCipherGroupDecl(app as3Application, cipherGroup *as3CipherGroup, sharedApp as3Application) (string,
+	*as3CipherGroup) {
+	content := struct {
+		Order   string     `json:"order,omitempty"`
+		Allow   [][]string `json:"allow,omitempty"`
+		Exclude [][]string `json:"exclude,omitempty"`
+	}{Order: cipherGroup.Order}
+	for _, rule := range cipherGroup.AllowCipherRules {
+		cipherRule, ok := app[rule.Use].(*as3CipherRule)
+		if !ok {
+			return "", nil
+		}
+		content.Allow = append(content.Allow, cipherRule.CipherSuites)
+	}
+	for _, rule := range cipherGroup.ExcludeCipherRules {
+		cipherRule, ok := app[rule.Use].(*as3CipherRule)
+		if !ok {
+			return "", nil
+		}
+		content.Exclude = append(content.Exclude, cipherRule.CipherSuites)
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", nil
+	}
+	hash := sha256.Sum256(data)
+	name := "cipher_group_" + hex.EncodeToString(hash[:])[:12]
+	sharedGroup := &as3CipherGroup{
+		Class: "Cipher_Group",
+		Order: cipherGroup.Order,
+	}
+	for i, suites := range content.Allow {
+		ruleName := fmt.Sprintf("%v_allow%d", name, i)
+		sharedApp[ruleName] = &as3CipherRule{Class: "Cipher_Rule", CipherSuites: suites}
+		sharedGroup.AllowCipherRules = append(sharedGroup.AllowCipherRules, as3ResourcePointer{Use: ruleName})
+	}
+	for i, suites := range content.Exclude {
+		ruleName := fmt.Sprintf("%v_exclude%d", name, i)
+		sharedApp[ruleName] = &as3CipherRule{Class: "Cipher_Rule", CipherSuites: suites}
+		sharedGroup.ExcludeCipherRules = append(sharedGroup.ExcludeCipherRules, as3ResourcePointer{Use: ruleName})
+	}
+	return name, sharedGroup
+}
+
 func createCertificateDecl(prof CustomProfile, app as3Application) {
 	for index, certificate := range prof.Certificates {
 		if len(certificate.Cert) > 0 && len(certificate.Key) > 0 {
@@ -850,6 +1848,42 @@ func createUpdateCABundle(prof CustomProfile, caBundleName string, app as3Applic
 func createMonitorDecl(cfg *ResourceConfig, app as3Application) {
 
 	for _, v := range cfg.Monitors {
+		if v.Type == ExternalMonitorType {
+			monitor := &as3ExternalMonitor{
+				Class:       "Monitor",
+				MonitorType: ExternalMonitorType,
+				Interval:    v.Interval,
+				Timeout:     v.Timeout,
+				Script:      as3IFileSource{Base64: v.Script},
+				Arguments:   v.Arguments,
+			}
+			if v.UserDefined != "" {
+				monitor.EnvironmentVariables = make(map[string]string)
+				for _, variable := range strings.Split(v.UserDefined, ",") {
+					name, value, _ := strings.Cut(variable, "=")
+					monitor.EnvironmentVariables[name] = value
+				}
+			}
+			app[v.Name] = monitor
+			continue
+		}
+		if v.Type == LDAPMonitorType {
+			monitor := &as3LDAPMonitor{
+				Class:       "Monitor",
+				MonitorType: LDAPMonitorType,
+				Interval:    v.Interval,
+				Timeout:     v.Timeout,
+				Base:        v.Base,
+				Filter:      v.Filter,
+				Security:    v.Security,
+				Username:    v.Username,
+			}
+			if v.Password != "" {
+				monitor.Passphrase = &as3Secret{Ciphertext: v.Password, Protected: AS3SecretProtected}
+			}
+			app[v.Name] = monitor
+			continue
+		}
 		monitor := &as3Monitor{}
 		monitor.Class = "Monitor"
 		monitor.Interval = v.Interval
@@ -918,6 +1952,11 @@ func createTransportServiceDecl(cfg *ResourceConfig, app as3Application, tenant
 		log.Warningf("[AS3] virtualServer: %v, ProfileBotDefense feature is not supported with BIG-IP Next", cfg.Virtual.Name)
 	}
 
+	if len(cfg.Virtual.ProfileProtocolInspection) > 0 {
+		log.Warningf("[AS3] virtualServer: %v, ProfileProtocolInspection feature is not supported with BIG-IP Next",
+			cfg.Virtual.Name)
+	}
+
 	if len(cfg.Virtual.TCP.Client) > 0 || len(cfg.Virtual.TCP.Server) > 0 {
 		if cfg.Virtual.TCP.Client == "" {
 			log.Errorf("[AS3] resetting ProfileTCP as client profile doesnt co-exist with TCP Server Profile, Please include client TCP Profile ")
@@ -981,11 +2020,147 @@ func createTransportServiceDecl(cfg *ResourceConfig, app as3Application, tenant
 	app[cfg.Virtual.Name] = svc
 }
 
+// Create AS3 Rewrite_Profile with uri-translation rules for CRD
+func createRewriteProfileDecl(cfg *ResourceConfig, app as3Application, svc *as3Service) {
+	rule := as3RewriteURIRule{
+		Type:   "both",
+		Client: as3RewriteURI{Path: "/"},
+		Server: as3RewriteURI{Path: "/"},
+	}
+	if cfg.Virtual.Rewrite.AppRoot != "" {
+		rule.Server.Path = cfg.Virtual.Rewrite.AppRoot
+	}
+	if cfg.Virtual.Rewrite.RequestHost != "" {
+		serverScheme := HTTP
+		if cfg.Virtual.TLSTermination == TLSReencrypt {
+			serverScheme = HTTPS
+		}
+		rule.Client.Scheme = cfg.MetaData.Protocol
+		rule.Client.Host = cfg.Virtual.Rewrite.Host
+		rule.Server.Scheme = serverScheme
+		rule.Server.Host = cfg.Virtual.Rewrite.RequestHost
+	}
+	profileName := cfg.Virtual.Name + "_rewrite_profile"
+	app[profileName] = &as3RewriteProfile{
+		Class:       "Rewrite_Profile",
+		RewriteMode: "uri-translation",
+		URIRules:    []as3RewriteURIRule{rule},
+	}
+	svc.ProfileRewrite = &as3ResourcePointer{
+		Use: profileName,
+	}
+}
+
+// Create AS3 rate limit configuration for CRD
+// rps limits the connection rate of the virtual, bps attaches a static Bandwidth_Control_Policy
+func createRateLimitDecl(cfg *ResourceConfig, app as3Application, svc *as3Service) {
+	limit := cfg.Virtual.RateLimit.Rate + cfg.Virtual.RateLimit.Burst
+	if cfg.Virtual.RateLimit.Unit != RateLimitUnitBPS {
+		svc.RateLimit = limit
+		return
+	}
+	policyName := cfg.Virtual.Name + "_bwc_policy"
+	app[policyName] = &as3BandwidthControlPolicy{
+		Class:            "Bandwidth_Control_Policy",
+		MaxBandwidth:     limit,
+		MaxBandwidthUnit: RateLimitUnitBPS,
+	}
+	svc.BandwidthControl = &as3ResourcePointer{
+		Use: policyName,
+	}
+}
+
+// Create AS3 Stream profile of VirtualServer, the BIG-IP profile is referenced directly when there are no overrides
+func createStreamProfileDecl(cfg *ResourceConfig, app as3Application, svc *as3Service) {
+	stream := cfg.Virtual.Stream
+	if stream.Source == "" && stream.Target == "" && stream.ChunkSize == 0 {
+		svc.ProfileStream = &as3ResourcePointer{
+			BigIP: stream.Profile,
+		}
+		return
+	}
+	profileName := cfg.Virtual.Name + "_stream_profile"
+	app[profileName] = &as3StreamProfile{
+		Class:           "Stream_Profile",
+		ParentProfile:   &as3ResourcePointer{BigIP: stream.Profile},
+		Source:          stream.Source,
+		Target:          stream.Target,
+		ChunkingEnabled: stream.ChunkSize > 0,
+		ChunkSize:       stream.ChunkSize,
+	}
+	svc.ProfileStream = &as3ResourcePointer{
+		Use: profileName,
+	}
+}
+
+// Create AS3 Multiplex profile for connection pooling of VirtualServer
+func createMultiplexProfileDecl(cfg *ResourceConfig, app as3Application, svc *as3Service) {
+	profileName := cfg.Virtual.Name + "_multiplex_profile"
+	app[profileName] = &as3MultiplexProfile{
+		Class:              "Multiplex_Profile",
+		MaxConnections:     cfg.Virtual.Multiplex.MaxConnections,
+		MaxConnectionReuse: cfg.Virtual.Multiplex.MaxConnectionReuse,
+		MaxConnectionAge:   cfg.Virtual.Multiplex.MaxConnectionAge,
+		SourceMask:         cfg.Virtual.Multiplex.SourceMask,
+	}
+	svc.ProfileMultiplex = &as3ResourcePointer{
+		Use: profileName,
+	}
+}
+
+// Create AS3 HTTP profile and iRule with X-Forwarded-For header setting for CRD, neither is created when an
+// HTTP profile is already configured
+func createXFFHTTPProfileDecl(cfg *ResourceConfig, app as3Application, svc *as3Service) {
+	if svc.ProfileHTTP != nil {
+		log.Warningf("[AS3] virtualServer: %v, %v annotation is ignored as HTTP profile is already configured",
+			cfg.Virtual.Name, XFFInsertAnnotation)
+		return
+	}
+	var xForwardedFor bool
+	switch cfg.Virtual.XFFInsert {
+	case XFFInsertEnabled:
+		// insert the client address, the header sent by the client is kept
+		xForwardedFor = true
+	case XFFInsertReplace:
+		// remove the header sent by the client and insert the client address
+		xForwardedFor = true
+		createXFFIRuleDecl(cfg, app, svc)
+	case XFFInsertDisabled:
+		// remove the header sent by the client without inserting the client address
+		createXFFIRuleDecl(cfg, app, svc)
+	default:
+		return
+	}
+	profileName := cfg.Virtual.Name + "_http_profile"
+	app[profileName] = &as3HTTPProfile{
+		Class:         "HTTP_Profile",
+		XForwardedFor: &xForwardedFor,
+	}
+	svc.ProfileHTTP = &as3ResourcePointer{
+		Use: profileName,
+	}
+}
+
+// Create AS3 iRule removing the X-Forwarded-For header sent by the client
+func createXFFIRuleDecl(cfg *ResourceConfig, app as3Application, svc *as3Service) {
+	ruleName := getRSCfgResName(cfg.Virtual.Name, XFFIRuleName)
+	app[ruleName] = &as3IRules{
+		Class: "iRule",
+		IRule: xffRemoveIRule(),
+	}
+	iRules, _ := svc.IRules.([]interface{})
+	svc.IRules = append(iRules, ruleName)
+}
+
 // Process common declaration for VS and TS
 func processCommonDecl(cfg *ResourceConfig, svc *as3Service) {
 
-	if cfg.Virtual.SNAT == "auto" || cfg.Virtual.SNAT == "none" {
+	if cfg.Virtual.SNAT == DEFAULT_SNAT || cfg.Virtual.SNAT == SNATNone || cfg.Virtual.SNAT == SNATSelf {
 		svc.SNAT = cfg.Virtual.SNAT
+	} else if cfg.Virtual.SNATPool != nil {
+		svc.SNAT = &as3ResourcePointer{
+			Use: getSNATPoolName(cfg.Virtual.Name),
+		}
 	} else {
 		svc.SNAT = &as3ResourcePointer{
 			BigIP: fmt.Sprintf("%v", cfg.Virtual.SNAT),
@@ -1005,6 +2180,17 @@ func processCommonDecl(cfg *ResourceConfig, svc *as3Service) {
 		svc.Firewall = &as3ResourcePointer{
 			BigIP: fmt.Sprintf("%v", cfg.Virtual.Firewall),
 		}
+	} else if len(cfg.Virtual.AddressLists) > 0 || len(cfg.Virtual.PortLists) > 0 {
+		svc.Firewall = &as3ResourcePointer{
+			Use: getAccessListFirewallPolicyName(cfg.Virtual.Name),
+		}
+	}
+
+	//Attach NAT policy
+	if cfg.Virtual.NATPolicy != nil {
+		svc.PolicyNAT = &as3ResourcePointer{
+			Use: getNATPolicyName(cfg.Virtual.Name),
+		}
 	}
 
 	//Attach ipIntelligence policy
@@ -1055,7 +2241,7 @@ func (req *RequestHandler) createAS3Config(rsConfig ResourceConfigRequest, pm *P
 	}
 	for tenant, cfg := range pm.AS3PostManager.createAS3BIGIPConfig(rsConfig.bigIpResourceConfig, pm.defaultPartition, pm.cachedTenantDeclMap,
 		rsConfig.poolMemberType) {
-		if !reflect.DeepEqual(cfg, pm.cachedTenantDeclMap[tenant]) ||
+		if !tenantDeclEqual(cfg, pm.cachedTenantDeclMap[tenant]) ||
 			(req.PrimaryClusterHealthProbeParams.EndPoint != "" && req.PrimaryClusterHealthProbeParams.statusChanged) {
 			as3cfg.incomingTenantDeclMap[tenant] = cfg.(as3Tenant)
 			as3cfg.tenantResponseMap[tenant] = tenantResponse{}
@@ -1066,10 +2252,45 @@ func (req *RequestHandler) createAS3Config(rsConfig ResourceConfigRequest, pm *P
 			}
 		}
 	}
+	pm.validateOptimisticLockKeys(&as3cfg)
 	as3cfg.data = string(pm.AS3PostManager.createAS3Declaration(as3cfg.incomingTenantDeclMap, req.userAgent))
 	return as3cfg
 }
 
+// tenantDeclEqual compares a tenant declaration with the cached one, tenants restored from the declaration
+// checkpoint hold plain JSON values, so both are compared in their canonical JSON form
+func tenantDeclEqual(decl interface{}, cached as3Tenant) bool {
+	if reflect.DeepEqual(decl, cached) {
+		return true
+	}
+	if cached == nil {
+		return false
+	}
+	jsonDecl, err := canonicalJSON(decl)
+	if err != nil {
+		return false
+	}
+	jsonCached, err := canonicalJSON(cached)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(jsonDecl, jsonCached)
+}
+
+// canonicalJSON round trips a value through JSON, so that values with the same JSON form are deeply equal
+// regardless of their Go types, pointers and the key order
+func canonicalJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
 func (as3PM *AS3PostManager) createAS3BIGIPConfig(config BigIpResourceConfig, partition string, cachedTenantDeclMap map[string]as3Tenant,
 	poolMemberType string) as3ADC {
 	adc := as3PM.createAS3LTMConfigADC(config, partition, cachedTenantDeclMap, poolMemberType)
@@ -1098,6 +2319,12 @@ func (postMgr *AS3PostManager) createAS3LTMConfigADC(config BigIpResourceConfig,
 			"class": "Tenant",
 			"label": cisLabel,
 		}
+		postMgr.setTenantSettings(tenantName, tenantDecl, partitionConfig)
+		// sharedApp holds the objects shared by the virtuals of the tenant
+		sharedApp := as3Application{
+			"class":    "Application",
+			"template": "shared",
+		}
 		for _, resourceConfig := range partitionConfig.ResourceMap {
 			// Create Shared as3Application object
 			app := as3Application{}
@@ -1111,19 +2338,102 @@ func (postMgr *AS3PostManager) createAS3LTMConfigADC(config BigIpResourceConfig,
 			// Process CustomProfiles
 			processCustomProfilesForAS3(resourceConfig, app, postMgr.bigIPAS3Version)
 
+			// Share the cipher groups along with the nodes
+			if config.shareNodes {
+				shareCipherGroupsForAS3(app, sharedApp, tenantName)
+			}
+
+			processBotDefenseForAS3(resourceConfig, app, postMgr.bigIPAS3Version)
+
+			processDOSProfileForAS3(resourceConfig, app, postMgr.afmUnavailable())
+
+			processProtocolInspectionForAS3(resourceConfig, app, postMgr.bigIPAS3Version, postMgr.afmUnavailable())
+
+			processHTMLProfileForAS3(resourceConfig, app, postMgr.bigIPAS3Version)
+
+			processTCPAnalyticsProfileForAS3(resourceConfig, app, postMgr.avrUnlicensed)
+
+			processAccessProfileForAS3(resourceConfig, app, postMgr.apmUnlicensed)
+
+			processUnprovisionedModulesForAS3(resourceConfig, app, postMgr.unprovisionedModules)
+
+			processDiameterProfileForAS3(resourceConfig, app, postMgr.bigIPAS3Version)
+
+			processSCTPServiceForAS3(resourceConfig, app, postMgr.bigIPAS3Version)
+
 			// Process Profiles
 			processProfilesForAS3(resourceConfig, app)
 
+			processIFilesForAS3(resourceConfig, app)
+
 			processIRulesForAS3(resourceConfig, app)
 
 			processDataGroupForAS3(resourceConfig, app)
 			tenantDecl[resourceConfig.Virtual.Name] = app
 		}
+		if len(sharedApp) > 2 {
+			tenantDecl[SharedApplication] = sharedApp
+		}
 		adc[tenantName] = tenantDecl
 	}
 	return adc
 }
 
+// setTenantSettings sets the route domain, optimistic lock key and log level of the tenant requested by its resources,
+// resources are processed in the order of their names and the first requested value is used
+func (postMgr *AS3PostManager) setTenantSettings(tenantName string, tenantDecl as3Tenant, partitionConfig *PartitionConfig) {
+	var names []string
+	for name := range partitionConfig.ResourceMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	routeDomain := postMgr.defaultRouteDomain
+	var routeDomainSource, lockKey, lockKeySource, logLevel, logLevelSource string
+	for _, name := range names {
+		metaData := partitionConfig.ResourceMap[name].MetaData
+		if metaData.routeDomain != nil {
+			if routeDomainSource == "" {
+				routeDomain = *metaData.routeDomain
+				routeDomainSource = name
+			} else if *metaData.routeDomain != routeDomain {
+				log.Warningf("[AS3] tenant %v: route domain %v of %v conflicts with route domain %v of %v, using %v",
+					tenantName, *metaData.routeDomain, name, routeDomain, routeDomainSource, routeDomain)
+			}
+		}
+		if metaData.optimisticLockKey != "" {
+			if lockKeySource == "" {
+				lockKey = metaData.optimisticLockKey
+				lockKeySource = name
+			} else if metaData.optimisticLockKey != lockKey {
+				log.Warningf("[AS3] tenant %v: optimistic lock key of %v conflicts with the key of %v, using the key of %v",
+					tenantName, name, lockKeySource, lockKeySource)
+			}
+		}
+		if metaData.as3LogLevel != "" {
+			if logLevelSource == "" {
+				logLevel = metaData.as3LogLevel
+				logLevelSource = name
+			} else if metaData.as3LogLevel != logLevel {
+				log.Warningf("[AS3] tenant %v: log level %v of %v conflicts with log level %v of %v, using %v",
+					tenantName, metaData.as3LogLevel, name, logLevel, logLevelSource, logLevel)
+			}
+		}
+	}
+	if routeDomain != 0 {
+		tenantDecl["defaultRouteDomain"] = routeDomain
+	}
+	if lockKey != "" {
+		tenantDecl["optimisticLockKey"] = lockKey
+	}
+	// the tenant controls override the global controls of the declaration for the tenant only
+	if logLevel != "" {
+		tenantDecl["controls"] = map[string]interface{}{
+			"class":    "Controls",
+			"logLevel": logLevel,
+		}
+	}
+}
+
 // removeDeletedTenantsForBigIP will check the tenant exists on bigip or not
 // if tenant exists and rsConfig does not have tenant, update the tenant with empty PartitionConfig
 func removeDeletedTenantsForBigIP(rsConfig *BigIpResourceConfig, cisLabel string, as3Config map[string]interface{}, partition string) {
@@ -0,0 +1,192 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// The dry-run checks the declarations against the AS3 schema CIS is built for, shipped in the schemas
+// directory of the image. The declarations are not validated against the whole JSON schema, which AS3 does on
+// BIG-IP, the dry-run checks that the declaration is an AS3 request of tenants and applications and that every
+// object of the declaration has a class of the AS3 schema.
+
+const (
+	// maxSchemaErrors is the number of schema violations reported for a declaration
+	maxSchemaErrors = 10
+	// as3SchemaURI is the JSON schema draft of the AS3 schema
+	as3SchemaURI = "http://json-schema.org/draft-07/schema#"
+)
+
+var (
+	as3SchemaOnce sync.Once
+	as3Schema     *AS3Schema
+	as3SchemaErr  error
+)
+
+// AS3Schema holds the classes of the AS3 JSON schema the declarations are checked against
+type AS3Schema struct {
+	classes map[string]struct{}
+}
+
+// getAS3SchemaFile returns the name of the AS3 schema file of the AS3 version CIS supports
+func getAS3SchemaFile() string {
+	return fmt.Sprintf("as3-schema-%v-%v-cis.json", defaultAS3Version, defaultAS3Build)
+}
+
+// getDefaultAS3Schema loads the AS3 schema from the schemas directory of the image, next to the bin directory
+// of CIS, or from the schemas directory of the working directory
+func getDefaultAS3Schema() (*AS3Schema, error) {
+	as3SchemaOnce.Do(func() {
+		dirs := []string{"schemas"}
+		if exe, err := os.Executable(); err == nil {
+			dirs = append([]string{filepath.Join(filepath.Dir(exe), "..", "vendor", "src", "f5", "schemas")}, dirs...)
+		}
+		for _, dir := range dirs {
+			as3Schema, as3SchemaErr = loadAS3Schema(filepath.Join(dir, getAS3SchemaFile()))
+			if as3SchemaErr == nil {
+				return
+			}
+		}
+	})
+	return as3Schema, as3SchemaErr
+}
+
+// loadAS3Schema reads the AS3 schema of the file, the file must be a JSON schema of the expected draft
+func loadAS3Schema(path string) (*AS3Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the AS3 schema: %v", err)
+	}
+	var root map[string]interface{}
+	if err = json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid AS3 schema %v: %v", path, err)
+	}
+	if uri, _ := root["$schema"].(string); uri != as3SchemaURI {
+		return nil, fmt.Errorf("invalid AS3 schema %v: $schema should be %v", path, as3SchemaURI)
+	}
+	schema := &AS3Schema{classes: make(map[string]struct{})}
+	schema.addClasses(root)
+	return schema, nil
+}
+
+// addClasses adds the classes of the class properties of the schema definitions, e.g. "class": {"const": "Pool"}
+func (schema *AS3Schema) addClasses(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if properties, ok := v["properties"].(map[string]interface{}); ok {
+			if class, ok := properties["class"].(map[string]interface{}); ok {
+				if name, ok := class["const"].(string); ok {
+					schema.classes[name] = struct{}{}
+				}
+				enum, _ := class["enum"].([]interface{})
+				for _, item := range enum {
+					if name, ok := item.(string); ok {
+						schema.classes[name] = struct{}{}
+					}
+				}
+			}
+		}
+		for _, value := range v {
+			schema.addClasses(value)
+		}
+	case []interface{}:
+		for _, value := range v {
+			schema.addClasses(value)
+		}
+	}
+}
+
+// Validate returns the violations of the AS3 schema in the declaration, at most maxSchemaErrors of them
+func (schema *AS3Schema) Validate(data string) []string {
+	var decl map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &decl); err != nil {
+		return []string{err.Error()}
+	}
+	var errs []string
+	if decl["class"] != "AS3" {
+		errs = append(errs, "/class: should be AS3")
+	}
+	adc, ok := decl["declaration"].(map[string]interface{})
+	if !ok {
+		return append(errs, "/declaration: should be an object")
+	}
+	if adc["class"] != "ADC" {
+		errs = append(errs, "/declaration/class: should be ADC")
+	}
+	for _, name := range sortedKeys(adc) {
+		obj, ok := adc[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := "/declaration/" + name
+		if obj["class"] != "Tenant" {
+			errs = append(errs, schema.validateClass(obj, path)...)
+			continue
+		}
+		// the objects of a tenant are its applications and their objects
+		for _, appName := range sortedKeys(obj) {
+			app, ok := obj[appName].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			appPath := path + "/" + appName
+			if app["class"] != "Application" {
+				errs = append(errs, schema.validateClass(app, appPath)...)
+				continue
+			}
+			for _, objName := range sortedKeys(app) {
+				if appObj, ok := app[objName].(map[string]interface{}); ok {
+					errs = append(errs, schema.validateClass(appObj, appPath+"/"+objName)...)
+				}
+			}
+		}
+	}
+	if len(errs) > maxSchemaErrors {
+		errs = errs[:maxSchemaErrors]
+	}
+	return errs
+}
+
+// validateClass returns the violation of the object without a class of the AS3 schema, path is the JSON path of
+// the object
+func (schema *AS3Schema) validateClass(obj map[string]interface{}, path string) []string {
+	class, ok := obj["class"].(string)
+	if !ok {
+		return []string{fmt.Sprintf("%v: required property class is missing", path)}
+	}
+	if _, found := schema.classes[class]; !found {
+		return []string{fmt.Sprintf("%v: %v is not a class of the AS3 schema", path, class)}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the object in order, so that the same violations are reported for the same
+// declaration
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,140 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// certManagerCertificate holds the fields of a cert-manager.io/v1 Certificate the renewal is tracked with
+type certManagerCertificate struct {
+	namespace   string
+	name        string
+	secretName  string
+	issuer      string
+	notAfter    string
+	renewalTime time.Time
+	revision    int64
+}
+
+// certRenewalState is the last seen revision of a Certificate and whether its renewal was synced
+type certRenewalState struct {
+	revision int64
+	synced   bool
+}
+
+// certRenewalHandler checks the cert-manager Certificates managed by CIS every CertRenewalCheckInterval
+func (ctlr *Controller) certRenewalHandler() {
+	ticker := time.NewTicker(CertRenewalCheckInterval)
+	defer ticker.Stop()
+	for {
+		ctlr.checkCertificateRenewals()
+		<-ticker.C
+	}
+}
+
+// getManagedCertificates lists the cert-manager Certificates labelled with cis.f5.com/managed: "true"
+func (ctlr *Controller) getManagedCertificates() ([]certManagerCertificate, error) {
+	certificatesRaw, err := ctlr.clientsets.KubeClient.Discovery().RESTClient().Get().AbsPath(CertManagerCertificatesPath).
+		Param("labelSelector", CertManagerManagedLabel+"=true").DoRaw(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	var certificates unstructured.UnstructuredList
+	if err = json.Unmarshal(certificatesRaw, &certificates); err != nil {
+		return nil, err
+	}
+	var managedCerts []certManagerCertificate
+	for _, certificate := range certificates.Items {
+		cert := certManagerCertificate{
+			namespace: certificate.GetNamespace(),
+			name:      certificate.GetName(),
+		}
+		cert.secretName, _, _ = unstructured.NestedString(certificate.Object, "spec", "secretName")
+		cert.issuer, _, _ = unstructured.NestedString(certificate.Object, "spec", "issuerRef", "name")
+		cert.notAfter, _, _ = unstructured.NestedString(certificate.Object, "status", "notAfter")
+		cert.revision, _, _ = unstructured.NestedInt64(certificate.Object, "status", "revision")
+		if renewalTime, ok, _ := unstructured.NestedString(certificate.Object, "status", "renewalTime"); ok {
+			cert.renewalTime, _ = time.Parse(time.RFC3339, renewalTime)
+		}
+		if cert.secretName == "" {
+			continue
+		}
+		managedCerts = append(managedCerts, cert)
+	}
+	return managedCerts, nil
+}
+
+// checkCertificateRenewals re-syncs the TLSProfiles referencing the secret of a cert-manager Certificate,
+// which is renewed or due for renewal within the CertRenewalLeadDays, so that only the certificate of the
+// virtuals is updated on BIG-IP. The expiry and issuer of the certificate are reported in the TLSProfile status.
+func (ctlr *Controller) checkCertificateRenewals() {
+	certificates, err := ctlr.getManagedCertificates()
+	if err != nil {
+		log.Warningf("Unable to list the cert-manager Certificates: %v", err)
+		return
+	}
+	if ctlr.certRenewals == nil {
+		ctlr.certRenewals = make(map[string]certRenewalState)
+	}
+	leadTime := time.Duration(ctlr.certRenewalLeadDays) * 24 * time.Hour
+	for _, cert := range certificates {
+		if _, ok := ctlr.getNamespacedCRInformer(cert.namespace); !ok {
+			continue
+		}
+		key := cert.namespace + "/" + cert.name
+		state, seen := ctlr.certRenewals[key]
+		renewed := seen && state.revision != cert.revision
+		if renewed {
+			state.synced = false
+		}
+		// a revision due for renewal is synced once, the renewed revision is synced again
+		dueForRenewal := !state.synced && !cert.renewalTime.IsZero() && time.Until(cert.renewalTime) <= leadTime
+		ctlr.certRenewals[key] = certRenewalState{revision: cert.revision, synced: state.synced || dueForRenewal}
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: cert.secretName, Namespace: cert.namespace}}
+		for _, tlsProfile := range ctlr.getTLSProfilesForSecret(secret) {
+			ctlr.updateTLSProfileCertStatus(tlsProfile, cert)
+			if renewed || dueForRenewal {
+				log.Infof("Updating the certificate of TLSProfile %v/%v from cert-manager Certificate %v, renewal time %v",
+					tlsProfile.Namespace, tlsProfile.Name, key, cert.renewalTime)
+				ctlr.enqueueTLSProfile(tlsProfile, Update)
+			}
+		}
+	}
+}
+
+// updateTLSProfileCertStatus sets the certExpiry and certIssuer of the TLSProfile status
+func (ctlr *Controller) updateTLSProfileCertStatus(tls *cisapiv1.TLSProfile, cert certManagerCertificate) {
+	if tls.Status.CertExpiry == cert.notAfter && tls.Status.CertIssuer == cert.issuer {
+		return
+	}
+	tlsCopy := tls.DeepCopy()
+	tlsCopy.Status.CertExpiry = cert.notAfter
+	tlsCopy.Status.CertIssuer = cert.issuer
+	_, updateErr := ctlr.clientsets.KubeCRClient.CisV1().TLSProfiles(tls.Namespace).UpdateStatus(context.TODO(), tlsCopy, metav1.UpdateOptions{})
+	if nil != updateErr {
+		log.Debugf("Error while updating TLSProfile status:%v", updateErr)
+	}
+}
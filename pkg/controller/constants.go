@@ -20,6 +20,16 @@ const (
 	ExternalDNS = "ExternalDNS"
 	// Policy is collection of BIG-IP profiles, LTM policies and iRules
 	CustomPolicy = "CustomPolicy"
+	// AddressList is a F5 Custom Resource Kind
+	AddressList = "AddressList"
+	// PortList is a F5 Custom Resource Kind
+	PortList = "PortList"
+	// NATPolicy is a F5 Custom Resource Kind
+	NATPolicy = "NATPolicy"
+	// SNATPool is a F5 Custom Resource Kind
+	SNATPool = "SNATPool"
+	// PacketFilter is a F5 Custom Resource Kind
+	PacketFilter = "PacketFilter"
 	// IPAM is a F5 Custom Resource Kind
 	IPAM = "IPAM"
 	// Service is a k8s native Service Resource.
@@ -28,8 +38,14 @@ const (
 	Pod = "Pod"
 	//Secret  is a k8s native object
 	K8sSecret = "Secret"
+	// ConfigMap is a k8s native ConfigMap Resource.
+	ConfigMap = "ConfigMap"
 	// Endpoints is a k8s native Endpoint Resource.
 	Endpoints = "Endpoints"
+	// NetworkPolicy is a k8s native NetworkPolicy Resource.
+	NetworkPolicy = "NetworkPolicy"
+	// Ingress is a k8s native Ingress Resource.
+	Ingress = "Ingress"
 	// Namespace is k8s namespace
 	Namespace = "Namespace"
 	// ConfigCR is k8s native ConfigCR resource
@@ -43,6 +59,17 @@ const (
 	Cluster  = "cluster"
 	Auto     = "auto"
 
+	// Sources of the VirtualServer address reported in the status
+	VSAddressSourceSpec     = "virtualServerAddress"
+	VSAddressSourceIPAM     = "ipam"
+	VSAddressSourceLBStatus = "serviceLoadBalancerStatus"
+	VSAddressSourcePool     = "virtualAddressPool"
+
+	// MaxVirtualAddressPoolSize limits the number of addresses expanded from the virtual address pool CIDRs
+	MaxVirtualAddressPoolSize = 65536
+	// VirtualAddressPoolAllocationsKey is the key of the allocations in the virtual address pool ConfigMap
+	VirtualAddressPoolAllocationsKey = "allocations"
+
 	StandAloneCIS = "standalone"
 	SecondaryCIS  = "secondary"
 	PrimaryCIS    = "primary"
@@ -74,6 +101,328 @@ const (
 	LBServiceHostAnnotation       = "cis.f5.com/host"
 	HealthMonitorAnnotation       = "cis.f5.com/health"
 	LBServicePolicyNameAnnotation = "cis.f5.com/policyName"
+	SlowRampTimeAnnotation        = "cis.f5.com/slow-ramp-time"
+	// MaxSlowRampTime is the maximum supported pool slowRampTime in seconds
+	MaxSlowRampTime = 900
+	// External monitor of the pools of a Service with the script of a ConfigMap in the Service namespace
+	ExternalMonitorAnnotation            = "cis.f5.com/monitor-external"
+	ExternalMonitorArgsAnnotation        = "cis.f5.com/monitor-args"
+	ExternalMonitorUserDefinedAnnotation = "cis.f5.com/monitor-user-defined"
+	ExternalMonitorTimeoutAnnotation     = "cis.f5.com/monitor-timeout"
+	// ExternalMonitorScriptKey is the ConfigMap key of the script, a ConfigMap with a single key may use any key
+	ExternalMonitorScriptKey = "script"
+	ExternalMonitorType      = "external"
+	// MaxExternalMonitorScriptSize is the size limit of an external monitor script on BIG-IP
+	MaxExternalMonitorScriptSize = 64 * 1024
+	// LDAP monitor of the pools of a Service with the bind password of a Secret in the Service namespace
+	MonitorTypeAnnotation         = "cis.f5.com/monitor-type"
+	MonitorSecretAnnotation       = "cis.f5.com/monitor-secret"
+	LDAPMonitorBaseAnnotation     = "cis.f5.com/monitor-ldap-base"
+	LDAPMonitorFilterAnnotation   = "cis.f5.com/monitor-ldap-filter"
+	LDAPMonitorSecurityAnnotation = "cis.f5.com/monitor-ldap-security"
+	LDAPMonitorUsernameAnnotation = "cis.f5.com/monitor-ldap-username"
+	// LDAPMonitorPasswordKey is the Secret key of the bind password, a Secret with a single key may use any key
+	LDAPMonitorPasswordKey = "password"
+	LDAPMonitorType        = "ldap"
+	// AS3SecretProtected is the protected header of the AS3 secrets whose ciphertext is base64 encoded plain text
+	AS3SecretProtected = "eyJhbGciOiJkaXIiLCJlbmMiOiJub25lIn0="
+	// MaxMonitorTimeout is the maximum supported monitor timeout in seconds
+	MaxMonitorTimeout = 900
+
+	// Address lists referenced by VirtualServer for CIDR based access control
+	AllowAddressListAnnotation = "cis.f5.com/allow-address-list"
+	DenyAddressListAnnotation  = "cis.f5.com/deny-address-list"
+	AddressListAllow           = "allow"
+	AddressListDeny            = "deny"
+	AllowPortListAnnotation    = "cis.f5.com/allow-port-list"
+	NATPolicyAnnotation        = "cis.f5.com/nat-policy"
+	MaxPortNumber              = 65535
+
+	// X-Forwarded-For header configuration for VirtualServer
+	XFFInsertAnnotation = "cis.f5.com/xff-insert"
+	XFFInsertEnabled    = "true"
+	XFFInsertReplace    = "replace"
+	XFFInsertDisabled   = "disabled"
+
+	// Rate limiting of VirtualServer traffic
+	RateLimitRPSAnnotation   = "cis.f5.com/rate-limit-rps"
+	RateLimitBurstAnnotation = "cis.f5.com/rate-limit-burst"
+	RateLimitUnitAnnotation  = "cis.f5.com/rate-limit-unit"
+	RateLimitUnitRPS         = "rps"
+	RateLimitUnitBPS         = "bps"
+	// MaxRateLimitRPS is the maximum connection rate limit supported on a BIG-IP virtual
+	MaxRateLimitRPS = 4294967295
+	// MinRateLimitBPS and MaxRateLimitBPS are the bandwidth control policy limits in bps
+	MinRateLimitBPS = 1000000
+	MaxRateLimitBPS = 320000000000
+
+	// URL rewrite profile of VirtualServer
+	RewriteAppRootAnnotation     = "cis.f5.com/rewrite-app-root"
+	RewriteRequestHostAnnotation = "cis.f5.com/rewrite-request-host"
+
+	// Connection multiplexing (OneConnect) of VirtualServer
+	ConnectionMultiplexAnnotation         = "cis.f5.com/connection-multiplex"
+	MultiplexMaxConnectionsAnnotation     = "cis.f5.com/multiplex-max-connections"
+	MultiplexMaxConnectionReuseAnnotation = "cis.f5.com/multiplex-max-connection-reuse"
+	MultiplexSourceMaskAnnotation         = "cis.f5.com/multiplex-source-mask"
+	MultiplexMaxConnectionAgeAnnotation   = "cis.f5.com/multiplex-max-connection-age"
+	// OneConnect annotations are equivalent to the connection multiplex ones
+	OneConnectAnnotation                   = "cis.f5.com/one-connect"
+	OneConnectMaxConnectionsAnnotation     = "cis.f5.com/one-connect-max-connections"
+	OneConnectMaxConnectionReuseAnnotation = "cis.f5.com/one-connect-max-connection-reuse"
+	OneConnectMaxConnectionAgeAnnotation   = "cis.f5.com/one-connect-max-connection-age"
+	// Defaults of the AS3 Multiplex_Profile
+	DefaultMultiplexMaxConnections     = 10000
+	DefaultMultiplexMaxConnectionReuse = 1000
+	DefaultMultiplexMaxConnectionAge   = 86400
+	DefaultMultiplexSourceMask         = "0.0.0.0"
+	// MaxMultiplexLimit is the maximum value of the multiplex connection limits
+	MaxMultiplexLimit = 4294967295
+
+	// Stream profile of VirtualServer for payload inspection and replacement
+	StreamProfileAnnotation   = "cis.f5.com/stream-profile"
+	StreamSourceAnnotation    = "cis.f5.com/stream-source"
+	StreamTargetAnnotation    = "cis.f5.com/stream-target"
+	StreamChunkSizeAnnotation = "cis.f5.com/stream-chunk-size"
+
+	// Bot defense profile of VirtualServer
+	BotDefenseAnnotation        = "cis.f5.com/bot-defense"
+	BotDefenseProfileAnnotation = "cis.f5.com/bot-defense-profile"
+	DefaultBotDefenseProfile    = "/Common/bot-defense"
+	// BotDefenseMinAS3Version is the first AS3 version supporting profileBotDefense
+	BotDefenseMinAS3Version = 3.20
+
+	// DoS profile of VirtualServer for DoS/DDoS mitigation with BIG-IP AFM
+	DOSProfileAnnotation               = "cis.f5.com/dos-profile"
+	DOSVectorAnnotation                = "cis.f5.com/dos-vector"
+	DOSDetectThresholdAnnotation       = "cis.f5.com/dos-detect-threshold"
+	DOSRateIncreaseThresholdAnnotation = "cis.f5.com/dos-rate-increase-threshold"
+	DOSRateLimitAnnotation             = "cis.f5.com/dos-rate-limit"
+	DefaultDOSVector                   = "tcp-syn-flood"
+	// MaxDOSThreshold is the maximum value of the DoS vector thresholds
+	MaxDOSThreshold = 4294967295
+
+	// Protocol inspection profile of VirtualServer for protocol validation with BIG-IP AFM
+	ProtocolInspectionAnnotation        = "cis.f5.com/protocol-inspection"
+	ProtocolInspectionProfileAnnotation = "cis.f5.com/inspection-profile"
+	DefaultProtocolInspectionProfile    = "/Common/protocol_inspection"
+	// ProtocolInspectionMinAS3Version is the first AS3 version supporting profileProtocolInspection
+	ProtocolInspectionMinAS3Version = 3.16
+
+	// HTML profile of VirtualServer for HTML content rewriting
+	HTMLProfileAnnotation          = "cis.f5.com/html-profile"
+	HTMLContentDetectionAnnotation = "cis.f5.com/html-content-detection"
+	HTMLContentSelectionAnnotation = "cis.f5.com/html-content-selection"
+	// HTMLProfileMinAS3Version is the first AS3 version supporting the HTML_Profile class
+	HTMLProfileMinAS3Version = 3.20
+
+	// TCP analytics profile of VirtualServer
+	TCPAnalyticsAnnotation                    = "cis.f5.com/tcp-analytics"
+	TCPAnalyticsCollectRemoteHostIPAnnotation = "cis.f5.com/tcp-analytics-collect-remote-host-ip"
+	TCPAnalyticsCollectNexthopAnnotation      = "cis.f5.com/tcp-analytics-collect-nexthop"
+	TCPAnalyticsByteDistributionAnnotation    = "cis.f5.com/tcp-analytics-byte-distribution"
+
+	// Diameter endpoint profile of TCP VirtualServer for telecom workloads
+	DiameterProfileAnnotation       = "cis.f5.com/diameter-profile"
+	DiameterParentProfileAnnotation = "cis.f5.com/diameter-parent-profile"
+	DiameterDestHostAnnotation      = "cis.f5.com/diameter-dest-host"
+	DiameterDestRealmAnnotation     = "cis.f5.com/diameter-dest-realm"
+	DiameterSupportedAppsAnnotation = "cis.f5.com/diameter-supported-apps"
+	// RADIUS profile of UDP VirtualServer for RADIUS authentication offload
+	RadiusProfileAnnotation             = "cis.f5.com/radius-profile"
+	RadiusPersistAttributeAnnotation    = "cis.f5.com/radius-persist-attribute"
+	RadiusSubscriberDiscoveryAnnotation = "cis.f5.com/radius-subscriber-discovery"
+	// RadiusPortsAnnotation lists the non-standard RADIUS ports the profile is allowed on besides 1812 and 1813
+	RadiusPortsAnnotation = "cis.f5.com/radius-ports"
+	RadiusAuthPort        = 1812
+	RadiusAccountingPort  = 1813
+	// HTTP compression profile of HTTP/HTTPS VirtualServer
+	HTTPCompressionAnnotation                   = "cis.f5.com/http-compression"
+	HTTPCompressionGzipAnnotation               = "cis.f5.com/http-compression-gzip"
+	HTTPCompressionDeflateAnnotation            = "cis.f5.com/http-compression-deflate"
+	HTTPCompressionContentTypeIncludeAnnotation = "cis.f5.com/http-compression-content-type-include"
+	HTTPCompressionMinimumSizeAnnotation        = "cis.f5.com/http-compression-minimum-size"
+	// HTTPCompressionMinMinimumSize and HTTPCompressionMaxMinimumSize are the range in bytes of the minimum size
+	// of the compressed responses accepted by AS3
+	HTTPCompressionMinMinimumSize = 128
+	HTTPCompressionMaxMinimumSize = 131072
+	// DiameterProfileMinAS3Version is the first AS3 version supporting the Diameter endpoint profile of CIS
+	DiameterProfileMinAS3Version = 3.45
+
+	// PoolPodSelectorAnnotation is a JSON label selector restricting the pool members to the selected pods
+	PoolPodSelectorAnnotation = "cis.f5.com/pool-pod-selector"
+
+	// Request and response adapt profiles of the VirtualServer, the values are the paths of BIG-IP profiles
+	RequestAdaptProfileAnnotation  = "cis.f5.com/request-adapt-profile"
+	ResponseAdaptProfileAnnotation = "cis.f5.com/response-adapt-profile"
+
+	// ICAP server the HTTP requests of the VirtualServer are adapted by, e.g. for DLP or antivirus scanning
+	ICAPServiceAnnotation     = "cis.f5.com/icap-service"
+	ICAPPortAnnotation        = "cis.f5.com/icap-port"
+	ICAPAllowHTTP10Annotation = "cis.f5.com/icap-allow-http10"
+	ICAPServiceDownAnnotation = "cis.f5.com/icap-service-down"
+	DefaultICAPPort           = 1344
+	// Actions of the ICAP service-down annotation
+	ICAPServiceDownContinue = "continue"
+	ICAPServiceDownDrop     = "drop"
+
+	// AccessProfileAnnotation is the path of the BIG-IP APM access profile of the VirtualServer
+	AccessProfileAnnotation = "cis.f5.com/access-profile"
+
+	// Masking modes of the WebSocket profile, auto keeps the BIG-IP default
+	WebSocketMaskingAuto      = "auto"
+	WebSocketMaskingUnmask    = "unmask"
+	WebSocketMaskingSelective = "selective"
+
+	// IFileConfigMapAnnotation references the ConfigMap whose data is created as AS3 iFiles for the iRules
+	IFileConfigMapAnnotation = "cis.f5.com/ifile-configmap"
+	// LogPublisherAnnotation references the BIG-IP Log Publisher the security events of a VirtualServer are logged to
+	LogPublisherAnnotation = "cis.f5.com/log-publisher"
+	// MonitorLibraryLabel marks the ConfigMaps holding the health monitor templates, keyed by the monitor name
+	MonitorLibraryLabel = "cis.f5.com/monitor-library"
+	// MonitorRefAnnotation references the library monitors attached to the pools of a VirtualServer
+	MonitorRefAnnotation = "cis.f5.com/monitor-ref"
+	// Load balancing strategy of the VirtualServer, the connection-directed strategy balances the pools on the
+	// fastest application response and evaluates the endpoint policies with a custom Endpoint_Strategy
+	LBStrategyAnnotation            = "cis.f5.com/lb-strategy"
+	LBStrategyMatchMethodAnnotation = "cis.f5.com/lb-strategy-match-method"
+	LBStrategyOperandsAnnotation    = "cis.f5.com/lb-strategy-operands"
+	LBStrategyConnectionDirected    = "connection-directed"
+	DefaultLBStrategyMatchMethod    = "first-match"
+	// MaxIFileSize is the size limit of an iFile on BIG-IP
+	MaxIFileSize           = 1024 * 1024
+	IFileSizeExceededEvent = "IFileSizeExceeded"
+
+	// AS3 Tenant settings of the VirtualServer partition
+	RouteDomainAnnotation       = "cis.f5.com/route-domain"
+	OptimisticLockKeyAnnotation = "cis.f5.com/optimistic-lock-key"
+	MaxRouteDomain              = 65535
+	MaxOptimisticLockKeyLength  = 128
+	// AS3LogLevelAnnotation on a Namespace sets the AS3 log level of the tenants of its VirtualServers
+	AS3LogLevelAnnotation = "cis.f5.com/as3-log-level"
+
+	// Raw TCP/UDP VirtualServer
+	TCPProfileAnnotation = "cis.f5.com/tcp-profile"
+	UDPProfileAnnotation = "cis.f5.com/udp-profile"
+
+	// Raw IP VirtualServer for protocols other than TCP and UDP, e.g. GRE
+	ProtocolAnnotation         = "cis.f5.com/protocol"
+	IPProtocolNumberAnnotation = "cis.f5.com/ip-protocol-number"
+	DefaultIPOtherProfile      = "/Common/ipother"
+	MaxIPProtocolNumber        = 255
+	// IP protocol numbers of TCP and UDP which are handled by Service_TCP, Service_UDP and Service_HTTP
+	TCPProtocolNumber = 6
+	UDPProtocolNumber = 17
+
+	// Forwarding VirtualServer routing the traffic transparently without terminating the connections
+	ForwardingAnnotation          = "cis.f5.com/forwarding"
+	ForwardingTypeAnnotation      = "cis.f5.com/forwarding-type"
+	ForwardingMirroringAnnotation = "cis.f5.com/forwarding-mirroring"
+	ForwardingTypeIP              = "ip"
+	ForwardingTypeL2              = "l2"
+
+	// SNI routing of passthrough VirtualServers with the server name of the TLS client hello
+	SNIRoutingAnnotation = "cis.f5.com/sni-routing"
+
+	// Status condition of VirtualServer validation
+	VSConditionValid           = "Valid"
+	VSReasonValid              = "Valid"
+	VSReasonUnsupportedInUDP   = "UnsupportedUDPConfiguration"
+	VSReasonInvalidIPProtocol  = "InvalidIPProtocolConfiguration"
+	VSReasonInvalidForwarding  = "InvalidForwardingConfiguration"
+	VSReasonInvalidSNIRouting  = "InvalidSNIRoutingConfiguration"
+	VSReasonInvalidSIP         = "InvalidSIPConfiguration"
+	VSReasonSIPWithoutTLS      = "SecureSIPWithoutTLSProfile"
+	VSReasonInvalidMQTT        = "InvalidMQTTConfiguration"
+	VSReasonInvalidSCTP        = "InvalidSCTPConfiguration"
+	VSReasonInvalidWebSocket   = "InvalidWebSocketConfiguration"
+	VSReasonTenantUnauthorized = "TenantUnauthorized"
+
+	// Status condition of the snat of VirtualServer
+	VSConditionSNATConfigured   = "SNATConfigured"
+	VSReasonSNATApplied         = "SNATApplied"
+	VSReasonInvalidSNAT         = "InvalidSNAT"
+	VSReasonSNATPoolUnavailable = "SNATPoolUnavailable"
+
+	// Status condition of the DoS profile of VirtualServer
+	VSConditionDOSEnabled     = "DOSEnabled"
+	VSReasonDOSApplied        = "DOSProfileApplied"
+	VSReasonInvalidDOS        = "InvalidDOSProfile"
+	VSReasonDOSAFMUnavailable = "AFMUnavailable"
+
+	// Status condition of the protocol inspection profile of VirtualServer
+	VSConditionProtocolInspectionEnabled     = "ProtocolInspectionEnabled"
+	VSReasonProtocolInspectionApplied        = "ProtocolInspectionProfileApplied"
+	VSReasonInvalidProtocolInspection        = "InvalidProtocolInspectionProfile"
+	VSReasonProtocolInspectionAS3Unsupported = "AS3VersionUnsupported"
+
+	// Status condition of TLSProfile validation
+	TLSProfileConditionValid = "Valid"
+	TLSProfileReasonValid    = "Valid"
+	TLSProfileReasonInvalid  = "InvalidTLSProfile"
+
+	// Traffic classification profile of VirtualServer
+	ClassificationProfileAnnotation = "cis.f5.com/classification-profile"
+	DefaultClassificationProfile    = "/Common/classification"
+
+	// Tenant write permission checked with RBAC, e.g. a Role rule of apiGroups cis.f5.com,
+	// resources tenants, resourceNames <tenant> and verbs write
+	TenantAPIGroup  = "cis.f5.com"
+	TenantResource  = "tenants"
+	TenantWriteVerb = "write"
+	// TenantServiceAccount is the ServiceAccount of the namespace its resources are authorized as
+	TenantServiceAccount = "cis-tenant-writer"
+	// TenantAccessCacheTTL is the time the result of a tenant write check is reused
+	TenantAccessCacheTTL = time.Minute
+
+	// CrossNamespaceServiceAnnotation references the pool services of other namespaces as namespace/service
+	CrossNamespaceServiceAnnotation = "cis.f5.com/cross-ns-service"
+	// CrossNamespaceServiceDeniedEvent is recorded when the NetworkPolicies deny the BIG-IP traffic to a service
+	CrossNamespaceServiceDeniedEvent = "CrossNamespaceServiceDenied"
+
+	// CommonPartition is the BIG-IP partition of the objects shared by the tenants
+	CommonPartition = "Common"
+	// SharedApplication holds the AS3 objects shared by the virtuals of a tenant
+	SharedApplication = "Shared"
+
+	// packetFilterDescription prefixes the description of the BIG-IP packet filters created for the PacketFilters
+	packetFilterDescription = "Managed by CIS for PacketFilter "
+
+	// Features requiring an add-on module license on BIG-IP
+	LicenseFeatureFirewall  = "Firewall policy"
+	LicenseFeatureAnalytics = "Analytics"
+	LicenseFeatureGSLB      = "GSLB"
+	LicenseFeatureAccess    = "Access profile"
+	LicenseFeatureDOS       = "DoS profile"
+	// LicenseCheckInterval is the interval to re-check the BIG-IP license
+	LicenseCheckInterval = 24 * time.Hour
+	// BIG-IP modules gating the features of the virtuals when they are not provisioned
+	ModuleAFM = "afm"
+	ModuleAVR = "avr"
+	ModuleAPM = "apm"
+	ModulePEM = "pem"
+
+	// CertExpiryCheckInterval is the interval to check the expiry of the BIG-IP management certificate
+	CertExpiryCheckInterval = 12 * time.Hour
+	// CheckpointWriteDelay is the delay to write the checkpoint ConfigMap after a post, the posts within
+	// the delay are written together
+	CheckpointWriteDelay = 5 * time.Second
+	// ServiceAccountNamespaceFile holds the namespace of the pod in the service account mount
+	ServiceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+	// CertExpiringEvent is the reason of the event raised when the BIG-IP management certificate is about to expire
+	CertExpiringEvent = "BIGIPCertificateExpiring"
+
+	// BGP advertisement of VirtualServer addresses through a LoadBalancer Service
+	BGPAdvertiseAnnotation    = "cis.f5.com/bgp-advertise"
+	BGPAdvertiseServiceSuffix = "-bgp-advertise"
+	BGPAdvertiseLabel         = "cis.f5.com/bgp-advertise-for"
+
+	// TopologyZoneLabel is the well-known node label that holds the zone of a node
+	TopologyZoneLabel = "topology.kubernetes.io/zone"
+	// DefaultLocalZoneRatio is the pool member ratio for endpoints in the BIG-IP zone
+	DefaultLocalZoneRatio = 10
+	// DefaultRemoteZoneRatio is the pool member ratio for endpoints in other zones
+	DefaultRemoteZoneRatio = 1
 
 	//Antrea NodePortLocal support
 	NPLPodAnnotation = "nodeportlocal.antrea.io"
@@ -88,6 +437,8 @@ const (
 	defaultAS3Version = "3.48.0"
 	defaultAS3Build   = "10"
 	clusterHealthPath = "/readyz"
+	// AS3StatsPath is the path of the diagnostic endpoint serving the last known state of the AS3 tenants
+	AS3StatsPath = "/stats/as3"
 
 	Create = "Create"
 	Update = "Update"
@@ -102,6 +453,26 @@ const (
 
 	HTTP  = "http"
 	HTTPS = "https"
+	TCP   = "tcp"
+	UDP   = "udp"
+	// ProtocolOther is the protocol of virtuals handling IP protocols other than TCP and UDP
+	ProtocolOther = "other"
+	// SIP is the protocol of virtuals handling the Session Initiation Protocol over TCP or UDP
+	SIP            = "sip"
+	DefaultSIPPort = 5060
+	// SIPPersistenceMethod persists the SIP dialogs on the same pool member
+	SIPPersistenceMethod = "sip-info"
+	// MQTT is the protocol of TCP virtuals handling the MQTT messages of IoT workloads
+	MQTT            = "mqtt"
+	DefaultMQTTPort = 1883
+	// SCTP is the protocol of virtuals handling the SCTP associations of telecom signaling traffic
+	SCTP               = "sctp"
+	DefaultSCTPProfile = "/Common/sctp"
+	// SCTPServiceMinAS3Version is the AS3 schema version of CIS, which the Service_SCTP class is validated against
+	SCTPServiceMinAS3Version = as3Version
+	sctpProfileDescription   = "Managed by CIS for SCTP VirtualServers"
+	// SCTPProfileConflictEvent is the warning event of a SCTP VirtualServer with HTTP level features
+	SCTPProfileConflictEvent = "SCTPProfileConflict"
 
 	defaultRouteGroupName string = "defaultRouteGroup"
 
@@ -121,7 +492,13 @@ const (
 	//Calico CNI
 	CALICO                      = "calico"
 	CALICO_API_BLOCK_AFFINITIES = "/apis/crd.projectcalico.org/v1/blockaffinities"
-	CALICONodeIPAnnotation      = "projectcalico.org/IPv4Address"
+	// CertManagerCertificatesPath lists the cert-manager Certificates of all the namespaces
+	CertManagerCertificatesPath = "/apis/cert-manager.io/v1/certificates"
+	// CertManagerManagedLabel marks the cert-manager Certificates whose renewal is tracked by CIS
+	CertManagerManagedLabel = "cis.f5.com/managed"
+	// CertRenewalCheckInterval is the interval to check the renewal of the cert-manager Certificates
+	CertRenewalCheckInterval = time.Hour
+	CALICONodeIPAnnotation   = "projectcalico.org/IPv4Address"
 
 	//CNI plugin
 	FLANNEL      = "flannel"
@@ -138,6 +515,12 @@ const (
 	F5ClientSslProfileAnnotation       = "virtual-server.f5.com/clientssl"
 	F5HealthMonitorAnnotation          = "virtual-server.f5.com/health"
 	PodConcurrentConnectionsAnnotation = "virtual-server.f5.com/pod-concurrent-connections"
+	F5VsBindAddrAnnotation             = "virtual-server.f5.com/ip"
+	F5VsPartitionAnnotation            = "virtual-server.f5.com/partition"
+
+	// Ingresses of the f5 ingress class are processed
+	IngressClassAnnotation = "kubernetes.io/ingress.class"
+	F5IngressClass         = "f5"
 
 	TLSVerion1_3 TLSVersion = "1.3"
 
@@ -156,12 +539,29 @@ const (
 
 	Ok              = "Ok"
 	UnknownResponse = "unknown response"
+
+	// States of the AS3 task of an asynchronous declaration
+	AS3TaskRunning   = "RUNNING"
+	AS3TaskFailed    = "FAILED"
+	AS3TaskSucceeded = "SUCCEEDED"
+
+	// Status labels of the managed tenants metric
+	TenantHealthy = "healthy"
+	TenantFailed  = "failed"
+)
+
+const (
+	TenantAdded    TenantChangeType = "added"
+	TenantRemoved  TenantChangeType = "removed"
+	TenantModified TenantChangeType = "modified"
 )
 
 const (
 	DEFAULT_HTTP_PORT  int32  = 80
 	DEFAULT_HTTPS_PORT int32  = 443
 	DEFAULT_SNAT       string = "auto"
+	SNATNone           string = "none"
+	SNATSelf           string = "self"
 
 	// Constants for CustomProfile.Type as defined in CCCL
 	CustomProfileClient string = "clientside"
@@ -178,6 +578,7 @@ const (
 	HttpsRedirectDgName = "https_redirect_dg"
 	TLSIRuleName        = "tls_irule"
 	ABPathIRuleName     = "ab_deployment_path_irule"
+	XFFIRuleName        = "xff_irule"
 )
 
 // constants for TLS references
@@ -242,9 +643,28 @@ const CmDeclareTaskApi = "/api/v1/spaces/default/appsvcs/task/"
 
 const CmDeclareInfoApi = "/api/v1/spaces/default/appsvcs/info"
 
+// OpenTelemetry tracing of the AS3 declaration posts
+const (
+	DefaultOTelServiceName = "k8s-bigip-ctlr"
+	OTelScopeName          = "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/controller"
+	OTelTracesPath         = "/v1/traces"
+	OTelExportInterval     = 5 * time.Second
+	OTelExportTimeout      = 10 * time.Second
+	// OTelMaxQueuedSpans bounds the spans waiting for export, newer spans are dropped when it is reached
+	OTelMaxQueuedSpans = 2048
+	// OTLP span kind of the posts and status code of the failed posts
+	OTelSpanKindClient = 3
+	OTelStatusError    = 2
+	// TraceparentHeader carries the W3C trace context of the requests to BIG-IP
+	TraceparentHeader = "traceparent"
+)
+
 // Constants for Errors
 const (
 	NetworkConfigInvalid   = "network config is invalid"
 	NamespaceConfigInvalid = "namespace config is invalid"
 	DeployConfigInvalid    = "deploy config is invalid"
 )
+
+// AS3LogLevels are the log levels supported by the AS3 Controls of a tenant
+var AS3LogLevels = []string{"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
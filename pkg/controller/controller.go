@@ -17,6 +17,8 @@
 package controller
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"github.com/F5Networks/f5-ipam-controller/pkg/ipammachinery"
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
@@ -26,6 +28,9 @@ import (
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/statusmanager"
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/tokenmanager"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	"net/url"
+	"os"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +38,8 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/workqueue"
 )
 
@@ -74,8 +81,18 @@ func RunController(params Params) *Controller {
 	// enable http endpoint
 	go ctlr.enableHttpEndpoint(params.HttpAddress)
 
+	// enable the diagnostic endpoint on its own address
+	if params.DiagnosticsAddr != "" {
+		go ctlr.enableDiagnosticsEndpoint(params.DiagnosticsAddr)
+	}
+
 	go ctlr.Start()
 
+	// track the renewal of the cert-manager Certificates
+	if ctlr.certRenewalLeadDays > 0 {
+		go ctlr.certRenewalHandler()
+	}
+
 	return ctlr
 }
 
@@ -100,13 +117,55 @@ func NewController(params Params, statusManager *statusmanager.StatusManager) *C
 			params.CMSSLInsecure,
 			statusManager),
 		managedResources: ManagedResources{
+			ManageRoutes:          params.OpenShiftRoute,
 			ManageCustomResources: true,
 			ManageTransportServer: true,
 			ManageIL:              true,
+			ManageSecrets:         true,
 		},
 		bigIpConfigMap: make(BigIpConfigMap),
-		PostParams:     PostParams{},
-		clientsets:     params.ClientSets,
+		PostParams: PostParams{
+			StalenessThreshold:      params.StalenessThreshold,
+			CheckpointConfigMap:     params.CheckpointConfigMap,
+			WarmStart:               params.WarmStart,
+			DefaultRouteDomain:      params.DefaultRouteDomain,
+			AuditLogEnabled:         params.AuditLogEnabled,
+			AuditLogPath:            params.AuditLogPath,
+			DriftDetectionInterval:  params.DriftDetectionInterval,
+			DriftResync:             params.DriftResync,
+			CertExpiryWarningDays:   params.CertExpiryWarningDays,
+			ClassVersions:           params.ClassVersions,
+			AsyncMode:               params.AS3AsyncMode,
+			AsyncPollInterval:       params.AS3AsyncPollInterval,
+			AsyncPollMaxInterval:    params.AS3AsyncPollMaxInterval,
+			AsyncTimeout:            params.AS3AsyncTimeout,
+			ProvisionCheckInterval:  params.ProvisionCheckInterval,
+			RetryBackoff:            params.AS3RetryBackoff,
+			DryRun:                  params.DryRun,
+			MaxDeclarationSize:      params.MaxDeclarationSizeBytes,
+			CircuitBreakerThreshold: params.CircuitBreakerThreshold,
+			CircuitBreakerCooldown:  params.CircuitBreakerCooldown,
+			tracer:                  NewTracer(params.OTelEndpoint, params.OTelServiceName),
+		},
+		clientsets: params.ClientSets,
+		topologyConfig: TopologyConfig{
+			Enabled:     params.TopologyAwarePoolWeights,
+			TopologyKey: params.TopologyKey,
+			BIGIPZone:   params.BIGIPZone,
+			ZoneWeights: params.ZoneWeights,
+		},
+		bgpAdvertise:          params.BGPAdvertise,
+		manageIngress:         params.ManageIngress,
+		certRenewalLeadDays:   params.CertRenewalLeadDays,
+		defaultLogPublisher:   params.DefaultLogPublisher,
+		firewallEnabled:       params.FirewallEnabled,
+		apmEnabled:            params.APMEnabled,
+		rbacEnabled:           params.RBACEnabled,
+		bigipSourceIP:         params.BIGIPSourceIP,
+		tenantToDeviceMapping: params.TenantToDeviceMapping,
+		bigipTargets:          params.BIGIPTargets,
+		rolloutPause:          params.RolloutPause,
+		externalMonitors:      params.ExternalMonitors,
 	}
 
 	log.Debug("Controller Created")
@@ -124,8 +183,49 @@ func NewController(params Params, statusManager *statusmanager.StatusManager) *C
 	// Initialize the controller with base resources in CIS config CR
 	ctlr.initController()
 
+	if params.ClientSets != nil {
+		ctlr.PostParams.kubeClient = params.ClientSets.KubeClient
+	}
+	if params.AuditLogEnabled {
+		ctlr.PostParams.auditUser = getServiceAccountUser(params.Config)
+	}
+	ctlr.PostParams.podName, ctlr.PostParams.podNamespace = getControllerPod()
+	adminNamespace := params.AdminNamespace
+	if adminNamespace == "" {
+		adminNamespace = ctlr.PostParams.podNamespace
+	}
+	if adminNamespace != "" && params.ClientSets != nil {
+		ctlr.adminInformer = ctlr.newAdminInformer(adminNamespace)
+		ctlr.addAdminEventHandlers(ctlr.adminInformer)
+	}
+	if ctlr.managedResources.ManageCustomResources && params.ClientSets != nil {
+		ctlr.nsAnnotationInformer = ctlr.newNamespaceAnnotationInformer()
+		ctlr.addNamespaceAnnotationEventHandlers(ctlr.nsAnnotationInformer)
+	}
+	if len(params.VirtualAddressPool) > 0 {
+		var kubeClient kubernetes.Interface
+		if params.ClientSets != nil {
+			kubeClient = params.ClientSets.KubeClient
+		}
+		vipPool, err := NewVirtualAddressPool(params.VirtualAddressPool, params.VirtualAddressPoolConfigMap, kubeClient)
+		if err != nil {
+			log.Errorf("Unable to create the virtual address pool: %v", err)
+		} else {
+			ctlr.vipPool = vipPool
+		}
+	}
+
+	// the schema the dry-run checks the declarations against is checked at startup rather than with the first post
+	if params.DryRun {
+		if _, err := getDefaultAS3Schema(); err != nil {
+			log.Errorf("[INIT] AS3 schema health check failed: %v", err)
+		} else {
+			log.Infof("[INIT] AS3 schema %v loaded", getAS3SchemaFile())
+		}
+	}
+
 	// create the new request handler
-	ctlr.NewRequestHandler(params.UserAgent, params.httpClientMetrics)
+	ctlr.NewRequestHandler(getUserAgent(params.UserAgent, params.CISVersion, params.Config), params.httpClientMetrics)
 
 	return ctlr
 }
@@ -139,7 +239,71 @@ func (ctlr *Controller) NewRequestHandler(userAgent string, httpClientMetrics bo
 		CMTokenManager:    ctlr.CMTokenManager,
 		PostParams:        ctlr.PostParams,
 		httpClientMetrics: httpClientMetrics,
+		rolloutPaused:     ctlr.rolloutPause,
+		heldRequests:      make(map[cisapiv1.BigIpConfig]ResourceConfigRequest),
+		rolloutResumed:    make(chan struct{}, 1),
+		partitionsFetched: make(chan cisapiv1.BigIpConfig, 1),
+	}
+}
+
+// getUserAgent appends the cluster and the go runtime to the user agent with the CIS and platform versions,
+// the cluster name is derived from the host of the Kubernetes API server URL
+func getUserAgent(userAgent, cisVersion string, config *rest.Config) string {
+	clusterName := "unknown"
+	if config != nil && config.Host != "" {
+		host := config.Host
+		if !strings.Contains(host, "://") {
+			host = "https://" + host
+		}
+		if u, err := url.Parse(host); err == nil && u.Hostname() != "" {
+			clusterName = u.Hostname()
+		}
+	}
+	if userAgent == "" {
+		userAgent = "CIS/unknown"
+		if cisVersion != "" {
+			userAgent = fmt.Sprintf("CIS/v%v", cisVersion)
+		}
+	}
+	return fmt.Sprintf("%v cluster/%v go/%v", userAgent, clusterName, runtime.Version())
+}
+
+// getServiceAccountUser returns the user CIS authenticates to the cluster as, read from the subject of
+// the service account token
+func getServiceAccountUser(config *rest.Config) string {
+	if config == nil {
+		return "unknown"
+	}
+	token := config.BearerToken
+	if token == "" && config.BearerTokenFile != "" {
+		if data, err := os.ReadFile(config.BearerTokenFile); err == nil {
+			token = strings.TrimSpace(string(data))
+		}
+	}
+	if parts := strings.Split(token, "."); len(parts) == 3 {
+		if payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "=")); err == nil {
+			var claims struct {
+				Subject string `json:"sub"`
+			}
+			if json.Unmarshal(payload, &claims) == nil && claims.Subject != "" {
+				return claims.Subject
+			}
+		}
+	}
+	if config.Username != "" {
+		return config.Username
+	}
+	return "unknown"
+}
+
+// getControllerPod returns the name and namespace of the CIS pod, they are empty when CIS runs out of cluster
+func getControllerPod() (string, string) {
+	data, err := os.ReadFile(ServiceAccountNamespaceFile)
+	if err != nil {
+		return "", ""
 	}
+	// the hostname of a pod is its name
+	return os.Getenv("HOSTNAME"), strings.TrimSpace(string(data))
 }
 
 func (ctlr *Controller) setupIPAM(params Params) {
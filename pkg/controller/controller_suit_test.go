@@ -52,9 +52,10 @@ func newMockController() *mockController {
 			resourceSelectorConfig: ResourceSelectorConfig{},
 			CMTokenManager:         tokenManager,
 			RequestHandler: &RequestHandler{
-				PostManagers: PostManagers{sync.RWMutex{}, make(map[cisapiv1.BigIpConfig]*PostManager)},
-				reqChan:      make(chan ResourceConfigRequest, 1),
-				PostParams:   PostParams{tokenManager: tokenManager},
+				PostManagers:   PostManagers{sync.RWMutex{}, make(map[cisapiv1.BigIpConfig]*PostManager)},
+				reqChan:        make(chan ResourceConfigRequest, 1),
+				PostParams:     PostParams{tokenManager: tokenManager},
+				CMTokenManager: tokenManager,
 			},
 			bigIpConfigMap:   make(BigIpConfigMap),
 			PostParams:       PostParams{},
@@ -1,9 +1,36 @@
 package controller
 
 import (
+	"encoding/base64"
+	"runtime"
+
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
 )
 
 var _ = Describe("OtherSDNType", func() {
 
 })
+
+var _ = Describe("User Agent", func() {
+	It("Default user agent with version and cluster info", func() {
+		Expect(getUserAgent("CIS/v3.0.0 K8S/v1.29.0", "3.0.0",
+			&rest.Config{Host: "https://api.cluster1.example.com:6443"})).To(
+			Equal("CIS/v3.0.0 K8S/v1.29.0 cluster/api.cluster1.example.com go/" + runtime.Version()))
+		Expect(getUserAgent("", "3.0.0", &rest.Config{Host: "10.96.0.1:443"})).To(
+			Equal("CIS/v3.0.0 cluster/10.96.0.1 go/" + runtime.Version()))
+		Expect(getUserAgent("", "", nil)).To(
+			Equal("CIS/unknown cluster/unknown go/" + runtime.Version()))
+	})
+})
+
+var _ = Describe("Service Account User", func() {
+	It("Service account user from the token subject", func() {
+		payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"system:serviceaccount:kube-system:bigip-ctlr"}`))
+		Expect(getServiceAccountUser(&rest.Config{BearerToken: "header." + payload + ".signature"})).To(
+			Equal("system:serviceaccount:kube-system:bigip-ctlr"))
+		Expect(getServiceAccountUser(&rest.Config{Username: "admin"})).To(Equal("admin"))
+		Expect(getServiceAccountUser(nil)).To(Equal("unknown"))
+	})
+})
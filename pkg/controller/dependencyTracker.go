@@ -0,0 +1,74 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"sort"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+)
+
+// addDependency records that the dependent waits for the dependency to become valid
+func (dt *dependencyTracker) addDependency(dependency, dependent resourceRef) {
+	dt.Lock()
+	defer dt.Unlock()
+	if dt.dependents == nil {
+		dt.dependents = make(map[resourceRef]map[resourceRef]struct{})
+	}
+	if _, ok := dt.dependents[dependency]; !ok {
+		dt.dependents[dependency] = make(map[resourceRef]struct{})
+	}
+	dt.dependents[dependency][dependent] = struct{}{}
+}
+
+// removeDependents removes and returns the resources waiting for the dependency, sorted by namespace and name
+func (dt *dependencyTracker) removeDependents(dependency resourceRef) []resourceRef {
+	dt.Lock()
+	defer dt.Unlock()
+	var dependents []resourceRef
+	for dependent := range dt.dependents[dependency] {
+		dependents = append(dependents, dependent)
+	}
+	delete(dt.dependents, dependency)
+	sort.Slice(dependents, func(i, j int) bool {
+		if dependents[i].namespace != dependents[j].namespace {
+			return dependents[i].namespace < dependents[j].namespace
+		}
+		return dependents[i].name < dependents[j].name
+	})
+	return dependents
+}
+
+// enqueueDependents enqueues the VirtualServers which waited for the dependency to become valid
+func (ctlr *Controller) enqueueDependents(dependency resourceRef) {
+	for _, dependent := range ctlr.resourceDependencies.removeDependents(dependency) {
+		if dependent.kind != VirtualServer {
+			continue
+		}
+		crInf, ok := ctlr.getNamespacedCRInformer(dependent.namespace)
+		if !ok {
+			continue
+		}
+		obj, found, _ := crInf.vsInformer.GetIndexer().GetByKey(dependent.namespace + "/" + dependent.name)
+		if !found {
+			continue
+		}
+		log.Debugf("%v %v/%v became valid, enqueueing VirtualServer %v/%v", dependency.kind, dependency.namespace,
+			dependency.name, dependent.namespace, dependent.name)
+		ctlr.enqueueVirtualServer(obj)
+	}
+}
@@ -105,6 +105,12 @@ func (ctlr *Controller) startInformers() {
 	for _, inf := range ctlr.comInformers {
 		inf.start()
 	}
+	if ctlr.adminInformer != nil {
+		ctlr.adminInformer.start()
+	}
+	if ctlr.nsAnnotationInformer != nil {
+		ctlr.nsAnnotationInformer.start()
+	}
 	if ctlr.managedResources.ManageRoutes { // nrInformers only with openShiftMode
 		for _, inf := range ctlr.nrInformers {
 			inf.start()
@@ -137,6 +143,12 @@ func (ctlr *Controller) stopInformers() {
 	for ns, nsInf := range ctlr.nsInformers {
 		nsInf.stop(ns)
 	}
+	if ctlr.adminInformer != nil {
+		ctlr.adminInformer.stop()
+	}
+	if ctlr.nsAnnotationInformer != nil {
+		ctlr.nsAnnotationInformer.stop("")
+	}
 	// stop node Informer
 	for _, nodeInf := range ctlr.multiClusterNodeInformers {
 		nodeInf.stop()
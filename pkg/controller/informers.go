@@ -33,7 +33,10 @@ import (
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
 	routeapi "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 )
@@ -174,6 +177,11 @@ func (comInfr *CommonInformer) start() {
 		go comInfr.epsInformer.Run(comInfr.stopCh)
 		cacheSyncs = append(cacheSyncs, comInfr.epsInformer.HasSynced)
 	}
+	if comInfr.epSliceInformer != nil {
+		log.Debugf("Starting endpointSlice informer for namespace %v", comInfr.namespace)
+		go comInfr.epSliceInformer.Run(comInfr.stopCh)
+		cacheSyncs = append(cacheSyncs, comInfr.epSliceInformer.HasSynced)
+	}
 	if comInfr.ednsInformer != nil {
 		log.Debugf("Starting externalDNS informer for namespace %v", comInfr.namespace)
 		go comInfr.ednsInformer.Run(comInfr.stopCh)
@@ -184,6 +192,26 @@ func (comInfr *CommonInformer) start() {
 		go comInfr.plcInformer.Run(comInfr.stopCh)
 		cacheSyncs = append(cacheSyncs, comInfr.plcInformer.HasSynced)
 	}
+	if comInfr.adlInformer != nil {
+		log.Debugf("Starting addressList informer for namespace %v", comInfr.namespace)
+		go comInfr.adlInformer.Run(comInfr.stopCh)
+		cacheSyncs = append(cacheSyncs, comInfr.adlInformer.HasSynced)
+	}
+	if comInfr.prtInformer != nil {
+		log.Debugf("Starting portList informer for namespace %v", comInfr.namespace)
+		go comInfr.prtInformer.Run(comInfr.stopCh)
+		cacheSyncs = append(cacheSyncs, comInfr.prtInformer.HasSynced)
+	}
+	if comInfr.natInformer != nil {
+		log.Debugf("Starting natPolicy informer for namespace %v", comInfr.namespace)
+		go comInfr.natInformer.Run(comInfr.stopCh)
+		cacheSyncs = append(cacheSyncs, comInfr.natInformer.HasSynced)
+	}
+	if comInfr.snatInformer != nil {
+		log.Debugf("Starting snatPool informer for namespace %v", comInfr.namespace)
+		go comInfr.snatInformer.Run(comInfr.stopCh)
+		cacheSyncs = append(cacheSyncs, comInfr.snatInformer.HasSynced)
+	}
 	if comInfr.podInformer != nil {
 		log.Debugf("Starting pod informer for namespace %v", comInfr.namespace)
 		go comInfr.podInformer.Run(comInfr.stopCh)
@@ -199,6 +227,21 @@ func (comInfr *CommonInformer) start() {
 		go comInfr.configCRInformer.Run(comInfr.stopCh)
 		cacheSyncs = append(cacheSyncs, comInfr.configCRInformer.HasSynced)
 	}
+	if comInfr.npInformer != nil {
+		log.Debugf("Starting networkPolicy informer for namespace %v", comInfr.namespace)
+		go comInfr.npInformer.Run(comInfr.stopCh)
+		cacheSyncs = append(cacheSyncs, comInfr.npInformer.HasSynced)
+	}
+	if comInfr.ingressInformer != nil {
+		log.Debugf("Starting ingress informer for namespace %v", comInfr.namespace)
+		go comInfr.ingressInformer.Run(comInfr.stopCh)
+		cacheSyncs = append(cacheSyncs, comInfr.ingressInformer.HasSynced)
+	}
+	if comInfr.cmInformer != nil {
+		log.Debugf("Starting configMap informer for namespace %v", comInfr.namespace)
+		go comInfr.cmInformer.Run(comInfr.stopCh)
+		cacheSyncs = append(cacheSyncs, comInfr.cmInformer.HasSynced)
+	}
 	cache.WaitForNamedCacheSync(
 		"F5 CIS Ingress Controller",
 		comInfr.stopCh,
@@ -530,6 +573,20 @@ func (ctlr *Controller) newNamespacedCommonResourceInformer(
 			resyncPeriod,
 			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
 		)
+		// the topology hints of the endpoints are available only in the EndpointSlices
+		if ctlr.topologyConfig.Enabled {
+			comInf.epSliceInformer = cache.NewSharedIndexInformer(
+				cache.NewFilteredListWatchFromClient(
+					ctlr.clientsets.KubeClient.DiscoveryV1().RESTClient(),
+					"endpointslices",
+					namespace,
+					everything,
+				),
+				&discoveryv1.EndpointSlice{},
+				resyncPeriod,
+				cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+			)
+		}
 	}
 
 	if ctlr.managedResources.ManageEDNS {
@@ -550,6 +607,37 @@ func (ctlr *Controller) newNamespacedCommonResourceInformer(
 		crOptions,
 	)
 
+	comInf.adlInformer = cisinfv1.NewFilteredAddressListInformer(
+		ctlr.clientsets.KubeCRClient,
+		namespace,
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		crOptions,
+	)
+
+	comInf.prtInformer = cisinfv1.NewFilteredPortListInformer(
+		ctlr.clientsets.KubeCRClient,
+		namespace,
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		crOptions,
+	)
+
+	comInf.natInformer = cisinfv1.NewFilteredNATPolicyInformer(
+		ctlr.clientsets.KubeCRClient,
+		namespace,
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		crOptions,
+	)
+
+	comInf.snatInformer = cisinfv1.NewFilteredSNATPoolInformer(
+		ctlr.clientsets.KubeCRClient,
+		namespace,
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		crOptions,
+	)
 	comInf.configCRInformer = cisinfv1.NewFilteredDeployConfigInformer(
 		ctlr.clientsets.KubeCRClient,
 		namespace,
@@ -575,6 +663,50 @@ func (ctlr *Controller) newNamespacedCommonResourceInformer(
 			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
 		)
 	}
+
+	// NetworkPolicies of the namespaces for the firewall policies and the cross namespace services
+	if ctlr.firewallEnabled || ctlr.bigipSourceIP != "" {
+		comInf.npInformer = cache.NewSharedIndexInformer(
+			cache.NewFilteredListWatchFromClient(
+				ctlr.clientsets.KubeClient.NetworkingV1().RESTClient(),
+				"networkpolicies",
+				namespace,
+				everything,
+			),
+			&networkingv1.NetworkPolicy{},
+			resyncPeriod,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		)
+	}
+
+	if ctlr.manageIngress {
+		comInf.ingressInformer = cache.NewSharedIndexInformer(
+			cache.NewFilteredListWatchFromClient(
+				ctlr.clientsets.KubeClient.NetworkingV1().RESTClient(),
+				"ingresses",
+				namespace,
+				everything,
+			),
+			&networkingv1.Ingress{},
+			resyncPeriod,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		)
+	}
+
+	// ConfigMaps referenced by the ifile-configmap annotation of the VirtualServers
+	if ctlr.managedResources.ManageCustomResources {
+		comInf.cmInformer = cache.NewSharedIndexInformer(
+			cache.NewFilteredListWatchFromClient(
+				restClientv1,
+				"configmaps",
+				namespace,
+				everything,
+			),
+			&corev1.ConfigMap{},
+			resyncPeriod,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		)
+	}
 	return comInf
 }
 
@@ -594,7 +726,7 @@ func (ctlr *Controller) addCustomResourceEventHandlers(crInf *CRInformer) {
 		crInf.tlsInformer.AddEventHandler(
 			&cache.ResourceEventHandlerFuncs{
 				AddFunc:    func(obj interface{}) { ctlr.enqueueTLSProfile(obj, Create) },
-				UpdateFunc: func(old, cur interface{}) { ctlr.enqueueTLSProfile(cur, Update) },
+				UpdateFunc: func(old, cur interface{}) { ctlr.enqueueUpdatedTLSProfile(old, cur) },
 				// DeleteFunc: func(obj interface{}) { ctlr.enqueueTLSProfile(obj) },
 			},
 		)
@@ -647,6 +779,17 @@ func (ctlr *Controller) addCommonResourceEventHandlers(comInf *CommonInformer) {
 		comInf.epsInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(Endpoints, Local))
 	}
 
+	if comInf.epSliceInformer != nil {
+		comInf.epSliceInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueueEndpointSlice(obj, comInf) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueEndpointSlice(cur, comInf) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueueEndpointSlice(obj, comInf) },
+			},
+		)
+		comInf.epSliceInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(Endpoints, Local))
+	}
+
 	if comInf.ednsInformer != nil {
 		comInf.ednsInformer.AddEventHandler(
 			&cache.ResourceEventHandlerFuncs{
@@ -668,6 +811,50 @@ func (ctlr *Controller) addCommonResourceEventHandlers(comInf *CommonInformer) {
 		comInf.plcInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(CustomPolicy, Local))
 	}
 
+	if comInf.adlInformer != nil {
+		comInf.adlInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueueAddressList(obj, Create) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueAddressList(cur, Update) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueueAddressList(obj, Delete) },
+			},
+		)
+		comInf.adlInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(AddressList, Local))
+	}
+
+	if comInf.prtInformer != nil {
+		comInf.prtInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueuePortList(obj, Create) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueuePortList(cur, Update) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueuePortList(obj, Delete) },
+			},
+		)
+		comInf.prtInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(PortList, Local))
+	}
+
+	if comInf.natInformer != nil {
+		comInf.natInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueueNATPolicy(obj, Create) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueNATPolicy(cur, Update) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueueNATPolicy(obj, Delete) },
+			},
+		)
+		comInf.natInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(NATPolicy, Local))
+	}
+
+	if comInf.snatInformer != nil {
+		comInf.snatInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueueSNATPool(obj, Create) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueSNATPool(cur, Update) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueueSNATPool(obj, Delete) },
+			},
+		)
+		comInf.snatInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(SNATPool, Local))
+	}
+
 	if comInf.podInformer != nil {
 		comInf.podInformer.AddEventHandler(
 			&cache.ResourceEventHandlerFuncs{
@@ -690,6 +877,39 @@ func (ctlr *Controller) addCommonResourceEventHandlers(comInf *CommonInformer) {
 		comInf.secretsInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(Secret, Local))
 	}
 
+	if comInf.npInformer != nil {
+		comInf.npInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueueNetworkPolicy(obj, Create) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueNetworkPolicy(cur, Update) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueueNetworkPolicy(obj, Delete) },
+			},
+		)
+		comInf.npInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(NetworkPolicy, Local))
+	}
+
+	if comInf.ingressInformer != nil {
+		comInf.ingressInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueueIngress(obj, Create) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueUpdatedIngress(obj, cur) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueueIngress(obj, Delete) },
+			},
+		)
+		comInf.ingressInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(Ingress, Local))
+	}
+
+	if comInf.cmInformer != nil {
+		comInf.cmInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueueConfigMap(obj, Create) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueConfigMap(cur, Update) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueueConfigMap(obj, Delete) },
+			},
+		)
+		comInf.cmInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(ConfigMap, Local))
+	}
+
 	if comInf.configCRInformer != nil {
 		comInf.configCRInformer.AddEventHandler(
 			&cache.ResourceEventHandlerFuncs{
@@ -800,11 +1020,26 @@ func (ctlr *Controller) enqueueVirtualServer(obj interface{}) {
 	ctlr.resourceQueue.Add(key)
 }
 
+// resyncVirtualServer enqueues the VirtualServer as updated, so that it is processed again when a resource
+// it refers to changes. The created VirtualServers are skipped once processed
+func (ctlr *Controller) resyncVirtualServer(vs *cisapiv1.VirtualServer) {
+	log.Debugf("Enqueueing VirtualServer for resync: %v/%v", vs.Namespace, vs.Name)
+	key := &rqKey{
+		namespace: vs.ObjectMeta.Namespace,
+		kind:      VirtualServer,
+		rscName:   vs.ObjectMeta.Name,
+		rsc:       vs,
+		event:     Update,
+	}
+	ctlr.resourceQueue.Add(key)
+}
+
 func (ctlr *Controller) enqueueUpdatedVirtualServer(oldObj, newObj interface{}) {
 	oldVS := oldObj.(*cisapiv1.VirtualServer)
 	newVS := newObj.(*cisapiv1.VirtualServer)
 	// Skip virtual servers on status updates
-	if reflect.DeepEqual(oldVS.Spec, newVS.Spec) && reflect.DeepEqual(oldVS.Labels, newVS.Labels) {
+	if reflect.DeepEqual(oldVS.Spec, newVS.Spec) && reflect.DeepEqual(oldVS.Labels, newVS.Labels) &&
+		reflect.DeepEqual(oldVS.Annotations, newVS.Annotations) {
 		return
 	}
 	updateEvent := true
@@ -882,6 +1117,21 @@ func (ctlr *Controller) enqueueTLSProfile(obj interface{}, event string) {
 	ctlr.resourceQueue.Add(key)
 }
 
+func (ctlr *Controller) enqueueUpdatedTLSProfile(oldObj, newObj interface{}) {
+	oldTLS := oldObj.(*cisapiv1.TLSProfile)
+	newTLS := newObj.(*cisapiv1.TLSProfile)
+	// Skip TLSProfiles on status updates, only the VirtualServers waiting for the TLSProfile are enqueued
+	// when it becomes valid
+	if reflect.DeepEqual(oldTLS.Spec, newTLS.Spec) {
+		if !meta.IsStatusConditionTrue(oldTLS.Status.Conditions, TLSProfileConditionValid) &&
+			meta.IsStatusConditionTrue(newTLS.Status.Conditions, TLSProfileConditionValid) {
+			ctlr.enqueueDependents(resourceRef{kind: TLSProfile, namespace: newTLS.Namespace, name: newTLS.Name})
+		}
+		return
+	}
+	ctlr.enqueueTLSProfile(newObj, Update)
+}
+
 func (ctlr *Controller) enqueueTransportServer(obj interface{}) {
 	ts := obj.(*cisapiv1.TransportServer)
 	log.Debugf("Enqueueing TransportServer: %v", ts)
@@ -980,6 +1230,76 @@ func (ctlr *Controller) enqueuePolicy(obj interface{}, event string) {
 	ctlr.resourceQueue.Add(key)
 }
 
+func (ctlr *Controller) enqueueAddressList(obj interface{}, event string) {
+	adl := obj.(*cisapiv1.AddressList)
+	log.Debugf("Enqueueing AddressList: %v", adl)
+	key := &rqKey{
+		namespace: adl.ObjectMeta.Namespace,
+		kind:      AddressList,
+		rscName:   adl.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+
+	ctlr.resourceQueue.Add(key)
+}
+
+func (ctlr *Controller) enqueuePortList(obj interface{}, event string) {
+	prt := obj.(*cisapiv1.PortList)
+	log.Debugf("Enqueueing PortList: %v", prt)
+	key := &rqKey{
+		namespace: prt.ObjectMeta.Namespace,
+		kind:      PortList,
+		rscName:   prt.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+
+	ctlr.resourceQueue.Add(key)
+}
+
+func (ctlr *Controller) enqueueNATPolicy(obj interface{}, event string) {
+	nat := obj.(*cisapiv1.NATPolicy)
+	log.Debugf("Enqueueing NATPolicy: %v", nat)
+	key := &rqKey{
+		namespace: nat.ObjectMeta.Namespace,
+		kind:      NATPolicy,
+		rscName:   nat.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+
+	ctlr.resourceQueue.Add(key)
+}
+
+func (ctlr *Controller) enqueuePacketFilter(obj interface{}, event string) {
+	filter := obj.(*cisapiv1.PacketFilter)
+	log.Debugf("Enqueueing PacketFilter: %v", filter)
+	key := &rqKey{
+		namespace: filter.ObjectMeta.Namespace,
+		kind:      PacketFilter,
+		rscName:   filter.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+
+	ctlr.resourceQueue.Add(key)
+}
+
+func (ctlr *Controller) enqueueSNATPool(obj interface{}, event string) {
+	snat := obj.(*cisapiv1.SNATPool)
+	log.Debugf("Enqueueing SNATPool: %v", snat)
+	key := &rqKey{
+		namespace: snat.ObjectMeta.Namespace,
+		kind:      SNATPool,
+		rscName:   snat.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+
+	ctlr.resourceQueue.Add(key)
+}
+
 func (ctlr *Controller) enqueueDeletedPolicy(obj interface{}) {
 	pol := obj.(*cisapiv1.Policy)
 	log.Debugf("Enqueueing Policy: %v", pol)
@@ -1243,6 +1563,58 @@ func (ctlr *Controller) enqueueEndpoints(obj interface{}, event string, clusterN
 	ctlr.resourceQueue.Add(key)
 }
 
+func (ctlr *Controller) enqueueIngress(obj interface{}, event string) {
+	ing, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		return
+	}
+	log.Debugf("Enqueueing Ingress: %v/%v", ing.Namespace, ing.Name)
+	key := &rqKey{
+		namespace: ing.ObjectMeta.Namespace,
+		kind:      Ingress,
+		rscName:   ing.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+	ctlr.resourceQueue.Add(key)
+}
+
+func (ctlr *Controller) enqueueUpdatedIngress(oldObj, newObj interface{}) {
+	oldIng := oldObj.(*networkingv1.Ingress)
+	newIng := newObj.(*networkingv1.Ingress)
+	// Skip ingresses on status updates
+	if reflect.DeepEqual(oldIng.Spec, newIng.Spec) && reflect.DeepEqual(oldIng.Annotations, newIng.Annotations) {
+		return
+	}
+	event := Update
+	oldPartition := ctlr.getCRPartition(oldIng.Annotations[F5VsPartitionAnnotation])
+	newPartition := ctlr.getCRPartition(newIng.Annotations[F5VsPartitionAnnotation])
+	// the virtuals of the ingress are deleted from the previous partition
+	if oldPartition != newPartition {
+		bigipConfig := ctlr.getBIGIPConfig(BigIPLabel)
+		ctlr.resources.updatePartitionPriority(oldPartition, 1, bigipConfig)
+		ctlr.enqueueIngress(oldObj, Delete)
+		event = Create
+	}
+	ctlr.enqueueIngress(newObj, event)
+}
+
+func (ctlr *Controller) enqueueNetworkPolicy(obj interface{}, event string) {
+	np, ok := obj.(*networkingv1.NetworkPolicy)
+	if !ok {
+		return
+	}
+	log.Debugf("Enqueueing NetworkPolicy: %v/%v", np.Namespace, np.Name)
+	key := &rqKey{
+		namespace: np.ObjectMeta.Namespace,
+		kind:      NetworkPolicy,
+		rscName:   np.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+	ctlr.resourceQueue.Add(key)
+}
+
 func (ctlr *Controller) enqueueSecret(obj interface{}, event string) {
 	secret := obj.(*corev1.Secret)
 	log.Debugf("Enqueueing Secrets: %v/%v", secret.Namespace, secret.Name)
@@ -1387,6 +1759,36 @@ func (ctlr *Controller) enqueuePod(obj interface{}, clusterName string) {
 	ctlr.resourceQueue.Add(key)
 }
 
+// enqueueEndpointSlice enqueues the Endpoints of the EndpointSlice's service, so that the
+// pool members are updated with the topology hints of the slice.
+func (ctlr *Controller) enqueueEndpointSlice(obj interface{}, comInf *CommonInformer) {
+	var slice *discoveryv1.EndpointSlice
+	switch obj.(type) {
+	case *discoveryv1.EndpointSlice:
+		slice = obj.(*discoveryv1.EndpointSlice)
+	case cache.DeletedFinalStateUnknown:
+		dFSUObj := obj.(cache.DeletedFinalStateUnknown)
+		var ok bool
+		slice, ok = dFSUObj.Obj.(*discoveryv1.EndpointSlice)
+		if slice == nil || !ok {
+			log.Warningf("Unknown object received as endpointSlice event: %v", dFSUObj.Key)
+			return
+		}
+	default:
+		log.Warningf("Unknown object received as endpointSlice event: %v", obj)
+		return
+	}
+	svcName, ok := slice.Labels[discoveryv1.LabelServiceName]
+	if !ok || comInf.epsInformer == nil {
+		return
+	}
+	item, found, _ := comInf.epsInformer.GetIndexer().GetByKey(slice.Namespace + "/" + svcName)
+	if !found {
+		return
+	}
+	ctlr.enqueueEndpoints(item, Update, "")
+}
+
 func (ctlr *Controller) enqueueDeletedPod(obj interface{}, clusterName string) {
 	var pod *corev1.Pod
 	switch obj.(type) {
@@ -1445,6 +1847,146 @@ func (nodeInfr *NodeInformer) stop() {
 	close(nodeInfr.stopCh)
 }
 
+func (adminInfr *AdminInformer) start() {
+	if adminInfr.cmInformer != nil {
+		log.Debugf("Starting ConfigMap informer for admin namespace %v", adminInfr.namespace)
+		go adminInfr.cmInformer.Run(adminInfr.stopCh)
+	}
+	if adminInfr.packetFilterInformer != nil {
+		log.Debugf("Starting packetFilter informer for admin namespace %v", adminInfr.namespace)
+		go adminInfr.packetFilterInformer.Run(adminInfr.stopCh)
+	}
+}
+
+func (adminInfr *AdminInformer) stop() {
+	log.Debugf("Stopping informers for admin namespace %v", adminInfr.namespace)
+	close(adminInfr.stopCh)
+}
+
+// newNamespaceAnnotationInformer creates the informer of the namespaces of the VirtualServers, the namespaces
+// are filtered with the namespace label when one is configured
+func (ctlr *Controller) newNamespaceAnnotationInformer() *NSInformer {
+	namespaceOptions := func(options *metav1.ListOptions) {
+		options.LabelSelector = ctlr.resourceSelectorConfig.NamespaceLabel
+	}
+	return &NSInformer{
+		stopCh: make(chan struct{}),
+		nsInformer: cache.NewSharedIndexInformer(
+			cache.NewFilteredListWatchFromClient(
+				ctlr.clientsets.KubeClient.CoreV1().RESTClient(),
+				"namespaces",
+				"",
+				namespaceOptions,
+			),
+			&corev1.Namespace{},
+			0*time.Second,
+			cache.Indexers{},
+		),
+	}
+}
+
+func (ctlr *Controller) addNamespaceAnnotationEventHandlers(nsInf *NSInformer) {
+	nsInf.nsInformer.AddEventHandler(
+		&cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, cur interface{}) { ctlr.enqueueUpdatedNamespaceAnnotations(old, cur) },
+		},
+	)
+	nsInf.nsInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(Namespace, Local))
+}
+
+// enqueueUpdatedNamespaceAnnotations re-syncs the VirtualServers of the namespace when the annotations that
+// apply to their tenants change
+func (ctlr *Controller) enqueueUpdatedNamespaceAnnotations(oldObj, newObj interface{}) {
+	oldNS, ok := oldObj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	newNS, ok := newObj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	if oldNS.Annotations[AS3LogLevelAnnotation] == newNS.Annotations[AS3LogLevelAnnotation] {
+		return
+	}
+	for _, vs := range ctlr.getAllVirtualServers(newNS.Name) {
+		ctlr.resyncVirtualServer(vs)
+	}
+}
+
+// newAdminInformer creates the informers of the ConfigMaps and the PacketFilters of the admin namespace
+func (ctlr *Controller) newAdminInformer(namespace string) *AdminInformer {
+	log.Debugf("Creating informers for admin namespace %v", namespace)
+	everything := func(options *metav1.ListOptions) {
+		options.LabelSelector = ""
+	}
+	crOptions := func(options *metav1.ListOptions) {
+		options.LabelSelector = ctlr.resourceSelectorConfig.customResourceSelector.String()
+	}
+	adminInf := &AdminInformer{
+		namespace: namespace,
+		stopCh:    make(chan struct{}),
+		cmInformer: cache.NewSharedIndexInformer(
+			cache.NewFilteredListWatchFromClient(
+				ctlr.clientsets.KubeClient.CoreV1().RESTClient(),
+				"configmaps",
+				namespace,
+				everything,
+			),
+			&corev1.ConfigMap{},
+			0*time.Second,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		),
+	}
+	if ctlr.clientsets.KubeCRClient != nil {
+		adminInf.packetFilterInformer = cisinfv1.NewFilteredPacketFilterInformer(
+			ctlr.clientsets.KubeCRClient,
+			namespace,
+			0*time.Second,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+			crOptions,
+		)
+	}
+	return adminInf
+}
+
+func (ctlr *Controller) addAdminEventHandlers(adminInf *AdminInformer) {
+	adminInf.cmInformer.AddEventHandler(
+		&cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { ctlr.enqueueConfigMap(obj, Create) },
+			UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueConfigMap(cur, Update) },
+			DeleteFunc: func(obj interface{}) { ctlr.enqueueConfigMap(obj, Delete) },
+		},
+	)
+	adminInf.cmInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(ConfigMap, Local))
+
+	if adminInf.packetFilterInformer != nil {
+		adminInf.packetFilterInformer.AddEventHandler(
+			&cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctlr.enqueuePacketFilter(obj, Create) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueuePacketFilter(cur, Update) },
+				DeleteFunc: func(obj interface{}) { ctlr.enqueuePacketFilter(obj, Delete) },
+			},
+		)
+		adminInf.packetFilterInformer.SetWatchErrorHandler(ctlr.getErrorHandlerFunc(PacketFilter, Local))
+	}
+}
+
+func (ctlr *Controller) enqueueConfigMap(obj interface{}, event string) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+	log.Debugf("Enqueueing ConfigMap: %v/%v", cm.Namespace, cm.Name)
+	key := &rqKey{
+		namespace: cm.ObjectMeta.Namespace,
+		kind:      ConfigMap,
+		rscName:   cm.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+	ctlr.resourceQueue.Add(key)
+}
+
 func (ctlr *Controller) createNamespaceLabeledInformer(label string) error {
 	selector, err := createLabelSelector(label)
 	if err != nil {
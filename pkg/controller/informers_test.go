@@ -13,6 +13,7 @@ import (
 	. "github.com/onsi/gomega"
 	routeapi "github.com/openshift/api/route/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
@@ -244,6 +245,54 @@ var _ = Describe("Informers Tests", func() {
 			Expect(mockCtlr.processResources()).To(Equal(true))
 		})
 
+		It("TLS Profile dependents", func() {
+			_ = mockCtlr.addNamespacedInformers(namespace, false)
+			tlsp := test.NewTLSProfile(
+				"SampleTLS",
+				namespace,
+				cisapiv1.TLSProfileSpec{
+					TLS: cisapiv1.TLS{Termination: "edge"},
+				})
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host:           "test.com",
+					TLSProfileName: "SampleTLS",
+				})
+			crInf, _ := mockCtlr.getNamespacedCRInformer(namespace)
+			_ = crInf.vsInformer.GetStore().Add(vs)
+			_ = crInf.tlsInformer.GetStore().Add(tlsp)
+			// VirtualServer waits for the invalid TLSProfile
+			Expect(mockCtlr.getTLSProfileForVirtualServer(vs)).To(BeNil())
+
+			// status update without the Valid condition becoming true is skipped
+			invalidTLS := tlsp.DeepCopy()
+			meta.SetStatusCondition(&invalidTLS.Status.Conditions, metav1.Condition{Type: TLSProfileConditionValid,
+				Status: metav1.ConditionFalse, Reason: TLSProfileReasonInvalid})
+			Expect(isTLSProfileInvalid(invalidTLS)).To(BeTrue())
+			mockCtlr.enqueueUpdatedTLSProfile(tlsp, invalidTLS)
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(0), "TLSProfile status update should be skipped")
+
+			// the waiting VirtualServer is enqueued once the TLSProfile becomes valid
+			validTLS := invalidTLS.DeepCopy()
+			meta.SetStatusCondition(&validTLS.Status.Conditions, metav1.Condition{Type: TLSProfileConditionValid,
+				Status: metav1.ConditionTrue, Reason: TLSProfileReasonValid})
+			mockCtlr.enqueueUpdatedTLSProfile(invalidTLS, validTLS)
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(1), "Dependent VirtualServer not enqueued")
+			key, _ := mockCtlr.resourceQueue.Get()
+			Expect(key.(*rqKey).kind).To(Equal(VirtualServer))
+			Expect(key.(*rqKey).rscName).To(Equal("SampleVS"))
+			Expect(mockCtlr.resourceDependencies.removeDependents(
+				resourceRef{kind: TLSProfile, namespace: namespace, name: "SampleTLS"})).To(BeEmpty())
+
+			// spec updates are enqueued
+			updatedTLS := validTLS.DeepCopy()
+			updatedTLS.Spec.TLS.ClientSSL = "/Common/clientssl"
+			mockCtlr.enqueueUpdatedTLSProfile(validTLS, updatedTLS)
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(1), "TLSProfile spec update should be enqueued")
+		})
+
 		It("TransportServer", func() {
 			ts := test.NewTransportServer(
 				"SampleTS",
@@ -0,0 +1,245 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// isF5Ingress checks whether the Ingress is of the f5 ingress class
+func isF5Ingress(ing *networkingv1.Ingress) bool {
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName == F5IngressClass
+	}
+	return ing.Annotations[IngressClassAnnotation] == F5IngressClass
+}
+
+// translateIngress translates the rules of an Ingress into VirtualServers, one per host, with a pool
+// for each of the http paths and the edge TLSProfiles of the hosts covered by spec.tls.
+// The spec.defaultBackend is set as the default pool of the VirtualServers
+func translateIngress(ing *networkingv1.Ingress) ([]*cisapiv1.VirtualServer, map[string]*cisapiv1.TLSProfile) {
+	tlsProfiles := make(map[string]*cisapiv1.TLSProfile)
+	for _, tls := range ing.Spec.TLS {
+		if tls.SecretName == "" {
+			continue
+		}
+		for _, host := range tls.Hosts {
+			if _, ok := tlsProfiles[host]; ok {
+				continue
+			}
+			tlsProfiles[host] = &cisapiv1.TLSProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tls.SecretName,
+					Namespace: ing.Namespace,
+				},
+				Spec: cisapiv1.TLSProfileSpec{
+					Hosts: []string{host},
+					TLS: cisapiv1.TLS{
+						Termination: TLSEdge,
+						ClientSSL:   tls.SecretName,
+						Reference:   Secret,
+					},
+				},
+			}
+		}
+	}
+
+	var virtuals []*cisapiv1.VirtualServer
+	vsByHost := make(map[string]*cisapiv1.VirtualServer)
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		vs, ok := vsByHost[rule.Host]
+		if !ok {
+			vs = &cisapiv1.VirtualServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        ing.Name,
+					Namespace:   ing.Namespace,
+					Annotations: ing.Annotations,
+				},
+				Spec: cisapiv1.VirtualServerSpec{
+					Host:        rule.Host,
+					HTTPTraffic: TLSAllowInsecure,
+				},
+			}
+			if tls, ok := tlsProfiles[rule.Host]; ok {
+				vs.Spec.TLSProfileName = tls.Name
+			}
+			vsByHost[rule.Host] = vs
+			virtuals = append(virtuals, vs)
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				log.Warningf("Skipping path %v of Ingress %v/%v without a service backend", path.Path,
+					ing.Namespace, ing.Name)
+				continue
+			}
+			pool := cisapiv1.VSPool{
+				Path:    path.Path,
+				Service: path.Backend.Service.Name,
+			}
+			if pool.Path == "" {
+				pool.Path = "/"
+			}
+			if path.Backend.Service.Port.Name != "" {
+				pool.ServicePort = intstr.FromString(path.Backend.Service.Port.Name)
+			} else {
+				pool.ServicePort = intstr.FromInt(int(path.Backend.Service.Port.Number))
+			}
+			vs.Spec.Pools = append(vs.Spec.Pools, pool)
+		}
+	}
+
+	if backend := ing.Spec.DefaultBackend; backend != nil {
+		if backend.Service == nil {
+			log.Warningf("Skipping default backend of Ingress %v/%v without a service", ing.Namespace, ing.Name)
+			return virtuals, tlsProfiles
+		}
+		defaultPool := cisapiv1.DefaultPool{
+			Service:   backend.Service.Name,
+			Reference: ServiceRef,
+		}
+		if backend.Service.Port.Name != "" {
+			defaultPool.ServicePort = intstr.FromString(backend.Service.Port.Name)
+		} else {
+			defaultPool.ServicePort = intstr.FromInt(int(backend.Service.Port.Number))
+		}
+		// an Ingress with only the default backend serves all the hosts
+		if len(virtuals) == 0 {
+			virtuals = append(virtuals, &cisapiv1.VirtualServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        ing.Name,
+					Namespace:   ing.Namespace,
+					Annotations: ing.Annotations,
+				},
+				Spec: cisapiv1.VirtualServerSpec{
+					HTTPTraffic: TLSAllowInsecure,
+				},
+			})
+		}
+		for _, vs := range virtuals {
+			vs.Spec.DefaultPool = defaultPool
+		}
+	}
+	return virtuals, tlsProfiles
+}
+
+// processIngress creates the HTTP and HTTPS virtuals of an Ingress of the f5 ingress class
+func (ctlr *Controller) processIngress(ing *networkingv1.Ingress, isDeleted bool) error {
+	startTime := time.Now()
+	defer func() {
+		endTime := time.Now()
+		log.Debugf("Finished syncing Ingress %v/%v (%v)", ing.Namespace, ing.Name, endTime.Sub(startTime))
+	}()
+
+	bigipConfig := ctlr.getBIGIPConfig(BigIPLabel)
+	partition := ctlr.getCRPartition(ing.Annotations[F5VsPartitionAnnotation])
+	name := fmt.Sprintf("ingress_%s_%s", ing.Namespace, ing.Name)
+	httpName := formatCustomVirtualServerName(name, DEFAULT_HTTP_PORT)
+	httpsName := formatCustomVirtualServerName(name, DEFAULT_HTTPS_PORT)
+
+	if isDeleted || !isF5Ingress(ing) {
+		ctlr.deleteVirtualServer(partition, httpName, bigipConfig)
+		ctlr.deleteVirtualServer(partition, httpsName, bigipConfig)
+		return nil
+	}
+	ip := ing.Annotations[F5VsBindAddrAnnotation]
+	if ip == "" {
+		log.Errorf("No %v annotation found for Ingress %v/%v", F5VsBindAddrAnnotation, ing.Namespace, ing.Name)
+		return nil
+	}
+
+	virtuals, tlsProfiles := translateIngress(ing)
+	if len(tlsProfiles) > 0 && !ctlr.managedResources.ManageSecrets {
+		log.Errorf("Ignoring the TLS of Ingress %v/%v as the kubernetes secrets are not managed", ing.Namespace, ing.Name)
+		tlsProfiles = map[string]*cisapiv1.TLSProfile{}
+		for _, vs := range virtuals {
+			vs.Spec.TLSProfileName = ""
+		}
+	}
+
+	vsMap := make(ResourceMap)
+	for _, port := range []int32{DEFAULT_HTTP_PORT, DEFAULT_HTTPS_PORT} {
+		rsName := httpName
+		if port == DEFAULT_HTTPS_PORT {
+			rsName = httpsName
+			if len(tlsProfiles) == 0 {
+				ctlr.deleteVirtualServer(partition, rsName, bigipConfig)
+				continue
+			}
+		}
+		rsCfg := &ResourceConfig{}
+		rsCfg.Virtual.Partition = partition
+		rsCfg.MetaData.ResourceType = VirtualServer
+		rsCfg.Virtual.Enabled = true
+		rsCfg.Virtual.Name = rsName
+		rsCfg.MetaData.Protocol = HTTP
+		if port == DEFAULT_HTTPS_PORT {
+			rsCfg.MetaData.Protocol = HTTPS
+		}
+		rsCfg.MetaData.baseResources = map[string]string{ing.Namespace + "/" + ing.Name: Ingress}
+		rsCfg.Virtual.SetVirtualAddress(ip, port)
+		rsCfg.IntDgMap = make(InternalDataGroupMap)
+		rsCfg.IRulesMap = make(IRulesMap)
+		rsCfg.customProfiles = make(map[SecretKey]CustomProfile)
+
+		defaultPoolSet := false
+		for _, vs := range virtuals {
+			tlsProf, secure := tlsProfiles[vs.Spec.Host]
+			if port == DEFAULT_HTTPS_PORT && !secure {
+				continue
+			}
+			var tlsTermination string
+			if port == DEFAULT_HTTPS_PORT {
+				tlsTermination = TLSEdge
+			}
+			// The HTTP virtual serves all the hosts of the Ingress
+			virtual := vs.DeepCopy()
+			if port == DEFAULT_HTTP_PORT {
+				virtual.Spec.TLSProfileName = ""
+			}
+			// the virtual has a single default pool, it is added with the first host only
+			if defaultPoolSet {
+				virtual.Spec.DefaultPool = cisapiv1.DefaultPool{}
+			}
+			defaultPoolSet = true
+			err := ctlr.prepareRSConfigFromVirtualServer(rsCfg, virtual, false, tlsTermination)
+			if err != nil {
+				return fmt.Errorf("unable to translate Ingress %v/%v: %v", ing.Namespace, ing.Name, err)
+			}
+			if port == DEFAULT_HTTPS_PORT && !ctlr.handleVirtualServerTLS(rsCfg, virtual, tlsProf, ip) {
+				return fmt.Errorf("unable to translate the TLS of Ingress %v/%v for host %v", ing.Namespace,
+					ing.Name, vs.Spec.Host)
+			}
+		}
+		vsMap[rsName] = rsCfg
+	}
+
+	rsMap := ctlr.resources.getPartitionResourceMap(partition, bigipConfig)
+	for rsName, rsCfg := range vsMap {
+		rsMap[rsName] = rsCfg
+	}
+	return nil
+}
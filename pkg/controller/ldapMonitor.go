@@ -0,0 +1,296 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	// ldapDNAttributeRegex matches the attribute types of the distinguished names, a name or a numeric OID
+	ldapDNAttributeRegex = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*|[0-9]+(\.[0-9]+)*)$`)
+	// ldapFilterAttributeRegex matches the attribute descriptions of the search filters, which may have options
+	ldapFilterAttributeRegex = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*|[0-9]+(\.[0-9]+)*)(;[A-Za-z0-9-]+)*$`)
+)
+
+// createServiceLDAPMonitor monitors the pool with the LDAP monitor of its Service annotated with
+// cis.f5.com/monitor-type: ldap, the bind password is read from the Secret of the cis.f5.com/monitor-secret
+// annotation of the Service
+func (ctlr *Controller) createServiceLDAPMonitor(pool *Pool, rsCfg *ResourceConfig) {
+	if pool.Cluster != "" {
+		return
+	}
+	_, svc := ctlr.fetchService(MultiClusterServiceKey{
+		serviceName: pool.ServiceName,
+		namespace:   pool.ServiceNamespace,
+	})
+	if svc == nil || !strings.EqualFold(svc.Annotations[MonitorTypeAnnotation], LDAPMonitorType) {
+		return
+	}
+	monitorName := formatMonitorName(svc.Namespace, svc.Name, LDAPMonitorType, pool.ServicePort, "", "")
+	monitor := Monitor{
+		Name:      monitorName,
+		Partition: rsCfg.Virtual.Partition,
+		Type:      LDAPMonitorType,
+		Base:      svc.Annotations[LDAPMonitorBaseAnnotation],
+		Filter:    svc.Annotations[LDAPMonitorFilterAnnotation],
+		Security:  strings.ToLower(svc.Annotations[LDAPMonitorSecurityAnnotation]),
+		Username:  svc.Annotations[LDAPMonitorUsernameAnnotation],
+	}
+	if err := validateLDAPMonitor(monitor); err != nil {
+		log.Errorf("Invalid LDAP monitor of service %v/%v: %v", svc.Namespace, svc.Name, err)
+		return
+	}
+	if secretName, ok := svc.Annotations[MonitorSecretAnnotation]; ok {
+		if monitor.Username == "" {
+			log.Errorf("Invalid LDAP monitor of service %v/%v: %v annotation requires the %v annotation",
+				svc.Namespace, svc.Name, MonitorSecretAnnotation, LDAPMonitorUsernameAnnotation)
+			return
+		}
+		// the Secret is read from the informer, its updates re-sync the pools of the services referencing it
+		comInf, ok := ctlr.getNamespacedCommonInformer(svc.Namespace)
+		if !ok || comInf.secretsInformer == nil {
+			log.Errorf("Unable to get Secret %v of %v annotation in service %v/%v: informer not found",
+				secretName, MonitorSecretAnnotation, svc.Namespace, svc.Name)
+			return
+		}
+		obj, found, err := comInf.secretsInformer.GetIndexer().GetByKey(svc.Namespace + "/" + secretName)
+		if err != nil || !found {
+			log.Errorf("Unable to get Secret %v of %v annotation in service %v/%v: %v",
+				secretName, MonitorSecretAnnotation, svc.Namespace, svc.Name, err)
+			return
+		}
+		secret := obj.(*v1.Secret)
+		password, ok := secret.Data[LDAPMonitorPasswordKey]
+		if !ok && len(secret.Data) == 1 {
+			for _, value := range secret.Data {
+				password = value
+			}
+		} else if !ok {
+			log.Errorf("Secret %v of %v annotation in service %v/%v has no %v key",
+				secretName, MonitorSecretAnnotation, svc.Namespace, svc.Name, LDAPMonitorPasswordKey)
+			return
+		}
+		monitor.Password = base64.StdEncoding.EncodeToString(password)
+	}
+	pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: JoinBigipPath(rsCfg.Virtual.Partition, monitorName)})
+	for _, existing := range rsCfg.Monitors {
+		if existing.Name == monitorName {
+			return
+		}
+	}
+	rsCfg.Monitors = append(rsCfg.Monitors, monitor)
+}
+
+// getServicesForMonitorSecret returns the services whose monitor reads the password from the Secret
+func (ctlr *Controller) getServicesForMonitorSecret(secret *v1.Secret) []*v1.Service {
+	comInf, ok := ctlr.getNamespacedCommonInformer(secret.Namespace)
+	if !ok || comInf.svcInformer == nil {
+		return nil
+	}
+	objs, err := comInf.svcInformer.GetIndexer().ByIndex(cache.NamespaceIndex, secret.Namespace)
+	if err != nil {
+		return nil
+	}
+	var services []*v1.Service
+	for _, obj := range objs {
+		if svc, ok := obj.(*v1.Service); ok && svc.Annotations[MonitorSecretAnnotation] == secret.Name {
+			services = append(services, svc)
+		}
+	}
+	return services
+}
+
+// validateLDAPMonitor returns the error of the LDAP monitor parameters which BIG-IP would reject
+func validateLDAPMonitor(monitor Monitor) error {
+	if monitor.Base == "" {
+		return fmt.Errorf("%v annotation is required", LDAPMonitorBaseAnnotation)
+	}
+	if err := validateLDAPDN(monitor.Base); err != nil {
+		return fmt.Errorf("invalid %v annotation: %v", LDAPMonitorBaseAnnotation, err)
+	}
+	if monitor.Filter == "" {
+		return fmt.Errorf("%v annotation is required", LDAPMonitorFilterAnnotation)
+	}
+	if err := validateLDAPFilter(monitor.Filter); err != nil {
+		return fmt.Errorf("invalid %v annotation: %v", LDAPMonitorFilterAnnotation, err)
+	}
+	switch monitor.Security {
+	case "", "none", "ssl", "tls":
+	default:
+		return fmt.Errorf("invalid %v annotation %v, supported values are none, ssl and tls",
+			LDAPMonitorSecurityAnnotation, monitor.Security)
+	}
+	if monitor.Username != "" {
+		if err := validateLDAPDN(monitor.Username); err != nil {
+			return fmt.Errorf("invalid %v annotation: %v", LDAPMonitorUsernameAnnotation, err)
+		}
+	}
+	return nil
+}
+
+// validateLDAPDN returns the error of a distinguished name which doesn't conform to RFC 4514,
+// e.g. cn=admin,dc=example,dc=com
+func validateLDAPDN(dn string) error {
+	rdns, err := splitLDAPDN(dn, ',')
+	if err != nil {
+		return err
+	}
+	for _, rdn := range rdns {
+		avas, _ := splitLDAPDN(rdn, '+')
+		for _, ava := range avas {
+			attr, value, found := strings.Cut(ava, "=")
+			if !found || !ldapDNAttributeRegex.MatchString(strings.TrimSpace(attr)) || strings.TrimSpace(value) == "" {
+				return fmt.Errorf("invalid relative distinguished name %q in %q, should be <attribute>=<value>",
+					rdn, dn)
+			}
+		}
+	}
+	return nil
+}
+
+// splitLDAPDN splits the distinguished name on the separator unless it's escaped with a backslash
+func splitLDAPDN(dn string, sep rune) ([]string, error) {
+	var parts []string
+	var part strings.Builder
+	escaped := false
+	for _, c := range dn {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == sep:
+			parts = append(parts, part.String())
+			part.Reset()
+			continue
+		}
+		part.WriteRune(c)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in %q", dn)
+	}
+	return append(parts, part.String()), nil
+}
+
+// validateLDAPFilter returns the error of a search filter which doesn't conform to RFC 4515,
+// e.g. (&(objectClass=person)(uid=cis))
+func validateLDAPFilter(filter string) error {
+	rest, err := parseLDAPFilter(filter)
+	if err != nil {
+		return err
+	}
+	if rest != "" {
+		return fmt.Errorf("unexpected %q after the filter", rest)
+	}
+	return nil
+}
+
+// parseLDAPFilter parses the parenthesized filter at the beginning of the string and returns the rest of it
+func parseLDAPFilter(filter string) (string, error) {
+	if !strings.HasPrefix(filter, "(") {
+		return "", fmt.Errorf("filter %q should be enclosed in parentheses", filter)
+	}
+	rest := filter[1:]
+	var err error
+	switch {
+	case strings.HasPrefix(rest, "&"), strings.HasPrefix(rest, "|"):
+		operator := rest[:1]
+		rest = rest[1:]
+		count := 0
+		for strings.HasPrefix(rest, "(") {
+			if rest, err = parseLDAPFilter(rest); err != nil {
+				return "", err
+			}
+			count++
+		}
+		if count == 0 {
+			return "", fmt.Errorf("%v filter without filters in %q", operator, filter)
+		}
+	case strings.HasPrefix(rest, "!"):
+		if rest, err = parseLDAPFilter(rest[1:]); err != nil {
+			return "", err
+		}
+	default:
+		end := strings.IndexAny(rest, "()")
+		if end < 0 || rest[end] != ')' {
+			return "", fmt.Errorf("unbalanced parentheses in %q", filter)
+		}
+		if err = validateLDAPFilterItem(rest[:end]); err != nil {
+			return "", err
+		}
+		rest = rest[end:]
+	}
+	if !strings.HasPrefix(rest, ")") {
+		return "", fmt.Errorf("unbalanced parentheses in %q", filter)
+	}
+	return rest[1:], nil
+}
+
+// validateLDAPFilterItem returns the error of a simple, presence, substring or extensible match filter item,
+// e.g. uid=cis, cn=*, cn=ci*s or cn:dn:2.5.13.5:=cis
+func validateLDAPFilterItem(item string) error {
+	attr, value, found := strings.Cut(item, "=")
+	if !found {
+		return fmt.Errorf("invalid filter item %q, should be <attribute><operator><value>", item)
+	}
+	switch {
+	case strings.Contains(attr, ":"):
+		// extensible match, the attribute, dn and the matching rule are optional, but not all of them
+		parts := strings.Split(strings.TrimSuffix(attr, ":"), ":")
+		if !strings.HasSuffix(attr, ":") || (parts[0] == "" && len(parts) == 1) {
+			return fmt.Errorf("invalid extensible match filter item %q", item)
+		}
+		for i, part := range parts {
+			if (i == 0 && part == "") || (part == "dn" && i > 0) {
+				continue
+			}
+			if !ldapFilterAttributeRegex.MatchString(part) {
+				return fmt.Errorf("invalid extensible match filter item %q", item)
+			}
+		}
+	default:
+		if strings.HasSuffix(attr, "~") || strings.HasSuffix(attr, ">") || strings.HasSuffix(attr, "<") {
+			attr = attr[:len(attr)-1]
+		}
+		if !ldapFilterAttributeRegex.MatchString(attr) {
+			return fmt.Errorf("invalid attribute %q in filter item %q", attr, item)
+		}
+	}
+	// the special characters of the values are escaped as a backslash and two hex digits, e.g. \28 for (
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			continue
+		}
+		if i+2 >= len(value) || !isHexDigit(value[i+1]) || !isHexDigit(value[i+2]) {
+			return fmt.Errorf("invalid escape in filter item %q, should be a backslash and two hex digits", item)
+		}
+		i += 2
+	}
+	return nil
+}
+
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
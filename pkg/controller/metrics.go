@@ -18,8 +18,10 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/prometheus"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
 )
@@ -27,12 +29,26 @@ import (
 func (ctlr *Controller) enableHttpEndpoint(httpAddress string) {
 	// Expose Prometheus metrics
 	http.Handle("/metrics", promhttp.Handler())
-	bigIPPrometheus.RegisterMetrics(ctlr.RequestHandler.httpClientMetrics, ctlr.CMTokenManager.ServerURL)
+	bigIPPrometheus.RegisterMetrics(prometheus.DefaultRegisterer, ctlr.RequestHandler.httpClientMetrics)
+	if ctlr.RequestHandler.httpClientMetrics {
+		log.Infof("Registered http metrics for central manager %v", ctlr.CMTokenManager.ServerURL)
+	}
 	// Expose cis health endpoint
 	http.Handle("/health", ctlr.CISHealthCheckHandler())
+	// Expose the health of the tenants
+	http.Handle("/healthz", ctlr.TenantHealthHandler())
 	log.Fatal(http.ListenAndServe(httpAddress, nil).Error())
 }
 
+// enableDiagnosticsEndpoint serves the diagnostic informations of CIS, which are not exposed on the http endpoint
+func (ctlr *Controller) enableDiagnosticsEndpoint(diagnosticsAddr string) {
+	mux := http.NewServeMux()
+	// Expose the last known state of the AS3 tenants
+	mux.Handle(AS3StatsPath, ctlr.AS3TenantStatusHandler())
+	log.Infof("Serving the diagnostic informations on %v", diagnosticsAddr)
+	log.Fatal(http.ListenAndServe(diagnosticsAddr, mux).Error())
+}
+
 func (ctlr *Controller) CISHealthCheckHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if ctlr.clientsets.KubeClient != nil {
@@ -59,3 +75,49 @@ func (ctlr *Controller) CISHealthCheckHandler() http.Handler {
 		}
 	})
 }
+
+// TenantHealthHandler responds with the health summary of the tenants of each BIG-IP
+func (ctlr *Controller) TenantHealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		summaries := make(map[string]HealthSummary)
+		ctlr.RequestHandler.PostManagers.RLock()
+		for bigipConfig, pm := range ctlr.RequestHandler.PostManagers.PostManagerMap {
+			summaries[bigipConfig.BigIpAddress] = pm.TenantHealthSummary()
+		}
+		ctlr.RequestHandler.PostManagers.RUnlock()
+		body, err := json.Marshal(summaries)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+}
+
+// AS3TenantStatusHandler responds with the last known state of the AS3 tenants of each BIG-IP
+func (ctlr *Controller) AS3TenantStatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		tenantStatus := make(map[string]map[string]TenantStatus)
+		ctlr.RequestHandler.PostManagers.RLock()
+		for bigipConfig, pm := range ctlr.RequestHandler.PostManagers.PostManagerMap {
+			tenantStatus[bigipConfig.BigIpAddress] = pm.GetTenantStatus()
+		}
+		ctlr.RequestHandler.PostManagers.RUnlock()
+		body, err := json.Marshal(tenantStatus)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"encoding/json"
 	"fmt"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -10,6 +11,8 @@ import (
 	"k8s.io/client-go/rest"
 	"net/http"
 	"time"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
 )
 
 var _ = Describe("Metrics", func() {
@@ -39,6 +42,27 @@ var _ = Describe("Metrics", func() {
 		Expect(err).To(BeNil())
 		Expect(resp).To(Equal(Ok))
 	})
+
+	It("Serve the AS3 tenant status on the diagnostics endpoint", func() {
+		bigIpKey := cisapiv1.BigIpConfig{BigIpAddress: "10.8.3.11", BigIpLabel: "bigip1"}
+		mockCtlr.RequestHandler = newMockAgent("")
+		mockCtlr.RequestHandler.PostManagers.PostManagerMap[bigIpKey] = &PostManager{
+			tenantStatus: map[string]TenantStatus{"test": {LastResponseCode: http.StatusUnprocessableEntity,
+				DeclarationHash: "abc", Failed: true}},
+		}
+		go mockCtlr.enableDiagnosticsEndpoint("127.0.0.1:8082")
+		var resp string
+		Eventually(func() error {
+			var err error
+			resp, err = makeHTTPRequest("http://127.0.0.1:8082" + AS3StatsPath)
+			return err
+		}, 5*time.Second, 100*time.Millisecond).Should(Succeed())
+		tenantStatus := make(map[string]map[string]TenantStatus)
+		Expect(json.Unmarshal([]byte(resp), &tenantStatus)).To(Succeed())
+		Expect(tenantStatus["10.8.3.11"]).To(HaveKey("test"))
+		Expect(tenantStatus["10.8.3.11"]["test"].Failed).To(BeTrue())
+		Expect(tenantStatus["10.8.3.11"]["test"].DeclarationHash).To(Equal("abc"))
+	})
 })
 
 func makeHTTPRequest(url string) (string, error) {
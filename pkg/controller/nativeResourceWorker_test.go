@@ -16,6 +16,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+	"sync"
 	"time"
 )
 
@@ -146,6 +148,47 @@ var _ = Describe("Routes", func() {
 			Expect(dg[ns].Records[0].Data).To(BeEquivalentTo("foo_80_default"), "Invalid vsHostname in datagroup")
 		})
 
+		It("Route processing is gated by manage-routes", func() {
+			mockCtlr.resources.extdSpecMap[ns] = &extendedParsedSpec{
+				global: &cisapiv1.ExtendedRouteGroupSpec{
+					VServerName:   "samplevs",
+					VServerAddr:   "10.10.10.10",
+					AllowOverride: "False",
+				},
+				namespaces: []string{ns},
+				partition:  "test",
+			}
+			mockCtlr.resources.invertedNamespaceLabelMap[ns] = ns
+			svcPorts := []v1.ServicePort{{Port: 80, NodePort: 30001}}
+			mockCtlr.addService(test.NewService("samplesvc", "1", ns, "NodePort", svcPorts))
+			mockCtlr.addEndpoints(test.NewEndpoints("samplesvc", "1", "node0", ns, []string{"10.1.1.1"}, []string{},
+				convertSvcPortsToEndpointPorts(svcPorts)))
+			mockCtlr.addRoute(rt)
+			mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+				workqueue.DefaultControllerRateLimiter(), "native-resource-controller")
+			defer mockCtlr.resourceQueue.ShutDown()
+			mockCtlr.requestMap = &requestMap{sync.RWMutex{}, make(map[cisapiv1.BigIpConfig]requestMeta)}
+			rsKey := resourceRef{kind: Route, namespace: rt.Namespace, name: rt.Name}
+
+			// Route is ignored when manage-routes is disabled
+			mockCtlr.managedResources.ManageRoutes = false
+			mockCtlr.enqueueRoute(rt, Create)
+			Expect(mockCtlr.processResources()).To(BeTrue())
+			_, ok := mockCtlr.resources.processedNativeResources[rsKey]
+			Expect(ok).To(BeFalse(), "Route should not be processed without manage-routes")
+			_, ok = mockCtlr.resources.bigIpMap[bigipConfig].ltmConfig["test"]
+			Expect(ok).To(BeFalse(), "Virtual should not be created without manage-routes")
+
+			// Route is translated when manage-routes is enabled
+			mockCtlr.managedResources.ManageRoutes = true
+			mockCtlr.enqueueRoute(rt, Create)
+			Expect(mockCtlr.processResources()).To(BeTrue())
+			_, ok = mockCtlr.resources.processedNativeResources[rsKey]
+			Expect(ok).To(BeTrue(), "Route should be processed with manage-routes")
+			_, ok = mockCtlr.resources.bigIpMap[bigipConfig].ltmConfig["test"].ResourceMap["samplevs_80"]
+			Expect(ok).To(BeTrue(), "Virtual should be created with manage-routes")
+		})
+
 		It("Route Admit Status", func() {
 			spec1 := routeapi.RouteSpec{
 				Host: "foo.com",
@@ -0,0 +1,288 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// getCrossNamespaceServices returns the namespaces of the pool services referenced by the
+// cis.f5.com/cross-ns-service annotation, the annotation is a comma separated list of namespace/service
+func getCrossNamespaceServices(vs *cisapiv1.VirtualServer) map[string]string {
+	services := make(map[string]string)
+	value, ok := vs.Annotations[CrossNamespaceServiceAnnotation]
+	if !ok {
+		return services
+	}
+	for _, ref := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(ref), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			log.Errorf("Invalid %v annotation %v in VirtualServer %v/%v, use namespace/service",
+				CrossNamespaceServiceAnnotation, ref, vs.Namespace, vs.Name)
+			continue
+		}
+		services[parts[1]] = parts[0]
+	}
+	return services
+}
+
+// isSourceAllowedByNetworkPolicies checks whether the NetworkPolicies allow the ingress traffic from the source IP
+// to the pods with the labels, pods not selected by any ingress policy are not isolated. Ports are not checked.
+func isSourceAllowedByNetworkPolicies(policies []networkingv1.NetworkPolicy, podLabels map[string]string,
+	sourceIP net.IP) bool {
+	isolated := false
+	for _, policy := range policies {
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil || !selector.Matches(labels.Set(podLabels)) || !hasIngressPolicyType(policy) {
+			continue
+		}
+		isolated = true
+		for _, rule := range policy.Spec.Ingress {
+			// a rule without peers allows all the sources
+			if len(rule.From) == 0 {
+				return true
+			}
+			for _, peer := range rule.From {
+				if peer.IPBlock != nil && ipBlockContains(peer.IPBlock, sourceIP) {
+					return true
+				}
+			}
+		}
+	}
+	return !isolated
+}
+
+func hasIngressPolicyType(policy networkingv1.NetworkPolicy) bool {
+	// policies without policy types always apply to ingress
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return true
+	}
+	for _, policyType := range policy.Spec.PolicyTypes {
+		if policyType == networkingv1.PolicyTypeIngress {
+			return true
+		}
+	}
+	return false
+}
+
+func ipBlockContains(block *networkingv1.IPBlock, ip net.IP) bool {
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil || !cidr.Contains(ip) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, exceptCIDR, err := net.ParseCIDR(except); err == nil && exceptCIDR.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCrossNamespaceService checks whether the NetworkPolicies of the service namespace allow the traffic from
+// the BIG-IP source IP to the pods of a service referenced by a VirtualServer of another namespace, a Warning
+// event is recorded on the VirtualServer when the traffic is not allowed
+func (ctlr *Controller) checkCrossNamespaceService(vs *cisapiv1.VirtualServer, namespace, service string) bool {
+	svcKey := namespace + "/" + service
+	err, svc := ctlr.fetchService(MultiClusterServiceKey{serviceName: service, namespace: namespace})
+	if err != nil || svc == nil || len(svc.Spec.Selector) == 0 {
+		// pods of the services without selector are not known
+		ctlr.clearVirtualServerWarning(vs, CrossNamespaceServiceDeniedEvent, svcKey)
+		return true
+	}
+	policies, err := ctlr.getNetworkPolicies(namespace)
+	if err != nil {
+		log.Errorf("Unable to list the NetworkPolicies of namespace %v for VirtualServer %v/%v: %v", namespace,
+			vs.Namespace, vs.Name, err)
+		return true
+	}
+	if isSourceAllowedByNetworkPolicies(policies, svc.Spec.Selector, net.ParseIP(ctlr.bigipSourceIP)) {
+		ctlr.clearVirtualServerWarning(vs, CrossNamespaceServiceDeniedEvent, svcKey)
+		return true
+	}
+	message := fmt.Sprintf("no NetworkPolicy of namespace %v allows the traffic from BIG-IP source IP %v to "+
+		"service %v, skipping its pool members", namespace, ctlr.bigipSourceIP, service)
+	log.Warningf("VirtualServer %v/%v: %v", vs.Namespace, vs.Name, message)
+	ctlr.recordVirtualServerWarning(vs, CrossNamespaceServiceDeniedEvent, svcKey, message)
+	return false
+}
+
+// getCrossNamespaceVirtualServers returns the VirtualServers of the other namespaces with pools of the services of
+// the namespace, their pools are checked against the NetworkPolicies of the namespace
+func (ctlr *Controller) getCrossNamespaceVirtualServers(namespace string) []*cisapiv1.VirtualServer {
+	var virtuals []*cisapiv1.VirtualServer
+	for _, crInf := range ctlr.crInformers {
+		if crInf.vsInformer == nil {
+			continue
+		}
+		for _, obj := range crInf.vsInformer.GetIndexer().List() {
+			vs, ok := obj.(*cisapiv1.VirtualServer)
+			if !ok || vs.Namespace == namespace {
+				continue
+			}
+			if hasServiceOfNamespace(vs, namespace) {
+				virtuals = append(virtuals, vs)
+			}
+		}
+	}
+	return virtuals
+}
+
+// hasServiceOfNamespace checks whether a pool of the VirtualServer refers to a service of the namespace
+func hasServiceOfNamespace(vs *cisapiv1.VirtualServer, namespace string) bool {
+	crossNamespaceServices := getCrossNamespaceServices(vs)
+	for _, pl := range vs.Spec.Pools {
+		svcNamespace := pl.ServiceNamespace
+		if svcNamespace == "" {
+			svcNamespace = crossNamespaceServices[pl.Service]
+		}
+		if svcNamespace == namespace {
+			return true
+		}
+		for _, svc := range pl.AlternateBackends {
+			if svc.ServiceNamespace == namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getNetworkPolicyCIDRs returns the ipBlock CIDRs of the ingress NetworkPolicies selecting the pods with the labels,
+// restricted is false when the pods are not isolated or a policy rule allows the traffic from any source
+func getNetworkPolicyCIDRs(policies []networkingv1.NetworkPolicy, podLabels map[string]string) (cidrs []string,
+	restricted bool) {
+	seen := make(map[string]struct{})
+	for _, policy := range policies {
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil || !selector.Matches(labels.Set(podLabels)) || !hasIngressPolicyType(policy) {
+			continue
+		}
+		restricted = true
+		for _, rule := range policy.Spec.Ingress {
+			// a rule without peers allows all the sources
+			if len(rule.From) == 0 {
+				return nil, false
+			}
+			for _, peer := range rule.From {
+				if peer.IPBlock == nil {
+					continue
+				}
+				if _, _, err := net.ParseCIDR(peer.IPBlock.CIDR); err != nil {
+					log.Warningf("Skipping invalid ipBlock CIDR %v in NetworkPolicy %v/%v", peer.IPBlock.CIDR,
+						policy.Namespace, policy.Name)
+					continue
+				}
+				if len(peer.IPBlock.Except) > 0 {
+					log.Debugf("Ignoring the except CIDRs %v of ipBlock %v in NetworkPolicy %v/%v",
+						peer.IPBlock.Except, peer.IPBlock.CIDR, policy.Namespace, policy.Name)
+				}
+				if _, ok := seen[peer.IPBlock.CIDR]; ok {
+					continue
+				}
+				seen[peer.IPBlock.CIDR] = struct{}{}
+				cidrs = append(cidrs, peer.IPBlock.CIDR)
+			}
+		}
+	}
+	return cidrs, restricted
+}
+
+// getNetworkPolicies returns the NetworkPolicies of the namespace from the informer cache when available
+func (ctlr *Controller) getNetworkPolicies(namespace string) ([]networkingv1.NetworkPolicy, error) {
+	if comInf, ok := ctlr.getNamespacedCommonInformer(namespace); ok && comInf.npInformer != nil {
+		var policies []networkingv1.NetworkPolicy
+		objs, err := comInf.npInformer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if policy, ok := obj.(*networkingv1.NetworkPolicy); ok {
+				policies = append(policies, *policy)
+			}
+		}
+		return policies, nil
+	}
+	policies, err := ctlr.clientsets.KubeClient.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return policies.Items, nil
+}
+
+// handleVirtualServerNetworkPolicies allows the virtual only from the ipBlocks of the NetworkPolicies selecting
+// the pods of its pool services, the CIDRs are attached as an allow AddressList of the virtual firewall policy
+func (ctlr *Controller) handleVirtualServerNetworkPolicies(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer,
+	pools []Pool) {
+	if !ctlr.firewallEnabled {
+		return
+	}
+	var cidrs []string
+	seen := make(map[string]struct{})
+	for _, pool := range pools {
+		err, svc := ctlr.fetchService(MultiClusterServiceKey{serviceName: pool.ServiceName,
+			namespace: pool.ServiceNamespace})
+		if err != nil || svc == nil || len(svc.Spec.Selector) == 0 {
+			// pods of the services without selector are not known
+			continue
+		}
+		policies, err := ctlr.getNetworkPolicies(pool.ServiceNamespace)
+		if err != nil {
+			log.Errorf("Unable to list the NetworkPolicies of namespace %v for VirtualServer %v/%v: %v",
+				pool.ServiceNamespace, vs.Namespace, vs.Name, err)
+			continue
+		}
+		poolCIDRs, restricted := getNetworkPolicyCIDRs(policies, svc.Spec.Selector)
+		if !restricted {
+			continue
+		}
+		if len(poolCIDRs) == 0 {
+			log.Debugf("No ipBlocks found in the NetworkPolicies of service %v/%v for VirtualServer %v/%v",
+				pool.ServiceNamespace, pool.ServiceName, vs.Namespace, vs.Name)
+			continue
+		}
+		for _, cidr := range poolCIDRs {
+			if _, ok := seen[cidr]; ok {
+				continue
+			}
+			seen[cidr] = struct{}{}
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	if len(cidrs) == 0 {
+		return
+	}
+	name := rsCfg.Virtual.Name + "_network_policy"
+	if rsCfg.Virtual.hasAddressList(name) {
+		return
+	}
+	rsCfg.Virtual.AddressLists = append(rsCfg.Virtual.AddressLists, AddressListRef{
+		Name:      name,
+		Addresses: cidrs,
+		Action:    AddressListAllow,
+	})
+}
@@ -0,0 +1,289 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+)
+
+// AS3 doesn't declare the packet filters of BIG-IP, so the rules of the PacketFilter CRs are applied with
+// the iControl REST API of the net/packet-filter objects in the Common partition. The packet filters apply to all
+// the traffic of BIG-IP, so only the PacketFilters of the admin namespace are applied
+
+// processPacketFilters applies the rules of all the PacketFilters to the BIG-IPs, the error of any BIG-IP is
+// returned so that the PacketFilters are processed again
+func (ctlr *Controller) processPacketFilters() error {
+	filters := ctlr.buildBigIPPacketFilters(ctlr.getAllPacketFilters())
+	if ctlr.RequestHandler == nil {
+		return nil
+	}
+	ctlr.RequestHandler.PostManagers.RLock()
+	defer ctlr.RequestHandler.PostManagers.RUnlock()
+	var errs []string
+	for _, pm := range ctlr.RequestHandler.PostManagers.PostManagerMap {
+		if err := pm.syncPacketFilters(filters); err != nil {
+			errs = append(errs, fmt.Sprintf("%v%v", pm.postManagerPrefix, err))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("unable to sync the packet filters: %v", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// getAllPacketFilters returns the PacketFilters of the admin namespace sorted by name
+func (ctlr *Controller) getAllPacketFilters() []*cisapiv1.PacketFilter {
+	var filters []*cisapiv1.PacketFilter
+	if ctlr.adminInformer == nil || ctlr.adminInformer.packetFilterInformer == nil {
+		return filters
+	}
+	for _, obj := range ctlr.adminInformer.packetFilterInformer.GetIndexer().List() {
+		filters = append(filters, obj.(*cisapiv1.PacketFilter))
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].Name < filters[j].Name
+	})
+	return filters
+}
+
+// buildBigIPPacketFilters returns the BIG-IP packet filters of the rules keyed by name, the invalid rules and
+// the rules reusing the order of an earlier rule are skipped
+func (ctlr *Controller) buildBigIPPacketFilters(filters []*cisapiv1.PacketFilter) map[string]bigIPPacketFilter {
+	bigipFilters := make(map[string]bigIPPacketFilter)
+	orders := make(map[int]string)
+	for _, filter := range filters {
+		for _, rule := range filter.Spec.Rules {
+			bigipFilter, err := newBigIPPacketFilter(filter, rule)
+			if err != nil {
+				log.Errorf("Skipping rule %v of PacketFilter %v/%v: %v", rule.Order, filter.Namespace, filter.Name, err)
+				continue
+			}
+			if name, ok := orders[rule.Order]; ok {
+				log.Errorf("Skipping rule %v of PacketFilter %v/%v: order is used by %v", rule.Order,
+					filter.Namespace, filter.Name, name)
+				continue
+			}
+			orders[rule.Order] = bigipFilter.Name
+			bigipFilters[bigipFilter.Name] = bigipFilter
+		}
+	}
+	return bigipFilters
+}
+
+// newBigIPPacketFilter returns the BIG-IP packet filter of the rule of the PacketFilter
+func newBigIPPacketFilter(filter *cisapiv1.PacketFilter, rule cisapiv1.PacketFilterRule) (bigIPPacketFilter, error) {
+	bigipFilter := bigIPPacketFilter{
+		Name:        fmt.Sprintf("%v_%v_%v", filter.Namespace, filter.Name, rule.Order),
+		Partition:   CommonPartition,
+		Order:       rule.Order,
+		Description: packetFilterDescription + filter.Namespace + "/" + filter.Name,
+	}
+	if rule.Order < 0 {
+		return bigipFilter, fmt.Errorf("invalid order %v", rule.Order)
+	}
+	switch rule.Action {
+	case "allow":
+		bigipFilter.Action = "accept"
+	case "reject", "discard":
+		bigipFilter.Action = rule.Action
+	default:
+		return bigipFilter, fmt.Errorf("invalid action %v", rule.Action)
+	}
+	var expressions []string
+	switch strings.ToLower(rule.Protocol) {
+	case "":
+	case "tcp", "udp", "icmp", "sctp":
+		expressions = append(expressions, strings.ToLower(rule.Protocol))
+	default:
+		return bigipFilter, fmt.Errorf("invalid protocol %v", rule.Protocol)
+	}
+	for _, address := range []struct {
+		direction string
+		value     string
+	}{{"src", rule.Source}, {"dst", rule.Destination}} {
+		if address.value == "" {
+			continue
+		}
+		expression, err := packetFilterAddressExpression(address.direction, address.value)
+		if err != nil {
+			return bigipFilter, err
+		}
+		expressions = append(expressions, expression)
+	}
+	// BIG-IP matches all the packets with an empty rule
+	if len(expressions) == 0 {
+		return bigipFilter, fmt.Errorf("no protocol, source or destination to match")
+	}
+	bigipFilter.Rule = strings.Join(expressions, " and ")
+	return bigipFilter, nil
+}
+
+// packetFilterAddressExpression returns the tcpdump expression matching the address or the network
+func packetFilterAddressExpression(direction, address string) (string, error) {
+	if ip := net.ParseIP(address); ip != nil {
+		return fmt.Sprintf("%v host %v", direction, address), nil
+	}
+	if _, _, err := net.ParseCIDR(address); err == nil {
+		return fmt.Sprintf("%v net %v", direction, address), nil
+	}
+	return "", fmt.Errorf("invalid %v address %v", direction, address)
+}
+
+// syncPacketFilters creates, updates and deletes the packet filters on BIG-IP to match the given filters,
+// the filters created by CIS are fetched from BIG-IP with the first sync. The names of the filters which
+// failed are returned as an error
+func (postMgr *PostManager) syncPacketFilters(filters map[string]bigIPPacketFilter) error {
+	postMgr.packetFilterLock.Lock()
+	defer postMgr.packetFilterLock.Unlock()
+	if postMgr.DryRun {
+		for _, name := range packetFilterNames(filters) {
+			log.Infof("[AS3]%v [dry-run] packet filter not applied to BIG-IP: %+v", postMgr.postManagerPrefix, filters[name])
+		}
+		return nil
+	}
+	if postMgr.packetFilters == nil {
+		current, err := postMgr.GetBigipPacketFilters()
+		if err != nil {
+			log.Errorf("[AS3]%v Unable to fetch the packet filters from BIG-IP: %v", postMgr.postManagerPrefix, err)
+			return err
+		}
+		postMgr.packetFilters = current
+	}
+	var failed []string
+	url := postMgr.getBigipPacketFilterURL()
+	for _, name := range packetFilterNames(filters) {
+		filter := filters[name]
+		applied, ok := postMgr.packetFilters[name]
+		if ok && applied == filter {
+			continue
+		}
+		method, filterURL := http.MethodPost, url
+		if ok {
+			method, filterURL = http.MethodPatch, url+"/~"+CommonPartition+"~"+name
+		}
+		if err := postMgr.packetFilterRequest(method, filterURL, &filter); err != nil {
+			log.Errorf("[AS3]%v Unable to apply packet filter %v: %v", postMgr.postManagerPrefix, name, err)
+			failed = append(failed, name)
+			continue
+		}
+		postMgr.packetFilters[name] = filter
+	}
+	for _, name := range packetFilterNames(postMgr.packetFilters) {
+		if _, ok := filters[name]; ok {
+			continue
+		}
+		if err := postMgr.packetFilterRequest(http.MethodDelete, url+"/~"+CommonPartition+"~"+name, nil); err != nil {
+			log.Errorf("[AS3]%v Unable to delete packet filter %v: %v", postMgr.postManagerPrefix, name, err)
+			failed = append(failed, name)
+			continue
+		}
+		delete(postMgr.packetFilters, name)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("packet filters %v not synced", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// packetFilterNames returns the sorted names of the packet filters
+func packetFilterNames(filters map[string]bigIPPacketFilter) []string {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetBigipPacketFilters returns the packet filters created by CIS on BIG-IP
+func (postMgr *PostManager) GetBigipPacketFilters() (map[string]bigIPPacketFilter, error) {
+	url := postMgr.getBigipPacketFilterURL()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("[AS3]%v Posting GET BIGIP packet filter request on %v", postMgr.postManagerPrefix, url)
+	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
+
+	httpResp, err := postMgr.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
+	}
+	var response struct {
+		Items []bigIPPacketFilter `json:"items"`
+	}
+	if err = json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	filters := make(map[string]bigIPPacketFilter)
+	for _, filter := range response.Items {
+		if strings.HasPrefix(filter.Description, packetFilterDescription) {
+			filters[filter.Name] = filter
+		}
+	}
+	return filters, nil
+}
+
+// packetFilterRequest sends the request of the packet filter to BIG-IP
+func (postMgr *PostManager) packetFilterRequest(method, url string, filter *bigIPPacketFilter) error {
+	var body io.Reader
+	if filter != nil {
+		data, err := json.Marshal(filter)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	log.Debugf("[AS3]%v Posting %v BIGIP packet filter request on %v", postMgr.postManagerPrefix, method, url)
+	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
+	req.Header.Add("Content-Type", "application/json")
+
+	httpResp, err := postMgr.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(httpResp.Body)
+		return fmt.Errorf("status code %v: %v", httpResp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+func (postMgr *PostManager) getBigipPacketFilterURL() string {
+	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/net/packet-filter"
+	return apiURL
+}
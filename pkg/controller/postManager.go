@@ -18,6 +18,8 @@ package controller
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -25,67 +27,203 @@ import (
 	cisv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/statusmanager"
 	"io"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"math"
+	"math/rand"
 	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/prometheus"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// checkpointKeyRegex matches the characters not allowed in ConfigMap keys
+var checkpointKeyRegex = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
 func NewPostManager(params PostParams, partition string) *PostManager {
 
 	var pm = &PostManager{
 		AS3PostManager: &AS3PostManager{
-			AS3Config: params.AS3Config,
+			AS3Config:          params.AS3Config,
+			defaultRouteDomain: params.DefaultRouteDomain,
+			classVersions:      params.ClassVersions,
 		},
 		tokenManager:           params.tokenManager,
 		cachedTenantDeclMap:    make(map[string]as3Tenant),
 		postChan:               make(chan agentConfig, 1),
+		retryReady:             make(chan struct{}, 1),
 		defaultPartition:       partition,
 		tenantDeclarationIDMap: make(map[string]string),
+		tenantLastSeen:         make(map[string]time.Time),
 	}
+	pm.PostParams = params
+	pm.setupBIGIPRESTClient()
 	// postManager runs as a separate go routine
 	// blocks on postChan to get new/updated AS3/L3 declaration to be posted to BIG-IP
 	go pm.postManager()
-	pm.PostParams = params
-	pm.setupBIGIPRESTClient()
 	return pm
 }
 
 // blocks on post channel and handles posting of AS3,L3 declaration to BIGIP pairs.
+// Declaration drift detection runs on the same go routine, so that it does not race with the posts.
 func (postMgr *PostManager) postManager() {
-	for config := range postMgr.postChan {
-		// For the very first post after starting controller, need not wait to post
-		if !postMgr.AS3PostManager.firstPost && postMgr.AS3PostManager.AS3Config.PostDelayAS3 != 0 {
-			// Time (in seconds) that CIS waits to post the AS3 declaration to BIG-IP.
-			log.Debugf("[AS3] Delaying post to BIG-IP for %v seconds ", postMgr.AS3PostManager.AS3Config.PostDelayAS3)
-			_ = <-time.After(time.Duration(postMgr.AS3PostManager.AS3Config.PostDelayAS3) * time.Second)
+	var driftTicker, certTicker <-chan time.Time
+	if postMgr.DriftDetectionInterval > 0 {
+		ticker := time.NewTicker(postMgr.DriftDetectionInterval)
+		defer ticker.Stop()
+		driftTicker = ticker.C
+	}
+	if postMgr.CertExpiryWarningDays > 0 {
+		ticker := time.NewTicker(CertExpiryCheckInterval)
+		defer ticker.Stop()
+		certTicker = ticker.C
+	}
+	for {
+		select {
+		case config, ok := <-postMgr.postChan:
+			if !ok {
+				postMgr.cancelRetry()
+				postMgr.flushCheckpoint()
+				return
+			}
+			// the failed tenants of the pending retry are posted with the latest request
+			postMgr.cancelRetry()
+			postMgr.postAgentConfig(config)
+			// the certificate is checked once the BIG-IP is known to be reachable
+			if postMgr.CertExpiryWarningDays > 0 && postMgr.certCheckedAt.IsZero() {
+				postMgr.checkCertExpiry()
+			}
+		case <-postMgr.retryReady:
+			if config, ok := postMgr.takeRetry(); ok {
+				postMgr.postAgentConfig(config)
+			}
+		case <-driftTicker:
+			postMgr.detectDeclarationDrift()
+		case <-certTicker:
+			postMgr.checkCertExpiry()
 		}
-		// Set the target address for the as3 request
-		config.as3Config.targetAddress = config.BigIpConfig.BigIpAddress
+	}
+}
 
-		//Handle AS3 post
-		postMgr.publishConfig(&config.as3Config)
-		//TODO: L3 post manger handling
-		//TODO: after post check for failed state and update retry chan
+// postAgentConfig posts the AS3 declaration of the agent config and notifies the response handler
+func (postMgr *PostManager) postAgentConfig(config agentConfig) {
+	// For the very first post after starting controller, need not wait to post
+	if !postMgr.AS3PostManager.firstPost && postMgr.AS3PostManager.AS3Config.PostDelayAS3 != 0 {
+		// Time (in seconds) that CIS waits to post the AS3 declaration to BIG-IP.
+		log.Debugf("[AS3] Delaying post to BIG-IP for %v seconds ", postMgr.AS3PostManager.AS3Config.PostDelayAS3)
+		_ = <-time.After(time.Duration(postMgr.AS3PostManager.AS3Config.PostDelayAS3) * time.Second)
+	}
+	// Set the target address for the as3 request
+	config.as3Config.targetAddress = config.BigIpConfig.BigIpAddress
+	config.as3Config.retryDelay = 0
+	postMgr.bigIpAddress = config.BigIpConfig.BigIpAddress
+	postedAt := time.Now()
+
+	//Handle AS3 post
+	postMgr.publishConfig(&config.as3Config)
+	//TODO: L3 post manger handling
+	//TODO: after post check for failed state and update retry chan
 
-		if !postMgr.AS3Config.DocumentAPI {
-			postMgr.updateTenantCache(&config.as3Config)
-		}
+	if !postMgr.AS3Config.DocumentAPI {
+		postMgr.updateTenantCache(&config.as3Config)
+	}
 
-		/*
-			If there are any tenants with 201 response code,
-			poll for its status continuously and block incoming requests
-		*/
-		if !postMgr.AS3Config.DocumentAPI {
-			postMgr.pollTenantStatus(&config.as3Config)
+	/*
+		If there are any tenants with 201 response code,
+		poll for its status continuously and block incoming requests
+	*/
+	if !postMgr.AS3Config.DocumentAPI && !postMgr.DryRun {
+		postMgr.pollTenantStatus(&config.as3Config)
+		postMgr.deleteUnreferencedSCTPProfiles()
+	}
+	recordTenantPostMetrics(&config.as3Config, time.Since(postedAt))
+	if len(config.as3Config.failedTenants) == 0 {
+		postMgr.retryAttempts = 0
+	} else if config.as3Config.retryDelay == 0 {
+		config.as3Config.retryDelay = postMgr.nextRetryDelay()
+	}
+	if !postMgr.DryRun {
+		postMgr.writeAuditEvents(&config.as3Config, postedAt)
+	}
+	postMgr.updateHealthSummary(&config.as3Config, postedAt)
+	if !postMgr.AS3Config.DocumentAPI && !postMgr.DryRun {
+		postMgr.scheduleCheckpoint()
+	}
+	// notify resourceStatusUpdate response handler on successful tenant update
+	postMgr.respChan <- &config
+}
+
+// detectDeclarationDrift compares the tenants posted by CIS with the declaration stored by AS3 on BIG-IP,
+// the drifted tenants are posted again when drift resync is enabled.
+// AS3 returns the last declaration it applied and not the live BIG-IP objects, so this detects the tenants
+// declared by other AS3 clients, e.g. another CIS or a manual AS3 post. Changes made to the objects with
+// the BIG-IP GUI or tmsh do not update the stored declaration and are not detected.
+func (postMgr *PostManager) detectDeclarationDrift() []string {
+	if postMgr.AS3Config.DocumentAPI || len(postMgr.cachedTenantDeclMap) == 0 {
+		return nil
+	}
+	currentConfig, err := postMgr.GetAS3DeclarationFromBigIP()
+	if err != nil {
+		log.Warningf("[AS3]%v Could not fetch the AS3 declaration from BIG-IP to detect drift: %v",
+			postMgr.postManagerPrefix, err)
+		return nil
+	}
+	driftedTenants := getDeclarationDriftedTenants(postMgr.cachedTenantDeclMap, currentConfig)
+	if len(driftedTenants) == 0 {
+		log.Debugf("[AS3]%v No drift detected in the AS3 declaration on BIG-IP", postMgr.postManagerPrefix)
+		return nil
+	}
+	log.Warningf("[AS3]%v Drift detected in the AS3 declaration on BIG-IP for tenants %v",
+		postMgr.postManagerPrefix, driftedTenants)
+	prometheus.DriftEvents.WithLabelValues(postMgr.bigIpAddress).Inc()
+	if !postMgr.DriftResync {
+		return driftedTenants
+	}
+	cfg := as3Config{
+		targetAddress:         postMgr.bigIpAddress,
+		tenantResponseMap:     make(map[string]tenantResponse),
+		failedTenants:         make(map[string]struct{}),
+		incomingTenantDeclMap: make(map[string]as3Tenant),
+	}
+	for _, tenant := range driftedTenants {
+		cfg.incomingTenantDeclMap[tenant] = postMgr.cachedTenantDeclMap[tenant]
+		cfg.tenantResponseMap[tenant] = tenantResponse{}
+	}
+	cfg.data = string(postMgr.AS3PostManager.createAS3Declaration(cfg.incomingTenantDeclMap, postMgr.UserAgent))
+	log.Infof("[AS3]%v Posting the drifted tenants %v to BIG-IP", postMgr.postManagerPrefix, driftedTenants)
+	postMgr.publishConfig(&cfg)
+	postMgr.updateTenantCache(&cfg)
+	postMgr.pollTenantStatus(&cfg)
+	return driftedTenants
+}
+
+// getDeclarationDriftedTenants returns the tenants whose declaration stored by AS3 differs from the posted declaration
+func getDeclarationDriftedTenants(tenantDeclMap map[string]as3Tenant, currentConfig map[string]interface{}) []string {
+	var driftedTenants []string
+	for tenant, decl := range tenantDeclMap {
+		expected, err := json.Marshal(decl)
+		if err != nil {
+			continue
+		}
+		// tenants missing on BIG-IP are marshalled to null and reported as drifted
+		current, err := json.Marshal(currentConfig[tenant])
+		if err != nil || !DeepEqualJSON(as3Declaration(expected), as3Declaration(current)) {
+			driftedTenants = append(driftedTenants, tenant)
 		}
-		// notify resourceStatusUpdate response handler on successful tenant update
-		postMgr.respChan <- &config
 	}
+	sort.Strings(driftedTenants)
+	return driftedTenants
 }
 
 func (postMgr *PostManager) setupBIGIPRESTClient() {
@@ -136,6 +274,9 @@ func (postMgr *PostManager) getAS3APIURL(bigipAddress string) string {
 	var apiURL string
 	if !postMgr.AS3Config.DocumentAPI {
 		apiURL = postMgr.tokenManager.ServerURL + CmDeclareApi + "?target_address=" + bigipAddress
+		if postMgr.AsyncMode {
+			apiURL += "&async=true"
+		}
 	} else {
 		apiURL = postMgr.tokenManager.ServerURL + CmDocumentApi
 	}
@@ -159,6 +300,25 @@ func (postMgr *PostManager) getAS3TaskIdURL(taskId string) string {
 // publishConfig posts incoming configuration to BIG-IP
 func (postMgr *PostManager) publishConfig(cfg *as3Config) {
 	log.Debugf("[AS3]%v PostManager Accepted the configuration", postMgr.postManagerPrefix)
+	postMgr.logDeclarationDiff(cfg)
+	if as3Err := postMgr.checkDeclarationSize(cfg); as3Err != nil {
+		postMgr.reportAS3Error(cfg, http.StatusRequestEntityTooLarge, as3Err)
+		postMgr.failPendingTenants(cfg, http.StatusRequestEntityTooLarge)
+		return
+	}
+	if postMgr.DryRun {
+		postMgr.syncSCTPProfiles(cfg)
+		postMgr.dryRunConfig(cfg)
+		return
+	}
+	if as3Err := postMgr.checkCircuitBreaker(); as3Err != nil {
+		postMgr.reportAS3Error(cfg, http.StatusServiceUnavailable, as3Err)
+		// the retries of the failed tenants are rejected as well until the cool-down expires
+		postMgr.failPendingTenants(cfg, http.StatusServiceUnavailable)
+		return
+	}
+	// the SCTP profiles referenced by the SCTP services must exist before AS3 processes the declaration
+	postMgr.syncSCTPProfiles(cfg)
 	// postConfig updates the tenantResponseMap with response codes
 	if !postMgr.AS3Config.DocumentAPI {
 		postMgr.postConfig(cfg)
@@ -172,6 +332,7 @@ func (postMgr *PostManager) postConfig(cfg *as3Config) {
 	if postMgr.AS3PostManager.AS3Config.DebugAS3 {
 		postMgr.logAS3Request(cfg.data)
 	}
+	recordDeclarationMetrics(cfg)
 	httpReqBody := bytes.NewBuffer([]byte(cfg.data))
 	var tenants []string
 	if len(cfg.failedTenants) > 0 {
@@ -198,10 +359,29 @@ func (postMgr *PostManager) postConfig(cfg *as3Config) {
 	req.Header.Add("Authorization", "Bearer "+postMgr.tokenManager.GetToken())
 	// add content type header to the req
 	req.Header.Add("Content-Type", "application/json")
-	httpResp, responseMap := postMgr.httpPOST(req)
-	if httpResp == nil || responseMap == nil {
+	span := postMgr.tracer.startSpan("postAS3Config")
+	span.setAttribute("bigip.address", postMgr.bigIpAddress)
+	span.setAttribute("request.id", cfg.id)
+	span.setAttribute("http.url", cfg.as3APIURL)
+	span.setAttribute("as3.tenants", strings.Join(tenants, ","))
+	defer span.finish()
+	span.injectTraceContext(req)
+	// the task status requests of the declaration carry the trace context of the post
+	cfg.span = span
+	httpResp, responseMap, as3Err := postMgr.doAS3Request(req)
+	if as3Err != nil {
+		span.setError(as3Err.Error())
+		httpCode := 0
+		if httpResp != nil {
+			httpCode = httpResp.StatusCode
+		}
+		postMgr.reportAS3Error(cfg, httpCode, as3Err)
 		return
 	}
+	span.setAttribute("http.status_code", httpResp.StatusCode)
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		span.setError(http.StatusText(httpResp.StatusCode))
+	}
 
 	if postMgr.AS3PostManager.firstPost {
 		postMgr.AS3PostManager.firstPost = false
@@ -223,10 +403,124 @@ func (postMgr *PostManager) postConfig(cfg *as3Config) {
 	case http.StatusNotFound:
 		log.Infof("%v[AS3]%v post resulted in FAILURE", getRequestPrefix(cfg.id), postMgr.postManagerPrefix)
 		postMgr.handleResponseStatusNotFound(responseMap, cfg)
+	case http.StatusUnprocessableEntity:
+		log.Infof("%v[AS3]%v post resulted in FAILURE", getRequestPrefix(cfg.id), postMgr.postManagerPrefix)
+		postMgr.handleResponseStatusUnprocessableEntity(responseMap, cfg)
 	default:
 		log.Infof("%v[AS3]%v post resulted in FAILURE", getRequestPrefix(cfg.id), postMgr.postManagerPrefix)
 		postMgr.handleResponseOthers(responseMap, cfg, httpResp.StatusCode)
 	}
+	postMgr.updateCircuitBreaker(httpResp.StatusCode)
+}
+
+// dryRunConfig validates and logs the declaration instead of posting it to BIG-IP, the tenants of a valid
+// declaration are handled as posted successfully and the tenants of an invalid one as failed
+func (postMgr *PostManager) dryRunConfig(cfg *as3Config) {
+	var declaration map[string]interface{}
+	var violations []string
+	if err := json.Unmarshal([]byte(cfg.data), &declaration); err != nil {
+		log.Errorf("%v[AS3]%v [dry-run] invalid declaration: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, err)
+		violations = []string{err.Error()}
+	} else if schema, err := postMgr.getAS3Schema(); err != nil {
+		// the dry-run degrades to logging the declaration, the missing schema doesn't fail the tenants
+		log.Warningf("%v[AS3]%v [dry-run] AS3 schema unavailable, proceeding without validation: %v",
+			getRequestPrefix(cfg.id), postMgr.postManagerPrefix, err)
+		prometheus.AS3ValidationSkipped.WithLabelValues(cfg.targetAddress).Inc()
+	} else if violations = schema.Validate(cfg.data); len(violations) > 0 {
+		log.Errorf("%v[AS3]%v [dry-run] declaration violates the AS3 schema %v: %v", getRequestPrefix(cfg.id),
+			postMgr.postManagerPrefix, getAS3SchemaFile(), strings.Join(violations, "; "))
+	}
+	if len(violations) == 0 {
+		log.Infof("%v[AS3]%v [dry-run] declaration not posted to %v: %v", getRequestPrefix(cfg.id),
+			postMgr.postManagerPrefix, cfg.targetAddress, redactAS3Declaration(cfg.data))
+	}
+	postMgr.AS3PostManager.firstPost = false
+	// the violations of a tenant fail the tenant, the other violations fail all the tenants
+	failedTenants := make(map[string]struct{})
+	failAll := false
+	for _, violation := range violations {
+		if _, ok := cfg.incomingTenantDeclMap[getViolationTenant(violation)]; ok {
+			failedTenants[getViolationTenant(violation)] = struct{}{}
+		} else {
+			failAll = true
+		}
+	}
+	for tenant, tenantDecl := range cfg.incomingTenantDeclMap {
+		code := http.StatusOK
+		if _, failed := failedTenants[tenant]; failed || failAll {
+			code = http.StatusUnprocessableEntity
+		}
+		postMgr.updateTenantResponseCode(code, cfg, tenant, code == http.StatusOK && isDeletedTenant(tenantDecl))
+	}
+}
+
+// getViolationTenant returns the tenant of the schema violation, e.g. test of /declaration/test/app/pool: ...
+func getViolationTenant(violation string) string {
+	path, _, _ := strings.Cut(violation, ": ")
+	if parts := strings.Split(path, "/"); len(parts) > 2 && parts[1] == "declaration" {
+		return parts[2]
+	}
+	return ""
+}
+
+// getAS3Schema returns the AS3 schema the dry-run validates the declarations against
+func (postMgr *PostManager) getAS3Schema() (*AS3Schema, error) {
+	if postMgr.as3Schema != nil {
+		return postMgr.as3Schema, nil
+	}
+	return getDefaultAS3Schema()
+}
+
+// isDeletedTenant checks whether the tenant declaration deletes the tenant, which has no applications
+func isDeletedTenant(tenantDecl as3Tenant) bool {
+	for _, value := range tenantDecl {
+		switch value.(type) {
+		case as3Application, map[string]interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// logDeclarationDiff logs the changes of the posted tenants from their declaration posted last
+func (postMgr *PostManager) logDeclarationDiff(cfg *as3Config) {
+	cachedTenants := make(map[string]as3Tenant)
+	for tenant := range cfg.incomingTenantDeclMap {
+		if decl, ok := postMgr.cachedTenantDeclMap[tenant]; ok {
+			cachedTenants[tenant] = decl
+		}
+	}
+	cached, err := json.Marshal(cachedTenants)
+	if err != nil {
+		return
+	}
+	for _, diff := range DiffAS3Declarations(as3Declaration(cached), as3Declaration(cfg.data)) {
+		if diff.Change == TenantModified {
+			log.Infof("%v[AS3]%v tenant %v %v: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix,
+				diff.Tenant, diff.Change, strings.Join(diff.Keys, ", "))
+		} else {
+			log.Infof("%v[AS3]%v tenant %v %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, diff.Tenant,
+				diff.Change)
+		}
+		log.Debugf("%v[AS3]%v tenant %v before: %v after: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix,
+			diff.Tenant, redactAS3Declaration(diff.Before), redactAS3Declaration(diff.After))
+	}
+}
+
+// recordDeclarationMetrics updates the size metrics of the declaration posted to the BIG-IP
+func recordDeclarationMetrics(cfg *as3Config) {
+	size := float64(len(cfg.data))
+	prometheus.DeclarationSize.WithLabelValues(cfg.targetAddress).Set(size)
+	prometheus.DeclarationBytesSent.WithLabelValues(cfg.targetAddress).Add(size)
+	prometheus.DeclarationTenants.WithLabelValues(cfg.targetAddress).Set(float64(len(cfg.incomingTenantDeclMap)))
+}
+
+// recordTenantPostMetrics records the latency and the response code of the posted tenants
+func recordTenantPostMetrics(cfg *as3Config, latency time.Duration) {
+	for tenant, resp := range cfg.tenantResponseMap {
+		prometheus.TenantPostLatency.WithLabelValues(tenant, cfg.as3APIURL).Observe(latency.Seconds())
+		prometheus.TenantPostResponses.WithLabelValues(tenant, strconv.Itoa(resp.agentResponseCode)).Inc()
+	}
 }
 
 func (postMgr *PostManager) postConfigUsingDocumentAPI(cfg *as3Config) {
@@ -234,6 +528,7 @@ func (postMgr *PostManager) postConfigUsingDocumentAPI(cfg *as3Config) {
 	if postMgr.AS3PostManager.AS3Config.DebugAS3 {
 		postMgr.logAS3Request(cfg.data)
 	}
+	recordDeclarationMetrics(cfg)
 	httpReqBody := bytes.NewBuffer([]byte(cfg.data))
 	var tenants []string
 	if len(cfg.failedTenants) > 0 {
@@ -452,17 +747,23 @@ func updateTenantDeletion(tenant string, declaration map[string]interface{}) boo
 }
 
 func (postMgr *PostManager) httpPOST(request *http.Request) (*http.Response, map[string]interface{}) {
+	httpResp, response, _ := postMgr.doAS3Request(request)
+	return httpResp, response
+}
+
+// doAS3Request posts the request to BIG-IP and returns the AS3Error of the post, which didn't get a valid response
+func (postMgr *PostManager) doAS3Request(request *http.Request) (*http.Response, map[string]interface{}, *AS3Error) {
 	httpResp, err := postMgr.httpClient.Do(request)
 	if err != nil {
 		log.Errorf("[AS3]%v REST call error: %v ", postMgr.postManagerPrefix, err)
-		return nil, nil
+		return nil, nil, classifyRequestError(err)
 	}
 	defer httpResp.Body.Close()
 
 	body, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		log.Errorf("[AS3]%v REST call response error: %v ", postMgr.postManagerPrefix, err)
-		return nil, nil
+		return nil, nil, classifyRequestError(err)
 	}
 	var response map[string]interface{}
 	err = json.Unmarshal(body, &response)
@@ -471,9 +772,10 @@ func (postMgr *PostManager) httpPOST(request *http.Request) (*http.Response, map
 		if postMgr.AS3PostManager.AS3Config.DebugAS3 {
 			log.Errorf("[AS3]%v Raw response from Big-IP: %v", postMgr.postManagerPrefix, string(body))
 		}
-		return nil, nil
+		return nil, nil, newAS3Error(httpResp.StatusCode, "",
+			fmt.Sprintf("invalid response with status %v from BIG-IP", httpResp.Status))
 	}
-	return httpResp, response
+	return httpResp, response, nil
 }
 
 func (postMgr *PostManager) updateTenantResponseCode(code int, cfg *as3Config, tenant string, isDeleted bool) {
@@ -571,6 +873,7 @@ func (postMgr *PostManager) getTenantConfigStatus(id string, cfg *as3Config) {
 	log.Debugf("[AS3]%v posting request with taskId to %v", postMgr.postManagerPrefix, url)
 	// add authorization header to the req
 	req.Header.Add("Authorization", "Bearer "+postMgr.tokenManager.GetToken())
+	cfg.span.injectTraceContext(req)
 
 	httpResp, responseMap := postMgr.httpPOST(req)
 	if httpResp == nil || responseMap == nil {
@@ -581,6 +884,20 @@ func (postMgr *PostManager) getTenantConfigStatus(id string, cfg *as3Config) {
 	if postMgr.AS3Config.DocumentAPI {
 		declarationKey = "request"
 	}
+	if httpResp.StatusCode == http.StatusOK && postMgr.AsyncMode {
+		// the results of an asynchronous declaration are available once its task succeeded
+		switch status, _ := responseMap["status"].(string); status {
+		case AS3TaskRunning:
+			log.Debugf("[AS3]%v task %v is running", postMgr.postManagerPrefix, id)
+			return
+		case AS3TaskFailed:
+			log.Errorf("%v[AS3]%v task %v failed: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, id,
+				responseMap["message"])
+			cfg.acceptedTaskId = ""
+			postMgr.failPendingTenants(cfg, http.StatusUnprocessableEntity)
+			return
+		}
+	}
 	if httpResp.StatusCode == http.StatusOK {
 		var results []interface{}
 		if !postMgr.AS3Config.DocumentAPI {
@@ -694,20 +1011,15 @@ func (postMgr *PostManager) handleDocumentAPIResponseAccepted(responseMap map[st
 func (postMgr *PostManager) handleResponseStatusServiceUnavailable(responseMap map[string]interface{}, cfg *as3Config) {
 	var errorMsg string
 	if err, ok := (responseMap["error"]).(map[string]interface{}); ok {
-		errorMsg = fmt.Sprintf("%v[AS3]%v Big-IP Responded with error code: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, err["code"])
+		errorMsg = fmt.Sprintf("Big-IP Responded with error code: %v", err["code"])
 	} else {
-		errorMsg = fmt.Sprintf("%v[AS3]%v Unknown response from BIG-IP: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, responseMap)
+		errorMsg = fmt.Sprintf("Unknown response from BIG-IP: %v", responseMap)
 	}
-	log.Debugf("[AS3]%v Response from BIG-IP: BIG-IP is busy, waiting %v seconds and re-posting the declaration", postMgr.postManagerPrefix, timeoutMedium)
-	postMgr.tokenManager.StatusManager.AddRequest(statusmanager.DeployConfig, "", "", false,
-		&cisv1.BigIPStatus{
-			BigIPAddress: cfg.targetAddress,
-			AS3Status: &cisv1.AS3Status{
-				Message:       http.StatusText(http.StatusServiceUnavailable),
-				Error:         errorMsg,
-				LastSubmitted: metav1.Now(),
-			},
-		})
+	cfg.retryDelay = postMgr.nextRetryDelay()
+	log.Debugf("[AS3]%v Response from BIG-IP: BIG-IP is busy, waiting %v and re-posting the declaration", postMgr.postManagerPrefix, cfg.retryDelay)
+	as3Err := newAS3Error(http.StatusServiceUnavailable, "", errorMsg)
+	as3Err.RetryAfter = cfg.retryDelay
+	postMgr.reportAS3Error(cfg, http.StatusServiceUnavailable, as3Err)
 	postMgr.updateTenantResponseCode(http.StatusServiceUnavailable, cfg, "", false)
 }
 
@@ -715,36 +1027,109 @@ func (postMgr *PostManager) handleResponseStatusNotFound(responseMap map[string]
 	unknownResponse := false
 	var errorMsg string
 	if err, ok := (responseMap["error"]).(map[string]interface{}); ok {
-		errorMsg = fmt.Sprintf("%v[AS3]%v Big-IP Responded with error code: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, err["code"])
+		errorMsg = fmt.Sprintf("Big-IP Responded with error code: %v", err["code"])
 	} else {
 		errorMsg = fmt.Sprintf("Unknown response from BIG-IP: %v", responseMap)
 		unknownResponse = true
 	}
-	postMgr.tokenManager.StatusManager.AddRequest(statusmanager.DeployConfig, "", "", false,
-		&cisv1.BigIPStatus{
-			BigIPAddress: cfg.targetAddress,
-			AS3Status: &cisv1.AS3Status{
-				Message:       http.StatusText(http.StatusNotFound),
-				Error:         errorMsg,
-				LastSubmitted: metav1.Now(),
-			},
-		})
+	postMgr.reportAS3Error(cfg, http.StatusNotFound, newAS3Error(http.StatusNotFound, "", errorMsg))
 	if postMgr.AS3PostManager.AS3Config.DebugAS3 || unknownResponse {
 		postMgr.logAS3Response(responseMap)
 	}
 	postMgr.updateTenantResponseCode(http.StatusNotFound, cfg, "", false)
 }
 
+// handleResponseStatusUnprocessableEntity marks only the tenant the declaration is invalid for as failed and posts
+// the declaration of the other tenants again, so that an invalid tenant doesn't block the rest of the tenants
+func (postMgr *PostManager) handleResponseStatusUnprocessableEntity(responseMap map[string]interface{}, cfg *as3Config) {
+	body, _ := json.Marshal(responseMap)
+	tenant, message := parseAS3ErrorResponse(string(body))
+	if _, ok := cfg.tenantResponseMap[tenant]; !ok {
+		if tenant != "" {
+			// the error refers to a tenant, which is not part of the declaration posted by CIS
+			as3Err := &AS3Error{Code: ErrInvalidTenant, Tenant: tenant, Message: message}
+			log.Errorf("%v[AS3]%v %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, as3Err)
+			prometheus.AS3Errors.WithLabelValues(postMgr.bigIpAddress, string(as3Err.Code)).Inc()
+		}
+		postMgr.handleResponseOthers(responseMap, cfg, http.StatusUnprocessableEntity)
+		return
+	}
+	postMgr.updateTenantResponseCode(http.StatusUnprocessableEntity, cfg, tenant, false)
+	postMgr.reportAS3Error(cfg, http.StatusUnprocessableEntity,
+		newAS3Error(http.StatusUnprocessableEntity, tenant, fmt.Sprintf("Declaration of tenant %v is invalid: %v", tenant, message)))
+	data, remainingTenants := removeTenantFromDeclaration(cfg.data, tenant)
+	if remainingTenants == 0 {
+		return
+	}
+	// the invalid tenant stays failed, so that it is posted again once its resources are updated
+	cfg.data = data
+	log.Infof("%v[AS3]%v posting the declaration without the invalid tenant %v", getRequestPrefix(cfg.id),
+		postMgr.postManagerPrefix, tenant)
+	postMgr.postConfig(cfg)
+}
+
+// parseAS3ErrorResponse extracts the tenant and the error message from the AS3 422 response, the schema
+// validation errors are reported with the path of the invalid property, e.g. "/tenant/app/vs/port: message"
+func parseAS3ErrorResponse(body string) (tenant string, message string) {
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return "", ""
+	}
+	message, _ = response["message"].(string)
+	errors, _ := response["errors"].([]interface{})
+	for _, err := range errors {
+		errMsg, ok := err.(string)
+		if !ok || !strings.HasPrefix(errMsg, "/") {
+			continue
+		}
+		path := strings.SplitN(errMsg, ":", 2)[0]
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		if segments[0] == "declaration" && len(segments) > 1 {
+			segments = segments[1:]
+		}
+		if segments[0] != "" {
+			return segments[0], errMsg
+		}
+	}
+	return "", message
+}
+
+// removeTenantFromDeclaration removes the tenant from the AS3 declaration and returns the declaration with the
+// number of tenants left in it
+func removeTenantFromDeclaration(data string, tenant string) (string, int) {
+	var as3Config map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &as3Config); err != nil {
+		return data, 0
+	}
+	adc, ok := as3Config["declaration"].(map[string]interface{})
+	if !ok {
+		return data, 0
+	}
+	delete(adc, tenant)
+	remainingTenants := 0
+	for _, decl := range adc {
+		if obj, ok := decl.(map[string]interface{}); ok && obj["class"] == "Tenant" {
+			remainingTenants++
+		}
+	}
+	decl, err := json.Marshal(as3Config)
+	if err != nil {
+		return data, 0
+	}
+	return string(decl), remainingTenants
+}
+
 func (postMgr *PostManager) handleResponseOthers(responseMap map[string]interface{}, cfg *as3Config, httpCode int) {
 	unknownResponse := false
-	var errorMsg string
+	var errorMsg, errorTenant string
 	if results, ok := (responseMap["results"]).([]interface{}); ok {
 		for _, value := range results {
 			if v, ok := value.(map[string]interface{}); ok {
 				code, ok1 := v["code"].(float64)
 				tenant, ok2 := v["tenant"].(string)
 				if ok1 && ok2 {
-					errorMsg = fmt.Sprintf("%v[AS3]%v Response from BIG-IP: code: %v --- tenant:%v --- message: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, v["code"], v["tenant"], v["message"])
+					errorMsg = fmt.Sprintf("Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
+					errorTenant = tenant
 					postMgr.updateTenantResponseCode(int(code), cfg, tenant, false)
 				} else {
 					unknownResponse = true
@@ -754,7 +1139,7 @@ func (postMgr *PostManager) handleResponseOthers(responseMap map[string]interfac
 			}
 		}
 	} else if err, ok := (responseMap["error"]).(map[string]interface{}); ok {
-		errorMsg = fmt.Sprintf("%v[AS3]%v Big-IP Responded with error code: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, err["code"])
+		errorMsg = fmt.Sprintf("Big-IP Responded with error code: %v", err["code"])
 		if code, ok := err["code"].(float64); ok {
 			postMgr.updateTenantResponseCode(int(code), cfg, "", false)
 		} else {
@@ -762,24 +1147,15 @@ func (postMgr *PostManager) handleResponseOthers(responseMap map[string]interfac
 		}
 	} else {
 		unknownResponse = true
-		errorMsg = fmt.Sprintf("%v[AS3]%v Big-IP Responded with code: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, responseMap["code"])
+		errorMsg = fmt.Sprintf("Big-IP Responded with code: %v", responseMap["code"])
 		if code, ok := responseMap["code"].(float64); ok {
 			postMgr.updateTenantResponseCode(int(code), cfg, "", false)
 		}
 	}
 	if errorMsg == "" && unknownResponse {
-		errorMsg = fmt.Sprintf("%v[AS3]%v Unknown response from BIG-IP: %v", getRequestPrefix(cfg.id), postMgr.postManagerPrefix, responseMap)
+		errorMsg = fmt.Sprintf("Unknown response from BIG-IP: %v", responseMap)
 	}
-
-	postMgr.tokenManager.StatusManager.AddRequest(statusmanager.DeployConfig, "", "", false,
-		&cisv1.BigIPStatus{
-			BigIPAddress: cfg.targetAddress,
-			AS3Status: &cisv1.AS3Status{
-				Message:       http.StatusText(httpCode),
-				Error:         errorMsg,
-				LastSubmitted: metav1.Now(),
-			},
-		})
+	postMgr.reportAS3Error(cfg, httpCode, newAS3Error(httpCode, errorTenant, errorMsg))
 	if postMgr.AS3PostManager.AS3Config.DebugAS3 || unknownResponse {
 		postMgr.logAS3Response(responseMap)
 	}
@@ -855,15 +1231,16 @@ func (postMgr *PostManager) GetBigipRegKey() (string, error) {
 	return "", fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
 }
 
-func (postMgr *PostManager) GetAS3DeclarationFromBigIP() (map[string]interface{}, error) {
-	url := postMgr.getAS3APIURL("")
+// GetBigipLicensedModules returns the descriptions of the modules active in the BIG-IP license
+func (postMgr *PostManager) GetBigipLicensedModules() ([]string, error) {
+	url := postMgr.getBigipLicenseURL()
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		log.Errorf("[AS3]%v Creating new HTTP request error: %v ", postMgr.postManagerPrefix, err)
 		return nil, err
 	}
 
-	log.Debugf("[AS3]%v posting GET BIGIP AS3 declaration request on %v", postMgr.postManagerPrefix, url)
+	log.Debugf("[AS3]%v Posting GET BIGIP license request on %v", postMgr.postManagerPrefix, url)
 	// add authorization header to the req
 	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
 
@@ -872,118 +1249,669 @@ func (postMgr *PostManager) GetAS3DeclarationFromBigIP() (map[string]interface{}
 		return nil, fmt.Errorf("Internal Error")
 	}
 
-	switch httpResp.StatusCode {
-	case http.StatusOK:
-		return responseMap, err
-	case http.StatusNotFound:
-		responseMap["code"] = int(responseMap["code"].(float64))
-		if responseMap["code"] == http.StatusNotFound {
-			return nil, fmt.Errorf("AS3 RPM is not installed on BIGIP,"+
-				" Error response from BIGIP with status code %v", httpResp.StatusCode)
-		}
+	if httpResp.StatusCode == http.StatusOK {
+		// active modules are reported as nested stats entries with a description
+		var modules []string
+		collectLicenseDescriptions(responseMap, &modules)
+		return modules, nil
 	}
 	return nil, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
 }
 
-func (postMgr *PostManager) httpReq(request *http.Request) (*http.Response, map[string]interface{}) {
-	httpResp, err := postMgr.httpClient.Do(request)
-	if err != nil {
-		log.Errorf("[AS3]%v REST call error: %v ", postMgr.postManagerPrefix, err)
-		return nil, nil
+func collectLicenseDescriptions(obj interface{}, modules *[]string) {
+	switch val := obj.(type) {
+	case map[string]interface{}:
+		for key, v := range val {
+			if desc, ok := v.(string); ok && key == "description" {
+				*modules = append(*modules, desc)
+				continue
+			}
+			collectLicenseDescriptions(v, modules)
+		}
+	case []interface{}:
+		for _, v := range val {
+			collectLicenseDescriptions(v, modules)
+		}
 	}
-	defer httpResp.Body.Close()
+}
 
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		log.Errorf("[AS3]%v REST call response error: %v ", postMgr.postManagerPrefix, err)
-		return nil, nil
+// licensedFeatureModules maps the features to the names of their module in the BIG-IP license
+var licensedFeatureModules = map[string][]string{
+	LicenseFeatureFirewall:  {"AFM", "Advanced Firewall"},
+	LicenseFeatureAnalytics: {"AVR", "Application Visibility"},
+	LicenseFeatureGSLB:      {"GTM", "Global Traffic", "BIG-IP DNS"},
+	LicenseFeatureAccess:    {"APM", "Access Policy"},
+	LicenseFeatureDOS:       {"AFM", "Advanced Firewall"},
+}
+
+// checkLicensedFeatures warns about the configured features whose BIG-IP module is not licensed.
+// License is fetched with the first declaration and re-checked daily to catch license expiry.
+func (postMgr *PostManager) checkLicensedFeatures(rsConfig *BigIpResourceConfig) []string {
+	if !postMgr.licenseCheckedAt.IsZero() && time.Since(postMgr.licenseCheckedAt) < LicenseCheckInterval {
+		return nil
 	}
-	var response map[string]interface{}
-	err = json.Unmarshal(body, &response)
+	modules, err := postMgr.GetBigipLicensedModules()
 	if err != nil {
-		log.Errorf("[AS3]%v Response body unmarshal failed: %v\n", postMgr.postManagerPrefix, err)
-		if postMgr.AS3PostManager.AS3Config.DebugAS3 {
-			log.Errorf("[AS3]%v Raw response from Big-IP: %v", postMgr.postManagerPrefix, string(body))
+		log.Warningf("[AS3]%v Could not fetch the license from BIG-IP: %v", postMgr.postManagerPrefix, err)
+		return nil
+	}
+	postMgr.licensedModules = modules
+	postMgr.licenseCheckedAt = time.Now()
+	if postMgr.AS3PostManager != nil {
+		postMgr.AS3PostManager.avrUnlicensed = !postMgr.isModuleLicensed(licensedFeatureModules[LicenseFeatureAnalytics])
+		postMgr.AS3PostManager.apmUnlicensed = !postMgr.isModuleLicensed(licensedFeatureModules[LicenseFeatureAccess])
+		postMgr.AS3PostManager.afmUnlicensed = !postMgr.isModuleLicensed(licensedFeatureModules[LicenseFeatureDOS])
+	}
+	configured := make(map[string]bool)
+	for _, partitionConfig := range rsConfig.ltmConfig {
+		for _, rsCfg := range partitionConfig.ResourceMap {
+			if rsCfg.Virtual.Firewall != "" {
+				configured[LicenseFeatureFirewall] = true
+			}
+			if rsCfg.Virtual.AnalyticsProfiles != (AnalyticsProfiles{}) {
+				configured[LicenseFeatureAnalytics] = true
+			}
+			if rsCfg.Virtual.ProfileAccess != "" {
+				configured[LicenseFeatureAccess] = true
+			}
+			if rsCfg.Virtual.ProfileDOS != "" || rsCfg.Virtual.DOS != nil {
+				configured[LicenseFeatureDOS] = true
+			}
 		}
-		return nil, nil
 	}
-	return httpResp, response
+	if len(rsConfig.gtmConfig) > 0 {
+		configured[LicenseFeatureGSLB] = true
+	}
+	var unlicensed []string
+	for _, feature := range []string{LicenseFeatureFirewall, LicenseFeatureAnalytics, LicenseFeatureGSLB, LicenseFeatureAccess,
+		LicenseFeatureDOS} {
+		if configured[feature] && !postMgr.isModuleLicensed(licensedFeatureModules[feature]) {
+			log.Warningf("[AS3]%v %v is configured but the required module is not licensed on BIG-IP",
+				postMgr.postManagerPrefix, feature)
+			unlicensed = append(unlicensed, feature)
+		}
+	}
+	return unlicensed
 }
 
-func (postMgr *PostManager) getAS3VersionURL() string {
-	apiURL := postMgr.tokenManager.ServerURL + CmDeclareInfoApi
-	return apiURL
+func (postMgr *PostManager) isModuleLicensed(names []string) bool {
+	for _, module := range postMgr.licensedModules {
+		for _, name := range names {
+			if strings.Contains(strings.ToLower(module), strings.ToLower(name)) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-func (postMgr *PostManager) getBigipRegKeyURL() string {
-	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/shared/licensing/registration"
-	return apiURL
-}
+// GetBigipProvisionedModules returns the names of the BIG-IP modules provisioned with a level other than none
+func (postMgr *PostManager) GetBigipProvisionedModules() (map[string]struct{}, error) {
+	url := postMgr.getBigipProvisionURL()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		log.Errorf("[AS3]%v Creating new HTTP request error: %v ", postMgr.postManagerPrefix, err)
+		return nil, err
+	}
 
-func (postMgr *PostManager) logAS3Response(responseMap map[string]interface{}) {
-	// removing the certificates/privateKey from response log
-	if declaration, ok := (responseMap["declaration"]).([]interface{}); ok {
-		for _, value := range declaration {
-			if tenantMap, ok := value.(map[string]interface{}); ok {
-				for _, value2 := range tenantMap {
-					if appMap, ok := value2.(map[string]interface{}); ok {
-						for _, obj := range appMap {
-							if crt, ok := obj.(map[string]interface{}); ok {
-								if crt["class"] == "Certificate" {
-									crt["certificate"] = ""
-									crt["privateKey"] = ""
-									crt["chainCA"] = ""
-								}
-							}
-						}
-					}
-				}
+	log.Debugf("[AS3]%v Posting GET BIGIP provision request on %v", postMgr.postManagerPrefix, url)
+	// add authorization header to the req
+	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
+
+	httpResp, responseMap := postMgr.httpReq(req)
+	if httpResp == nil || responseMap == nil {
+		return nil, fmt.Errorf("Internal Error")
+	}
+
+	if httpResp.StatusCode == http.StatusOK {
+		modules := make(map[string]struct{})
+		items, _ := responseMap["items"].([]interface{})
+		for _, item := range items {
+			module, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := module["name"].(string)
+			level, _ := module["level"].(string)
+			if name != "" && level != "" && level != "none" {
+				modules[name] = struct{}{}
 			}
 		}
-		decl, err := json.Marshal(declaration)
-		if err != nil {
-			log.Errorf("[AS3]%v error while reading declaration from AS3 response: %v\n", postMgr.postManagerPrefix, err)
-			return
-		}
-		responseMap["declaration"] = as3Declaration(decl)
+		return modules, nil
 	}
-	log.Debugf("[AS3]%v Raw response from Big-IP: %v ", postMgr.postManagerPrefix, responseMap)
+	return nil, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
 }
 
-func (postMgr *PostManager) logAS3Request(cfg string) {
-	var as3Config, adc map[string]interface{}
-	err := json.Unmarshal([]byte(cfg), &as3Config)
+// GetBigipLogPublishers returns the full paths of the Log Publishers on BIG-IP
+func (postMgr *PostManager) GetBigipLogPublishers() (map[string]struct{}, error) {
+	url := postMgr.getBigipLogPublisherURL()
+	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		log.Errorf("[AS3]%v Request body unmarshal failed: %v\n", postMgr.postManagerPrefix, err)
+		log.Errorf("[AS3]%v Creating new HTTP request error: %v ", postMgr.postManagerPrefix, err)
+		return nil, err
 	}
-	if !postMgr.AS3Config.DocumentAPI {
-		adc = as3Config["declaration"].(map[string]interface{})
-	} else {
-		adc = as3Config
+
+	log.Debugf("[AS3]%v Posting GET BIGIP log publisher request on %v", postMgr.postManagerPrefix, url)
+	// add authorization header to the req
+	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
+
+	httpResp, responseMap := postMgr.httpReq(req)
+	if httpResp == nil || responseMap == nil {
+		return nil, fmt.Errorf("Internal Error")
 	}
-	for _, value := range adc {
-		if tenantMap, ok := value.(map[string]interface{}); ok {
-			for _, value2 := range tenantMap {
-				if appMap, ok := value2.(map[string]interface{}); ok {
-					for _, obj := range appMap {
-						if crt, ok := obj.(map[string]interface{}); ok {
-							if crt["class"] == "Certificate" {
-								crt["certificate"] = ""
-								crt["privateKey"] = ""
-								crt["chainCA"] = ""
-							}
-						}
-					}
+
+	if httpResp.StatusCode == http.StatusOK {
+		publishers := make(map[string]struct{})
+		items, _ := responseMap["items"].([]interface{})
+		for _, item := range items {
+			if publisher, ok := item.(map[string]interface{}); ok {
+				if fullPath, _ := publisher["fullPath"].(string); fullPath != "" {
+					publishers[fullPath] = struct{}{}
 				}
 			}
 		}
+		return publishers, nil
+	}
+	return nil, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
+}
+
+// checkLogPublishers removes the Log Publishers of the virtuals which do not exist on BIG-IP, the publishers
+// are fetched again only when a virtual references a publisher not seen before. The resource configs of the
+// request are shared with the controller, so the publishers are removed from copies of the resource configs
+func (postMgr *PostManager) checkLogPublishers(rsConfig *BigIpResourceConfig) {
+	type virtualRef struct {
+		partition string
+		name      string
+		rsCfg     *ResourceConfig
+	}
+	var virtuals []virtualRef
+	refetch := false
+	for partition, partitionConfig := range rsConfig.ltmConfig {
+		for name, rsCfg := range partitionConfig.ResourceMap {
+			if rsCfg.Virtual.LogPublisher == "" {
+				continue
+			}
+			virtuals = append(virtuals, virtualRef{partition: partition, name: name, rsCfg: rsCfg})
+			if _, ok := postMgr.logPublishers[rsCfg.Virtual.LogPublisher]; !ok {
+				refetch = true
+			}
+		}
+	}
+	if refetch {
+		publishers, err := postMgr.GetBigipLogPublishers()
+		if err != nil {
+			// the publishers can't be validated, so the declaration is left to AS3 to validate
+			log.Warningf("[AS3]%v Could not fetch the log publishers from BIG-IP: %v", postMgr.postManagerPrefix, err)
+			return
+		}
+		postMgr.logPublishers = publishers
+	}
+	var ltmConfig LTMConfig
+	copiedPartitions := make(map[string]struct{})
+	for _, virtual := range virtuals {
+		if _, ok := postMgr.logPublishers[virtual.rsCfg.Virtual.LogPublisher]; ok {
+			continue
+		}
+		log.Errorf("[AS3]%v Log publisher %v of virtual %v not found on BIG-IP, skipping the security log profile",
+			postMgr.postManagerPrefix, virtual.rsCfg.Virtual.LogPublisher, virtual.rsCfg.Virtual.Name)
+		if ltmConfig == nil {
+			ltmConfig = copyLTMConfig(rsConfig.ltmConfig)
+		}
+		if _, ok := copiedPartitions[virtual.partition]; !ok {
+			partitionConfig := ltmConfig[virtual.partition]
+			ltmConfig[virtual.partition] = &PartitionConfig{ResourceMap: make(ResourceMap, len(partitionConfig.ResourceMap)),
+				Priority: partitionConfig.Priority}
+			for name, rsCfg := range partitionConfig.ResourceMap {
+				ltmConfig[virtual.partition].ResourceMap[name] = rsCfg
+			}
+			copiedPartitions[virtual.partition] = struct{}{}
+		}
+		// the declaration only reads the resource config, so a shallow copy is enough
+		rsCfg := *virtual.rsCfg
+		rsCfg.Virtual.LogPublisher = ""
+		ltmConfig[virtual.partition].ResourceMap[virtual.name] = &rsCfg
+	}
+	if ltmConfig != nil {
+		rsConfig.ltmConfig = ltmConfig
+	}
+}
+
+// gatedModules are the BIG-IP modules whose features are skipped when they are not provisioned
+var gatedModules = []string{ModuleAFM, ModuleAVR, ModuleAPM, ModulePEM}
+
+// checkProvisionedModules refreshes the provisioned BIG-IP modules every ProvisionCheckInterval and
+// re-evaluates the features gated by them when modules are provisioned or deprovisioned
+func (postMgr *PostManager) checkProvisionedModules() bool {
+	if !postMgr.provisionCheckedAt.IsZero() &&
+		(postMgr.ProvisionCheckInterval == 0 || time.Since(postMgr.provisionCheckedAt) < postMgr.ProvisionCheckInterval) {
+		return false
+	}
+	return postMgr.refreshProvisionedModules()
+}
+
+// refreshProvisionedModules fetches the provisioned BIG-IP modules, it returns true when the gated modules
+// changed since the previous fetch, so that the declaration has to be rebuilt
+func (postMgr *PostManager) refreshProvisionedModules() bool {
+	modules, err := postMgr.GetBigipProvisionedModules()
+	if err != nil {
+		log.Warningf("[AS3]%v Could not fetch the provisioned modules from BIG-IP: %v", postMgr.postManagerPrefix, err)
+		return false
+	}
+	firstCheck := postMgr.provisionCheckedAt.IsZero()
+	if !firstCheck {
+		for name := range modules {
+			if _, ok := postMgr.provisionedModules[name]; !ok {
+				log.Infof("[AS3]%v Module %v is provisioned on BIG-IP", postMgr.postManagerPrefix, name)
+			}
+		}
+		for name := range postMgr.provisionedModules {
+			if _, ok := modules[name]; !ok {
+				log.Warningf("[AS3]%v Module %v is no longer provisioned on BIG-IP", postMgr.postManagerPrefix, name)
+			}
+		}
+	}
+	postMgr.provisionedModules = modules
+	postMgr.provisionCheckedAt = time.Now()
+	if postMgr.AS3PostManager == nil {
+		return false
+	}
+	unprovisioned := make(map[string]struct{})
+	for _, name := range gatedModules {
+		if _, ok := modules[name]; !ok {
+			unprovisioned[name] = struct{}{}
+		}
+	}
+	changed := !firstCheck && !reflect.DeepEqual(unprovisioned, postMgr.AS3PostManager.unprovisionedModules)
+	postMgr.AS3PostManager.unprovisionedModules = unprovisioned
+	return changed
+}
+
+// GetBigipCertExpiry returns the expiry time of the BIG-IP management certificate
+func (postMgr *PostManager) GetBigipCertExpiry() (time.Time, error) {
+	url := postMgr.getBigipCertURL()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		log.Errorf("[AS3]%v Creating new HTTP request error: %v ", postMgr.postManagerPrefix, err)
+		return time.Time{}, err
+	}
+
+	log.Debugf("[AS3]%v Posting GET BIGIP certificate request on %v", postMgr.postManagerPrefix, url)
+	// add authorization header to the req
+	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
+
+	httpResp, responseMap := postMgr.httpReq(req)
+	if httpResp == nil || responseMap == nil {
+		return time.Time{}, fmt.Errorf("Internal Error")
+	}
+
+	if httpResp.StatusCode == http.StatusOK {
+		// expirationDate is the expiry in seconds since epoch
+		if expiry, ok := responseMap["expirationDate"].(float64); ok {
+			return time.Unix(int64(expiry), 0), nil
+		}
+		return time.Time{}, fmt.Errorf("Missing expirationDate in BIGIP certificate response")
+	}
+	return time.Time{}, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
+}
+
+// checkCertExpiry publishes the days until the BIG-IP management certificate expires and
+// warns with an event on the CIS pod when it expires within the warning days
+func (postMgr *PostManager) checkCertExpiry() {
+	expiry, err := postMgr.GetBigipCertExpiry()
+	if err != nil {
+		log.Warningf("[AS3]%v Could not fetch the management certificate from BIG-IP: %v", postMgr.postManagerPrefix, err)
+		return
+	}
+	postMgr.certCheckedAt = time.Now()
+	days := int(time.Until(expiry).Hours() / 24)
+	prometheus.BigIPCertDaysUntilExpiry.WithLabelValues(postMgr.bigIpAddress).Set(float64(days))
+	if days >= postMgr.CertExpiryWarningDays {
+		return
+	}
+	message := fmt.Sprintf("BIG-IP %v management certificate expires in %v days on %v", postMgr.bigIpAddress, days,
+		expiry.UTC().Format(time.RFC3339))
+	log.Warningf("[AS3]%v %v", postMgr.postManagerPrefix, message)
+	postMgr.recordControllerPodEvent(v1.EventTypeWarning, CertExpiringEvent, message)
+}
+
+// recordControllerPodEvent creates an event on the CIS pod, it is skipped when CIS does not run in a pod
+func (postMgr *PostManager) recordControllerPodEvent(eventType, reason, message string) {
+	if postMgr.kubeClient == nil || postMgr.podName == "" || postMgr.podNamespace == "" {
+		return
+	}
+	event := postMgr.newControllerPodEvent(eventType, reason, message)
+	_, err := postMgr.kubeClient.CoreV1().Events(postMgr.podNamespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	if err != nil {
+		log.Debugf("[AS3]%v Error while creating pod event: %v", postMgr.postManagerPrefix, err)
+	}
+}
+
+func (postMgr *PostManager) newControllerPodEvent(eventType, reason, message string) *v1.Event {
+	now := metav1.Now()
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: postMgr.podName + ".",
+			Namespace:    postMgr.podNamespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:       "Pod",
+			APIVersion: "v1",
+			Namespace:  postMgr.podNamespace,
+			Name:       postMgr.podName,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         v1.EventSource{Component: "k8s-bigip-ctlr"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+}
+
+// GetBigipPartitions returns the names of the partitions on BIG-IP
+func (postMgr *PostManager) GetBigipPartitions() ([]string, error) {
+	url := postMgr.getBigipPartitionURL()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		log.Errorf("[AS3]%v Creating new HTTP request error: %v ", postMgr.postManagerPrefix, err)
+		return nil, err
+	}
+
+	log.Debugf("[AS3]%v Posting GET BIGIP partitions request on %v", postMgr.postManagerPrefix, url)
+	// add authorization header to the req
+	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
+
+	httpResp, responseMap := postMgr.httpReq(req)
+	if httpResp == nil || responseMap == nil {
+		return nil, fmt.Errorf("Internal Error")
+	}
+
+	if httpResp.StatusCode == http.StatusOK {
+		var partitions []string
+		if items, ok := responseMap["items"].([]interface{}); ok {
+			for _, item := range items {
+				if partition, ok := item.(map[string]interface{}); ok {
+					if name, ok := partition["name"].(string); ok {
+						partitions = append(partitions, name)
+					}
+				}
+			}
+		}
+		return partitions, nil
+	}
+	return nil, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
+}
+
+// skipUnownedPartitions refuses to manage the tenants which collide with a BIG-IP partition not managed by CIS,
+// e.g. Common or a manually created partition, so that the configuration in the partition is not overwritten.
+// Partitions are fetched with the first declaration, a partition is managed by CIS when its AS3 tenant has the CIS label.
+// The ltmConfig of the request is shared with the controller, so the tenants are skipped on a copy of it.
+// fetchUnownedPartitions fetches the BIG-IP partitions which are not managed by CIS, the partitions of the tenants
+// restored from the checkpoint and the tenants labelled with the default partition are managed by CIS
+func (postMgr *PostManager) fetchUnownedPartitions() error {
+	partitions, err := postMgr.GetBigipPartitions()
+	if err != nil {
+		return fmt.Errorf("could not fetch the partitions from BIG-IP: %v", err)
+	}
+	currentConfig, err := postMgr.GetAS3DeclarationFromBigIP()
+	if err != nil {
+		return fmt.Errorf("could not fetch the latest AS3 declaration from BIG-IP: %v", err)
+	}
+	unownedPartitions := make(map[string]struct{})
+	for _, partition := range partitions {
+		if _, ok := postMgr.cachedTenantDeclMap[partition]; ok {
+			continue
+		}
+		if decl, ok := currentConfig[partition].(map[string]interface{}); ok {
+			if label, found := decl["label"]; found && label == postMgr.defaultPartition {
+				continue
+			}
+		}
+		unownedPartitions[partition] = struct{}{}
+	}
+	postMgr.unownedPartitionsLock.Lock()
+	postMgr.unownedPartitions = unownedPartitions
+	postMgr.unownedPartitionsLock.Unlock()
+	return nil
+}
+
+// unownedPartitionsFetched returns true once the BIG-IP partitions which are not managed by CIS are fetched
+func (postMgr *PostManager) unownedPartitionsFetched() bool {
+	postMgr.unownedPartitionsLock.RLock()
+	defer postMgr.unownedPartitionsLock.RUnlock()
+	return postMgr.unownedPartitions != nil
+}
+
+// skipUnownedPartitions skips the tenants which collide with the BIG-IP partitions not managed by CIS
+func (postMgr *PostManager) skipUnownedPartitions(rsConfig *BigIpResourceConfig) {
+	postMgr.unownedPartitionsLock.RLock()
+	defer postMgr.unownedPartitionsLock.RUnlock()
+	var ltmConfig LTMConfig
+	for tenant := range rsConfig.ltmConfig {
+		if _, ok := postMgr.unownedPartitions[tenant]; ok {
+			log.Criticalf("[AS3]%v Refusing to manage tenant %v, it collides with the BIG-IP partition %v which is not "+
+				"managed by CIS", postMgr.postManagerPrefix, tenant, tenant)
+			if ltmConfig == nil {
+				ltmConfig = copyLTMConfig(rsConfig.ltmConfig)
+			}
+			delete(ltmConfig, tenant)
+		}
+	}
+	if ltmConfig != nil {
+		rsConfig.ltmConfig = ltmConfig
+	}
+}
+
+func (postMgr *PostManager) GetAS3DeclarationFromBigIP() (map[string]interface{}, error) {
+	url := postMgr.getAS3APIURL("")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		log.Errorf("[AS3]%v Creating new HTTP request error: %v ", postMgr.postManagerPrefix, err)
+		return nil, err
+	}
+
+	log.Debugf("[AS3]%v posting GET BIGIP AS3 declaration request on %v", postMgr.postManagerPrefix, url)
+	// add authorization header to the req
+	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
+
+	httpResp, responseMap := postMgr.httpReq(req)
+	if httpResp == nil || responseMap == nil {
+		return nil, fmt.Errorf("Internal Error")
+	}
+
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+		return responseMap, err
+	case http.StatusNotFound:
+		responseMap["code"] = int(responseMap["code"].(float64))
+		if responseMap["code"] == http.StatusNotFound {
+			return nil, fmt.Errorf("AS3 RPM is not installed on BIGIP,"+
+				" Error response from BIGIP with status code %v", httpResp.StatusCode)
+		}
+	}
+	return nil, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
+}
+
+// validateOptimisticLockKeys drops the tenants whose optimistic lock key does not match the key of the
+// tenant on BIG-IP, so that a tenant modified out of band is not overwritten
+func (postMgr *PostManager) validateOptimisticLockKeys(cfg *as3Config) {
+	var lockedTenants []string
+	for tenant, decl := range cfg.incomingTenantDeclMap {
+		if _, ok := decl["optimisticLockKey"]; ok {
+			lockedTenants = append(lockedTenants, tenant)
+		}
+	}
+	if len(lockedTenants) == 0 {
+		return
+	}
+	currentConfig, err := postMgr.GetAS3DeclarationFromBigIP()
+	if err != nil {
+		log.Errorf("[AS3]%v Could not fetch the latest AS3 declaration from BIG-IP to validate optimistic lock keys: %v",
+			postMgr.postManagerPrefix, err)
+		return
+	}
+	for _, tenant := range lockedTenants {
+		current, ok := currentConfig[tenant].(map[string]interface{})
+		if !ok {
+			// tenant does not exist on BIG-IP yet
+			continue
+		}
+		currentKey, _ := current["optimisticLockKey"].(string)
+		if currentKey != "" && currentKey != cfg.incomingTenantDeclMap[tenant]["optimisticLockKey"] {
+			log.Errorf("[AS3]%v Skipping tenant %v, optimistic lock key does not match the key on BIG-IP",
+				postMgr.postManagerPrefix, tenant)
+			delete(cfg.incomingTenantDeclMap, tenant)
+			delete(cfg.tenantResponseMap, tenant)
+		}
+	}
+}
+
+func (postMgr *PostManager) httpReq(request *http.Request) (*http.Response, map[string]interface{}) {
+	httpResp, err := postMgr.httpClient.Do(request)
+	if err != nil {
+		log.Errorf("[AS3]%v REST call error: %v ", postMgr.postManagerPrefix, err)
+		return nil, nil
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		log.Errorf("[AS3]%v REST call response error: %v ", postMgr.postManagerPrefix, err)
+		return nil, nil
+	}
+	var response map[string]interface{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		log.Errorf("[AS3]%v Response body unmarshal failed: %v\n", postMgr.postManagerPrefix, err)
+		if postMgr.AS3PostManager.AS3Config.DebugAS3 {
+			log.Errorf("[AS3]%v Raw response from Big-IP: %v", postMgr.postManagerPrefix, string(body))
+		}
+		return nil, nil
+	}
+	return httpResp, response
+}
+
+func (postMgr *PostManager) getAS3VersionURL() string {
+	apiURL := postMgr.tokenManager.ServerURL + CmDeclareInfoApi
+	return apiURL
+}
+
+func (postMgr *PostManager) getBigipLicenseURL() string {
+	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/sys/license"
+	return apiURL
+}
+
+func (postMgr *PostManager) getBigipProvisionURL() string {
+	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/sys/provision"
+	return apiURL
+}
+
+func (postMgr *PostManager) getBigipLogPublisherURL() string {
+	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/sys/log-config/publisher"
+	return apiURL
+}
+
+func (postMgr *PostManager) getBigipPartitionURL() string {
+	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/auth/partition"
+	return apiURL
+}
+
+func (postMgr *PostManager) getBigipCertURL() string {
+	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/sys/crypto/cert/~Common~server.crt"
+	return apiURL
+}
+
+func (postMgr *PostManager) getBigipRegKeyURL() string {
+	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/shared/licensing/registration"
+	return apiURL
+}
+
+func (postMgr *PostManager) logAS3Response(responseMap map[string]interface{}) {
+	// removing the certificates/privateKey from response log
+	if declaration, ok := (responseMap["declaration"]).([]interface{}); ok {
+		for _, value := range declaration {
+			if tenantMap, ok := value.(map[string]interface{}); ok {
+				for _, value2 := range tenantMap {
+					if appMap, ok := value2.(map[string]interface{}); ok {
+						for _, obj := range appMap {
+							if crt, ok := obj.(map[string]interface{}); ok {
+								if crt["class"] == "Certificate" {
+									crt["certificate"] = ""
+									crt["privateKey"] = ""
+									crt["chainCA"] = ""
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		decl, err := json.Marshal(declaration)
+		if err != nil {
+			log.Errorf("[AS3]%v error while reading declaration from AS3 response: %v\n", postMgr.postManagerPrefix, err)
+			return
+		}
+		responseMap["declaration"] = as3Declaration(decl)
+	}
+	log.Debugf("[AS3]%v Raw response from Big-IP: %v ", postMgr.postManagerPrefix, responseMap)
+}
+
+// redactAS3Secrets blanks the certificates, the private keys and the AS3 secrets, e.g. the monitor passphrases,
+// of the declaration, so that they are not written to the logs or the checkpoint
+func redactAS3Secrets(obj interface{}) {
+	switch value := obj.(type) {
+	case map[string]interface{}:
+		if value["class"] == "Certificate" {
+			value["certificate"] = ""
+			value["privateKey"] = ""
+			value["chainCA"] = ""
+		}
+		if _, ok := value["ciphertext"]; ok {
+			value["ciphertext"] = ""
+		}
+		for _, nested := range value {
+			redactAS3Secrets(nested)
+		}
+	case []interface{}:
+		for _, nested := range value {
+			redactAS3Secrets(nested)
+		}
 	}
-	decl, err := json.Marshal(as3Config)
-	if err != nil {
-		log.Errorf("[AS3]%v Unified declaration error: %v\n", postMgr.postManagerPrefix, err)
-		return
-	}
-	log.Debugf("[AS3]%v Unified declaration: %v\n", postMgr.postManagerPrefix, as3Declaration(decl))
+}
+
+// redactAS3Declaration returns the JSON with its secrets blanked by redactAS3Secrets
+func redactAS3Declaration(data string) string {
+	var obj interface{}
+	if err := json.Unmarshal([]byte(data), &obj); err != nil {
+		return data
+	}
+	redactAS3Secrets(obj)
+	redacted, err := json.Marshal(obj)
+	if err != nil {
+		return data
+	}
+	return string(redacted)
+}
+
+func (postMgr *PostManager) logAS3Request(cfg string) {
+	var as3Config, adc map[string]interface{}
+	err := json.Unmarshal([]byte(cfg), &as3Config)
+	if err != nil {
+		log.Errorf("[AS3]%v Request body unmarshal failed: %v\n", postMgr.postManagerPrefix, err)
+	}
+	if !postMgr.AS3Config.DocumentAPI {
+		adc = as3Config["declaration"].(map[string]interface{})
+	} else {
+		adc = as3Config
+	}
+	redactAS3Secrets(adc)
+	decl, err := json.Marshal(as3Config)
+	if err != nil {
+		log.Errorf("[AS3]%v Unified declaration error: %v\n", postMgr.postManagerPrefix, err)
+		return
+	}
+	log.Debugf("[AS3]%v Unified declaration: %v\n", postMgr.postManagerPrefix, as3Declaration(decl))
 }
 
 func (postMgr *PostManager) updateTenantCache(cfg *as3Config) {
@@ -1009,19 +1937,499 @@ func (postMgr *PostManager) updateTenantCache(cfg *as3Config) {
 	}
 }
 
+// updateHealthSummary updates the health of the tenants with the responses of the posted tenants
+func (postMgr *PostManager) updateHealthSummary(cfg *as3Config, postedAt time.Time) {
+	if postMgr.failedTenants == nil {
+		postMgr.failedTenants = make(map[string]struct{})
+	}
+	failed := false
+	for tenant, resp := range cfg.tenantResponseMap {
+		if resp.agentResponseCode == http.StatusOK {
+			delete(postMgr.failedTenants, tenant)
+		} else {
+			postMgr.failedTenants[tenant] = struct{}{}
+			failed = true
+		}
+	}
+	summary := HealthSummary{LastSyncTime: postedAt}
+	for tenant := range postMgr.cachedTenantDeclMap {
+		if _, ok := postMgr.failedTenants[tenant]; !ok {
+			summary.HealthyTenants++
+		}
+	}
+	summary.FailedTenants = len(postMgr.failedTenants)
+	summary.TotalTenants = summary.HealthyTenants + summary.FailedTenants
+
+	postMgr.healthLock.Lock()
+	postMgr.updateTenantStatus(cfg, postedAt)
+	summary.LastSuccessTime = postMgr.healthSummary.LastSuccessTime
+	if !failed {
+		summary.LastSuccessTime = postedAt
+	}
+	postMgr.healthSummary = summary
+	postMgr.healthLock.Unlock()
+
+	prometheus.ManagedTenants.WithLabelValues(cfg.targetAddress, TenantHealthy).Set(float64(summary.HealthyTenants))
+	prometheus.ManagedTenants.WithLabelValues(cfg.targetAddress, TenantFailed).Set(float64(summary.FailedTenants))
+	if !summary.LastSuccessTime.IsZero() {
+		prometheus.LastSuccessfulSync.WithLabelValues(cfg.targetAddress).Set(float64(summary.LastSuccessTime.Unix()))
+	}
+}
+
+// TenantHealthSummary returns the health of the tenants after the last post
+func (postMgr *PostManager) TenantHealthSummary() HealthSummary {
+	postMgr.healthLock.RLock()
+	defer postMgr.healthLock.RUnlock()
+	return postMgr.healthSummary
+}
+
+// updateTenantStatus records the state of the posted tenants, the tenants deleted from BIG-IP are forgotten.
+// The healthLock is acquired by the calling method.
+func (postMgr *PostManager) updateTenantStatus(cfg *as3Config, postedAt time.Time) {
+	if postMgr.tenantStatus == nil {
+		postMgr.tenantStatus = make(map[string]TenantStatus)
+	}
+	for tenant, resp := range cfg.tenantResponseMap {
+		if resp.isDeleted && resp.agentResponseCode == http.StatusOK {
+			delete(postMgr.tenantStatus, tenant)
+			continue
+		}
+		status := TenantStatus{
+			LastPostTime:     postedAt,
+			LastResponseCode: resp.agentResponseCode,
+			DeclarationHash:  postMgr.tenantStatus[tenant].DeclarationHash,
+		}
+		if decl, ok := cfg.incomingTenantDeclMap[tenant]; ok {
+			if data, err := json.Marshal(decl); err == nil {
+				status.DeclarationHash = fmt.Sprintf("%x", sha256.Sum256(data))
+			}
+		}
+		_, status.Failed = postMgr.failedTenants[tenant]
+		postMgr.tenantStatus[tenant] = status
+	}
+}
+
+// GetTenantStatus returns the last known state of the tenants posted to BIG-IP
+func (postMgr *PostManager) GetTenantStatus() map[string]TenantStatus {
+	postMgr.healthLock.RLock()
+	defer postMgr.healthLock.RUnlock()
+	tenantStatus := make(map[string]TenantStatus, len(postMgr.tenantStatus))
+	for tenant, status := range postMgr.tenantStatus {
+		tenantStatus[tenant] = status
+	}
+	return tenantStatus
+}
+
+// removeStaleTenants deletes the CIS managed tenants which have no Kubernetes resources and
+// are not seen within the staleness threshold, e.g. when resources are deleted while CIS is offline.
+// The ltmConfig of the request is shared with the controller, so the stale tenants are added to a copy of it
+func (postMgr *PostManager) removeStaleTenants(rsConfig *BigIpResourceConfig) {
+	if postMgr.StalenessThreshold == 0 {
+		return
+	}
+	now := time.Now()
+	if !postMgr.bigipTenantsFetched {
+		// tenants created before CIS restart are known only from the declaration on BIG-IP
+		currentConfig, err := postMgr.GetAS3DeclarationFromBigIP()
+		if err != nil {
+			log.Errorf("[AS3]%v Could not fetch the latest AS3 declaration from BIG-IP: %v", postMgr.postManagerPrefix, err)
+			return
+		}
+		for tenant, v := range currentConfig {
+			if decl, ok := v.(map[string]interface{}); ok {
+				if label, found := decl["label"]; found && label == postMgr.defaultPartition &&
+					tenant != postMgr.defaultPartition+"_gtm" {
+					if _, ok := postMgr.tenantLastSeen[tenant]; !ok {
+						postMgr.tenantLastSeen[tenant] = now
+					}
+				}
+			}
+		}
+		postMgr.bigipTenantsFetched = true
+	}
+	for tenant, partitionConfig := range rsConfig.ltmConfig {
+		if len(partitionConfig.ResourceMap) > 0 {
+			postMgr.tenantLastSeen[tenant] = now
+		}
+	}
+	var ltmConfig LTMConfig
+	for tenant, lastSeen := range postMgr.tenantLastSeen {
+		if _, ok := rsConfig.ltmConfig[tenant]; ok {
+			continue
+		}
+		if now.Sub(lastSeen) > postMgr.StalenessThreshold {
+			log.Infof("[AS3]%v Removing orphaned tenant %v, not seen since %v", postMgr.postManagerPrefix, tenant, lastSeen)
+			if ltmConfig == nil {
+				ltmConfig = copyLTMConfig(rsConfig.ltmConfig)
+			}
+			// adding an empty tenant to delete the tenant from BIGIP
+			priority := 1
+			ltmConfig[tenant] = &PartitionConfig{Priority: &priority}
+			delete(postMgr.tenantLastSeen, tenant)
+		}
+	}
+	if ltmConfig != nil {
+		rsConfig.ltmConfig = ltmConfig
+	}
+}
+
+// getCheckpointKey returns the checkpoint ConfigMap key of a BIG-IP, as ConfigMap keys allow only alphanumerics, '-', '_' and '.'
+func getCheckpointKey(bigIpAddress string) string {
+	address := strings.TrimPrefix(strings.TrimPrefix(bigIpAddress, "https://"), "http://")
+	return checkpointKeyRegex.ReplaceAllString(address, "_")
+}
+
+// scheduleCheckpoint queues the posted tenant declarations for the checkpoint ConfigMap. The failed tenants are
+// left out, so that they are posted again after a restart. The ConfigMap is written by writeCheckpoint
+// CheckpointWriteDelay after the first queued checkpoint, so that the posts don't wait for the Kubernetes API
+// and a burst of posts writes it once.
+func (postMgr *PostManager) scheduleCheckpoint() {
+	if postMgr.CheckpointConfigMap == "" || postMgr.kubeClient == nil {
+		return
+	}
+	// the tenant declarations are replaced and never modified by the posts, so a copy of the map is enough
+	tenantDeclMap := make(map[string]as3Tenant, len(postMgr.cachedTenantDeclMap))
+	for tenant, decl := range postMgr.cachedTenantDeclMap {
+		if _, failed := postMgr.failedTenants[tenant]; !failed {
+			tenantDeclMap[tenant] = decl
+		}
+	}
+	postMgr.checkpointLock.Lock()
+	defer postMgr.checkpointLock.Unlock()
+	postMgr.pendingCheckpoint = tenantDeclMap
+	if postMgr.checkpointTimer == nil {
+		postMgr.checkpointTimer = time.AfterFunc(CheckpointWriteDelay, postMgr.writeCheckpoint)
+	}
+}
+
+// writeCheckpoint writes the queued tenant declarations to the checkpoint ConfigMap, the writes are serialized
+// by checkpointWriteLock, so that an older checkpoint never overwrites a newer one
+func (postMgr *PostManager) writeCheckpoint() {
+	postMgr.checkpointWriteLock.Lock()
+	defer postMgr.checkpointWriteLock.Unlock()
+	postMgr.checkpointLock.Lock()
+	tenantDeclMap := postMgr.pendingCheckpoint
+	postMgr.pendingCheckpoint = nil
+	postMgr.checkpointTimer = nil
+	postMgr.checkpointLock.Unlock()
+	if tenantDeclMap == nil {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(postMgr.CheckpointConfigMap)
+	if err != nil || namespace == "" {
+		log.Errorf("[AS3]%v Invalid checkpoint ConfigMap %v, should be namespace/name", postMgr.postManagerPrefix,
+			postMgr.CheckpointConfigMap)
+		return
+	}
+	decl, err := json.Marshal(tenantDeclMap)
+	if err != nil {
+		log.Errorf("[AS3]%v Unable to marshal the declaration checkpoint: %v", postMgr.postManagerPrefix, err)
+		return
+	}
+	// the secrets are not stored in the ConfigMap, the tenants with secrets differ from their restored
+	// declaration, so they are posted once again after a restart
+	decl = []byte(redactAS3Declaration(string(decl)))
+	cmClient := postMgr.kubeClient.CoreV1().ConfigMaps(namespace)
+	// the ConfigMap is shared by the post managers of all BIG-IPs, so retry on update conflicts
+	for retries := 0; retries < 3; retries++ {
+		var cm *v1.ConfigMap
+		cm, err = cmClient.Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Data:       map[string]string{postMgr.checkpointKey: string(decl)},
+			}
+			_, err = cmClient.Create(context.TODO(), cm, metav1.CreateOptions{})
+		} else if err == nil {
+			// the checkpoint may be edited or reformatted, so skip the update only when it is semantically unchanged
+			if DeepEqualJSON(as3Declaration(cm.Data[postMgr.checkpointKey]), as3Declaration(decl)) {
+				return
+			}
+			if cm.Data == nil {
+				cm.Data = make(map[string]string)
+			}
+			cm.Data[postMgr.checkpointKey] = string(decl)
+			_, err = cmClient.Update(context.TODO(), cm, metav1.UpdateOptions{})
+		}
+		if !apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err) {
+			break
+		}
+	}
+	if err != nil {
+		log.Errorf("[AS3]%v Unable to save the declaration checkpoint to ConfigMap %v: %v", postMgr.postManagerPrefix,
+			postMgr.CheckpointConfigMap, err)
+		return
+	}
+	log.Debugf("[AS3]%v Saved the declaration checkpoint to ConfigMap %v", postMgr.postManagerPrefix,
+		postMgr.CheckpointConfigMap)
+}
+
+// flushCheckpoint writes the queued checkpoint without waiting for CheckpointWriteDelay
+func (postMgr *PostManager) flushCheckpoint() {
+	postMgr.checkpointLock.Lock()
+	if postMgr.checkpointTimer != nil {
+		postMgr.checkpointTimer.Stop()
+	}
+	postMgr.checkpointLock.Unlock()
+	postMgr.writeCheckpoint()
+}
+
+// restoreDeclarationCheckpoint populates the tenant cache from the checkpoint ConfigMap, so that unchanged
+// tenants are not posted again and the tenants on BIG-IP need not be fetched after a restart
+func (postMgr *PostManager) restoreDeclarationCheckpoint() {
+	if postMgr.CheckpointConfigMap == "" || postMgr.kubeClient == nil {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(postMgr.CheckpointConfigMap)
+	if err != nil || namespace == "" {
+		log.Errorf("[AS3]%v Invalid checkpoint ConfigMap %v, should be namespace/name", postMgr.postManagerPrefix,
+			postMgr.CheckpointConfigMap)
+		return
+	}
+	cm, err := postMgr.kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		log.Warningf("[AS3]%v Unable to read the declaration checkpoint from ConfigMap %v, starting without it: %v",
+			postMgr.postManagerPrefix, postMgr.CheckpointConfigMap, err)
+		return
+	}
+	decl, ok := cm.Data[postMgr.checkpointKey]
+	if !ok {
+		log.Infof("[AS3]%v No declaration checkpoint found in ConfigMap %v", postMgr.postManagerPrefix,
+			postMgr.CheckpointConfigMap)
+		return
+	}
+	tenantDeclMap := make(map[string]as3Tenant)
+	if err = json.Unmarshal([]byte(decl), &tenantDeclMap); err != nil {
+		log.Errorf("[AS3]%v Invalid declaration checkpoint in ConfigMap %v: %v", postMgr.postManagerPrefix,
+			postMgr.CheckpointConfigMap, err)
+		return
+	}
+	now := time.Now()
+	for tenant, tenantDecl := range tenantDeclMap {
+		postMgr.cachedTenantDeclMap[tenant] = tenantDecl
+		postMgr.tenantLastSeen[tenant] = now
+	}
+	postMgr.bigipTenantsFetched = true
+	log.Infof("[AS3]%v Restored %v tenants from the declaration checkpoint", postMgr.postManagerPrefix, len(tenantDeclMap))
+}
+
+// writeAuditEvents appends an audit event for every tenant of the posted declaration to the audit log
+func (postMgr *PostManager) writeAuditEvents(cfg *as3Config, postedAt time.Time) {
+	if !postMgr.AuditLogEnabled || len(cfg.tenantResponseMap) == 0 {
+		return
+	}
+	file, err := os.OpenFile(postMgr.AuditLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Errorf("[AS3]%v Unable to open audit log %v: %v", postMgr.postManagerPrefix, postMgr.AuditLogPath, err)
+		return
+	}
+	defer file.Close()
+	var tenants []string
+	for tenant := range cfg.tenantResponseMap {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	encoder := json.NewEncoder(file)
+	for _, tenant := range tenants {
+		response := cfg.tenantResponseMap[tenant]
+		verb := "post"
+		if response.isDeleted {
+			verb = "delete"
+		}
+		event := auditEvent{
+			Kind:                     "Event",
+			APIVersion:               "audit.k8s.io/v1",
+			Level:                    "Metadata",
+			AuditID:                  uuid.New().String(),
+			Stage:                    "ResponseComplete",
+			RequestURI:               cfg.as3APIURL,
+			Verb:                     verb,
+			User:                     auditUserInfo{Username: postMgr.auditUser},
+			ObjectRef:                auditObjectRef{Resource: "tenants", Name: "/" + tenant, APIGroup: "as3"},
+			RequestReceivedTimestamp: metav1.NewMicroTime(postedAt),
+			StageTimestamp:           metav1.NewMicroTime(time.Now()),
+		}
+		if response.agentResponseCode != 0 {
+			event.ResponseStatus = &auditStatus{Code: response.agentResponseCode}
+		}
+		if decl, ok := cfg.incomingTenantDeclMap[tenant]; ok {
+			if data, err := json.Marshal(decl); err == nil {
+				event.Annotations = map[string]string{
+					"cis.f5.com/request-object-hash": fmt.Sprintf("sha256:%x", sha256.Sum256(data)),
+				}
+			}
+		}
+		if err = encoder.Encode(event); err != nil {
+			log.Errorf("[AS3]%v Unable to write audit event for tenant %v: %v", postMgr.postManagerPrefix, tenant, err)
+			return
+		}
+	}
+}
+
 func (postMgr *PostManager) pollTenantStatus(cfg *as3Config) {
 	// Keep retrying until accepted tenant statuses are updated
 	// This prevents agent from unlocking and thus any incoming post requests (config changes) also need to hold on
+	deadline := time.Now().Add(postMgr.AsyncTimeout)
+	taskId := cfg.acceptedTaskId
+	polls := 0
 	for cfg.acceptedTaskId != "" {
-		if !postMgr.AS3Config.DocumentAPI {
+		if postMgr.AsyncMode {
+			if time.Now().After(deadline) {
+				log.Errorf("%v[AS3]%v task %v did not complete in %v after %v polls", getRequestPrefix(cfg.id),
+					postMgr.postManagerPrefix, cfg.acceptedTaskId, postMgr.AsyncTimeout, polls)
+				cfg.acceptedTaskId = ""
+				cfg.tenantResponseMap = make(map[string]tenantResponse)
+				// the tenants of the task are retried as if BIG-IP was busy
+				postMgr.failPendingTenants(cfg, http.StatusServiceUnavailable)
+				postMgr.updateTenantCache(cfg)
+				return
+			}
+			<-time.After(postMgr.asyncPollInterval(polls))
+		} else if !postMgr.AS3Config.DocumentAPI {
 			<-time.After(timeoutMedium)
 		} else {
 			<-time.After(timeoutSmall)
 		}
+		polls++
+		if postMgr.AsyncMode {
+			prometheus.AsyncTaskPolls.WithLabelValues(cfg.targetAddress).Inc()
+		}
 		cfg.tenantResponseMap = make(map[string]tenantResponse)
 		postMgr.getTenantConfigStatus(cfg.acceptedTaskId, cfg)
 		postMgr.updateTenantCache(cfg)
 	}
+	if postMgr.AsyncMode && polls > 0 {
+		log.Debugf("%v[AS3]%v task %v completed after %v polls", getRequestPrefix(cfg.id),
+			postMgr.postManagerPrefix, taskId, polls)
+	}
+}
+
+// asyncPollInterval returns the interval before the next poll of an asynchronous task, which starts at
+// AsyncPollInterval and doubles with every poll of the task up to AsyncPollMaxInterval
+func (postMgr *PostManager) asyncPollInterval(polls int) time.Duration {
+	interval := postMgr.AsyncPollInterval
+	for i := 0; i < polls && interval < postMgr.AsyncPollMaxInterval; i++ {
+		interval *= 2
+	}
+	if postMgr.AsyncPollMaxInterval > 0 && interval > postMgr.AsyncPollMaxInterval {
+		return postMgr.AsyncPollMaxInterval
+	}
+	return interval
+}
+
+// nextRetryDelay returns the delay before the failed tenants are posted again. The delay grows exponentially
+// with the consecutive failed posts and half of it is random, so that the CIS instances of a busy or restarting
+// BIG-IP don't retry at the same time. Without a backoff the flat medium timeout is used.
+func (postMgr *PostManager) nextRetryDelay() time.Duration {
+	if postMgr.RetryBackoff.BaseDelay <= 0 {
+		return timeoutMedium
+	}
+	delay := postMgr.RetryBackoff.delay(postMgr.retryAttempts)
+	postMgr.retryAttempts++
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// scheduleRetry posts the failed tenants of the config again after the delay. The delay runs on a timer, so
+// that neither the response handler nor the postManager waits for it, and replaces the pending retry.
+func (postMgr *PostManager) scheduleRetry(config agentConfig, delay time.Duration) {
+	postMgr.retryLock.Lock()
+	defer postMgr.retryLock.Unlock()
+	if postMgr.retryTimer != nil {
+		postMgr.retryTimer.Stop()
+	}
+	postMgr.pendingRetry = &config
+	postMgr.retryTimer = time.AfterFunc(delay, func() {
+		select {
+		case postMgr.retryReady <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// takeRetry returns the pending retry once its delay expired
+func (postMgr *PostManager) takeRetry() (agentConfig, bool) {
+	postMgr.retryLock.Lock()
+	defer postMgr.retryLock.Unlock()
+	if postMgr.pendingRetry == nil {
+		return agentConfig{}, false
+	}
+	config := *postMgr.pendingRetry
+	postMgr.pendingRetry = nil
+	postMgr.retryTimer = nil
+	return config, true
+}
+
+// cancelRetry drops the pending retry
+func (postMgr *PostManager) cancelRetry() {
+	postMgr.retryLock.Lock()
+	defer postMgr.retryLock.Unlock()
+	if postMgr.retryTimer != nil {
+		postMgr.retryTimer.Stop()
+		postMgr.retryTimer = nil
+	}
+	postMgr.pendingRetry = nil
+}
+
+// checkCircuitBreaker returns the error of a post rejected by the open circuit breaker, the first post after the
+// cool-down probes BIG-IP and another 503 response opens the circuit breaker again
+func (postMgr *PostManager) checkCircuitBreaker() *AS3Error {
+	if postMgr.CircuitBreakerThreshold <= 0 || postMgr.unavailableResponses < postMgr.CircuitBreakerThreshold {
+		return nil
+	}
+	remaining := time.Until(postMgr.circuitOpenUntil)
+	if remaining <= 0 {
+		log.Infof("[AS3]%v Circuit breaker cool-down expired, probing BIG-IP", postMgr.postManagerPrefix)
+		return nil
+	}
+	return &AS3Error{
+		Code: ErrCircuitOpen,
+		Message: fmt.Sprintf("BIG-IP responded with %v consecutive %v responses, post rejected",
+			postMgr.unavailableResponses, http.StatusServiceUnavailable),
+		RetryAfter: remaining,
+	}
+}
+
+// updateCircuitBreaker counts the consecutive 503 responses of BIG-IP and opens the circuit breaker for the
+// cool-down once they reach the threshold, any other response closes it
+func (postMgr *PostManager) updateCircuitBreaker(httpCode int) {
+	if postMgr.CircuitBreakerThreshold <= 0 {
+		return
+	}
+	if httpCode != http.StatusServiceUnavailable {
+		if postMgr.unavailableResponses >= postMgr.CircuitBreakerThreshold {
+			log.Infof("[AS3]%v BIG-IP is available, circuit breaker closed", postMgr.postManagerPrefix)
+		}
+		postMgr.unavailableResponses = 0
+		return
+	}
+	postMgr.unavailableResponses++
+	if postMgr.unavailableResponses < postMgr.CircuitBreakerThreshold {
+		return
+	}
+	postMgr.circuitOpenUntil = time.Now().Add(postMgr.CircuitBreakerCooldown)
+	log.Warningf("[AS3]%v BIG-IP responded with %v consecutive %v responses, circuit breaker opened for %v",
+		postMgr.postManagerPrefix, postMgr.unavailableResponses, http.StatusServiceUnavailable,
+		postMgr.CircuitBreakerCooldown)
+}
+
+// delay returns the backoff delay of the given retry attempt, the flat medium timeout when the backoff is not set
+func (backoff RetryBackoff) delay(attempt int) time.Duration {
+	if backoff.BaseDelay <= 0 {
+		return timeoutMedium
+	}
+	delay := float64(backoff.BaseDelay) * math.Pow(math.Max(backoff.Multiplier, 1), float64(attempt))
+	if backoff.MaxDelay > 0 && delay > float64(backoff.MaxDelay) {
+		return backoff.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// failPendingTenants sets the response code of the posted tenants whose task did not succeed,
+// so that they are posted again with the failed tenants
+func (postMgr *PostManager) failPendingTenants(cfg *as3Config, code int) {
+	for tenant := range cfg.incomingTenantDeclMap {
+		postMgr.updateTenantResponseCode(code, cfg, tenant, false)
+	}
 }
 
 // function for returning the prefix string for request id
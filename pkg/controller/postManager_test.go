@@ -1,11 +1,24 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/prometheus"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 var _ = Describe("AS3PostManager Tests", func() {
@@ -43,6 +56,97 @@ var _ = Describe("AS3PostManager Tests", func() {
 			Expect(as3Cfg.tenantResponseMap[tnt].agentResponseCode).To(BeEquivalentTo(http.StatusOK), "Posting Failed")
 		})
 
+		It("Handle Dry Run", func() {
+			mockPM.DryRun = true
+			mockPM.httpClient = nil
+			as3Cfg.incomingTenantDeclMap = map[string]as3Tenant{
+				"test":    {"class": "Tenant", "Shared": map[string]interface{}{"class": "application"}},
+				"deleted": getDeletedTenantDeclaration("cis"),
+			}
+			// the schema isn't shipped with the tests, so the declaration isn't validated
+			skipped := &dto.Metric{}
+			counter := bigIPPrometheus.AS3ValidationSkipped.WithLabelValues(as3Cfg.targetAddress)
+			Expect(counter.Write(skipped)).To(Succeed())
+			skippedCount := skipped.GetCounter().GetValue()
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.tenantResponseMap["test"]).To(Equal(tenantResponse{http.StatusOK, false}))
+			Expect(as3Cfg.tenantResponseMap["deleted"]).To(Equal(tenantResponse{http.StatusOK, true}))
+			Expect(mockPM.AS3PostManager.firstPost).To(BeFalse())
+			Expect(counter.Write(skipped)).To(Succeed())
+			Expect(skipped.GetCounter().GetValue()).To(Equal(skippedCount+1), "Skipped validation not counted")
+
+			as3Cfg.data = `{"declaration": `
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.tenantResponseMap["test"].agentResponseCode).To(Equal(http.StatusUnprocessableEntity),
+				"Invalid declaration should fail the tenants")
+
+			// the classes of the declarations are checked against the AS3 schema
+			schema, err := loadAS3Schema(filepath.Join("..", "..", "schemas", getAS3SchemaFile()))
+			Expect(err).To(BeNil(), "AS3 schema not loaded")
+			mockPM.as3Schema = schema
+			pool := map[string]interface{}{"class": "Pool", "members": []interface{}{
+				map[string]interface{}{"servicePort": 80, "serverAddresses": []interface{}{"10.1.1.1"}}}}
+			as3Cfg.incomingTenantDeclMap = map[string]as3Tenant{
+				"test":  {"class": "Tenant", "app": as3Application{"class": "Application", "pool": pool}},
+				"other": {"class": "Tenant", "app": as3Application{"class": "Application", "pool": pool}},
+			}
+			as3Cfg.data = string(mockPM.AS3PostManager.createAS3Declaration(as3Cfg.incomingTenantDeclMap, ""))
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.tenantResponseMap["test"].agentResponseCode).To(Equal(http.StatusOK))
+			Expect(as3Cfg.tenantResponseMap["other"].agentResponseCode).To(Equal(http.StatusOK))
+
+			as3Cfg.incomingTenantDeclMap["test"] = as3Tenant{"class": "Tenant", "app": as3Application{
+				"class": "Application", "pool": map[string]interface{}{"class": "Pools"}}}
+			as3Cfg.data = string(mockPM.AS3PostManager.createAS3Declaration(as3Cfg.incomingTenantDeclMap, ""))
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.tenantResponseMap["test"].agentResponseCode).To(Equal(http.StatusUnprocessableEntity),
+				"Tenant violating the AS3 schema should fail")
+			Expect(as3Cfg.tenantResponseMap["other"].agentResponseCode).To(Equal(http.StatusOK),
+				"Valid tenant should not fail with the invalid one")
+		})
+
+		It("Check the AS3 schema", func() {
+			dir, err := os.MkdirTemp("", "cis-schema")
+			Expect(err).To(BeNil())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, getAS3SchemaFile())
+			_, err = loadAS3Schema(path)
+			Expect(err).To(MatchError(ContainSubstring("unable to read the AS3 schema")), "Missing schema not detected")
+
+			Expect(os.WriteFile(path, []byte(`{"$schema": `), 0644)).To(Succeed())
+			_, err = loadAS3Schema(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid AS3 schema")), "Malformed schema not detected")
+
+			Expect(os.WriteFile(path, []byte(`{"$schema": "http://json-schema.org/draft-04/schema#"}`), 0644)).To(Succeed())
+			_, err = loadAS3Schema(path)
+			Expect(err).To(MatchError(ContainSubstring("$schema should be "+as3SchemaURI)), "Schema draft not checked")
+
+			schema, err := loadAS3Schema(filepath.Join("..", "..", "schemas", getAS3SchemaFile()))
+			Expect(err).To(BeNil(), "AS3 schema shipped with CIS not loaded")
+			Expect(schema.classes).To(HaveKey("Service_HTTP"))
+		})
+
+		It("Handle Oversized Declaration", func() {
+			mockPM.MaxDeclarationSize = 10 * 1024 * 1024
+			mockPM.httpClient = nil
+			as3Cfg.incomingTenantDeclMap = map[string]as3Tenant{
+				"large": {"class": "Tenant", "app": as3Application{
+					"class": "Application", "remark": strings.Repeat("a", 11*1024*1024)}},
+				"small": {"class": "Tenant"},
+			}
+			as3Cfg.tenantResponseMap = map[string]tenantResponse{"large": {}, "small": {}}
+			as3Cfg.data = string(mockPM.AS3PostManager.createAS3Declaration(as3Cfg.incomingTenantDeclMap, ""))
+			as3Err := mockPM.checkDeclarationSize(&as3Cfg)
+			Expect(as3Err).NotTo(BeNil(), "Oversized declaration not detected")
+			Expect(as3Err.Code).To(Equal(ErrDeclarationTooLarge))
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.tenantResponseMap["large"].agentResponseCode).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(as3Cfg.tenantResponseMap["small"].agentResponseCode).To(Equal(http.StatusRequestEntityTooLarge))
+
+			mockPM.MaxDeclarationSize = 0
+			Expect(mockPM.checkDeclarationSize(&as3Cfg)).To(BeNil(), "Declaration size limit not disabled")
+		})
+
 		It("Handle HTTP StatusOK", func() {
 			tnt := "test"
 			mockPM.setResponses([]responceCtx{{
@@ -54,6 +158,46 @@ var _ = Describe("AS3PostManager Tests", func() {
 			Expect(as3Cfg.tenantResponseMap[tnt].agentResponseCode).To(BeEquivalentTo(http.StatusOK), "Posting Failed")
 		})
 
+		It("Trace the declaration post", func() {
+			var exported map[string]interface{}
+			collector := ghttp.NewServer()
+			defer collector.Close()
+			collector.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodPost, OTelTracesPath),
+				func(w http.ResponseWriter, r *http.Request) {
+					_ = json.NewDecoder(r.Body).Decode(&exported)
+				},
+			))
+			tracer := NewTracer(collector.URL(), "cis-test")
+			mockPM.tracer = tracer
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body:   "",
+			}}, http.MethodPost)
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.span).NotTo(BeNil(), "Declaration post not traced")
+
+			req, _ := http.NewRequest(http.MethodGet, "https://bigip", nil)
+			as3Cfg.span.injectTraceContext(req)
+			Expect(req.Header.Get(TraceparentHeader)).To(MatchRegexp("^00-%s-%s-01$", as3Cfg.span.traceID,
+				as3Cfg.span.spanID))
+
+			tracer.flush()
+			Expect(collector.ReceivedRequests()).To(HaveLen(1), "Spans not exported")
+			resourceSpans := exported["resourceSpans"].([]interface{})[0].(map[string]interface{})
+			spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+			Expect(spans).To(HaveLen(1))
+			post := spans[0].(map[string]interface{})
+			Expect(post["name"]).To(Equal("postAS3Config"))
+			Expect(post["traceId"]).To(Equal(as3Cfg.span.traceID))
+			Expect(post).NotTo(HaveKey("parentSpanId"))
+
+			// nothing is traced without a tracer
+			var noTracer *Tracer
+			Expect(noTracer.startSpan("postAS3Config")).To(BeNil())
+		})
+
 		It("Handle HTTP Status Accepted", func() {
 			tnt := "test"
 			mockPM.setResponses([]responceCtx{
@@ -125,6 +269,149 @@ var _ = Describe("AS3PostManager Tests", func() {
 			Expect(as3Cfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusAlreadyReported))
 		})
 
+		It("Handle HTTP Status Unprocessable Entity", func() {
+			as3Cfg.data = `{"declaration": {"class": "ADC", "invalid": {"class": "Tenant"}, "test": {"class": "Tenant"}}}`
+			as3Cfg.tenantResponseMap["invalid"] = tenantResponse{}
+			as3Cfg.tenantResponseMap["test"] = tenantResponse{}
+			mockPM.setResponses([]responceCtx{
+				{
+					tenant: "invalid",
+					status: http.StatusUnprocessableEntity,
+					body: fmt.Sprintf(`{"code":%d,"message":"declaration is invalid","errors":["/invalid/app/vs/virtualPort: should be <= 65535"]}`,
+						http.StatusUnprocessableEntity),
+				},
+				{
+					tenant: "test",
+					status: http.StatusOK,
+					body:   "",
+				},
+			}, http.MethodPost)
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.tenantResponseMap["invalid"].agentResponseCode).To(Equal(http.StatusUnprocessableEntity))
+			Expect(as3Cfg.tenantResponseMap["test"].agentResponseCode).To(Equal(http.StatusOK),
+				"Valid tenant should be posted without the invalid tenant")
+			Expect(as3Cfg.data).NotTo(ContainSubstring("invalid"))
+
+			tenant, message := parseAS3ErrorResponse(`{"code":422,"message":"declaration is invalid","errors":["/declaration/tenant1/app: should have required property 'class'"]}`)
+			Expect(tenant).To(Equal("tenant1"))
+			Expect(message).To(Equal("/declaration/tenant1/app: should have required property 'class'"))
+			tenant, message = parseAS3ErrorResponse(`{"code":422,"message":"declaration is invalid"}`)
+			Expect(tenant).To(BeEmpty())
+			Expect(message).To(Equal("declaration is invalid"))
+		})
+
+		It("Classify AS3 Errors", func() {
+			Expect(classifyAS3Error(http.StatusRequestEntityTooLarge, "")).To(Equal(ErrDeclarationTooLarge))
+			Expect(classifyAS3Error(http.StatusServiceUnavailable, "")).To(Equal(ErrServiceUnavailable))
+			Expect(classifyAS3Error(http.StatusGatewayTimeout, "")).To(Equal(ErrNetworkTimeout))
+			Expect(classifyAS3Error(http.StatusUnprocessableEntity, "declaration is invalid")).To(Equal(ErrUnprocessableEntity))
+			Expect(classifyAS3Error(http.StatusUnprocessableEntity, "/schemaVersion: should be equal to one of the allowed values")).
+				To(Equal(ErrSchemaMismatch))
+			Expect(classifyAS3Error(http.StatusInternalServerError, "")).To(Equal(ErrUnknownResponse))
+			Expect(classifyRequestError(fmt.Errorf("connection refused")).Code).To(Equal(ErrConnectionFailed))
+
+			as3Err := &AS3Error{Code: ErrServiceUnavailable, Tenant: "test", Message: "BIG-IP is busy", RetryAfter: timeoutMedium}
+			Expect(as3Err.Error()).To(Equal("ServiceUnavailable (tenant test): BIG-IP is busy, retrying after 30s"))
+
+			// the response, which is not an AS3 response, is classified by its status code
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusRequestEntityTooLarge,
+				body:   "<html>Request Entity Too Large</html>",
+			}}, http.MethodPost)
+			req, _ := http.NewRequest(http.MethodPost, as3Cfg.as3APIURL, strings.NewReader(as3Cfg.data))
+			_, responseMap, as3Err := mockPM.doAS3Request(req)
+			Expect(responseMap).To(BeNil())
+			Expect(as3Err.Code).To(Equal(ErrDeclarationTooLarge))
+		})
+
+		It("Back off the retries of failed tenants", func() {
+			// the flat medium timeout is used without a backoff
+			Expect(RetryBackoff{}.delay(3)).To(Equal(timeoutMedium))
+			Expect(mockPM.nextRetryDelay()).To(Equal(timeoutMedium), "Retry delay without a backoff is not flat")
+			Expect(mockPM.retryAttempts).To(BeZero())
+
+			mockPM.RetryBackoff = RetryBackoff{BaseDelay: 2 * time.Second, Multiplier: 2, MaxDelay: 10 * time.Second}
+			for attempt, delay := range []time.Duration{2, 4, 8, 10, 10, 10} {
+				delay *= time.Second
+				Expect(mockPM.RetryBackoff.delay(attempt)).To(Equal(delay))
+				retryDelay := mockPM.nextRetryDelay()
+				Expect(retryDelay).To(BeNumerically(">=", delay/2), "Retry delay should include the jitter range")
+				Expect(retryDelay).To(BeNumerically("<=", delay), "Retry delay should not exceed the backoff")
+			}
+			Expect(mockPM.retryAttempts).To(Equal(6))
+			Expect(mockPM.RetryBackoff.delay(1000)).To(Equal(10*time.Second), "Retry delay should not exceed the cap")
+
+			// a busy BIG-IP is retried with the backoff
+			mockPM.retryAttempts = 0
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusServiceUnavailable,
+				body:   fmt.Sprintf(`{"error": {"code":%d}}`, http.StatusServiceUnavailable),
+			}}, http.MethodPost)
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.retryDelay).To(BeNumerically(">=", time.Second))
+			Expect(as3Cfg.retryDelay).To(BeNumerically("<=", 2*time.Second))
+			Expect(mockPM.retryAttempts).To(Equal(1))
+		})
+
+		It("Schedule the retry of failed tenants", func() {
+			mockPM.retryReady = make(chan struct{}, 1)
+			mockPM.scheduleRetry(agentConfig{id: 1}, time.Hour)
+			mockPM.scheduleRetry(agentConfig{id: 2}, time.Millisecond)
+			Eventually(mockPM.retryReady).Should(Receive(), "Retry not signalled after its delay")
+			config, ok := mockPM.takeRetry()
+			Expect(ok).To(BeTrue())
+			Expect(config.id).To(Equal(2), "Pending retry should be replaced by the latest one")
+			_, ok = mockPM.takeRetry()
+			Expect(ok).To(BeFalse(), "Retry taken twice")
+
+			// a new request drops the pending retry
+			mockPM.scheduleRetry(agentConfig{id: 3}, time.Hour)
+			mockPM.cancelRetry()
+			_, ok = mockPM.takeRetry()
+			Expect(ok).To(BeFalse(), "Cancelled retry taken")
+		})
+
+		It("Handle Circuit Breaker", func() {
+			tnt := "test"
+			mockPM.CircuitBreakerThreshold = 2
+			mockPM.CircuitBreakerCooldown = time.Hour
+			as3Cfg.incomingTenantDeclMap = map[string]as3Tenant{tnt: {"class": "Tenant"}}
+			unavailable := responceCtx{
+				tenant: tnt,
+				status: http.StatusServiceUnavailable,
+				body:   fmt.Sprintf(`{"error": {"code":%d}}`, http.StatusServiceUnavailable),
+			}
+			for i := 0; i < 2; i++ {
+				Expect(mockPM.checkCircuitBreaker()).To(BeNil(), "Circuit breaker opened before the threshold")
+				mockPM.setResponses([]responceCtx{unavailable}, http.MethodPost)
+				mockPM.publishConfig(&as3Cfg)
+			}
+			Expect(mockPM.circuitOpenUntil).To(BeTemporally(">", time.Now().Add(59*time.Minute)), "Circuit breaker not opened")
+			Expect(as3Cfg.retryDelay).To(BeNumerically("<", time.Hour), "Retry should keep the backoff delay")
+
+			// the open circuit breaker rejects the post without sending it to BIG-IP
+			mockPM.httpClient = nil
+			as3Cfg.retryDelay = 0
+			as3Cfg.tenantResponseMap[tnt] = tenantResponse{}
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(as3Cfg.retryDelay).To(BeZero(), "Rejected post should be retried with the backoff delay")
+
+			// the post after the cool-down probes BIG-IP and closes the circuit breaker
+			mockPM.circuitOpenUntil = time.Now().Add(-time.Second)
+			mockPM.setResponses([]responceCtx{{
+				tenant: tnt,
+				status: http.StatusOK,
+				body:   "",
+			}}, http.MethodPost)
+			mockPM.publishConfig(&as3Cfg)
+			Expect(as3Cfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusOK))
+			Expect(mockPM.unavailableResponses).To(BeZero(), "Circuit breaker not closed")
+			Expect(mockPM.checkCircuitBreaker()).To(BeNil())
+		})
+
 		It("Handle Multiple HTTP Responses", func() {
 			tnt := "test"
 			mockPM.setResponses([]responceCtx{{
@@ -171,6 +458,62 @@ var _ = Describe("AS3PostManager Tests", func() {
 			Expect(len(as3Cfg.tenantResponseMap)).To(Equal(1), "Posting Failed")
 			Expect(as3Cfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusUnprocessableEntity))
 		})
+
+		It("Poll the task of an asynchronous declaration", func() {
+			tnt := "test"
+			mockPM.AsyncMode = true
+			mockPM.AsyncPollInterval = time.Millisecond
+			mockPM.AsyncTimeout = time.Minute
+			Expect(mockPM.getAS3APIURL("10.1.1.1")).To(HaveSuffix("?target_address=10.1.1.1&async=true"))
+			mockPM.setResponses([]responceCtx{
+				{
+					tenant: tnt,
+					status: http.StatusOK,
+					body:   `{"id":"100","status":"RUNNING","results":[]}`,
+				},
+				{
+					tenant: tnt,
+					status: http.StatusOK,
+					body: fmt.Sprintf(`{"id":"100","status":"SUCCEEDED","results":[{"code":%d,"message":"success", "tenant": "%s"}],"declaration": {"%s": {"Shared": {"class": "application"}}}}`,
+						http.StatusOK, tnt, tnt),
+				},
+				{
+					tenant: tnt,
+					status: http.StatusOK,
+					body:   `{"id":"101","status":"FAILED","message":"declaration failed"}`,
+				},
+			}, http.MethodGet)
+			as3Cfg := as3Config{
+				id:                    1,
+				acceptedTaskId:        "100",
+				tenantResponseMap:     make(map[string]tenantResponse),
+				incomingTenantDeclMap: map[string]as3Tenant{tnt: {"class": "Tenant"}},
+			}
+			mockPM.pollTenantStatus(&as3Cfg)
+			Expect(as3Cfg.acceptedTaskId).To(BeEmpty())
+			Expect(as3Cfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusOK))
+			Expect(mockPM.cachedTenantDeclMap).To(HaveKey(tnt))
+
+			as3Cfg.acceptedTaskId = "101"
+			mockPM.pollTenantStatus(&as3Cfg)
+			Expect(as3Cfg.acceptedTaskId).To(BeEmpty())
+			Expect(as3Cfg.failedTenants).To(HaveKey(tnt), "Tenant of the failed task should be retried")
+
+			// the task is given up after the timeout and retried as if BIG-IP was busy
+			mockPM.AsyncTimeout = -time.Second
+			as3Cfg.acceptedTaskId = "102"
+			mockPM.pollTenantStatus(&as3Cfg)
+			Expect(as3Cfg.acceptedTaskId).To(BeEmpty())
+			Expect(as3Cfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusServiceUnavailable))
+
+			// the poll interval doubles with every poll of a task up to the max interval
+			mockPM.AsyncPollInterval = time.Second
+			mockPM.AsyncPollMaxInterval = 30 * time.Second
+			for polls, interval := range []time.Duration{1, 2, 4, 8, 16, 30, 30} {
+				Expect(mockPM.asyncPollInterval(polls)).To(Equal(interval * time.Second))
+			}
+			Expect(mockPM.asyncPollInterval(100)).To(Equal(30 * time.Second))
+		})
 	})
 
 	Describe("BIGIP AS3 Version", func() {
@@ -262,6 +605,16 @@ var _ = Describe("AS3PostManager Tests", func() {
 			as3config := "{\"$schema\":\"https://raw.githubusercontent.com/F5Networks/f5-appsvcs-extension/master/schema/3.38.0/as3-schema-3.38.0-4.json\",\"class\":\"AS3\",\"declaration\":{\"class\":\"ADC\",\"controls\":{\"class\":\"Controls\",\"userAgent\":\"\"},\"id\":\"urn:uuid:85626792-9ee7-46bb-8fc8-4ba708cfdc1d\",\"k8s\":{\"Shared\":{\"Openshift_insecure_routes\":{\"class\":\"Endpoint_Policy\",\"rules\":[{\"name\":\"url_rewrite_rule1\",\"conditions\":[{\"type\":\"httpHeader\",\"name\":\"host\",\"event\":\"request\",\"all\":{\"values\":[\"foo.com:443\",\"foo.com\"],\"operand\":\"equals\"}},{\"name\":\"0\",\"event\":\"request\",\"pathSegment\":{\"values\":[\"foo.com\"],\"operand\":\"equals\"}},{\"name\":\"0\",\"event\":\"request\",\"path\":{\"values\":[\"foo.com\"],\"operand\":\"equals\"}},{\"type\":\"tcp\",\"event\":\"request\",\"address\":{\"values\":[\"foo.com\"]}}],\"actions\":[{\"type\":\"httpHeader\",\"event\":\"request\",\"replace\":{\"value\":\"newhost.com\",\"name\":\"host\"}}]}]},\"Openshift_secure_routes\":{\"class\":\"Endpoint_Policy\",\"rules\":[{\"name\":\"url_rewrite_rule1\",\"conditions\":[{\"type\":\"httpHeader\",\"name\":\"host\",\"event\":\"request\",\"all\":{\"values\":[\"foo.com:443\",\"foo.com\"],\"operand\":\"equals\"}},{\"name\":\"0\",\"event\":\"request\",\"pathSegment\":{\"values\":[\"foo.com\"],\"operand\":\"equals\"}},{\"name\":\"0\",\"event\":\"request\",\"path\":{\"values\":[\"foo.com\"],\"operand\":\"equals\"}},{\"type\":\"tcp\",\"event\":\"request\",\"address\":{\"values\":[\"foo.com\"]}}],\"actions\":[{\"type\":\"httpHeader\",\"event\":\"request\",\"replace\":{\"value\":\"newhost.com\",\"name\":\"host\"}}]}]},\"class\":\"Application\",\"serverssl_ca_bundle\":{\"class\":\"CA_Bundle\",\"bundle\":\"\\ncert\"},\"template\":\"shared\",\"test_clientssl\":{\"class\":\"Certificate\",\"certificate\":\"cert\",\"privateKey\":\"key\",\"chainCA\":\"ca-file\"},\"test_datagroup\":{\"records\":[{\"key\":\"test_record\",\"value\":\"/Common/serverssl\"}],\"keyDataType\":\"string\",\"class\":\"Data_Group\"},\"test_irule\":{\"class\":\"iRule\",\"iRule\":\"Dummy Code\"},\"test_monitor\":{\"class\":\"Monitor\",\"interval\":10,\"monitorType\":\"tcp\",\"targetAddress\":\"\",\"timeUntilUp\":0,\"dscp\":0,\"receive\":\"none\",\"send\":\"GET /\",\"targetPort\":0},\"test_pool\":{\"class\":\"Pool\",\"members\":[{\"addressDiscovery\":\"static\",\"serverAddresses\":[\"192.168.1.1\"],\"servicePort\":80,\"shareNodes\":true}],\"monitors\":[{\"use\":\"/k8s/Shared/test_monitor\"}]},\"test_virtual_secure\":{\"source\":\"0.0.0.0/0\",\"translateServerAddress\":true,\"translateServerPort\":true,\"class\":\"Service_HTTPS\",\"virtualAddresses\":[\"1.2.3.4\"],\"virtualPort\":443,\"snat\":\"auto\",\"clientTLS\":{\"bigip\":\"/Common/serverssl\"},\"serverTLS\":[{\"bigip\":\"/Common/clientssl\"}],\"redirect80\":false,\"pool\":\"/k8s/Shared/test_pool\"},\"test_virtual_secure_tls_client\":{\"class\":\"TLS_Client\",\"trustCA\":{\"use\":\"serverssl_ca_bundle\"}},\"test_virtual_secure_tls_server\":{\"class\":\"TLS_Server\",\"certificates\":[{\"certificate\":\"test_clientssl\"}],\"renegotiationEnabled\":false}},\"class\":\"Tenant\",\"defaultRouteDomain\":0},\"label\":\"CIS Declaration\",\"remark\":\"Auto-generated by CIS\",\"schemaVersion\":\"3.38.0\"}}"
 			mockPM.logAS3Request(as3config)
 		})
+		It("Redact the secrets of the declaration", func() {
+			decl := `{"test":{"class":"Tenant","app":{"class":"Application",` +
+				`"ldap":{"class":"Monitor","monitorType":"ldap","passphrase":{"ciphertext":"c2VjcmV0","protected":"eyJhbGciOiJkaXIiLCJlbmMiOiJub25lIn0="}},` +
+				`"crt":{"class":"Certificate","certificate":"cert","privateKey":"key"}}}}`
+			redacted := redactAS3Declaration(decl)
+			Expect(redacted).NotTo(ContainSubstring("c2VjcmV0"), "Monitor passphrase should be redacted")
+			Expect(redacted).NotTo(ContainSubstring(`"key"`), "Private key should be redacted")
+			Expect(redacted).To(ContainSubstring(`"protected":"eyJhbGciOiJkaXIiLCJlbmMiOiJub25lIn0="`))
+			Expect(redactAS3Declaration("invalid")).To(Equal("invalid"))
+		})
 	})
 
 	Describe("Get BIGIP AS3 Declaration", func() {
@@ -295,5 +648,672 @@ var _ = Describe("AS3PostManager Tests", func() {
 			Expect(err).NotTo(BeNil(), "Failed to fetch declaration")
 			Expect(dec).To(BeEmpty(), "Fetched invalid declaration")
 		})
+
+		It("Check licensed features", func() {
+			mockPM.licenseCheckedAt = time.Time{}
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body: `{"entries": {"https://localhost/mgmt/tm/sys/license/0": {"nestedStats": {"entries": {
+					"https://localhost/mgmt/tm/sys/license/0/activeModules": {"nestedStats": {"entries": {
+					"https://localhost/mgmt/tm/sys/license/0/activeModules/0": {"nestedStats": {"entries": {
+					"key": {"description": "Local Traffic Manager, VE"}}}},
+					"https://localhost/mgmt/tm/sys/license/0/activeModules/1": {"nestedStats": {"entries": {
+					"key": {"description": "Advanced Firewall Manager, VE"}}}}}}}}}}}}`,
+			}}, http.MethodGet)
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Firewall = "/Common/afm-policy"
+			rsCfg.Virtual.AnalyticsProfiles.HTTPAnalyticsProfile = "/Common/http-analytics"
+			rsConfig := BigIpResourceConfig{ltmConfig: LTMConfig{"test": &PartitionConfig{
+				ResourceMap: ResourceMap{"vs": rsCfg}}}}
+			unlicensed := mockPM.checkLicensedFeatures(&rsConfig)
+			Expect(mockPM.licensedModules).To(ConsistOf("Local Traffic Manager, VE", "Advanced Firewall Manager, VE"))
+			Expect(unlicensed).To(Equal([]string{LicenseFeatureAnalytics}), "Unlicensed analytics not reported")
+			Expect(mockPM.AS3PostManager.avrUnlicensed).To(BeTrue(), "Missing AVR license not recorded")
+			Expect(mockPM.AS3PostManager.apmUnlicensed).To(BeTrue(), "Missing APM license not recorded")
+
+			// license is not re-checked within the check interval
+			Expect(mockPM.checkLicensedFeatures(&rsConfig)).To(BeEmpty())
+		})
+
+		It("Check provisioned modules", func() {
+			mockPM.provisionCheckedAt = time.Time{}
+			mockPM.ProvisionCheckInterval = time.Minute
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body: `{"items": [{"name": "ltm", "level": "nominal"}, {"name": "afm", "level": "nominal"},
+					{"name": "avr", "level": "none"}]}`,
+			}}, http.MethodGet)
+			Expect(mockPM.checkProvisionedModules()).To(BeFalse(), "First fetch should not trigger a resync")
+			Expect(mockPM.provisionedModules).To(HaveKey(ModuleAFM))
+			Expect(mockPM.provisionedModules).NotTo(HaveKey(ModuleAVR))
+			Expect(mockPM.AS3PostManager.unprovisionedModules).To(Equal(map[string]struct{}{
+				ModuleAVR: {}, ModuleAPM: {}, ModulePEM: {}}))
+
+			// features of the unprovisioned modules are skipped
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Name = "vs"
+			app := as3Application{"vs": &as3Service{
+				Firewall:                &as3ResourcePointer{BigIP: "/Common/afm-policy"},
+				HttpAnalyticsProfile:    &as3ResourcePointer{BigIP: "/Common/http-analytics"},
+				ProfileAccess:           &as3ResourcePointer{BigIP: "/Common/access"},
+				ProfileDiameterEndpoint: &as3ResourcePointer{Use: "vs_diameter"},
+			}, "vs_diameter": &as3DiameterProfile{Class: "Enforcement_Diameter_Endpoint_Profile"}}
+			processUnprovisionedModulesForAS3(rsCfg, app, mockPM.AS3PostManager.unprovisionedModules)
+			svc := app["vs"].(*as3Service)
+			Expect(svc.Firewall).NotTo(BeNil(), "Firewall policy should be kept with AFM provisioned")
+			Expect(svc.HttpAnalyticsProfile).To(BeNil(), "Analytics profile should be skipped without AVR")
+			Expect(svc.ProfileAccess).To(BeNil(), "Access profile should be skipped without APM")
+			Expect(svc.ProfileDiameterEndpoint).To(BeNil(), "Diameter endpoint profile should be skipped without PEM")
+			Expect(app).NotTo(HaveKey("vs_diameter"))
+
+			// modules are not refreshed within the check interval
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body:   `{"items": [{"name": "avr", "level": "nominal"}]}`,
+			}}, http.MethodGet)
+			Expect(mockPM.checkProvisionedModules()).To(BeFalse())
+			Expect(mockPM.provisionedModules).NotTo(HaveKey(ModuleAVR))
+
+			// newly provisioned modules are picked up on refresh
+			mockPM.provisionCheckedAt = time.Now().Add(-2 * time.Minute)
+			Expect(mockPM.checkProvisionedModules()).To(BeTrue(), "Changed modules should trigger a resync")
+			Expect(mockPM.provisionedModules).To(HaveKey(ModuleAVR))
+			Expect(mockPM.AS3PostManager.unprovisionedModules).To(Equal(map[string]struct{}{
+				ModuleAFM: {}, ModuleAPM: {}, ModulePEM: {}}))
+			Expect(mockPM.refreshProvisionedModules()).To(BeFalse(), "Unchanged modules should not trigger a resync")
+		})
+
+		It("Check management certificate expiry", func() {
+			kubeClient := k8sfake.NewSimpleClientset()
+			mockPM.kubeClient = kubeClient
+			mockPM.podName = "k8s-bigip-ctlr-0"
+			mockPM.podNamespace = "kube-system"
+			mockPM.CertExpiryWarningDays = 30
+			expiry := time.Now().Add(10*24*time.Hour + time.Hour)
+			mockPM.setResponses([]responceCtx{
+				{
+					tenant: "test",
+					status: http.StatusOK,
+					body:   fmt.Sprintf(`{"name": "server.crt", "expirationDate": %d}`, expiry.Unix()),
+				},
+				{
+					tenant: "test",
+					status: http.StatusOK,
+					body:   fmt.Sprintf(`{"name": "server.crt", "expirationDate": %d}`, expiry.Add(60*24*time.Hour).Unix()),
+				},
+			}, http.MethodGet)
+			mockPM.checkCertExpiry()
+			Expect(mockPM.certCheckedAt.IsZero()).To(BeFalse())
+			events, _ := kubeClient.CoreV1().Events("kube-system").List(context.TODO(), metav1.ListOptions{})
+			Expect(events.Items).To(HaveLen(1), "Expiring certificate not reported")
+			Expect(events.Items[0].Reason).To(Equal(CertExpiringEvent))
+			Expect(events.Items[0].InvolvedObject.Name).To(Equal("k8s-bigip-ctlr-0"))
+			Expect(events.Items[0].Message).To(ContainSubstring("expires in 10 days"))
+
+			// certificate renewed
+			mockPM.checkCertExpiry()
+			events, _ = kubeClient.CoreV1().Events("kube-system").List(context.TODO(), metav1.ListOptions{})
+			Expect(events.Items).To(HaveLen(1), "Valid certificate should not be reported")
+		})
+
+		It("Detect declaration drift", func() {
+			mockPM.cachedTenantDeclMap = map[string]as3Tenant{
+				"test":  {"class": "Tenant", "label": "cis"},
+				"test2": {"class": "Tenant", "label": "cis"},
+				"test3": {"class": "Tenant", "label": "cis"},
+			}
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body: `{"class": "ADC", "schemaVersion": "3.48.0", "test": {"class": "Tenant", "label": "cis"},
+					"test2": {"class": "Tenant", "label": "manual"}}`,
+			}}, http.MethodGet)
+			Expect(mockPM.detectDeclarationDrift()).To(Equal([]string{"test2", "test3"}), "Drifted tenants not detected")
+
+			Expect(getDeclarationDriftedTenants(map[string]as3Tenant{"test": {"class": "Tenant"}},
+				map[string]interface{}{"test": map[string]interface{}{"class": "Tenant"}})).To(BeEmpty())
+		})
+
+		It("Remove stale tenants", func() {
+			mockPM.defaultPartition = "test"
+			mockPM.tenantLastSeen = make(map[string]time.Time)
+			mockPM.StalenessThreshold = time.Minute
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body:   `{"orphan": {"class": "Tenant", "label": "test"}, "other": {"class": "Tenant", "label": "other"}}`,
+			}}, http.MethodGet)
+			rsConfig := BigIpResourceConfig{ltmConfig: LTMConfig{"active": &PartitionConfig{
+				ResourceMap: ResourceMap{"vs": &ResourceConfig{}}}}}
+			mockPM.removeStaleTenants(&rsConfig)
+			Expect(mockPM.tenantLastSeen).To(HaveKey("orphan"), "CIS managed tenant on BIG-IP not tracked")
+			Expect(mockPM.tenantLastSeen).To(HaveKey("active"), "Tenant with resources not tracked")
+			Expect(mockPM.tenantLastSeen).NotTo(HaveKey("other"), "Tenant not managed by CIS tracked")
+			Expect(rsConfig.ltmConfig).NotTo(HaveKey("orphan"), "Tenant removed before staleness threshold")
+
+			mockPM.tenantLastSeen["orphan"] = time.Now().Add(-2 * time.Minute)
+			ltmConfig := rsConfig.ltmConfig
+			mockPM.removeStaleTenants(&rsConfig)
+			Expect(rsConfig.ltmConfig).To(HaveKey("orphan"), "Stale tenant not removed")
+			Expect(ltmConfig).NotTo(HaveKey("orphan"), "Shared ltmConfig modified")
+			Expect(rsConfig.ltmConfig["orphan"].ResourceMap).To(BeEmpty())
+			Expect(mockPM.tenantLastSeen).NotTo(HaveKey("orphan"))
+		})
+
+		It("Skip tenants colliding with unowned partitions", func() {
+			mockPM.defaultPartition = "test"
+			mockPM.cachedTenantDeclMap = map[string]as3Tenant{"cached": {"class": "Tenant", "label": "test"}}
+			client, _ := getMockHttpClient([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body: `{"items": [{"name": "Common"}, {"name": "manual"}, {"name": "owned"}, {"name": "other"},
+					{"name": "cached"}]}`,
+			}, {
+				tenant: "test",
+				status: http.StatusOK,
+				body:   `{"owned": {"class": "Tenant", "label": "test"}, "other": {"class": "Tenant", "label": "other"}}`,
+			}}, http.MethodGet)
+			mockPM.PostParams.httpClient = client
+			resourceMap := ResourceMap{"vs": &ResourceConfig{}}
+			rsConfig := BigIpResourceConfig{ltmConfig: LTMConfig{
+				"Common": &PartitionConfig{ResourceMap: resourceMap},
+				"manual": &PartitionConfig{ResourceMap: resourceMap},
+				"owned":  &PartitionConfig{ResourceMap: resourceMap},
+				"other":  &PartitionConfig{ResourceMap: resourceMap},
+				"cached": &PartitionConfig{ResourceMap: resourceMap},
+				"new":    &PartitionConfig{ResourceMap: resourceMap},
+			}}
+			ltmConfig := rsConfig.ltmConfig
+			Expect(mockPM.unownedPartitionsFetched()).To(BeFalse())
+			Expect(mockPM.fetchUnownedPartitions()).To(Succeed())
+			Expect(mockPM.unownedPartitionsFetched()).To(BeTrue())
+			mockPM.skipUnownedPartitions(&rsConfig)
+			Expect(ltmConfig).To(HaveLen(6), "Shared ltmConfig modified")
+			Expect(rsConfig.ltmConfig).NotTo(HaveKey("Common"), "Tenant colliding with Common not skipped")
+			Expect(rsConfig.ltmConfig).NotTo(HaveKey("manual"), "Tenant colliding with manual partition not skipped")
+			Expect(rsConfig.ltmConfig).NotTo(HaveKey("other"), "Tenant managed by other CIS not skipped")
+			Expect(rsConfig.ltmConfig).To(HaveKey("owned"))
+			Expect(rsConfig.ltmConfig).To(HaveKey("cached"))
+			Expect(rsConfig.ltmConfig).To(HaveKey("new"))
+		})
+
+		It("Hold the requests until the unowned partitions are fetched", func() {
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusServiceUnavailable,
+				body:   `{}`,
+			}}, http.MethodGet)
+			Expect(mockPM.fetchUnownedPartitions()).NotTo(Succeed())
+			Expect(mockPM.unownedPartitionsFetched()).To(BeFalse(), "Partitions fetched though BIG-IP is unreachable")
+
+			bigip := cisapiv1.BigIpConfig{BigIpAddress: "10.1.1.1"}
+			requestHandler := newMockAgent("as3")
+			requestHandler.PostManagers.PostManagerMap[bigip] = mockPM.PostManager
+			requestHandler.processRequest(ResourceConfigRequest{bigIpConfig: bigip, reqMeta: requestMeta{id: 1}})
+			Expect(mockPM.postChan).To(BeEmpty(), "Request posted before the partitions are fetched")
+			Expect(requestHandler.lastRequests).To(HaveKey(bigip), "Held request not kept")
+		})
+
+		It("Check log publishers", func() {
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body:   `{"items": [{"name": "remote-syslog", "fullPath": "/Common/remote-syslog"}]}`,
+			}}, http.MethodGet)
+			valid := &ResourceConfig{}
+			valid.Virtual.LogPublisher = "/Common/remote-syslog"
+			missing := &ResourceConfig{}
+			missing.Virtual.LogPublisher = "/Common/missing"
+			rsConfig := BigIpResourceConfig{ltmConfig: LTMConfig{
+				"test": &PartitionConfig{ResourceMap: ResourceMap{"valid": valid, "missing": missing}},
+			}}
+			mockPM.checkLogPublishers(&rsConfig)
+			Expect(mockPM.logPublishers).To(HaveKey("/Common/remote-syslog"))
+			Expect(rsConfig.ltmConfig["test"].ResourceMap["valid"]).To(BeIdenticalTo(valid))
+			Expect(rsConfig.ltmConfig["test"].ResourceMap["missing"].Virtual.LogPublisher).To(BeEmpty(),
+				"Log publisher not found on BIG-IP should be skipped")
+			Expect(missing.Virtual.LogPublisher).To(Equal("/Common/missing"), "Shared resource config modified")
+		})
+
+		It("Validate optimistic lock keys", func() {
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body: `{"locked": {"class": "Tenant", "optimisticLockKey": "old-key"},
+					"matched": {"class": "Tenant", "optimisticLockKey": "new-key"}}`,
+			}}, http.MethodGet)
+			cfg := &as3Config{
+				incomingTenantDeclMap: map[string]as3Tenant{
+					"locked":  {"class": "Tenant", "optimisticLockKey": "new-key"},
+					"matched": {"class": "Tenant", "optimisticLockKey": "new-key"},
+					"new":     {"class": "Tenant", "optimisticLockKey": "new-key"},
+				},
+				tenantResponseMap: map[string]tenantResponse{"locked": {}, "matched": {}, "new": {}},
+			}
+			mockPM.validateOptimisticLockKeys(cfg)
+			Expect(cfg.incomingTenantDeclMap).NotTo(HaveKey("locked"), "Tenant with mismatched lock key not skipped")
+			Expect(cfg.tenantResponseMap).NotTo(HaveKey("locked"))
+			Expect(cfg.incomingTenantDeclMap).To(HaveKey("matched"))
+			Expect(cfg.incomingTenantDeclMap).To(HaveKey("new"))
+		})
+
+		It("Write audit events", func() {
+			mockPM.AuditLogEnabled = true
+			dir, err := os.MkdirTemp("", "cis-audit")
+			Expect(err).To(BeNil())
+			defer os.RemoveAll(dir)
+			mockPM.AuditLogPath = filepath.Join(dir, "audit.log")
+			mockPM.auditUser = "system:serviceaccount:kube-system:bigip-ctlr"
+			cfg := &as3Config{
+				as3APIURL: "https://cm.example.com/api/v1/spaces/default/appsvcs/declare?target_address=10.1.1.1",
+				incomingTenantDeclMap: map[string]as3Tenant{
+					"active":  {"class": "Tenant", "label": "test", "app": as3Application{"class": "Application"}},
+					"deleted": {"class": "Tenant", "label": "test"},
+				},
+				tenantResponseMap: map[string]tenantResponse{
+					"active":  {agentResponseCode: http.StatusOK},
+					"deleted": {agentResponseCode: http.StatusOK, isDeleted: true},
+				},
+			}
+			mockPM.writeAuditEvents(cfg, time.Now())
+			data, err := os.ReadFile(mockPM.AuditLogPath)
+			Expect(err).To(BeNil(), "Audit log not written")
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			Expect(lines).To(HaveLen(2))
+			var event auditEvent
+			Expect(json.Unmarshal([]byte(lines[0]), &event)).To(Succeed())
+			Expect(event.APIVersion).To(Equal("audit.k8s.io/v1"))
+			Expect(event.Verb).To(Equal("post"))
+			Expect(event.User.Username).To(Equal("system:serviceaccount:kube-system:bigip-ctlr"))
+			Expect(event.ObjectRef.Name).To(Equal("/active"))
+			Expect(event.ResponseStatus.Code).To(Equal(http.StatusOK))
+			Expect(event.RequestURI).To(Equal(cfg.as3APIURL))
+			Expect(event.Annotations["cis.f5.com/request-object-hash"]).To(HavePrefix("sha256:"))
+			Expect(json.Unmarshal([]byte(lines[1]), &event)).To(Succeed())
+			Expect(event.Verb).To(Equal("delete"))
+			Expect(event.ObjectRef.Name).To(Equal("/deleted"))
+		})
+
+		It("Save and restore declaration checkpoint", func() {
+			kubeClient := k8sfake.NewSimpleClientset()
+			mockPM.kubeClient = kubeClient
+			mockPM.CheckpointConfigMap = "kube-system/cis-declaration-checkpoint"
+			mockPM.checkpointKey = getCheckpointKey("https://10.1.1.1:443")
+			Expect(mockPM.checkpointKey).To(Equal("10.1.1.1_443"))
+			svc := &as3Service{Class: "Service_HTTP", VirtualAddresses: []as3MultiTypeParam{"1.2.3.4"}, VirtualPort: 80}
+			tenantDecl := as3Tenant{"class": "Tenant", "label": "test", "app": as3Application{"class": "Application", "vs": svc}}
+			mockPM.cachedTenantDeclMap["test"] = tenantDecl
+			mockPM.scheduleCheckpoint()
+			// the ConfigMap is written after the delay and not with the post
+			_, err := kubeClient.CoreV1().ConfigMaps("kube-system").Get(context.TODO(), "cis-declaration-checkpoint",
+				metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue(), "Checkpoint ConfigMap written with the post")
+			mockPM.flushCheckpoint()
+			cm, err := kubeClient.CoreV1().ConfigMaps("kube-system").Get(context.TODO(), "cis-declaration-checkpoint",
+				metav1.GetOptions{})
+			Expect(err).To(BeNil(), "Checkpoint ConfigMap not created")
+			Expect(cm.Data).To(HaveKey("10.1.1.1_443"))
+
+			// update of the existing ConfigMap, the failed tenants are left out to post them again after a restart
+			mockPM.cachedTenantDeclMap["other"] = as3Tenant{"class": "Tenant", "label": "test"}
+			mockPM.cachedTenantDeclMap["failed"] = as3Tenant{"class": "Tenant", "label": "test"}
+			mockPM.failedTenants = map[string]struct{}{"failed": {}}
+			mockPM.scheduleCheckpoint()
+			mockPM.flushCheckpoint()
+			Expect(mockPM.checkpointTimer).To(BeNil())
+
+			restoredPM := newMockPostManger()
+			restoredPM.kubeClient = kubeClient
+			restoredPM.tenantLastSeen = make(map[string]time.Time)
+			restoredPM.CheckpointConfigMap = mockPM.CheckpointConfigMap
+			restoredPM.checkpointKey = mockPM.checkpointKey
+			restoredPM.restoreDeclarationCheckpoint()
+			Expect(restoredPM.cachedTenantDeclMap).To(HaveLen(2))
+			Expect(restoredPM.cachedTenantDeclMap).NotTo(HaveKey("failed"), "Failed tenant checkpointed")
+			Expect(restoredPM.tenantLastSeen).To(HaveKey("test"))
+			Expect(restoredPM.bigipTenantsFetched).To(BeTrue())
+			Expect(tenantDeclEqual(tenantDecl, restoredPM.cachedTenantDeclMap["test"])).To(BeTrue(),
+				"Restored tenant should match the unchanged declaration")
+			svc.VirtualPort = 8080
+			Expect(tenantDeclEqual(tenantDecl, restoredPM.cachedTenantDeclMap["test"])).To(BeFalse(),
+				"Restored tenant should not match the changed declaration")
+		})
+
+		It("Pin AS3 class versions", func() {
+			mockPM.PostManager.AS3PostManager.classVersions = map[string]string{"Service_HTTP": "3.40.0",
+				"TLS_Server": "3.38.0"}
+			svc := &as3Service{Class: "Service_HTTP", VirtualAddresses: []as3MultiTypeParam{"1.2.3.4"}, VirtualPort: 80}
+			pool := &as3Pool{Class: "Pool"}
+			tenantDecl := as3Tenant{"class": "Tenant", "app": as3Application{"class": "Application", "vs": svc,
+				"pool": pool}}
+			getADC := func(decl as3Declaration) map[string]interface{} {
+				var obj map[string]interface{}
+				Expect(json.Unmarshal([]byte(decl), &obj)).To(BeNil())
+				adc := obj["declaration"].(map[string]interface{})
+				app := adc["test"].(map[string]interface{})["app"].(map[string]interface{})
+				Expect(app["vs"]).NotTo(HaveKey("schemaVersion"), "Objects should not have a schemaVersion")
+				return adc
+			}
+
+			// pinning is skipped until the AS3 version on BIG-IP is known
+			mockPM.PostManager.AS3PostManager.AS3VersionInfo.as3SchemaVersion = ""
+			decl := mockPM.PostManager.AS3PostManager.createAS3Declaration(map[string]as3Tenant{"test": tenantDecl}, "test")
+			Expect(getADC(decl)["schemaVersion"]).To(Equal("3.0.0"))
+
+			mockPM.PostManager.AS3PostManager.AS3VersionInfo.as3SchemaVersion = "3.45.0"
+			decl = mockPM.PostManager.AS3PostManager.createAS3Declaration(map[string]as3Tenant{"test": tenantDecl}, "test")
+			Expect(getADC(decl)["schemaVersion"]).To(Equal("3.40.0"))
+
+			// the lowest version of the pinned classes in the declaration is used
+			tenantDecl["app"].(as3Application)["tls"] = &as3TLSServer{Class: "TLS_Server"}
+			decl = mockPM.PostManager.AS3PostManager.createAS3Declaration(map[string]as3Tenant{"test": tenantDecl}, "test")
+			Expect(getADC(decl)["schemaVersion"]).To(Equal("3.38.0"))
+			delete(tenantDecl["app"].(as3Application), "tls")
+
+			mockPM.PostManager.AS3PostManager.AS3VersionInfo.as3SchemaVersion = "3.40.0"
+			decl = mockPM.PostManager.AS3PostManager.createAS3Declaration(map[string]as3Tenant{"test": tenantDecl}, "test")
+			Expect(getADC(decl)["schemaVersion"]).To(Equal("3.0.0"), "Class pinned to the current version")
+			Expect(tenantDecl["app"].(as3Application)["vs"]).To(Equal(svc))
+		})
+
+		It("Summarize tenant health", func() {
+			mockPM.cachedTenantDeclMap = map[string]as3Tenant{"healthy": {}, "broken": {}}
+			firstPost := time.Now()
+			mockPM.updateHealthSummary(&as3Config{
+				targetAddress: "10.1.1.1",
+				tenantResponseMap: map[string]tenantResponse{
+					"healthy": {agentResponseCode: http.StatusOK},
+					"broken":  {agentResponseCode: http.StatusOK},
+				},
+			}, firstPost)
+			Expect(mockPM.TenantHealthSummary()).To(Equal(HealthSummary{
+				TotalTenants:    2,
+				HealthyTenants:  2,
+				LastSyncTime:    firstPost,
+				LastSuccessTime: firstPost,
+			}))
+
+			// a tenant failing for the first time is counted as failed only
+			secondPost := firstPost.Add(time.Minute)
+			mockPM.updateHealthSummary(&as3Config{
+				targetAddress: "10.1.1.1",
+				tenantResponseMap: map[string]tenantResponse{
+					"broken": {agentResponseCode: http.StatusUnprocessableEntity},
+					"new":    {agentResponseCode: http.StatusUnprocessableEntity},
+				},
+			}, secondPost)
+			Expect(mockPM.TenantHealthSummary()).To(Equal(HealthSummary{
+				TotalTenants:    3,
+				HealthyTenants:  1,
+				FailedTenants:   2,
+				LastSyncTime:    secondPost,
+				LastSuccessTime: firstPost,
+			}))
+		})
+
+		It("Report tenant status", func() {
+			postedAt := time.Now()
+			mockPM.updateHealthSummary(&as3Config{
+				targetAddress:         "10.1.1.1",
+				incomingTenantDeclMap: map[string]as3Tenant{"ok": {"class": "Tenant"}, "failed": {"class": "Tenant"}},
+				tenantResponseMap: map[string]tenantResponse{
+					"ok":      {agentResponseCode: http.StatusOK},
+					"failed":  {agentResponseCode: http.StatusUnprocessableEntity},
+					"deleted": {agentResponseCode: http.StatusOK},
+				},
+			}, postedAt)
+			tenantStatus := mockPM.GetTenantStatus()
+			Expect(tenantStatus).To(HaveLen(3))
+			Expect(tenantStatus["ok"]).To(Equal(TenantStatus{
+				LastPostTime:     postedAt,
+				LastResponseCode: http.StatusOK,
+				// SHA-256 of {"class":"Tenant"}
+				DeclarationHash: "b6e2861fcc231ae34201feac83d5f3f1192965d65a44f6605bd7eeb9432d05b1",
+			}))
+			Expect(tenantStatus["failed"].Failed).To(BeTrue())
+			Expect(tenantStatus["failed"].LastResponseCode).To(Equal(http.StatusUnprocessableEntity))
+
+			// the deleted tenants are forgotten and the failed tenants keep the hash of the last posted declaration
+			mockPM.updateHealthSummary(&as3Config{
+				targetAddress: "10.1.1.1",
+				tenantResponseMap: map[string]tenantResponse{
+					"deleted": {agentResponseCode: http.StatusOK, isDeleted: true},
+					"failed":  {agentResponseCode: http.StatusServiceUnavailable},
+				},
+			}, postedAt.Add(time.Minute))
+			Expect(mockPM.GetTenantStatus()).NotTo(HaveKey("deleted"))
+			Expect(mockPM.GetTenantStatus()["failed"].DeclarationHash).To(Equal(tenantStatus["failed"].DeclarationHash))
+			Expect(mockPM.GetTenantStatus()["failed"].LastResponseCode).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("Record tenant post metrics", func() {
+			registry := prometheus.NewRegistry()
+			bigIPPrometheus.RegisterMetrics(registry, true)
+			cfg := &as3Config{
+				targetAddress: "10.1.1.2",
+				as3APIURL:     "https://10.1.1.2/mgmt/shared/appsvcs/declare",
+				incomingTenantDeclMap: map[string]as3Tenant{
+					"metrics-ok": {}, "metrics-failed": {},
+				},
+				tenantResponseMap: map[string]tenantResponse{
+					"metrics-ok":     {agentResponseCode: http.StatusOK},
+					"metrics-failed": {agentResponseCode: http.StatusUnprocessableEntity},
+				},
+			}
+			recordDeclarationMetrics(cfg)
+			recordTenantPostMetrics(cfg, 2*time.Second)
+
+			families, err := registry.Gather()
+			Expect(err).To(BeNil())
+			values := make(map[string]float64)
+			for _, family := range families {
+				for _, metric := range family.GetMetric() {
+					var labels []string
+					for _, label := range metric.GetLabel() {
+						labels = append(labels, label.GetValue())
+					}
+					key := family.GetName() + "{" + strings.Join(labels, ",") + "}"
+					switch {
+					case metric.GetGauge() != nil:
+						values[key] = metric.GetGauge().GetValue()
+					case metric.GetCounter() != nil:
+						values[key] = metric.GetCounter().GetValue()
+					case metric.GetHistogram() != nil:
+						values[key] = metric.GetHistogram().GetSampleSum()
+					}
+				}
+			}
+			Expect(values["k8s_bigip_ctlr_as3_declaration_tenants{10.1.1.2}"]).To(Equal(float64(2)))
+			Expect(values["k8s_bigip_ctlr_as3_tenant_post_responses_total{422,metrics-failed}"]).To(Equal(float64(1)))
+			Expect(values["k8s_bigip_ctlr_as3_tenant_post_responses_total{200,metrics-ok}"]).To(Equal(float64(1)))
+			Expect(values["k8s_bigip_ctlr_as3_tenant_post_duration_seconds{"+cfg.as3APIURL+",metrics-ok}"]).
+				To(Equal(float64(2)))
+
+			// the tenant post metrics are registered only with the http client metrics
+			registry = prometheus.NewRegistry()
+			bigIPPrometheus.RegisterMetrics(registry, false)
+			families, err = registry.Gather()
+			Expect(err).To(BeNil())
+			for _, family := range families {
+				Expect(family.GetName()).NotTo(HavePrefix("k8s_bigip_ctlr_as3_tenant_post"))
+				Expect(family.GetName()).NotTo(Equal("k8s_bigip_ctlr_as3_declaration_tenants"))
+			}
+		})
+	})
+
+	Describe("Packet filters", func() {
+		It("Sync packet filters with BIG-IP", func() {
+			filter := &cisapiv1.PacketFilter{}
+			filter.Name = "web"
+			filter.Namespace = "default"
+			filter.Spec.Rules = []cisapiv1.PacketFilterRule{
+				{Order: 10, Action: "allow", Protocol: "TCP", Source: "10.1.0.0/16", Destination: "10.8.3.11"},
+				{Order: 20, Action: "discard", Protocol: "udp"},
+				{Order: 30, Action: "drop", Protocol: "udp"},
+				{Order: 40, Action: "reject", Source: "10.1.0.0/33"},
+				{Order: 50, Action: "discard"},
+			}
+			conflicting := &cisapiv1.PacketFilter{}
+			conflicting.Name = "other"
+			conflicting.Namespace = "default"
+			conflicting.Spec.Rules = []cisapiv1.PacketFilterRule{{Order: 10, Action: "reject", Protocol: "tcp"}}
+			ctlr := &Controller{}
+			filters := ctlr.buildBigIPPacketFilters([]*cisapiv1.PacketFilter{filter, conflicting})
+			Expect(filters).To(HaveLen(2), "Invalid and conflicting rules not skipped")
+			Expect(filters["default_web_10"]).To(Equal(bigIPPacketFilter{
+				Name:        "default_web_10",
+				Partition:   "Common",
+				Order:       10,
+				Action:      "accept",
+				Rule:        "tcp and src net 10.1.0.0/16 and dst host 10.8.3.11",
+				Description: packetFilterDescription + "default/web",
+			}))
+			Expect(filters["default_web_20"].Rule).To(Equal("udp"))
+			Expect(filters).NotTo(HaveKey("default_web_50"), "Rule without match criteria not skipped")
+
+			server := ghttp.NewServer()
+			defer server.Close()
+			mockPM.tokenManager.ServerURL = server.URL()
+			mockPM.httpClient = http.DefaultClient
+			url := "/mgmt/tm/net/packet-filter"
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, url),
+					ghttp.RespondWith(http.StatusOK, `{"items": [
+						{"name": "default_old_5", "order": 5, "action": "accept", "description": "`+
+						packetFilterDescription+`default/old"},
+						{"name": "manual", "order": 1, "action": "accept"}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPost, url),
+					ghttp.VerifyJSONRepresenting(filters["default_web_10"]),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPost, url),
+					ghttp.RespondWith(http.StatusBadRequest, `{"message": "invalid rule"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodDelete, url+"/~Common~default_old_5"),
+					ghttp.RespondWith(http.StatusOK, ``),
+				),
+			)
+			err := mockPM.syncPacketFilters(filters)
+			Expect(err).To(MatchError("packet filters default_web_20 not synced"), "Failed packet filter not reported")
+			Expect(server.ReceivedRequests()).To(HaveLen(4))
+			Expect(mockPM.packetFilters).To(HaveKey("default_web_10"))
+			Expect(mockPM.packetFilters).NotTo(HaveKey("default_web_20"), "Failed packet filter cached")
+			Expect(mockPM.packetFilters).NotTo(HaveKey("default_old_5"), "Deleted packet filter cached")
+
+			filter.Spec.Rules[0].Action = "reject"
+			filters = ctlr.buildBigIPPacketFilters([]*cisapiv1.PacketFilter{filter})
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPatch, url+"/~Common~default_web_10"),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPost, url),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+			Expect(mockPM.syncPacketFilters(filters)).To(Succeed())
+			Expect(server.ReceivedRequests()).To(HaveLen(6))
+			Expect(mockPM.packetFilters["default_web_10"].Action).To(Equal("reject"))
+			Expect(mockPM.packetFilters).To(HaveKey("default_web_20"))
+		})
+	})
+
+	Describe("SCTP profiles", func() {
+		It("Create and delete the SCTP profiles of the declaration on BIG-IP", func() {
+			created := newBigIPSCTPProfile(&SCTPProfile{HeartbeatInterval: 30})
+			existing := newBigIPSCTPProfile(&SCTPProfile{CookieExpiry: 60})
+			Expect(newBigIPSCTPProfile(&SCTPProfile{})).To(BeNil(), "Default SCTP profile should not be created")
+			mockPM.defaultPartition = "test"
+			description := sctpProfileDescription + " of test on BIG-IP 10.1.1.1"
+			Expect(mockPM.getSCTPProfileDescription("10.1.1.1")).To(Equal(description))
+			owned := *created
+			owned.Description = description
+			cfg := &as3Config{targetAddress: "10.1.1.1", incomingTenantDeclMap: map[string]as3Tenant{
+				"test": {
+					"Shared": as3Application{
+						"vs1": &as3Service{Class: "Service_SCTP", sctpProfile: created,
+							ProfileSCTP: &as3ResourcePointer{BigIP: "/Common/cis_sctp_30_0_0"}},
+						"vs2": &as3Service{Class: "Service_SCTP", sctpProfile: existing},
+						"vs3": &as3Service{Class: "Service_TCP"},
+					},
+				},
+			}}
+
+			server := ghttp.NewServer()
+			defer server.Close()
+			mockPM.tokenManager.ServerURL = server.URL()
+			mockPM.httpClient = http.DefaultClient
+			url := "/mgmt/tm/ltm/profile/sctp"
+			query := "target_address=10.1.1.1"
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, url, query),
+					ghttp.RespondWith(http.StatusOK, `{"items": [
+						{"name": "cis_sctp_1_1_1", "partition": "Common", "description": "`+description+`"},
+						{"name": "cis_sctp_2_2_2", "partition": "Common", "description": "`+sctpProfileDescription+
+						` of other on BIG-IP 10.1.1.1"},
+						{"name": "sctp", "partition": "Common"}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, url+"/~Common~cis_sctp_0_0_60", query),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, url+"/~Common~cis_sctp_30_0_0", query),
+					ghttp.RespondWith(http.StatusNotFound, `{}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPost, url, query),
+					ghttp.VerifyJSONRepresenting(owned),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+			mockPM.syncSCTPProfiles(cfg)
+			Expect(server.ReceivedRequests()).To(HaveLen(4))
+			Expect(mockPM.sctpProfiles).To(Equal(map[string]map[string]struct{}{
+				"10.1.1.1": {"cis_sctp_1_1_1": {}, "cis_sctp_30_0_0": {}}}),
+				"Only the profiles created by the post manager should be owned")
+
+			// the owned profiles are not requested again
+			delete(cfg.incomingTenantDeclMap["test"]["Shared"].(as3Application), "vs2")
+			mockPM.syncSCTPProfiles(cfg)
+			Expect(server.ReceivedRequests()).To(HaveLen(4))
+
+			// the profiles no longer referenced by the posted declarations are deleted
+			mockPM.cachedTenantDeclMap = map[string]as3Tenant{
+				"test": cfg.incomingTenantDeclMap["test"],
+				// declaration restored from BIG-IP
+				"test2": {"Shared": map[string]interface{}{
+					"vs1": map[string]interface{}{"profileSCTP": map[string]interface{}{"bigip": "/Common/cis_sctp_0_0_60"}},
+				}},
+			}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodDelete, url+"/~Common~cis_sctp_1_1_1", query),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+			mockPM.deleteUnreferencedSCTPProfiles()
+			Expect(server.ReceivedRequests()).To(HaveLen(5))
+			Expect(mockPM.sctpProfiles).To(Equal(map[string]map[string]struct{}{"10.1.1.1": {"cis_sctp_30_0_0": {}}}))
+
+			// the profiles are not synced while the circuit breaker rejects the posts
+			mockPM.CircuitBreakerThreshold = 1
+			mockPM.unavailableResponses = 1
+			mockPM.circuitOpenUntil = time.Now().Add(time.Minute)
+			mockPM.sctpProfiles = nil
+			cfg.tenantResponseMap = make(map[string]tenantResponse)
+			mockPM.publishConfig(cfg)
+			Expect(server.ReceivedRequests()).To(HaveLen(5), "SCTP profiles synced with the circuit breaker open")
+		})
 	})
 })
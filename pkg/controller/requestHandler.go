@@ -5,6 +5,7 @@ import (
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/prometheus"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
 	"reflect"
+	"sort"
 	"time"
 )
 
@@ -34,6 +35,12 @@ func (req *RequestHandler) startPostManager(config cisapiv1.BigIpConfig) {
 		pm := NewPostManager(req.PostParams, config.DefaultPartition)
 		pm.respChan = req.respChan
 		pm.tokenManager = req.CMTokenManager
+		pm.checkpointKey = getCheckpointKey(config.BigIpAddress)
+		if pm.WarmStart {
+			pm.restoreDeclarationCheckpoint()
+		}
+		// the requests of the BIG-IP are held until the partitions not managed by CIS are known
+		go req.fetchUnownedPartitions(config, pm)
 		// update agent Map
 		req.PostManagers.PostManagerMap[config] = pm
 		// increase the Agent Count
@@ -42,7 +49,34 @@ func (req *RequestHandler) startPostManager(config cisapiv1.BigIpConfig) {
 	req.PostManagers.Unlock()
 }
 
-func (req *RequestHandler) EnqueueRequestConfig(rsConfig ResourceConfigRequest) {
+// fetchUnownedPartitions fetches the BIG-IP partitions which are not managed by CIS, retrying with backoff until
+// it succeeds or the post manager is stopped, and signals partitionsFetched with the BIG-IP once fetched
+func (req *RequestHandler) fetchUnownedPartitions(config cisapiv1.BigIpConfig, pm *PostManager) {
+	delay := timeoutSmall
+	for {
+		err := pm.fetchUnownedPartitions()
+		if err == nil {
+			break
+		}
+		log.Errorf("[AS3]%v Holding the requests of BIG-IP %v, retrying in %v to detect the partitions not "+
+			"managed by CIS: %v", pm.postManagerPrefix, config.BigIpAddress, delay, err)
+		time.Sleep(delay)
+		req.PostManagers.RLock()
+		running := req.PostManagers.PostManagerMap[config] == pm
+		req.PostManagers.RUnlock()
+		if !running {
+			return
+		}
+		delay *= 2
+		if delay > timeoutLarge {
+			delay = timeoutLarge
+		}
+	}
+	req.partitionsFetched <- config
+}
+
+// EnqueueRequestConfig returns false when the request is dropped
+func (req *RequestHandler) EnqueueRequestConfig(rsConfig ResourceConfigRequest) bool {
 	// Always push latest activeConfig to channel
 	// Case1: Put latest config into the channel
 	// Case2: If channel is blocked because of earlier config, pop out earlier config and push latest config
@@ -50,26 +84,146 @@ func (req *RequestHandler) EnqueueRequestConfig(rsConfig ResourceConfigRequest)
 
 	select {
 	case req.reqChan <- rsConfig:
+		return true
 	case <-time.After(3 * time.Millisecond):
+		return false
 	}
 }
 
 // RequestHandler blocks on reqChan
 // whenever it gets unblocked, it creates an as3, l3 declaration for respective bigip and puts on post channel for postmanger to handle
+// while the rollout is paused the requests are held, the held requests are processed once the rollout is resumed
 func (req *RequestHandler) requestHandler() {
-	for rsConfig := range req.reqChan {
-		req.PostManagers.RLock()
-		if pm, ok := req.PostManagers.PostManagerMap[rsConfig.bigIpConfig]; ok {
-			//create post config declaration for BigIp pair and put in post channel
-			cfg := req.createDeclarationForBIGIP(rsConfig, pm)
-			if !reflect.DeepEqual(cfg, agentConfig{}) {
-				pm.postChan <- cfg
+	var provisionTicker <-chan time.Time
+	if req.PostParams.ProvisionCheckInterval > 0 {
+		ticker := time.NewTicker(req.PostParams.ProvisionCheckInterval)
+		defer ticker.Stop()
+		provisionTicker = ticker.C
+	}
+	for {
+		select {
+		case rsConfig, ok := <-req.reqChan:
+			if !ok {
+				return
+			}
+			if req.holdRequest(rsConfig) {
+				continue
+			}
+			req.processRequest(rsConfig)
+		case <-req.rolloutResumed:
+			for _, rsConfig := range req.releaseHeldRequests() {
+				req.processRequest(rsConfig)
+			}
+		case <-provisionTicker:
+			req.resyncProvisionedModules()
+		case bigIpConfig := <-req.partitionsFetched:
+			if rsConfig, ok := req.lastRequests[bigIpConfig]; ok && !req.holdRequest(rsConfig) {
+				req.processRequest(rsConfig)
 			}
 		}
-		req.PostManagers.RUnlock()
 	}
 }
 
+// resyncProvisionedModules refreshes the provisioned modules of the BIG-IPs, the latest request of a BIG-IP is
+// processed again when its gated modules changed, so that the features of the resources are added or skipped
+// without waiting for a resource update
+func (req *RequestHandler) resyncProvisionedModules() {
+	var requests []ResourceConfigRequest
+	req.PostManagers.RLock()
+	for bigIpConfig, pm := range req.PostManagers.PostManagerMap {
+		rsConfig, ok := req.lastRequests[bigIpConfig]
+		if !ok {
+			continue
+		}
+		if pm.refreshProvisionedModules() {
+			requests = append(requests, rsConfig)
+		}
+	}
+	req.PostManagers.RUnlock()
+	for _, rsConfig := range requests {
+		log.Infof("Provisioned modules changed on BIG-IP %v, re-syncing the resources",
+			rsConfig.bigIpConfig.BigIpAddress)
+		if req.holdRequest(rsConfig) {
+			continue
+		}
+		req.processRequest(rsConfig)
+	}
+}
+
+// SetRolloutPause pauses or resumes the rollout of the resource updates to BIG-IP
+func (req *RequestHandler) SetRolloutPause(paused bool) {
+	req.rolloutLock.Lock()
+	defer req.rolloutLock.Unlock()
+	if req.rolloutPaused == paused {
+		return
+	}
+	req.rolloutPaused = paused
+	if paused {
+		log.Infof("Rollout paused, the resource updates are held until the rollout is resumed")
+		return
+	}
+	log.Infof("Rollout resumed, posting %v held request(s)", len(req.heldRequests))
+	select {
+	case req.rolloutResumed <- struct{}{}:
+	default:
+	}
+}
+
+// holdRequest holds the request while the rollout is paused, only the latest request of every BIG-IP is held
+// as it carries the complete config of the BIG-IP
+func (req *RequestHandler) holdRequest(rsConfig ResourceConfigRequest) bool {
+	req.rolloutLock.Lock()
+	defer req.rolloutLock.Unlock()
+	if !req.rolloutPaused {
+		return false
+	}
+	if req.heldRequests == nil {
+		req.heldRequests = make(map[cisapiv1.BigIpConfig]ResourceConfigRequest)
+	}
+	req.heldRequests[rsConfig.bigIpConfig] = rsConfig
+	log.Debugf("Rollout paused, holding request %v of BIG-IP %v", rsConfig.reqMeta.id,
+		rsConfig.bigIpConfig.BigIpAddress)
+	return true
+}
+
+// releaseHeldRequests returns the held requests sorted by the BIG-IP address unless the rollout is paused again
+func (req *RequestHandler) releaseHeldRequests() []ResourceConfigRequest {
+	req.rolloutLock.Lock()
+	defer req.rolloutLock.Unlock()
+	if req.rolloutPaused {
+		return nil
+	}
+	requests := make([]ResourceConfigRequest, 0, len(req.heldRequests))
+	for _, rsConfig := range req.heldRequests {
+		requests = append(requests, rsConfig)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].bigIpConfig.BigIpAddress < requests[j].bigIpConfig.BigIpAddress
+	})
+	req.heldRequests = make(map[cisapiv1.BigIpConfig]ResourceConfigRequest)
+	return requests
+}
+
+// processRequest creates the declaration of the request and puts it on the post channel of the BIG-IP
+func (req *RequestHandler) processRequest(rsConfig ResourceConfigRequest) {
+	if req.lastRequests == nil {
+		req.lastRequests = make(map[cisapiv1.BigIpConfig]ResourceConfigRequest)
+	}
+	req.lastRequests[rsConfig.bigIpConfig] = rsConfig
+	req.PostManagers.RLock()
+	if pm, ok := req.PostManagers.PostManagerMap[rsConfig.bigIpConfig]; ok && !pm.unownedPartitionsFetched() {
+		log.Debugf("[AS3]%v Holding request %v until the partitions not managed by CIS are fetched",
+			pm.postManagerPrefix, rsConfig.reqMeta.id)
+	} else if ok {
+		//create post config declaration for BigIp pair and put in post channel
+		cfg := req.createDeclarationForBIGIP(rsConfig, pm)
+		if !reflect.DeepEqual(cfg, agentConfig{}) {
+			pm.postChan <- cfg
+		}
+	}
+	req.PostManagers.RUnlock()
+}
+
 func (req *RequestHandler) createDeclarationForBIGIP(rsConfig ResourceConfigRequest, pm *PostManager) agentConfig {
 	var agentCfg agentConfig
 	if req.HAMode {
@@ -99,6 +253,16 @@ func (req *RequestHandler) createDeclarationForBIGIP(rsConfig ResourceConfigRequ
 			pm.AS3PostManager.firstPost = false
 		}
 	}
+	// Delete the orphaned tenants which are not seen within the staleness threshold
+	pm.removeStaleTenants(&rsConfig.bigIpResourceConfig)
+	// Skip the tenants which collide with the BIG-IP partitions not managed by CIS
+	pm.skipUnownedPartitions(&rsConfig.bigIpResourceConfig)
+	// Warn about the configured features which are not licensed on BIG-IP
+	pm.checkLicensedFeatures(&rsConfig.bigIpResourceConfig)
+	// Refresh the provisioned modules which gate the features of the declaration
+	pm.checkProvisionedModules()
+	// Skip the Log Publishers of the virtuals which are not found on BIG-IP
+	pm.checkLogPublishers(&rsConfig.bigIpResourceConfig)
 	//for each request config create AS3, L3 declaration
 	// create the AS3 declaration for the bigip
 	as3cfg := req.createAS3Config(rsConfig, pm)
@@ -6,6 +6,7 @@ import (
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/tokenmanager"
 	"net/http"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -523,6 +524,124 @@ var _ = Describe("Backend Tests", func() {
 			ok := DeepEqualJSON(`{"key": "value"}`, `{"key": "value"}`)
 			Expect(ok).To(BeTrue())
 		})
+		It("Verify JSONs differing in key order and whitespace", func() {
+			ok := DeepEqualJSON(`{"a": 1, "b": {"c": [1, 2]}}`, `{"b":{"c":[1,2]},"a":1}`)
+			Expect(ok).To(BeTrue(), "Key order and whitespace should be ignored")
+			ok = DeepEqualJSON(`{"a": 1, "b": {"c": [1, 2]}}`, `{"a": 1, "b": {"c": [2, 1]}}`)
+			Expect(ok).To(BeFalse(), "Array order should not be ignored")
+		})
+		It("Verify tenant declarations in typed and JSON form", func() {
+			tenantDecl := as3Tenant{"class": "Tenant", "app": as3Application{"class": "Application",
+				"svc": &as3Service{Class: "Service_HTTP", VirtualPort: 80}}}
+			var cached as3Tenant
+			Expect(json.Unmarshal([]byte(`{"app": {"svc": {"virtualPort": 80, "class": "Service_HTTP"},
+				"class": "Application"}, "class": "Tenant"}`), &cached)).To(BeNil())
+			Expect(tenantDeclEqual(tenantDecl, cached)).To(BeTrue())
+			cached["label"] = "cis"
+			Expect(tenantDeclEqual(tenantDecl, cached)).To(BeFalse())
+		})
+		It("Diff the tenants of two declarations", func() {
+			oldDecl := `{"declaration": {"class": "ADC", "controls": {"class": "Controls"},
+				"same": {"class": "Tenant", "app": {"class": "Application"}},
+				"changed": {"class": "Tenant", "app": {"class": "Application", "vs": {"virtualPort": 80}},
+					"other": {"class": "Application"}},
+				"removed": {"class": "Tenant"}}}`
+			newDecl := `{"declaration": {"class": "ADC", "controls": {"class": "Controls", "userAgent": "cis"},
+				"same": {"class": "Tenant", "app": {"class": "Application"}},
+				"changed": {"class": "Tenant", "app": {"class": "Application", "vs": {"virtualPort": 443}},
+					"other": {"class": "Application"}, "new": {"class": "Application"}},
+				"added": {"class": "Tenant"}}}`
+			diffs := DiffAS3Declarations(as3Declaration(oldDecl), as3Declaration(newDecl))
+			Expect(diffs).To(Equal([]TenantDiff{
+				{Tenant: "added", Change: TenantAdded, After: `{"class":"Tenant"}`},
+				{
+					Tenant: "changed",
+					Change: TenantModified,
+					Keys:   []string{"app/vs/virtualPort", "new"},
+					Before: `{"app":{"class":"Application","vs":{"virtualPort":80}}}`,
+					After:  `{"app":{"class":"Application","vs":{"virtualPort":443}},"new":{"class":"Application"}}`,
+				},
+				{Tenant: "removed", Change: TenantRemoved, Before: `{"class":"Tenant"}`},
+			}))
+			Expect(DiffAS3Declarations(as3Declaration(newDecl), as3Declaration(newDecl))).To(BeEmpty())
+		})
+	})
+
+	Describe("Rollout pause", func() {
+		var requestHandler *RequestHandler
+		var bigip1, bigip2 cisapiv1.BigIpConfig
+		BeforeEach(func() {
+			requestHandler = newMockAgent("as3")
+			requestHandler.rolloutResumed = make(chan struct{}, 1)
+			bigip1 = cisapiv1.BigIpConfig{BigIpAddress: "10.1.1.1"}
+			bigip2 = cisapiv1.BigIpConfig{BigIpAddress: "10.1.1.2"}
+		})
+		It("Holds the latest request of every BIG-IP until the rollout is resumed", func() {
+			Expect(requestHandler.holdRequest(ResourceConfigRequest{bigIpConfig: bigip1})).To(BeFalse(),
+				"Request held while the rollout is not paused")
+
+			requestHandler.SetRolloutPause(true)
+			Expect(requestHandler.holdRequest(ResourceConfigRequest{bigIpConfig: bigip2,
+				reqMeta: requestMeta{id: 1}})).To(BeTrue())
+			Expect(requestHandler.holdRequest(ResourceConfigRequest{bigIpConfig: bigip1,
+				reqMeta: requestMeta{id: 2}})).To(BeTrue())
+			Expect(requestHandler.holdRequest(ResourceConfigRequest{bigIpConfig: bigip1,
+				reqMeta: requestMeta{id: 3}})).To(BeTrue())
+			Expect(requestHandler.heldRequests).To(HaveLen(2))
+			Expect(requestHandler.releaseHeldRequests()).To(BeEmpty(), "Requests released while paused")
+			Expect(requestHandler.rolloutResumed).To(BeEmpty())
+
+			requestHandler.SetRolloutPause(false)
+			Expect(requestHandler.rolloutResumed).To(HaveLen(1), "Resume not signalled")
+			requests := requestHandler.releaseHeldRequests()
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[0].bigIpConfig).To(Equal(bigip1))
+			Expect(requests[0].reqMeta.id).To(Equal(3), "Latest request of the BIG-IP not held")
+			Expect(requests[1].bigIpConfig).To(Equal(bigip2))
+			Expect(requestHandler.heldRequests).To(BeEmpty())
+		})
+		It("Re-syncs the latest request of the BIG-IP when the provisioned modules change", func() {
+			mockPM := newMockPostManger()
+			mockPM.provisionCheckedAt = time.Now()
+			mockPM.AS3PostManager.unprovisionedModules = map[string]struct{}{ModuleAFM: {}}
+			mockPM.setResponses([]responceCtx{{
+				tenant: "test",
+				status: http.StatusOK,
+				body:   `{"items": [{"name": "afm", "level": "nominal"}]}`,
+			}}, http.MethodGet)
+			requestHandler.PostManagers.PostManagerMap = map[cisapiv1.BigIpConfig]*PostManager{bigip1: mockPM.PostManager}
+			requestHandler.SetRolloutPause(true)
+			requestHandler.resyncProvisionedModules()
+			Expect(requestHandler.heldRequests).To(BeEmpty(), "BIG-IP without processed request re-synced")
+
+			requestHandler.lastRequests = map[cisapiv1.BigIpConfig]ResourceConfigRequest{
+				bigip1: {bigIpConfig: bigip1, reqMeta: requestMeta{id: 4}}}
+			requestHandler.resyncProvisionedModules()
+			Expect(requestHandler.heldRequests).To(HaveKey(bigip1), "Latest request not re-synced")
+			Expect(requestHandler.heldRequests[bigip1].reqMeta.id).To(Equal(4))
+		})
+		It("Does not signal the resume when the rollout is not paused", func() {
+			requestHandler.SetRolloutPause(false)
+			Expect(requestHandler.rolloutResumed).To(BeEmpty())
+		})
+		It("Pauses the rollout with the global DeployConfig CR", func() {
+			mockCtlr := newMockController()
+			mockCtlr.RequestHandler = requestHandler
+			mockCtlr.CISConfigCRKey = "kube-system/global-cm"
+			configCR := &cisapiv1.DeployConfig{}
+			configCR.Namespace = "kube-system"
+			configCR.Name = "global-cm"
+			configCR.Spec.AS3Config.RolloutPause = true
+			_, _ = mockCtlr.processConfigCR(configCR, false)
+			Expect(requestHandler.rolloutPaused).To(BeTrue())
+			_, _ = mockCtlr.processConfigCR(configCR, true)
+			Expect(requestHandler.rolloutPaused).To(BeFalse(), "Rollout paused after the CR is deleted")
+
+			mockCtlr.rolloutPause = true
+			configCR.Spec.AS3Config.RolloutPause = false
+			_, _ = mockCtlr.processConfigCR(configCR, false)
+			Expect(requestHandler.rolloutPaused).To(BeTrue(), "Rollout not paused by the rollout-pause flag")
+		})
 	})
 
 	Describe("Agent", func() {
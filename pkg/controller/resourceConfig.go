@@ -17,16 +17,20 @@
 package controller
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
 	"net"
+	"net/url"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/cache"
 
 	routeapi "github.com/openshift/api/route/v1"
@@ -34,8 +38,12 @@ import (
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// bigIPPathRegex matches the path of a BIG-IP object in a partition and an optional folder e.g. /Common/name
+var bigIPPathRegex = regexp.MustCompile(`^(/[a-zA-Z0-9_.-]+){2,3}$`)
+
 // NewResourceStore is Constructor for ResourceStore
 func NewResourceStore() *ResourceStore {
 	var rs ResourceStore
@@ -187,6 +195,32 @@ func (ctlr *Controller) virtualPorts(input interface{}) []portStruct {
 	switch input.(type) {
 	case *cisapiv1.VirtualServer:
 		vs := input.(*cisapiv1.VirtualServer)
+		// SIP virtual listens only on the SIP port, the port is TLS terminated for secure SIP
+		if vs.Spec.Protocol == SIP {
+			sip := portStruct{
+				protocol: HTTP,
+				port:     getSIPPort(vs),
+			}
+			if vs.Spec.SIP != nil && vs.Spec.SIP.Secure && len(vs.Spec.TLSProfileName) != 0 {
+				sip.protocol = HTTPS
+			}
+			return []portStruct{sip}
+		}
+		// MQTT virtual listens only on the MQTT port
+		if vs.Spec.Protocol == MQTT {
+			mqtt := portStruct{
+				protocol: HTTP,
+				port:     DefaultMQTTPort,
+			}
+			if vs.Spec.VirtualServerHTTPPort != 0 {
+				mqtt.port = vs.Spec.VirtualServerHTTPPort
+			}
+			return []portStruct{mqtt}
+		}
+		// SCTP virtual listens only on the virtualServerHTTPPort, there is no default SCTP port
+		if vs.Spec.Protocol == SCTP {
+			return []portStruct{{protocol: HTTP, port: vs.Spec.VirtualServerHTTPPort}}
+		}
 		if vs.Spec.VirtualServerHTTPPort != 0 {
 			http.port = vs.Spec.VirtualServerHTTPPort
 		}
@@ -205,6 +239,14 @@ func (ctlr *Controller) virtualPorts(input interface{}) []portStruct {
 	return ports
 }
 
+// getSIPPort returns the port of the SIP VirtualServer
+func getSIPPort(vs *cisapiv1.VirtualServer) int32 {
+	if vs.Spec.SIP != nil && vs.Spec.SIP.Port != 0 {
+		return vs.Spec.SIP.Port
+	}
+	return DefaultSIPPort
+}
+
 // format the virtual server name for an VirtualServer
 func formatVirtualServerName(ip string, port int32) string {
 	// Strip any bracket characters; replace special characters ". : /"
@@ -451,11 +493,26 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 		namespace: vs.Namespace,
 		kind:      VirtualServer,
 	}
+	// the pools of the VirtualServers translated from an Ingress refer the Ingress, so that it is processed again
+	// on the updates of their services
+	if rsCfg.MetaData.baseResources[vs.Namespace+"/"+vs.Name] == Ingress {
+		rsRef.kind = Ingress
+	}
 	framedPools := make(map[string]struct{})
 	///TODO: get bigipLabel from cr resource or service address cr resource
 	//	//Phase1 setting bigipLabel to default
 	bigipLabel := BigIPLabel
+	podSelector := getPoolPodSelector(vs)
+	crossNamespaceServices := getCrossNamespaceServices(vs)
+	libraryMonitors, err := ctlr.getLibraryMonitors(vs)
+	if err != nil {
+		return err
+	}
 	for _, pl := range vs.Spec.Pools {
+		// Service of another namespace referenced by the cross namespace service annotation
+		if namespace, ok := crossNamespaceServices[pl.Service]; ok && pl.ServiceNamespace == "" {
+			pl.ServiceNamespace = namespace
+		}
 		//Fetch service backends with weights for pool
 		backendSvcs := ctlr.GetPoolBackends(&pl)
 		for _, SvcBackend := range backendSvcs {
@@ -486,6 +543,11 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 				ReselectTries:     pl.ReselectTries,
 				ServiceDownAction: pl.ServiceDownAction,
 				Cluster:           SvcBackend.Cluster, // In all modes other than ratio, the cluster is ""
+				PodSelector:       podSelector,
+			}
+			// Check that the NetworkPolicies of the service namespace allow the BIG-IP traffic
+			if ctlr.bigipSourceIP != "" && svcNamespace != vs.Namespace && SvcBackend.Cluster == "" {
+				pool.NetworkPolicyDenied = !ctlr.checkCrossNamespaceService(vs, svcNamespace, SvcBackend.Name)
 			}
 
 			if ctlr.multiClusterMode != "" {
@@ -554,7 +616,15 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 					ctlr.createVirtualServerMonitor(monitor, &pool, rsCfg, formatPort, vs.Spec.Host, pl.Path,
 						vs.ObjectMeta.Namespace+"/"+vs.ObjectMeta.Name, SvcBackend.Cluster)
 				}
+			} else {
+				// Pools without monitors use the library monitors referenced by annotation
+				for _, monitor := range libraryMonitors {
+					ctlr.createVirtualServerMonitor(monitor, &pool, rsCfg, pl.ServicePort, vs.Spec.Host, pl.Path,
+						vs.ObjectMeta.Namespace+"/"+vs.ObjectMeta.Name, SvcBackend.Cluster)
+				}
 			}
+			ctlr.createServiceExternalMonitor(&pool, rsCfg)
+			ctlr.createServiceLDAPMonitor(&pool, rsCfg)
 			pools = append(pools, pool)
 			if tlsTermination != "" {
 				//Handle AB datagroup for secure virtualserver
@@ -597,102 +667,1559 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 			}
 		}
 	}
-
-	rsCfg.Pools = append(rsCfg.Pools, pools...)
-
-	// handle the default pool for virtual
-	ctlr.handleDefaultPool(rsCfg, vs, rsRef)
-
-	// set the SNAT policy to auto if it's not defined by end user
-	if vs.Spec.SNAT == "" {
-		if rsCfg.Virtual.SNAT == "" {
-			rsCfg.Virtual.SNAT = snat
+
+	rsCfg.Pools = append(rsCfg.Pools, pools...)
+
+	// handle the default pool for virtual
+	ctlr.handleDefaultPool(rsCfg, vs, rsRef)
+
+	// set the SNAT policy to auto if it's not defined by end user
+	if vs.Spec.SNAT == "" {
+		if rsCfg.Virtual.SNAT == "" {
+			rsCfg.Virtual.SNAT = snat
+		}
+	} else {
+		rsCfg.Virtual.SNAT = vs.Spec.SNAT
+	}
+	// Attach the SNATPool referenced by snat
+	ctlr.handleVirtualServerSNATPool(rsCfg, vs)
+
+	if len(rsCfg.ServiceAddress) == 0 {
+		for _, sa := range vs.Spec.ServiceIPAddress {
+			rsCfg.ServiceAddress = append(rsCfg.ServiceAddress, ServiceAddress(sa))
+		}
+	}
+
+	// set the WAF policy
+	if vs.Spec.WAF != "" {
+		rsCfg.Virtual.WAF = vs.Spec.WAF
+	}
+
+	// set the ConnectionMirroring
+	if vs.Spec.ConnectionMirroring != "" {
+		rsCfg.Virtual.ConnectionMirroring = vs.Spec.ConnectionMirroring
+	}
+	//Attach allowVlans.
+	if len(vs.Spec.AllowVLANs) > 0 {
+		rsCfg.Virtual.AllowVLANs = vs.Spec.AllowVLANs
+	}
+	if vs.Spec.PersistenceProfile != "" {
+		rsCfg.Virtual.PersistenceProfile = vs.Spec.PersistenceProfile
+	}
+
+	if len(vs.Spec.Profiles.TCP.Client) > 0 || len(vs.Spec.Profiles.TCP.Server) > 0 {
+		rsCfg.Virtual.TCP.Client = vs.Spec.Profiles.TCP.Client
+		rsCfg.Virtual.TCP.Server = vs.Spec.Profiles.TCP.Server
+	}
+
+	if len(vs.Spec.Profiles.HTTP2.Client) > 0 || len(vs.Spec.Profiles.HTTP2.Server) > 0 {
+		rsCfg.Virtual.HTTP2.Client = vs.Spec.Profiles.HTTP2.Client
+		rsCfg.Virtual.HTTP2.Server = vs.Spec.Profiles.HTTP2.Server
+	}
+
+	if vs.Spec.DOS != "" {
+		rsCfg.Virtual.ProfileDOS = vs.Spec.DOS
+	}
+
+	if len(vs.Spec.AllowSourceRange) > 0 {
+		rsCfg.Virtual.AllowSourceRange = vs.Spec.AllowSourceRange
+	}
+
+	if vs.Spec.BotDefense != "" {
+		rsCfg.Virtual.ProfileBotDefense = vs.Spec.BotDefense
+	}
+
+	// raw TCP/UDP virtual with optional custom TCP/UDP profile
+	switch vs.Spec.Protocol {
+	case TCP:
+		rsCfg.Virtual.Protocol = TCP
+		if profile, ok := vs.Annotations[TCPProfileAnnotation]; ok {
+			rsCfg.Virtual.TCP.Client = profile
+		}
+	case UDP:
+		rsCfg.Virtual.Protocol = UDP
+		if profile, ok := vs.Annotations[UDPProfileAnnotation]; ok {
+			rsCfg.Virtual.Profiles = append(rsCfg.Virtual.Profiles, ProfileRef{
+				Name:         profile,
+				Context:      "udp",
+				BigIPProfile: true,
+			})
+		}
+	case SIP:
+		// SIP is handled by a UDP service with the SIP profile attached, secure SIP runs over TCP
+		rsCfg.Virtual.Protocol = UDP
+		if vs.Spec.SIP != nil && (vs.Spec.SIP.Transport == TCP || vs.Spec.SIP.Secure) {
+			rsCfg.Virtual.Protocol = TCP
+		}
+		rsCfg.Virtual.SIP = true
+		if vs.Spec.SIP != nil && vs.Spec.SIP.DialogAware && rsCfg.Virtual.PersistenceProfile == "" {
+			rsCfg.Virtual.PersistenceProfile = SIPPersistenceMethod
+		}
+	case MQTT:
+		// MQTT runs over TCP with the MQTT protocol enabled
+		rsCfg.Virtual.Protocol = TCP
+		rsCfg.Virtual.MQTT = true
+	case SCTP:
+		// SCTP is handled by a SCTP service with the SCTP profile attached
+		rsCfg.Virtual.Protocol = SCTP
+		sctp := &SCTPProfile{}
+		if vs.Spec.SCTP != nil {
+			sctp.HeartbeatInterval = vs.Spec.SCTP.HeartbeatInterval
+			sctp.MaxInitRetries = vs.Spec.SCTP.MaxInitRetries
+			sctp.CookieExpiry = vs.Spec.SCTP.CookieExpiry
+		}
+		rsCfg.Virtual.SCTP = sctp
+	}
+	// raw IP virtual for the other IP protocols
+	if vs.Spec.Protocol == "" && vs.Annotations[ProtocolAnnotation] == ProtocolOther {
+		number, err := parseIPProtocolNumber(vs.Annotations[IPProtocolNumberAnnotation])
+		if err != nil {
+			log.Errorf("Invalid %v annotation in VirtualServer %v/%v: %v", IPProtocolNumberAnnotation,
+				vs.Namespace, vs.Name, err)
+		} else {
+			rsCfg.Virtual.Protocol = ProtocolOther
+			rsCfg.Virtual.IpProtocol = strconv.Itoa(number)
+		}
+	}
+	// forwarding virtual routing the traffic transparently
+	handleVirtualServerForwarding(rsCfg, vs)
+
+	// Attach the address lists referenced by annotations
+	ctlr.handleVirtualServerAddressLists(rsCfg, vs)
+
+	// Allow the virtual only from the ipBlocks of the NetworkPolicies of its pool services
+	ctlr.handleVirtualServerNetworkPolicies(rsCfg, vs, pools)
+
+	// Attach the port lists referenced by annotation
+	ctlr.handleVirtualServerPortLists(rsCfg, vs)
+
+	// Attach the NAT policy referenced by annotation
+	ctlr.handleVirtualServerNATPolicy(rsCfg, vs)
+
+	// Log the security events to the Log Publisher referenced by annotation
+	ctlr.handleVirtualServerLogPublisher(rsCfg, vs)
+
+	// Handle the X-Forwarded-For header configuration
+	handleVirtualServerXFF(rsCfg, vs, passthroughVS)
+
+	// Handle the rate limiting configuration
+	handleVirtualServerRateLimit(rsCfg, vs)
+
+	// Handle the URL rewrite profile configuration
+	handleVirtualServerRewrite(rsCfg, vs, passthroughVS)
+
+	// Handle the connection multiplexing configuration
+	handleVirtualServerMultiplex(rsCfg, vs, passthroughVS)
+
+	// Handle the stream profile configuration
+	handleVirtualServerStream(rsCfg, vs)
+
+	handleVirtualServerBotDefense(rsCfg, vs, passthroughVS)
+
+	// Handle the DoS profile configuration
+	handleVirtualServerDOS(rsCfg, vs)
+
+	// Handle the protocol inspection profile configuration
+	handleVirtualServerProtocolInspection(rsCfg, vs)
+
+	handleVirtualServerHTML(rsCfg, vs, passthroughVS)
+
+	// Handle the request and response adapt profiles
+	handleVirtualServerAdapt(rsCfg, vs, passthroughVS)
+	handleVirtualServerICAP(rsCfg, vs, passthroughVS)
+
+	// Handle the WebSocket profile
+	handleVirtualServerWebSocket(rsCfg, vs, passthroughVS)
+
+	// Handle the APM access profile
+	ctlr.handleVirtualServerAccessProfile(rsCfg, vs, passthroughVS)
+
+	// Handle the TCP analytics profile configuration
+	handleVirtualServerTCPAnalytics(rsCfg, vs)
+
+	// Handle the Diameter profile configuration
+	handleVirtualServerDiameter(rsCfg, vs)
+
+	// Handle the RADIUS profile configuration
+	handleVirtualServerRadius(rsCfg, vs)
+
+	// Handle the HTTP compression profile configuration
+	handleVirtualServerHTTPCompression(rsCfg, vs, passthroughVS)
+
+	// Handle the connection-directed load balancing strategy
+	handleVirtualServerLBStrategy(rsCfg, vs, pools)
+
+	// Handle the AS3 Tenant settings of the partition
+	handleVirtualServerTenantSettings(rsCfg, vs)
+	ctlr.handleVirtualServerAS3LogLevel(rsCfg, vs)
+
+	// Handle the iFiles referenced by the iRules
+	ctlr.handleVirtualServerIFiles(rsCfg, vs)
+
+	if vs.Spec.TrafficClassification {
+		rsCfg.Virtual.ProfileClassification = DefaultClassificationProfile
+		if profile, ok := vs.Annotations[ClassificationProfileAnnotation]; ok {
+			if strings.HasPrefix(profile, "/") {
+				rsCfg.Virtual.ProfileClassification = profile
+			} else {
+				log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a BIG-IP profile path, using %v",
+					profile, ClassificationProfileAnnotation, vs.Namespace, vs.Name, DefaultClassificationProfile)
+			}
+		}
+	}
+
+	if vs.Spec.ProfileMultiplex != "" {
+		rsCfg.Virtual.ProfileMultiplex = vs.Spec.ProfileMultiplex
+	}
+	// check if custom http port set on virtual
+	if vs.Spec.VirtualServerHTTPPort != 0 {
+		httpPort = vs.Spec.VirtualServerHTTPPort
+	}
+	// Do not Create Virtual Server L7 Forwarding policies if HTTPTraffic is set to None or Redirect
+	if len(vs.Spec.TLSProfileName) > 0 &&
+		rsCfg.Virtual.VirtualAddress.Port == httpPort &&
+		(vs.Spec.HTTPTraffic == TLSNoInsecure || vs.Spec.HTTPTraffic == TLSRedirectInsecure) {
+		return nil
+	}
+
+	// TCP/UDP virtual forwards the traffic to the default pool as L7 policies are not applicable
+	if isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		if rsCfg.Virtual.PoolName == "" && len(pools) > 0 {
+			rsCfg.Virtual.PoolName = pools[0].Name
+		}
+		if len(pools) > 1 {
+			log.Warningf("Only the first pool is used for %v VirtualServer %v/%v", rsCfg.Virtual.Protocol, vs.Namespace, vs.Name)
+		}
+	}
+
+	// skip the policy creation for passthrough termination and TCP/UDP virtual
+	if !passthroughVS && !isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		rules = ctlr.prepareVirtualServerRules(vs, rsCfg)
+		if rules == nil {
+			return fmt.Errorf("failed to create LTM Rules")
+		}
+
+		policyName := formatPolicyName(vs.Spec.Host, vs.Spec.HostGroup, rsCfg.Virtual.Name)
+
+		rsCfg.AddRuleToPolicy(policyName, vs.Namespace, rules)
+	}
+
+	// the passthrough VirtualServers sharing the HTTPS virtual are routed with the server name of the client hello
+	sniRouting, _ := strconv.ParseBool(vs.Annotations[SNIRoutingAnnotation])
+	if passthroughVS && sniRouting && rsCfg.Virtual.VirtualAddress.Port != httpPort {
+		rules = ctlr.prepareVirtualServerSNIRules(vs)
+		if rules == nil {
+			return fmt.Errorf("failed to create SNI routing rules")
+		}
+
+		policyName := formatPolicyName(vs.Spec.Host, vs.Spec.HostGroup, rsCfg.Virtual.Name)
+
+		rsCfg.AddRuleToPolicy(policyName, vs.Namespace, rules)
+	}
+
+	// Attach user specified iRules
+	if len(vs.Spec.IRules) > 0 {
+		rsCfg.Virtual.IRules = append(rsCfg.Virtual.IRules, vs.Spec.IRules...)
+	}
+
+	// Append all the hosts from a host group/ single host
+	if vs.Spec.Host != "" {
+		rsCfg.MetaData.hosts = append(rsCfg.MetaData.hosts, vs.Spec.Host)
+	}
+	return nil
+}
+
+// handleVirtualServerAddressLists attaches the allow/deny AddressLists referenced by the VirtualServer annotations
+func (ctlr *Controller) handleVirtualServerAddressLists(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	// deny lists are processed first so that the drop rules take precedence
+	for _, ref := range []struct{ annotation, action string }{
+		{DenyAddressListAnnotation, AddressListDeny},
+		{AllowAddressListAnnotation, AddressListAllow},
+	} {
+		names, ok := vs.Annotations[ref.annotation]
+		if !ok {
+			continue
+		}
+		for _, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" || rsCfg.Virtual.hasAddressList(name) {
+				continue
+			}
+			adl, err := ctlr.getAddressList(vs.Namespace, name)
+			if err != nil {
+				log.Errorf("Unable to attach AddressList to VirtualServer %v/%v: %v", vs.Namespace, vs.Name, err)
+				continue
+			}
+			if adl.Spec.Type != "" && adl.Spec.Type != ref.action {
+				log.Errorf("AddressList %v/%v of type %v can not be referenced with %v annotation in VirtualServer %v",
+					adl.Namespace, adl.Name, adl.Spec.Type, ref.annotation, vs.Name)
+				continue
+			}
+			var addresses []string
+			for _, address := range adl.Spec.Addresses {
+				if _, _, err := net.ParseCIDR(address); err != nil {
+					log.Warningf("Skipping invalid CIDR %v in AddressList %v/%v", address, adl.Namespace, adl.Name)
+					continue
+				}
+				addresses = append(addresses, address)
+			}
+			if len(addresses) == 0 {
+				log.Errorf("No valid addresses found in AddressList %v/%v", adl.Namespace, adl.Name)
+				continue
+			}
+			rsCfg.Virtual.AddressLists = append(rsCfg.Virtual.AddressLists, AddressListRef{
+				Name:      name,
+				Addresses: addresses,
+				Action:    ref.action,
+			})
+		}
+	}
+}
+
+// handleVirtualServerPortLists attaches the allow PortLists referenced by the VirtualServer annotation
+func (ctlr *Controller) handleVirtualServerPortLists(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	names, ok := vs.Annotations[AllowPortListAnnotation]
+	if !ok {
+		return
+	}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || rsCfg.Virtual.hasPortList(name) {
+			continue
+		}
+		prt, err := ctlr.getPortList(vs.Namespace, name)
+		if err != nil {
+			log.Errorf("Unable to attach PortList to VirtualServer %v/%v: %v", vs.Namespace, vs.Name, err)
+			continue
+		}
+		portList := PortListRef{Name: name}
+		for _, port := range prt.Spec.Ports {
+			if port < 0 || port > MaxPortNumber {
+				log.Warningf("Skipping invalid port %v in PortList %v/%v", port, prt.Namespace, prt.Name)
+				continue
+			}
+			portList.Ports = append(portList.Ports, port)
+		}
+		for _, portRange := range prt.Spec.PortRanges {
+			if portRange.Start < 0 || portRange.End > MaxPortNumber || portRange.Start > portRange.End {
+				log.Warningf("Skipping invalid port range %v-%v in PortList %v/%v", portRange.Start, portRange.End,
+					prt.Namespace, prt.Name)
+				continue
+			}
+			portList.PortRanges = append(portList.PortRanges, fmt.Sprintf("%d-%d", portRange.Start, portRange.End))
+		}
+		if len(portList.Ports) == 0 && len(portList.PortRanges) == 0 {
+			log.Errorf("No valid ports found in PortList %v/%v", prt.Namespace, prt.Name)
+			continue
+		}
+		rsCfg.Virtual.PortLists = append(rsCfg.Virtual.PortLists, portList)
+	}
+}
+
+// handleVirtualServerSNATPool attaches the SNATPool referenced by the snat of the VirtualServer
+func (ctlr *Controller) handleVirtualServerSNATPool(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	if !isSNATPoolName(vs.Spec.SNAT) {
+		return
+	}
+	snatPool, err := ctlr.getVirtualServerSNATPool(vs)
+	if err != nil {
+		log.Errorf("Unable to attach SNATPool to VirtualServer %v/%v, using %v snat: %v", vs.Namespace, vs.Name,
+			DEFAULT_SNAT, err)
+		rsCfg.Virtual.SNAT = DEFAULT_SNAT
+		return
+	}
+	rsCfg.Virtual.SNATPool = snatPool
+}
+
+// getVirtualServerSNATPool returns the SNATPool referenced by the snat of the VirtualServer with its valid
+// addresses, an error is returned when the SNATPool can't be attached
+func (ctlr *Controller) getVirtualServerSNATPool(vs *cisapiv1.VirtualServer) (*SNATPoolRef, error) {
+	snat, err := ctlr.getSNATPool(vs.Namespace, vs.Spec.SNAT)
+	if err != nil {
+		return nil, err
+	}
+	snatPool := &SNATPoolRef{Name: snat.Name}
+	for _, address := range snat.Spec.Addresses {
+		if net.ParseIP(address) == nil {
+			log.Warningf("Skipping invalid address %v in SNATPool %v/%v", address, snat.Namespace, snat.Name)
+			continue
+		}
+		snatPool.Addresses = append(snatPool.Addresses, address)
+	}
+	if len(snatPool.Addresses) == 0 {
+		return nil, fmt.Errorf("no valid addresses found in SNATPool %v/%v", snat.Namespace, snat.Name)
+	}
+	return snatPool, nil
+}
+
+// isSNATPoolName checks whether the snat refers a SNATPool CR, rather than auto, none, self or
+// the path of a SNAT pool on BIG-IP
+func isSNATPoolName(snat string) bool {
+	switch snat {
+	case "", DEFAULT_SNAT, SNATNone, SNATSelf:
+		return false
+	}
+	return !strings.HasPrefix(snat, "/")
+}
+
+// handleVirtualServerNATPolicy attaches the NATPolicy referenced by the VirtualServer annotation
+func (ctlr *Controller) handleVirtualServerNATPolicy(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	name, ok := vs.Annotations[NATPolicyAnnotation]
+	if !ok || name == "" {
+		return
+	}
+	nat, err := ctlr.getNATPolicy(vs.Namespace, name)
+	if err != nil {
+		log.Errorf("Unable to attach NATPolicy to VirtualServer %v/%v: %v", vs.Namespace, vs.Name, err)
+		return
+	}
+	// the source of the traffic is translated either by the SNAT pool or by the NAT rules
+	if rsCfg.Virtual.SNAT != DEFAULT_SNAT && rsCfg.Virtual.SNAT != "none" && rsCfg.Virtual.SNAT != "" {
+		log.Errorf("NATPolicy %v/%v conflicts with the SNAT pool %v of VirtualServer %v/%v", nat.Namespace,
+			nat.Name, rsCfg.Virtual.SNAT, vs.Namespace, vs.Name)
+		return
+	}
+	natPolicy := &NATPolicyRef{Name: name}
+	for _, rule := range nat.Spec.Rules {
+		if err := validateNATRule(rule); err != nil {
+			log.Errorf("Invalid rule %v in NATPolicy %v/%v: %v", rule.Name, nat.Namespace, nat.Name, err)
+			return
+		}
+		if rule.Protocol == "" {
+			rule.Protocol = "any"
+		}
+		natPolicy.Rules = append(natPolicy.Rules, rule)
+	}
+	if len(natPolicy.Rules) == 0 {
+		log.Errorf("No rules found in NATPolicy %v/%v", nat.Namespace, nat.Name)
+		return
+	}
+	rsCfg.Virtual.NATPolicy = natPolicy
+}
+
+// handleVirtualServerLogPublisher sets the BIG-IP Log Publisher of the VirtualServer annotation or the default one
+func (ctlr *Controller) handleVirtualServerLogPublisher(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	publisher, ok := vs.Annotations[LogPublisherAnnotation]
+	if !ok {
+		publisher = ctlr.defaultLogPublisher
+	}
+	if publisher == "" {
+		return
+	}
+	if !strings.HasPrefix(publisher, "/") {
+		publisher = JoinBigipPath(CommonPartition, publisher)
+	}
+	if strings.Count(publisher, "/") != 2 || strings.HasSuffix(publisher, "/") {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a BIG-IP path like "+
+			"/Common/publisher", publisher, LogPublisherAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	rsCfg.Virtual.LogPublisher = publisher
+}
+
+// validateNATRule validates the addresses and the protocol of a NAT rule
+func validateNATRule(rule cisapiv1.NATRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	for _, address := range []string{rule.Source, rule.Destination} {
+		if address == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(address); err != nil && net.ParseIP(address) == nil {
+			return fmt.Errorf("%v should be an IP address or CIDR", address)
+		}
+	}
+	if net.ParseIP(rule.Translation) == nil {
+		return fmt.Errorf("translation %v should be an IP address", rule.Translation)
+	}
+	switch rule.Protocol {
+	case "", "any", TCP, UDP:
+	default:
+		return fmt.Errorf("protocol %v should be one of tcp, udp or any", rule.Protocol)
+	}
+	return nil
+}
+
+// handleVirtualServerXFF configures the X-Forwarded-For header insertion based on VirtualServer annotation
+func handleVirtualServerXFF(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	xff, ok := vs.Annotations[XFFInsertAnnotation]
+	if !ok {
+		return
+	}
+	// X-Forwarded-For header can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("%v annotation is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			XFFInsertAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	// the HTTP profile and the iRule of the header setting are declared together with the service
+	switch xff {
+	case XFFInsertEnabled, XFFInsertReplace, XFFInsertDisabled:
+	default:
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, supported values are %v, %v and %v",
+			xff, XFFInsertAnnotation, vs.Namespace, vs.Name, XFFInsertEnabled, XFFInsertReplace, XFFInsertDisabled)
+		return
+	}
+	rsCfg.Virtual.XFFInsert = xff
+}
+
+// isLayer4Protocol checks whether the virtual handles raw TCP/UDP/SCTP traffic without HTTP processing
+func isLayer4Protocol(protocol string) bool {
+	return protocol == TCP || protocol == UDP || protocol == SCTP || protocol == ProtocolOther
+}
+
+// handleVirtualServerForwarding sets the forwarding type and the mirroring of a forwarding VirtualServer
+func handleVirtualServerForwarding(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	if forwarding, _ := strconv.ParseBool(vs.Annotations[ForwardingAnnotation]); !forwarding {
+		return
+	}
+	forwardingType, err := parseForwardingType(vs.Annotations[ForwardingTypeAnnotation])
+	if err != nil {
+		log.Errorf("Invalid %v annotation in VirtualServer %v/%v: %v", ForwardingTypeAnnotation, vs.Namespace,
+			vs.Name, err)
+		return
+	}
+	rsCfg.Virtual.Forwarding = &ForwardingConfig{Type: forwardingType}
+	if value, ok := vs.Annotations[ForwardingMirroringAnnotation]; ok {
+		mirroring, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Errorf("Invalid %v annotation in VirtualServer %v/%v: %v", ForwardingMirroringAnnotation,
+				vs.Namespace, vs.Name, err)
+		}
+		rsCfg.Virtual.Forwarding.Mirroring = mirroring
+	}
+}
+
+// parseForwardingType parses the forwarding type of a forwarding virtual, ip by default
+func parseForwardingType(value string) (string, error) {
+	switch value {
+	case "":
+		return ForwardingTypeIP, nil
+	case ForwardingTypeIP, ForwardingTypeL2:
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid forwarding type %v, supported types are %v and %v", value, ForwardingTypeIP,
+		ForwardingTypeL2)
+}
+
+// parseIPProtocolNumber parses the IP protocol number of a virtual for the other IP protocols
+func parseIPProtocolNumber(value string) (int, error) {
+	if value == "" {
+		return 0, fmt.Errorf("IP protocol number is required")
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 || number > MaxIPProtocolNumber {
+		return 0, fmt.Errorf("invalid IP protocol number %v, supported range is 0-%v", value, MaxIPProtocolNumber)
+	}
+	if number == TCPProtocolNumber || number == UDPProtocolNumber {
+		return 0, fmt.Errorf("IP protocol number %v is handled by the tcp or udp protocol of VirtualServer", number)
+	}
+	return number, nil
+}
+
+// handleVirtualServerRateLimit configures the rate limiting of the virtual based on VirtualServer annotations
+func handleVirtualServerRateLimit(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	rateValue, ok := vs.Annotations[RateLimitRPSAnnotation]
+	if !ok {
+		return
+	}
+	rateLimit := RateLimit{Unit: RateLimitUnitRPS}
+	if unit, ok := vs.Annotations[RateLimitUnitAnnotation]; ok {
+		rateLimit.Unit = unit
+	}
+	var minRate, maxRate int64
+	switch rateLimit.Unit {
+	case RateLimitUnitRPS:
+		minRate, maxRate = 1, MaxRateLimitRPS
+	case RateLimitUnitBPS:
+		minRate, maxRate = MinRateLimitBPS, MaxRateLimitBPS
+	default:
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, supported values are %v and %v",
+			rateLimit.Unit, RateLimitUnitAnnotation, vs.Namespace, vs.Name, RateLimitUnitRPS, RateLimitUnitBPS)
+		return
+	}
+	rate, err := strconv.ParseInt(rateValue, 10, 64)
+	if err != nil || rate < minRate || rate > maxRate {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, supported range is %v-%v %v",
+			rateValue, RateLimitRPSAnnotation, vs.Namespace, vs.Name, minRate, maxRate, rateLimit.Unit)
+		return
+	}
+	rateLimit.Rate = rate
+	if burstValue, ok := vs.Annotations[RateLimitBurstAnnotation]; ok {
+		// BIG-IP enforces a hard limit, so the burst allowance is added on top of the rate
+		burst, err := strconv.ParseInt(burstValue, 10, 64)
+		if err != nil || burst < 1 || rate+burst > maxRate {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, burst should be positive and "+
+				"rate plus burst should not exceed %v %v", burstValue, RateLimitBurstAnnotation, vs.Namespace, vs.Name,
+				maxRate, rateLimit.Unit)
+			return
+		}
+		rateLimit.Burst = burst
+	}
+	rsCfg.Virtual.RateLimit = rateLimit
+}
+
+// handleVirtualServerMultiplex configures the connection multiplexing (OneConnect) profile based on
+// VirtualServer annotations
+func handleVirtualServerMultiplex(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	enableAnnotation := ConnectionMultiplexAnnotation
+	if vs.Annotations[OneConnectAnnotation] == "true" {
+		enableAnnotation = OneConnectAnnotation
+	} else if vs.Annotations[ConnectionMultiplexAnnotation] != "true" {
+		return
+	}
+	if vs.Spec.ProfileMultiplex != "" {
+		log.Warningf("%v annotation is ignored as profileMultiplex is configured in VirtualServer %v/%v",
+			enableAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	// Multiplexing requires the HTTP profile, so it can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("%v annotation is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			enableAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	multiplex := &MultiplexProfile{
+		MaxConnections:     DefaultMultiplexMaxConnections,
+		MaxConnectionReuse: DefaultMultiplexMaxConnectionReuse,
+		MaxConnectionAge:   DefaultMultiplexMaxConnectionAge,
+		SourceMask:         DefaultMultiplexSourceMask,
+	}
+	for _, setting := range []struct {
+		annotations []string
+		limit       *int64
+		min         int64
+	}{
+		{[]string{MultiplexMaxConnectionsAnnotation, OneConnectMaxConnectionsAnnotation}, &multiplex.MaxConnections, 1},
+		{[]string{MultiplexMaxConnectionReuseAnnotation, OneConnectMaxConnectionReuseAnnotation}, &multiplex.MaxConnectionReuse, 1},
+		{[]string{MultiplexMaxConnectionAgeAnnotation, OneConnectMaxConnectionAgeAnnotation}, &multiplex.MaxConnectionAge, 0},
+	} {
+		for _, annotation := range setting.annotations {
+			value, ok := vs.Annotations[annotation]
+			if !ok {
+				continue
+			}
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed < setting.min || parsed > MaxMultiplexLimit {
+				log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, supported range is %v-%v",
+					value, annotation, vs.Namespace, vs.Name, setting.min, MaxMultiplexLimit)
+				return
+			}
+			*setting.limit = parsed
+		}
+	}
+	if multiplex.MaxConnectionAge == 0 {
+		log.Warningf("Maximum connection age is 0 in VirtualServer %v/%v, server connections are not reused "+
+			"though %v annotation is enabled", vs.Namespace, vs.Name, enableAnnotation)
+	}
+	if sourceMask, ok := vs.Annotations[MultiplexSourceMaskAnnotation]; ok {
+		if net.ParseIP(sourceMask) == nil {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be an IP mask",
+				sourceMask, MultiplexSourceMaskAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		multiplex.SourceMask = sourceMask
+	}
+	rsCfg.Virtual.Multiplex = multiplex
+}
+
+// handleVirtualServerStream configures the stream profile based on VirtualServer annotations
+func handleVirtualServerStream(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	profile, ok := vs.Annotations[StreamProfileAnnotation]
+	if !ok {
+		return
+	}
+	// Stream profile inspects the TCP payload, so it can not be used with UDP or other IP protocol virtual servers
+	if rsCfg.Virtual.Protocol == UDP || rsCfg.Virtual.Protocol == ProtocolOther {
+		log.Errorf("%v annotation is not supported with UDP or other IP protocol VirtualServer %v/%v",
+			StreamProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if !strings.HasPrefix(profile, "/") {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a BIG-IP profile path",
+			profile, StreamProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	stream := StreamProfile{Profile: profile}
+	for annotation, expr := range map[string]*string{
+		StreamSourceAnnotation: &stream.Source,
+		StreamTargetAnnotation: &stream.Target,
+	} {
+		value, ok := vs.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		if _, err := regexp.Compile(value); err != nil {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a valid regular expression: %v",
+				value, annotation, vs.Namespace, vs.Name, err)
+			return
+		}
+		*expr = value
+	}
+	if value, ok := vs.Annotations[StreamChunkSizeAnnotation]; ok {
+		chunkSize, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || chunkSize < 1 {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a positive integer",
+				value, StreamChunkSizeAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		stream.ChunkSize = chunkSize
+	}
+	rsCfg.Virtual.Stream = stream
+}
+
+// handleVirtualServerBotDefense configures the bot defense profile based on VirtualServer annotations
+func handleVirtualServerBotDefense(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	if vs.Annotations[BotDefenseAnnotation] != "true" {
+		return
+	}
+	if vs.Spec.BotDefense != "" {
+		log.Warningf("%v annotation is ignored as botDefense is configured in VirtualServer %v/%v",
+			BotDefenseAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	// Bot defense inspects HTTP requests, so it can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("%v annotation is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			BotDefenseAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	profile := DefaultBotDefenseProfile
+	if name, ok := vs.Annotations[BotDefenseProfileAnnotation]; ok {
+		if name == "" {
+			log.Errorf("Empty value for %v annotation in VirtualServer %v/%v",
+				BotDefenseProfileAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		profile = name
+		// profile names without a partition refer to the Common partition
+		if !strings.HasPrefix(profile, "/") {
+			profile = "/Common/" + profile
+		}
+	}
+	rsCfg.Virtual.ProfileBotDefense = profile
+}
+
+// handleVirtualServerProtocolInspection configures the protocol inspection profile based on VirtualServer annotations
+func handleVirtualServerProtocolInspection(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	value, ok := vs.Annotations[ProtocolInspectionAnnotation]
+	if !ok {
+		return
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+			value, ProtocolInspectionAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if !enabled {
+		return
+	}
+	profile := DefaultProtocolInspectionProfile
+	if name, ok := vs.Annotations[ProtocolInspectionProfileAnnotation]; ok {
+		if name == "" {
+			log.Errorf("Empty value for %v annotation in VirtualServer %v/%v",
+				ProtocolInspectionProfileAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		profile = name
+		// profile names without a partition refer to the Common partition
+		if !strings.HasPrefix(profile, "/") {
+			profile = "/Common/" + profile
+		}
+	}
+	rsCfg.Virtual.ProfileProtocolInspection = profile
+}
+
+// handleVirtualServerDOS configures the DoS profile of the VirtualServer with the cis.f5.com/dos-profile annotation,
+// a profile name creates the DOS_Profile with the thresholds of the DoS annotations and a path refers to an
+// existing DoS profile on BIG-IP
+func handleVirtualServerDOS(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	name, ok := vs.Annotations[DOSProfileAnnotation]
+	if !ok {
+		return
+	}
+	if vs.Spec.DOS != "" {
+		log.Warningf("%v annotation is ignored as dos is configured in VirtualServer %v/%v",
+			DOSProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if name == "" {
+		log.Errorf("Empty value for %v annotation in VirtualServer %v/%v", DOSProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if strings.HasPrefix(name, "/") {
+		rsCfg.Virtual.ProfileDOS = name
+		return
+	}
+	dos := &DOSProfile{Name: AS3NameFormatter(name), Vector: DefaultDOSVector}
+	if vector, ok := vs.Annotations[DOSVectorAnnotation]; ok {
+		if vector == "" {
+			log.Errorf("Empty value for %v annotation in VirtualServer %v/%v", DOSVectorAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		dos.Vector = vector
+	}
+	thresholds := []struct {
+		annotation string
+		value      *int64
+	}{
+		{DOSDetectThresholdAnnotation, &dos.DetectThreshold},
+		{DOSRateIncreaseThresholdAnnotation, &dos.RateIncreaseThreshold},
+		{DOSRateLimitAnnotation, &dos.RateLimit},
+	}
+	for _, threshold := range thresholds {
+		value, ok := vs.Annotations[threshold.annotation]
+		if !ok {
+			continue
+		}
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 1 || limit > MaxDOSThreshold {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be between 1 and %v",
+				value, threshold.annotation, vs.Namespace, vs.Name, MaxDOSThreshold)
+			return
+		}
+		*threshold.value = limit
+	}
+	rsCfg.Virtual.DOS = dos
+}
+
+// handleVirtualServerHTML configures the HTML profile based on VirtualServer annotations
+func handleVirtualServerHTML(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	profile, ok := vs.Annotations[HTMLProfileAnnotation]
+	if !ok {
+		return
+	}
+	// HTML content rewriting requires the HTTP profile, so it can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("%v annotation is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			HTMLProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if profile == "" {
+		log.Errorf("Empty value for %v annotation in VirtualServer %v/%v", HTMLProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	detection, detectionFound := vs.Annotations[HTMLContentDetectionAnnotation]
+	selection, selectionFound := vs.Annotations[HTMLContentSelectionAnnotation]
+	// profile paths refer to an existing profile on BIG-IP which can not be overridden
+	if strings.HasPrefix(profile, "/") {
+		if detectionFound || selectionFound {
+			log.Warningf("%v and %v annotations are ignored as %v refers to the BIG-IP profile %v in VirtualServer %v/%v",
+				HTMLContentDetectionAnnotation, HTMLContentSelectionAnnotation, HTMLProfileAnnotation, profile,
+				vs.Namespace, vs.Name)
+		}
+		rsCfg.Virtual.HTML = HTMLProfile{Profile: profile, BigIPProfile: true}
+		return
+	}
+	html := HTMLProfile{Profile: AS3NameFormatter(profile)}
+	if detectionFound {
+		enabled, err := strconv.ParseBool(detection)
+		if err != nil {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+				detection, HTMLContentDetectionAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		html.ContentDetectionEnabled = enabled
+	}
+	if selectionFound {
+		for _, contentType := range strings.Split(selection, ",") {
+			contentType = strings.TrimSpace(contentType)
+			if contentType == "" {
+				continue
+			}
+			if !strings.Contains(contentType, "/") {
+				log.Errorf("Invalid content type %v in %v annotation in VirtualServer %v/%v",
+					contentType, HTMLContentSelectionAnnotation, vs.Namespace, vs.Name)
+				return
+			}
+			html.ContentSelection = append(html.ContentSelection, contentType)
+		}
+	}
+	rsCfg.Virtual.HTML = html
+}
+
+// handleVirtualServerAdapt attaches the BIG-IP request and response adapt profiles of the VirtualServer annotations
+func handleVirtualServerAdapt(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	for annotation, profile := range map[string]*string{
+		RequestAdaptProfileAnnotation:  &rsCfg.Virtual.ProfileRequestAdapt,
+		ResponseAdaptProfileAnnotation: &rsCfg.Virtual.ProfileResponseAdapt,
+	} {
+		value, ok := vs.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		// adaptation works on the HTTP requests and responses, so it can only be handled on HTTP/HTTPS virtual servers
+		if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+			log.Errorf("%v annotation is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+				annotation, vs.Namespace, vs.Name)
+			continue
+		}
+		// adapt profiles are not created by CIS, so the value should refer to an existing BIG-IP profile
+		if !bigIPPathRegex.MatchString(value) {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a BIG-IP profile path e.g. /Common/requestadapt",
+				value, annotation, vs.Namespace, vs.Name)
+			continue
+		}
+		*profile = value
+	}
+}
+
+// handleVirtualServerICAP adapts the HTTP requests of the VirtualServer with the ICAP server of the
+// cis.f5.com/icap-service annotation
+func handleVirtualServerICAP(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	address, ok := vs.Annotations[ICAPServiceAnnotation]
+	if !ok {
+		return
+	}
+	// ICAP adapts the HTTP requests, so it can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("%v annotation is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			ICAPServiceAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if rsCfg.Virtual.ProfileRequestAdapt != "" {
+		log.Errorf("%v annotation can not be used with %v annotation in VirtualServer %v/%v",
+			ICAPServiceAnnotation, RequestAdaptProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if net.ParseIP(address) == nil {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be an IP address",
+			address, ICAPServiceAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	icap := &ICAPService{Address: address, Port: DefaultICAPPort, ServiceDownAction: "ignore"}
+	if value, ok := vs.Annotations[ICAPPortAnnotation]; ok {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be between 1 and 65535",
+				value, ICAPPortAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		icap.Port = int32(port)
+	}
+	if value, ok := vs.Annotations[ICAPAllowHTTP10Annotation]; ok {
+		allow, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+				value, ICAPAllowHTTP10Annotation, vs.Namespace, vs.Name)
+			return
+		}
+		icap.AllowHTTP10 = allow
+	}
+	if value, ok := vs.Annotations[ICAPServiceDownAnnotation]; ok {
+		switch value {
+		case ICAPServiceDownContinue:
+			icap.ServiceDownAction = "ignore"
+		case ICAPServiceDownDrop:
+			icap.ServiceDownAction = "drop"
+		default:
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be %v or %v",
+				value, ICAPServiceDownAnnotation, vs.Namespace, vs.Name, ICAPServiceDownContinue, ICAPServiceDownDrop)
+			return
+		}
+	}
+	rsCfg.Virtual.ICAP = icap
+}
+
+// handleVirtualServerAccessProfile configures the APM access profile of a VirtualServer
+// with the cis.f5.com/access-profile annotation
+func (ctlr *Controller) handleVirtualServerAccessProfile(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	value, ok := vs.Annotations[AccessProfileAnnotation]
+	if !ok {
+		return
+	}
+	if !ctlr.apmEnabled {
+		log.Warningf("%v annotation in VirtualServer %v/%v is ignored, use --apm-enabled to attach access profiles",
+			AccessProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	// access policies run on the HTTP requests, so they can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("%v annotation is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			AccessProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	// access profiles are not created by CIS, so the value should refer to an existing BIG-IP profile
+	if !bigIPPathRegex.MatchString(value) {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a BIG-IP profile path e.g. /Common/access",
+			value, AccessProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	rsCfg.Virtual.ProfileAccess = value
+}
+
+// handleVirtualServerWebSocket configures the WebSocket profile of a VirtualServer with webSocketEnabled
+func handleVirtualServerWebSocket(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	if !vs.Spec.WebSocketEnabled {
+		return
+	}
+	// WebSocket upgrades the HTTP connections, so it can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("webSocketEnabled is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			vs.Namespace, vs.Name)
+		return
+	}
+	webSocket := &WebSocketProfile{}
+	if ws := vs.Spec.WebSocket; ws != nil {
+		webSocket.Compression = ws.Compression
+		if ws.Masking != WebSocketMaskingAuto {
+			webSocket.Masking = ws.Masking
+		}
+	}
+	rsCfg.Virtual.WebSocket = webSocket
+}
+
+// handleVirtualServerTCPAnalytics configures the TCP analytics profile based on VirtualServer annotations
+func handleVirtualServerTCPAnalytics(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	value, ok := vs.Annotations[TCPAnalyticsAnnotation]
+	if !ok {
+		return
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+			value, TCPAnalyticsAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if !enabled {
+		return
+	}
+	// Analytics_TCP_Profile can only be attached to the TCP based services
+	if rsCfg.Virtual.Protocol == UDP || rsCfg.Virtual.Protocol == ProtocolOther {
+		log.Errorf("%v annotation is not supported with UDP or other IP protocol VirtualServer %v/%v",
+			TCPAnalyticsAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	tcpAnalytics := &TCPAnalyticsProfile{}
+	for annotation, setting := range map[string]*bool{
+		TCPAnalyticsCollectRemoteHostIPAnnotation: &tcpAnalytics.CollectRemoteHostIP,
+		TCPAnalyticsCollectNexthopAnnotation:      &tcpAnalytics.CollectNexthop,
+	} {
+		value, ok := vs.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		collect, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+				value, annotation, vs.Namespace, vs.Name)
+			return
+		}
+		*setting = collect
+	}
+	if _, ok := vs.Annotations[TCPAnalyticsByteDistributionAnnotation]; ok {
+		// byte distribution is not part of the AS3 Analytics_TCP_Profile schema
+		log.Warningf("%v annotation is ignored as byte distribution is not supported by AS3 Analytics_TCP_Profile in VirtualServer %v/%v",
+			TCPAnalyticsByteDistributionAnnotation, vs.Namespace, vs.Name)
+	}
+	rsCfg.Virtual.AnalyticsProfiles.TCPAnalyticsProfile = tcpAnalytics
+}
+
+// handleVirtualServerDiameter configures the Diameter profile of a TCP VirtualServer with the diameter-profile annotation
+func handleVirtualServerDiameter(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	value, ok := vs.Annotations[DiameterProfileAnnotation]
+	if !ok {
+		return
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+			value, DiameterProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if !enabled {
+		return
+	}
+	// Diameter runs over TCP, so the profile can only be attached to the TCP virtual servers
+	if rsCfg.Virtual.Protocol != TCP {
+		log.Errorf("%v annotation is supported only with TCP VirtualServer %v/%v",
+			DiameterProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	diameter := &DiameterProfile{
+		DestHost:  vs.Annotations[DiameterDestHostAnnotation],
+		DestRealm: vs.Annotations[DiameterDestRealmAnnotation],
+	}
+	if parent, ok := vs.Annotations[DiameterParentProfileAnnotation]; ok {
+		if !bigIPPathRegex.MatchString(parent) {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a BIG-IP profile path e.g. /Common/diameter-endpoint",
+				parent, DiameterParentProfileAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		diameter.ParentProfile = parent
+	}
+	// Gx is provisioned unless the applications are set, Gx and Sd are mutually exclusive on BIG-IP
+	diameter.SupportedApps = []string{"Gx"}
+	if apps, ok := vs.Annotations[DiameterSupportedAppsAnnotation]; ok {
+		diameter.SupportedApps = nil
+		seen := make(map[string]bool)
+		for _, app := range strings.Split(apps, ",") {
+			app = strings.TrimSpace(app)
+			if app != "Gx" && app != "Gy" && app != "Sd" {
+				log.Errorf("Invalid application %v in %v annotation in VirtualServer %v/%v, should be Gx, Gy or Sd",
+					app, DiameterSupportedAppsAnnotation, vs.Namespace, vs.Name)
+				return
+			}
+			if !seen[app] {
+				seen[app] = true
+				diameter.SupportedApps = append(diameter.SupportedApps, app)
+			}
+		}
+		if seen["Gx"] && seen["Sd"] {
+			log.Errorf("Gx and Sd applications of %v annotation in VirtualServer %v/%v are mutually exclusive",
+				DiameterSupportedAppsAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+	}
+	rsCfg.Virtual.Diameter = diameter
+}
+
+// handleVirtualServerRadius configures the RADIUS profile of a UDP VirtualServer with the radius-profile annotation
+func handleVirtualServerRadius(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	value, ok := vs.Annotations[RadiusProfileAnnotation]
+	if !ok {
+		return
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+			value, RadiusProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if !enabled {
+		return
+	}
+	// RADIUS runs over UDP on the authentication and accounting ports, unless other ports are allowed
+	if rsCfg.Virtual.Protocol != UDP || rsCfg.Virtual.SIP {
+		log.Errorf("%v annotation is supported only with UDP VirtualServer %v/%v",
+			RadiusProfileAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	port := vs.Spec.VirtualServerHTTPPort
+	if port == 0 {
+		port = DEFAULT_HTTP_PORT
+	}
+	allowedPorts := map[int32]struct{}{RadiusAuthPort: {}, RadiusAccountingPort: {}}
+	if ports, ok := vs.Annotations[RadiusPortsAnnotation]; ok {
+		for _, p := range strings.Split(ports, ",") {
+			allowedPort, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil || allowedPort < 1 || allowedPort > MaxPortNumber {
+				log.Errorf("Invalid port %v in %v annotation in VirtualServer %v/%v", p, RadiusPortsAnnotation,
+					vs.Namespace, vs.Name)
+				return
+			}
+			allowedPorts[int32(allowedPort)] = struct{}{}
+		}
+	}
+	if _, ok := allowedPorts[port]; !ok {
+		log.Errorf("%v annotation is supported only on RADIUS ports %v and %v, use %v to allow port %v "+
+			"in VirtualServer %v/%v", RadiusProfileAnnotation, RadiusAuthPort, RadiusAccountingPort,
+			RadiusPortsAnnotation, port, vs.Namespace, vs.Name)
+		return
+	}
+	radius := &RadiusProfile{PersistAttribute: vs.Annotations[RadiusPersistAttributeAnnotation]}
+	if code, err := strconv.Atoi(radius.PersistAttribute); err == nil && (code < 1 || code > 255) {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be a RADIUS attribute "+
+			"name or a code between 1 and 255", radius.PersistAttribute, RadiusPersistAttributeAnnotation,
+			vs.Namespace, vs.Name)
+		return
+	}
+	// the subscriber discovery requires PEM or AFM on BIG-IP, so it's declared only when it's set
+	if discovery, ok := vs.Annotations[RadiusSubscriberDiscoveryAnnotation]; ok {
+		enabled, err := strconv.ParseBool(discovery)
+		if err != nil {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+				discovery, RadiusSubscriberDiscoveryAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		radius.SubscriberDiscoveryEnabled = &enabled
+	}
+	rsCfg.Virtual.Radius = radius
+}
+
+// handleVirtualServerHTTPCompression configures the HTTP compression profile of a VirtualServer with the
+// http-compression annotation
+func handleVirtualServerHTTPCompression(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	value, ok := vs.Annotations[HTTPCompressionAnnotation]
+	if !ok {
+		return
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+			value, HTTPCompressionAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	if !enabled {
+		return
+	}
+	// Compression rewrites the HTTP responses, so it can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("%v annotation is not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			HTTPCompressionAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	compression := &HTTPCompressionProfile{Gzip: true, Deflate: true}
+	for annotation, method := range map[string]*bool{
+		HTTPCompressionGzipAnnotation:    &compression.Gzip,
+		HTTPCompressionDeflateAnnotation: &compression.Deflate,
+	} {
+		if value, ok := vs.Annotations[annotation]; ok {
+			*method, err = strconv.ParseBool(value)
+			if err != nil {
+				log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be true or false",
+					value, annotation, vs.Namespace, vs.Name)
+				return
+			}
+		}
+	}
+	if !compression.Gzip && !compression.Deflate {
+		log.Errorf("Either %v or %v must be enabled with %v annotation in VirtualServer %v/%v",
+			HTTPCompressionGzipAnnotation, HTTPCompressionDeflateAnnotation, HTTPCompressionAnnotation,
+			vs.Namespace, vs.Name)
+		return
+	}
+	if contentTypes, ok := vs.Annotations[HTTPCompressionContentTypeIncludeAnnotation]; ok {
+		for _, contentType := range strings.Split(contentTypes, ",") {
+			if contentType = strings.TrimSpace(contentType); contentType != "" {
+				compression.ContentTypeInclude = append(compression.ContentTypeInclude, contentType)
+			}
 		}
-	} else {
-		rsCfg.Virtual.SNAT = vs.Spec.SNAT
 	}
-
-	if len(rsCfg.ServiceAddress) == 0 {
-		for _, sa := range vs.Spec.ServiceIPAddress {
-			rsCfg.ServiceAddress = append(rsCfg.ServiceAddress, ServiceAddress(sa))
+	if size, ok := vs.Annotations[HTTPCompressionMinimumSizeAnnotation]; ok {
+		minimumSize, err := strconv.Atoi(size)
+		if err != nil || minimumSize < HTTPCompressionMinMinimumSize || minimumSize > HTTPCompressionMaxMinimumSize {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be between %v and %v bytes",
+				size, HTTPCompressionMinimumSizeAnnotation, vs.Namespace, vs.Name, HTTPCompressionMinMinimumSize,
+				HTTPCompressionMaxMinimumSize)
+			return
 		}
+		compression.MinimumSize = minimumSize
 	}
+	rsCfg.Virtual.HTTPCompression = compression
+}
 
-	// set the WAF policy
-	if vs.Spec.WAF != "" {
-		rsCfg.Virtual.WAF = vs.Spec.WAF
+// handleVirtualServerLBStrategy balances the pools of the VirtualServer on the fastest application response and
+// evaluates its endpoint policies with an Endpoint_Strategy built from the match method and operands annotations
+func handleVirtualServerLBStrategy(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, pools Pools) {
+	value, ok := vs.Annotations[LBStrategyAnnotation]
+	if !ok {
+		return
 	}
-
-	// set the ConnectionMirroring
-	if vs.Spec.ConnectionMirroring != "" {
-		rsCfg.Virtual.ConnectionMirroring = vs.Spec.ConnectionMirroring
+	if value != LBStrategyConnectionDirected {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, only %v is supported",
+			value, LBStrategyAnnotation, vs.Namespace, vs.Name, LBStrategyConnectionDirected)
+		return
 	}
-	//Attach allowVlans.
-	if len(vs.Spec.AllowVLANs) > 0 {
-		rsCfg.Virtual.AllowVLANs = vs.Spec.AllowVLANs
+	strategy := &EndpointStrategy{MatchMethod: DefaultLBStrategyMatchMethod}
+	if method, ok := vs.Annotations[LBStrategyMatchMethodAnnotation]; ok {
+		switch method {
+		case "all-match", "best-match", "first-match":
+			strategy.MatchMethod = method
+		default:
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be all-match, best-match "+
+				"or first-match", method, LBStrategyMatchMethodAnnotation, vs.Namespace, vs.Name)
+			return
+		}
 	}
-	if vs.Spec.PersistenceProfile != "" {
-		rsCfg.Virtual.PersistenceProfile = vs.Spec.PersistenceProfile
+	if operands, ok := vs.Annotations[LBStrategyOperandsAnnotation]; ok {
+		for _, operand := range strings.Split(operands, ",") {
+			if operand = strings.TrimSpace(operand); operand != "" {
+				strategy.Operands = append(strategy.Operands, operand)
+			}
+		}
 	}
-
-	if len(vs.Spec.Profiles.TCP.Client) > 0 || len(vs.Spec.Profiles.TCP.Server) > 0 {
-		rsCfg.Virtual.TCP.Client = vs.Spec.Profiles.TCP.Client
-		rsCfg.Virtual.TCP.Server = vs.Spec.Profiles.TCP.Server
+	poolNames := make(map[string]struct{})
+	for _, pool := range pools {
+		poolNames[pool.Name] = struct{}{}
+	}
+	for i := range rsCfg.Pools {
+		if _, ok := poolNames[rsCfg.Pools[i].Name]; !ok {
+			continue
+		}
+		if rsCfg.Pools[i].Balance != "" && rsCfg.Pools[i].Balance != "fastest-app-response" {
+			log.Debugf("Overriding the %v load balancing of pool %v with fastest-app-response for %v annotation "+
+				"in VirtualServer %v/%v", rsCfg.Pools[i].Balance, rsCfg.Pools[i].Name, LBStrategyAnnotation,
+				vs.Namespace, vs.Name)
+		}
+		rsCfg.Pools[i].Balance = "fastest-app-response"
 	}
+	rsCfg.Virtual.EndpointStrategy = strategy
+}
 
-	if len(vs.Spec.Profiles.HTTP2.Client) > 0 || len(vs.Spec.Profiles.HTTP2.Server) > 0 {
-		rsCfg.Virtual.HTTP2.Client = vs.Spec.Profiles.HTTP2.Client
-		rsCfg.Virtual.HTTP2.Server = vs.Spec.Profiles.HTTP2.Server
+// handleVirtualServerIFiles creates an iFile for every key of the ConfigMap referenced by the ifile-configmap
+// annotation, the data larger than the BIG-IP iFile limit is skipped and reported as an event
+func (ctlr *Controller) handleVirtualServerIFiles(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	cmName, ok := vs.Annotations[IFileConfigMapAnnotation]
+	if !ok {
+		ctlr.clearVirtualServerWarning(vs, IFileSizeExceededEvent, "")
+		return
 	}
+	comInf, ok := ctlr.getNamespacedCommonInformer(vs.Namespace)
+	if !ok || comInf.cmInformer == nil {
+		log.Errorf("ConfigMap informer not found for namespace %v, skipping %v annotation in VirtualServer %v/%v",
+			vs.Namespace, IFileConfigMapAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	obj, found, err := comInf.cmInformer.GetIndexer().GetByKey(vs.Namespace + "/" + cmName)
+	if err != nil || !found {
+		log.Errorf("ConfigMap %v of %v annotation in VirtualServer %v/%v not found",
+			cmName, IFileConfigMapAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	cm := obj.(*v1.ConfigMap)
+	data := make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+	for key, value := range cm.Data {
+		data[key] = []byte(value)
+	}
+	for key, value := range cm.BinaryData {
+		data[key] = value
+	}
+	var keys []string
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var iFiles []IFile
+	var exceeded []string
+	for _, key := range keys {
+		if len(data[key]) > MaxIFileSize {
+			message := fmt.Sprintf("iFile %v of ConfigMap %v is %v bytes, exceeds the BIG-IP iFile limit of %v bytes",
+				key, cmName, len(data[key]), MaxIFileSize)
+			log.Errorf("VirtualServer %v/%v: %v", vs.Namespace, vs.Name, message)
+			exceeded = append(exceeded, message)
+			continue
+		}
+		iFiles = append(iFiles, IFile{
+			Name:   AS3NameFormatter(key),
+			Base64: base64.StdEncoding.EncodeToString(data[key]),
+		})
+	}
+	if len(exceeded) > 0 {
+		ctlr.recordVirtualServerWarning(vs, IFileSizeExceededEvent, "", strings.Join(exceeded, "; "))
+	} else {
+		ctlr.clearVirtualServerWarning(vs, IFileSizeExceededEvent, "")
+	}
+	rsCfg.Virtual.IFiles = iFiles
+}
 
-	if vs.Spec.DOS != "" {
-		rsCfg.Virtual.ProfileDOS = vs.Spec.DOS
+// getVirtualServersForIFileConfigMap returns the VirtualServers whose ifile-configmap annotation refers to the ConfigMap
+func (ctlr *Controller) getVirtualServersForIFileConfigMap(cm *v1.ConfigMap) []*cisapiv1.VirtualServer {
+	var virtuals []*cisapiv1.VirtualServer
+	for _, vs := range ctlr.getAllVirtualServers(cm.Namespace) {
+		if vs.Annotations[IFileConfigMapAnnotation] == cm.Name {
+			virtuals = append(virtuals, vs)
+		}
 	}
+	return virtuals
+}
 
-	if len(vs.Spec.AllowSourceRange) > 0 {
-		rsCfg.Virtual.AllowSourceRange = vs.Spec.AllowSourceRange
+// getMonitorRefs returns the names of the library monitors of the monitor-ref annotation of the VirtualServer
+func getMonitorRefs(vs *cisapiv1.VirtualServer) []string {
+	value, ok := vs.Annotations[MonitorRefAnnotation]
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		names = append(names, strings.TrimSpace(name))
 	}
+	return names
+}
 
-	if vs.Spec.BotDefense != "" {
-		rsCfg.Virtual.ProfileBotDefense = vs.Spec.BotDefense
+// getLibraryMonitors returns the monitors of the monitor library referenced by the monitor-ref annotation of the
+// VirtualServer, the library is made of the ConfigMaps of the admin namespace labelled with
+// cis.f5.com/monitor-library: "true" whose keys are the monitor names and values the monitors in JSON,
+// e.g. {"type": "http", "send": "GET /", "interval": 5}
+func (ctlr *Controller) getLibraryMonitors(vs *cisapiv1.VirtualServer) ([]cisapiv1.Monitor, error) {
+	names := getMonitorRefs(vs)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if ctlr.adminInformer == nil {
+		return nil, fmt.Errorf("monitor library of %v annotation in VirtualServer %v/%v is not available without "+
+			"the admin namespace", MonitorRefAnnotation, vs.Namespace, vs.Name)
+	}
+	cms, _ := ctlr.adminInformer.cmInformer.GetIndexer().ByIndex(cache.NamespaceIndex, ctlr.adminInformer.namespace)
+	// the ConfigMaps are sorted, so that the monitor defined by several of them is always taken from the same one
+	sort.Slice(cms, func(i, j int) bool {
+		return cms[i].(*v1.ConfigMap).Name < cms[j].(*v1.ConfigMap).Name
+	})
+	library := make(map[string]string)
+	for _, obj := range cms {
+		cm := obj.(*v1.ConfigMap)
+		if cm.Labels[MonitorLibraryLabel] != "true" {
+			continue
+		}
+		for name, monitor := range cm.Data {
+			if _, found := library[name]; found {
+				log.Warningf("Monitor %v of library ConfigMap %v/%v is already defined in the monitor library",
+					name, cm.Namespace, cm.Name)
+				continue
+			}
+			library[name] = monitor
+		}
+	}
+	var monitors []cisapiv1.Monitor
+	for _, name := range names {
+		definition, found := library[name]
+		if !found {
+			return nil, fmt.Errorf("monitor %v of %v annotation in VirtualServer %v/%v not found in the monitor library",
+				name, MonitorRefAnnotation, vs.Namespace, vs.Name)
+		}
+		var monitor cisapiv1.Monitor
+		if err := json.Unmarshal([]byte(definition), &monitor); err != nil {
+			return nil, fmt.Errorf("invalid monitor %v in the monitor library: %v", name, err)
+		}
+		switch monitor.Type {
+		case HTTP, HTTPS, TCP:
+		default:
+			return nil, fmt.Errorf("invalid type %v of monitor %v in the monitor library, supported types are "+
+				"http, https and tcp", monitor.Type, name)
+		}
+		monitor.Name = AS3NameFormatter(name)
+		monitor.Reference = ""
+		monitors = append(monitors, monitor)
 	}
+	return monitors, nil
+}
 
-	if vs.Spec.ProfileMultiplex != "" {
-		rsCfg.Virtual.ProfileMultiplex = vs.Spec.ProfileMultiplex
+// getPoolPodSelector returns the label selector of the pool-pod-selector annotation of the VirtualServer,
+// the selector is given as a JSON LabelSelector e.g. {"matchLabels": {"version": "canary"}}
+func getPoolPodSelector(vs *cisapiv1.VirtualServer) string {
+	value, ok := vs.Annotations[PoolPodSelectorAnnotation]
+	if !ok {
+		return ""
 	}
-	// check if custom http port set on virtual
-	if vs.Spec.VirtualServerHTTPPort != 0 {
-		httpPort = vs.Spec.VirtualServerHTTPPort
+	var labelSelector metav1.LabelSelector
+	if err := json.Unmarshal([]byte(value), &labelSelector); err != nil {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v: %v",
+			value, PoolPodSelectorAnnotation, vs.Namespace, vs.Name, err)
+		return ""
 	}
-	// Do not Create Virtual Server L7 Forwarding policies if HTTPTraffic is set to None or Redirect
-	if len(vs.Spec.TLSProfileName) > 0 &&
-		rsCfg.Virtual.VirtualAddress.Port == httpPort &&
-		(vs.Spec.HTTPTraffic == TLSNoInsecure || vs.Spec.HTTPTraffic == TLSRedirectInsecure) {
-		return nil
+	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil {
+		log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v: %v",
+			value, PoolPodSelectorAnnotation, vs.Namespace, vs.Name, err)
+		return ""
+	}
+	if selector.Empty() {
+		return ""
 	}
+	return selector.String()
+}
 
-	// skip the policy creation for passthrough termination
-	if !passthroughVS {
-		rules = ctlr.prepareVirtualServerRules(vs, rsCfg)
-		if rules == nil {
-			return fmt.Errorf("failed to create LTM Rules")
+// handleVirtualServerTenantSettings configures the route domain and optimistic lock key of the AS3 Tenant
+// based on VirtualServer annotations
+func handleVirtualServerTenantSettings(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	if value, ok := vs.Annotations[RouteDomainAnnotation]; ok {
+		routeDomain, err := strconv.Atoi(value)
+		if err != nil || routeDomain < 0 || routeDomain > MaxRouteDomain {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, supported range is 0-%v",
+				value, RouteDomainAnnotation, vs.Namespace, vs.Name, MaxRouteDomain)
+		} else {
+			rsCfg.MetaData.routeDomain = &routeDomain
+		}
+	}
+	if key, ok := vs.Annotations[OptimisticLockKeyAnnotation]; ok {
+		if key == "" || len(key) > MaxOptimisticLockKeyLength {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be 1-%v characters",
+				key, OptimisticLockKeyAnnotation, vs.Namespace, vs.Name, MaxOptimisticLockKeyLength)
+		} else {
+			rsCfg.MetaData.optimisticLockKey = key
 		}
+	}
+}
 
-		policyName := formatPolicyName(vs.Spec.Host, vs.Spec.HostGroup, rsCfg.Virtual.Name)
+// handleVirtualServerAS3LogLevel sets the AS3 log level of the tenant based on the annotation of the
+// VirtualServer namespace, the annotation is read when the VirtualServer is processed
+func (ctlr *Controller) handleVirtualServerAS3LogLevel(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer) {
+	if ctlr.nsAnnotationInformer == nil {
+		return
+	}
+	obj, found, err := ctlr.nsAnnotationInformer.nsInformer.GetIndexer().GetByKey(vs.Namespace)
+	if err != nil || !found {
+		log.Debugf("Namespace %v of VirtualServer %v not found", vs.Namespace, vs.Name)
+		return
+	}
+	ns := obj.(*v1.Namespace)
+	level, ok := ns.Annotations[AS3LogLevelAnnotation]
+	if !ok {
+		return
+	}
+	for _, supported := range AS3LogLevels {
+		if level == supported {
+			rsCfg.MetaData.as3LogLevel = level
+			return
+		}
+	}
+	log.Errorf("Invalid value %v for %v annotation in namespace %v, supported values are %v",
+		level, AS3LogLevelAnnotation, vs.Namespace, strings.Join(AS3LogLevels, ", "))
+}
 
-		rsCfg.AddRuleToPolicy(policyName, vs.Namespace, rules)
+// handleVirtualServerRewrite configures the URL rewrite profile based on VirtualServer annotations
+func handleVirtualServerRewrite(rsCfg *ResourceConfig, vs *cisapiv1.VirtualServer, passthroughVS bool) {
+	appRoot, appRootFound := vs.Annotations[RewriteAppRootAnnotation]
+	requestHost, requestHostFound := vs.Annotations[RewriteRequestHostAnnotation]
+	if !appRootFound && !requestHostFound {
+		return
+	}
+	// URL rewrite can only be handled on HTTP/HTTPS virtual servers
+	if passthroughVS || rsCfg.Virtual.TLSTermination == TLSPassthrough || isLayer4Protocol(rsCfg.Virtual.Protocol) {
+		log.Errorf("%v and %v annotations are not supported with passthrough, TCP or UDP VirtualServer %v/%v",
+			RewriteAppRootAnnotation, RewriteRequestHostAnnotation, vs.Namespace, vs.Name)
+		return
+	}
+	rewrite := RewriteConfig{}
+	if appRootFound {
+		// rewrite paths must be absolute directory paths
+		if u, err := url.Parse(appRoot); err != nil || !strings.HasPrefix(appRoot, "/") || u.Path != appRoot {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v, should be an absolute path",
+				appRoot, RewriteAppRootAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		if !strings.HasSuffix(appRoot, "/") {
+			appRoot += "/"
+		}
+		rewrite.AppRoot = appRoot
+	}
+	if requestHostFound {
+		if errs := validation.IsDNS1123Subdomain(requestHost); len(errs) > 0 {
+			log.Errorf("Invalid value %v for %v annotation in VirtualServer %v/%v: %v",
+				requestHost, RewriteRequestHostAnnotation, vs.Namespace, vs.Name, strings.Join(errs, ", "))
+			return
+		}
+		if vs.Spec.Host == "" || strings.HasPrefix(vs.Spec.Host, "*") {
+			log.Errorf("%v annotation requires a non wildcard host in VirtualServer %v/%v",
+				RewriteRequestHostAnnotation, vs.Namespace, vs.Name)
+			return
+		}
+		rewrite.RequestHost = requestHost
+		rewrite.Host = vs.Spec.Host
 	}
+	rsCfg.Virtual.Rewrite = rewrite
+}
 
-	// Attach user specified iRules
-	if len(vs.Spec.IRules) > 0 {
-		rsCfg.Virtual.IRules = append(rsCfg.Virtual.IRules, vs.Spec.IRules...)
+// hasPortList checks whether the port list is already attached to the virtual
+func (v *Virtual) hasPortList(name string) bool {
+	for _, prt := range v.PortLists {
+		if prt.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	// Append all the hosts from a host group/ single host
-	if vs.Spec.Host != "" {
-		rsCfg.MetaData.hosts = append(rsCfg.MetaData.hosts, vs.Spec.Host)
+// hasAddressList checks whether the address list is already attached to the virtual
+func (v *Virtual) hasAddressList(name string) bool {
+	for _, adl := range v.AddressLists {
+		if adl.Name == name {
+			return true
+		}
 	}
-	return nil
+	return false
 }
 
 func (ctlr *Controller) createVirtualServerMonitor(monitor cisapiv1.Monitor, pool *Pool, rsCfg *ResourceConfig,
@@ -768,6 +2295,138 @@ func (ctlr *Controller) createTransportServerMonitor(monitor cisapiv1.Monitor, p
 	}
 }
 
+// getVirtualServersForMonitorLibrary returns the VirtualServers referencing the monitors of the ConfigMap with
+// their monitor-ref annotation, the ConfigMap may have been removed from the monitor library
+func (ctlr *Controller) getVirtualServersForMonitorLibrary(cm *v1.ConfigMap) []*cisapiv1.VirtualServer {
+	if ctlr.adminInformer == nil || cm.Namespace != ctlr.adminInformer.namespace {
+		return nil
+	}
+	var virtuals []*cisapiv1.VirtualServer
+	for _, crInf := range ctlr.crInformers {
+		if crInf.vsInformer == nil {
+			continue
+		}
+		for _, obj := range crInf.vsInformer.GetIndexer().List() {
+			vs, ok := obj.(*cisapiv1.VirtualServer)
+			if !ok {
+				continue
+			}
+			for _, name := range getMonitorRefs(vs) {
+				if _, found := cm.Data[name]; found {
+					virtuals = append(virtuals, vs)
+					break
+				}
+			}
+		}
+	}
+	return virtuals
+}
+
+// getServicesForExternalMonitor returns the services whose external monitor runs the script of the ConfigMap
+func (ctlr *Controller) getServicesForExternalMonitor(cm *v1.ConfigMap) []*v1.Service {
+	if ctlr.adminInformer == nil || cm.Namespace != ctlr.adminInformer.namespace {
+		return nil
+	}
+	var services []*v1.Service
+	for _, comInf := range ctlr.comInformers {
+		if comInf.svcInformer == nil {
+			continue
+		}
+		for _, obj := range comInf.svcInformer.GetIndexer().List() {
+			if svc, ok := obj.(*v1.Service); ok && svc.Annotations[ExternalMonitorAnnotation] == cm.Name {
+				services = append(services, svc)
+			}
+		}
+	}
+	return services
+}
+
+// createServiceExternalMonitor monitors the pool with the external monitor of its Service, the monitor runs the
+// script of the ConfigMap of the admin namespace referenced by the cis.f5.com/monitor-external annotation of the
+// Service. BIG-IP runs the scripts as root, so they are only allowed with the enable-external-monitors flag
+func (ctlr *Controller) createServiceExternalMonitor(pool *Pool, rsCfg *ResourceConfig) {
+	if pool.Cluster != "" {
+		return
+	}
+	_, svc := ctlr.fetchService(MultiClusterServiceKey{
+		serviceName: pool.ServiceName,
+		namespace:   pool.ServiceNamespace,
+	})
+	if svc == nil {
+		return
+	}
+	cmName, ok := svc.Annotations[ExternalMonitorAnnotation]
+	if !ok {
+		return
+	}
+	if !ctlr.externalMonitors || ctlr.adminInformer == nil {
+		log.Warningf("Skipping %v annotation in service %v/%v, external monitors are not enabled",
+			ExternalMonitorAnnotation, svc.Namespace, svc.Name)
+		return
+	}
+	obj, found, _ := ctlr.adminInformer.cmInformer.GetIndexer().GetByKey(ctlr.adminInformer.namespace + "/" + cmName)
+	if !found {
+		log.Errorf("ConfigMap %v of %v annotation in service %v/%v not found in the admin namespace %v",
+			cmName, ExternalMonitorAnnotation, svc.Namespace, svc.Name, ctlr.adminInformer.namespace)
+		return
+	}
+	cm := obj.(*v1.ConfigMap)
+	script, ok := cm.Data[ExternalMonitorScriptKey]
+	if !ok && len(cm.Data) == 1 {
+		for _, value := range cm.Data {
+			script = value
+		}
+	} else if !ok {
+		log.Errorf("ConfigMap %v of %v annotation in service %v/%v has no %v key",
+			cmName, ExternalMonitorAnnotation, svc.Namespace, svc.Name, ExternalMonitorScriptKey)
+		return
+	}
+	if len(script) > MaxExternalMonitorScriptSize {
+		log.Errorf("Script of ConfigMap %v of %v annotation in service %v/%v is %v bytes, exceeds the BIG-IP "+
+			"external monitor limit of %v bytes", cmName, ExternalMonitorAnnotation, svc.Namespace, svc.Name,
+			len(script), MaxExternalMonitorScriptSize)
+		return
+	}
+	monitorName := formatMonitorName(svc.Namespace, svc.Name, ExternalMonitorType, pool.ServicePort, "", "")
+	monitor := Monitor{
+		Name:      monitorName,
+		Partition: rsCfg.Virtual.Partition,
+		Type:      ExternalMonitorType,
+		Script:    base64.StdEncoding.EncodeToString([]byte(script)),
+		Arguments: svc.Annotations[ExternalMonitorArgsAnnotation],
+	}
+	if value, ok := svc.Annotations[ExternalMonitorTimeoutAnnotation]; ok {
+		timeout, err := strconv.Atoi(value)
+		if err != nil || timeout < 1 || timeout > MaxMonitorTimeout {
+			log.Errorf("Invalid value %v for %v annotation in service %v/%v, should be between 1 and %v",
+				value, ExternalMonitorTimeoutAnnotation, svc.Namespace, svc.Name, MaxMonitorTimeout)
+			return
+		}
+		monitor.Timeout = timeout
+	}
+	// user defined variables are passed to the script as environment variables, e.g. "PATH=/health,STATUS=200"
+	if value, ok := svc.Annotations[ExternalMonitorUserDefinedAnnotation]; ok {
+		var variables []string
+		for _, variable := range strings.Split(value, ",") {
+			variable = strings.TrimSpace(variable)
+			if name, _, found := strings.Cut(variable, "="); !found || name == "" {
+				log.Errorf("Invalid variable %v in %v annotation in service %v/%v, should be NAME=VALUE",
+					variable, ExternalMonitorUserDefinedAnnotation, svc.Namespace, svc.Name)
+				return
+			}
+			variables = append(variables, variable)
+		}
+		monitor.UserDefined = strings.Join(variables, ",")
+	}
+	pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: JoinBigipPath(rsCfg.Virtual.Partition, monitorName)})
+	for _, existing := range rsCfg.Monitors {
+		if existing.Name == monitorName {
+			return
+		}
+	}
+	rsCfg.Monitors = append(rsCfg.Monitors, monitor)
+}
+
 // Handle the default pool for virtual server
 func (ctlr *Controller) handleDefaultPool(
 	rsCfg *ResourceConfig,
@@ -805,6 +2464,7 @@ func (ctlr *Controller) handleDefaultPool(
 				Balance:           vs.Spec.DefaultPool.Balance,
 				ReselectTries:     vs.Spec.DefaultPool.ReselectTries,
 				ServiceDownAction: vs.Spec.DefaultPool.ServiceDownAction,
+				PodSelector:       getPoolPodSelector(vs),
 			}
 			if vs.Spec.DefaultPool.Monitors != nil {
 				for _, mtr := range vs.Spec.DefaultPool.Monitors {
@@ -1167,6 +2827,31 @@ func (ctlr *Controller) handleVirtualServerTLS(
 	} else if tls.Spec.TLS.ServerSSL != "" {
 		bigIPSSLProfiles.serverSSLs = append(bigIPSSLProfiles.serverSSLs, tls.Spec.TLS.ServerSSL)
 	}
+	if cv := tls.Spec.OCSPCRLProfile; cv != nil {
+		rsCfg.Virtual.CertificateValidator = CertificateValidator{
+			TrustCA:           cv.TrustCA,
+			OCSPEnabled:       cv.OCSPEnabled,
+			OCSPResponderURL:  cv.OCSPResponderURL,
+			DNSResolver:       cv.DNSResolver,
+			CRLEnabled:        cv.CRLEnabled,
+			CRLFile:           cv.CRLFile,
+			RequireOCSPStatus: cv.RequireOCSPStatus,
+		}
+	}
+	if cg := tls.Spec.CipherGroup; cg != nil {
+		cipherGroup := &CipherGroup{Name: cg.Name}
+		for _, rule := range cg.Rules {
+			if rule.OrderBy != "" {
+				cipherGroup.Order = rule.OrderBy
+			}
+			cipherGroup.Rules = append(cipherGroup.Rules, CipherRule{
+				Name:      rule.Name,
+				AllowList: rule.AllowList,
+				DenyList:  rule.DenyList,
+			})
+		}
+		rsCfg.Virtual.CipherGroup = cipherGroup
+	}
 	var poolPathRefs []poolPathRef
 	for _, pl := range vs.Spec.Pools {
 		poolBackends := ctlr.GetPoolBackends(&pl)
@@ -1224,15 +2909,82 @@ func validateTLSProfile(tls *cisapiv1.TLSProfile) bool {
 		}
 	} else {
 		// Pass-through
+		if tls.Spec.OCSPCRLProfile != nil {
+			log.Errorf("TLSProfile %s of type Pass-through termination should NOT contain ocspCRLProfile",
+				tls.ObjectMeta.Name)
+			return false
+		}
+		if tls.Spec.CipherGroup != nil {
+			log.Errorf("TLSProfile %s of type Pass-through termination should NOT contain cipherGroup",
+				tls.ObjectMeta.Name)
+			return false
+		}
 		if (tls.Spec.TLS.ClientSSL != "") || (tls.Spec.TLS.ServerSSL != "") || len(tls.Spec.TLS.ClientSSLs) != 0 || len(tls.Spec.TLS.ServerSSLs) != 0 {
 			log.Errorf("TLSProfile %s of type Pass-through termination should NOT contain either "+
 				"ClientSSLs or ServerSSLs", tls.ObjectMeta.Name)
 			return false
 		}
 	}
+	if cv := tls.Spec.OCSPCRLProfile; cv != nil {
+		if (cv.OCSPEnabled || cv.CRLEnabled) && cv.TrustCA == "" {
+			log.Errorf("TLSProfile %s with OCSP or CRL enabled should contain trustCA to validate the client certificates",
+				tls.ObjectMeta.Name)
+			return false
+		}
+		if cv.OCSPEnabled && cv.DNSResolver == "" {
+			log.Errorf("TLSProfile %s with OCSP enabled should contain dnsResolver to reach the OCSP responder",
+				tls.ObjectMeta.Name)
+			return false
+		}
+		if cv.CRLEnabled && cv.CRLFile == "" {
+			log.Errorf("TLSProfile %s with CRL enabled should contain crlFile", tls.ObjectMeta.Name)
+			return false
+		}
+		if cv.RequireOCSPStatus && !cv.OCSPEnabled {
+			log.Errorf("TLSProfile %s with requireOCSPStatus should have OCSP enabled", tls.ObjectMeta.Name)
+			return false
+		}
+	}
+	if cg := tls.Spec.CipherGroup; cg != nil {
+		if cg.Name == "" {
+			log.Errorf("TLSProfile %s with cipherGroup should contain the cipher group name", tls.ObjectMeta.Name)
+			return false
+		}
+		var orderBy string
+		for _, rule := range cg.Rules {
+			if rule.Name == "" || (len(rule.AllowList) == 0 && len(rule.DenyList) == 0) {
+				log.Errorf("TLSProfile %s cipherGroup rules should contain a name and either allowList or denyList",
+					tls.ObjectMeta.Name)
+				return false
+			}
+			if rule.OrderBy == "" {
+				continue
+			}
+			if _, ok := cipherGroupOrders[rule.OrderBy]; !ok {
+				log.Errorf("TLSProfile %s cipherGroup rule %s has invalid orderBy %s", tls.ObjectMeta.Name,
+					rule.Name, rule.OrderBy)
+				return false
+			}
+			if orderBy != "" && orderBy != rule.OrderBy {
+				log.Errorf("TLSProfile %s cipherGroup rules should not contain conflicting orderBy values",
+					tls.ObjectMeta.Name)
+				return false
+			}
+			orderBy = rule.OrderBy
+		}
+	}
 	return true
 }
 
+// cipherGroupOrders are the orders of the cipher rules supported by the AS3 Cipher_Group
+var cipherGroupOrders = map[string]struct{}{
+	"default":  {},
+	"speed":    {},
+	"strength": {},
+	"fips":     {},
+	"hardware": {},
+}
+
 // ConvertStringToProfileRef converts strings to profile references
 func ConvertStringToProfileRef(profileName, context, ns string) ProfileRef {
 	profName := strings.TrimSpace(strings.TrimPrefix(profileName, "/"))
@@ -1453,6 +3205,16 @@ func (rs *ResourceStore) getLTMConfigDeepCopy(bigip cisapiv1.BigIpConfig) LTMCon
 	return ltmConfig
 }
 
+// copyLTMConfig is a PartitionConfig reference copy of LTMConfig, the tenants of the copy are added and
+// removed without changing the LTMConfig shared with the controller
+func copyLTMConfig(ltmConfig LTMConfig) LTMConfig {
+	ltmConfigCopy := make(LTMConfig, len(ltmConfig))
+	for prtn, partitionConfig := range ltmConfig {
+		ltmConfigCopy[prtn] = partitionConfig
+	}
+	return ltmConfigCopy
+}
+
 // getGTMConfigCopy is a WideIP reference copy of GTMConfig
 func (rs *ResourceStore) getGTMConfigCopy(bigip cisapiv1.BigIpConfig) GTMConfig {
 	gtmConfig := make(GTMConfig)
@@ -1952,6 +3714,8 @@ func (ctlr *Controller) prepareRSConfigFromTransportServer(
 				vs.ObjectMeta.Namespace, vs.ObjectMeta.Name)
 		}
 	}
+	ctlr.createServiceExternalMonitor(&pool, rsCfg)
+	ctlr.createServiceLDAPMonitor(&pool, rsCfg)
 
 	rsCfg.Virtual.Mode = vs.Spec.Mode
 	rsCfg.Virtual.IpProtocol = vs.Spec.Type
@@ -2238,7 +4002,8 @@ func (ctlr *Controller) handlePoolResourceConfigForPolicy(
 		if pl.ServiceDownAction == "" && plc.Spec.PoolSettings.ServiceDownAction != "" {
 			pl.ServiceDownAction = plc.Spec.PoolSettings.ServiceDownAction
 		}
-		if plc.Spec.PoolSettings.SlowRampTime != 0 {
+		// slowRampTime set using the service annotation takes precedence
+		if pl.SlowRampTime == 0 && plc.Spec.PoolSettings.SlowRampTime != 0 {
 			pl.SlowRampTime = plc.Spec.PoolSettings.SlowRampTime
 		}
 		//update pool
@@ -1,7 +1,11 @@
 package controller
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"sort"
+	"strings"
 
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/clustermanager"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -12,7 +16,11 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
 )
 
 var _ = Describe("Resource Config Tests", func() {
@@ -326,6 +334,2243 @@ var _ = Describe("Resource Config Tests", func() {
 			Expect(rsCfg.Virtual.IRules[0]).To(Equal("SampleIRule"))
 		})
 
+		It("Prepare Resource Config from a VirtualServer with AddressLists", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			allowList := &cisapiv1.AddressList{}
+			allowList.Name = "allow-list"
+			allowList.Namespace = namespace
+			allowList.Spec = cisapiv1.AddressListSpec{Type: AddressListAllow, Addresses: []string{"10.1.0.0/16", "invalid"}}
+			denyList := &cisapiv1.AddressList{}
+			denyList.Name = "deny-list"
+			denyList.Namespace = namespace
+			denyList.Spec = cisapiv1.AddressListSpec{Type: AddressListDeny, Addresses: []string{"10.1.1.0/24"}}
+			_ = mockCtlr.comInformers[namespace].adlInformer.GetIndexer().Add(allowList)
+			_ = mockCtlr.comInformers[namespace].adlInformer.GetIndexer().Add(denyList)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				AllowAddressListAnnotation: "allow-list, deny-list",
+				DenyAddressListAnnotation:  "deny-list",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.AddressLists).To(Equal([]AddressListRef{
+				{Name: "deny-list", Addresses: []string{"10.1.1.0/24"}, Action: AddressListDeny},
+				{Name: "allow-list", Addresses: []string{"10.1.0.0/16"}, Action: AddressListAllow},
+			}), "Incorrect address lists attached to virtual")
+			Expect(mockCtlr.getVirtualsForAddressList(allowList)).To(BeNil(), "VirtualServer is not in informer cache")
+
+			app := as3Application{}
+			createAccessListDecl(rsCfg, app)
+			Expect(app["allow_list_address_list"]).To(Equal(&as3NetAddressList{Class: "Net_Address_List", Addresses: []string{"10.1.0.0/16"}}))
+			ruleList := app[rsCfg.Virtual.Name+"_fw_policy_rules"].(*as3FirewallRuleList)
+			Expect(len(ruleList.Rules)).To(Equal(3), "Incorrect number of firewall rules")
+			Expect(ruleList.Rules[0].Action).To(Equal("drop"))
+			Expect(ruleList.Rules[1].Action).To(Equal("accept"))
+			Expect(ruleList.Rules[2].Name).To(Equal("default_deny"))
+			svc := &as3Service{}
+			processCommonDecl(rsCfg, svc)
+			Expect(svc.Firewall).To(Equal(&as3ResourcePointer{Use: rsCfg.Virtual.Name + "_fw_policy"}))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with PortLists", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			webPorts := &cisapiv1.PortList{}
+			webPorts.Name = "web-ports"
+			webPorts.Namespace = namespace
+			webPorts.Spec = cisapiv1.PortListSpec{
+				Ports:      []int{80, 443, 70000},
+				PortRanges: []cisapiv1.PortRange{{Start: 8000, End: 8080}, {Start: 9000, End: 8000}},
+			}
+			invalidPorts := &cisapiv1.PortList{}
+			invalidPorts.Name = "invalid-ports"
+			invalidPorts.Namespace = namespace
+			invalidPorts.Spec = cisapiv1.PortListSpec{Ports: []int{-1}}
+			_ = mockCtlr.comInformers[namespace].prtInformer.GetIndexer().Add(webPorts)
+			_ = mockCtlr.comInformers[namespace].prtInformer.GetIndexer().Add(invalidPorts)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{AllowPortListAnnotation: "web-ports, invalid-ports, missing-ports"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.PortLists).To(Equal([]PortListRef{
+				{Name: "web-ports", Ports: []int{80, 443}, PortRanges: []string{"8000-8080"}},
+			}), "Incorrect port lists attached to virtual")
+			Expect(mockCtlr.getVirtualsForPortList(webPorts)).To(BeNil(), "VirtualServer is not in informer cache")
+
+			app := as3Application{}
+			createAccessListDecl(rsCfg, app)
+			Expect(app["web_ports_port_list"]).To(Equal(&as3NetPortList{
+				Class: "Net_Port_List",
+				Ports: []as3MultiTypeParam{80, 443, "8000-8080"},
+			}))
+			ruleList := app[rsCfg.Virtual.Name+"_fw_policy_rules"].(*as3FirewallRuleList)
+			Expect(len(ruleList.Rules)).To(Equal(2), "Incorrect number of firewall rules")
+			Expect(ruleList.Rules[0].Action).To(Equal("accept"))
+			Expect(ruleList.Rules[0].Destination.PortLists).To(Equal([]as3ResourcePointer{{Use: "web_ports_port_list"}}))
+			Expect(ruleList.Rules[1].Name).To(Equal("default_deny"))
+			svc := &as3Service{}
+			processCommonDecl(rsCfg, svc)
+			Expect(svc.Firewall).To(Equal(&as3ResourcePointer{Use: rsCfg.Virtual.Name + "_fw_policy"}))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with NATPolicy", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			natPolicy := &cisapiv1.NATPolicy{}
+			natPolicy.Name = "outbound-nat"
+			natPolicy.Namespace = namespace
+			natPolicy.Spec = cisapiv1.NATPolicySpec{
+				Rules: []cisapiv1.NATRule{{
+					Name:        "web-clients",
+					Source:      "10.1.0.0/16",
+					Destination: "10.8.3.11",
+					Translation: "10.192.75.100",
+					Protocol:    "tcp",
+				}},
+			}
+			invalidPolicy := &cisapiv1.NATPolicy{}
+			invalidPolicy.Name = "invalid-nat"
+			invalidPolicy.Namespace = namespace
+			invalidPolicy.Spec = cisapiv1.NATPolicySpec{
+				Rules: []cisapiv1.NATRule{{Name: "invalid", Source: "10.1.0.0/33", Translation: "10.192.75.100"}},
+			}
+			_ = mockCtlr.comInformers[namespace].natInformer.GetIndexer().Add(natPolicy)
+			_ = mockCtlr.comInformers[namespace].natInformer.GetIndexer().Add(invalidPolicy)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{NATPolicyAnnotation: "invalid-nat"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.NATPolicy).To(BeNil(), "Invalid NATPolicy attached to virtual")
+
+			vs.Spec.SNAT = "/Common/snatpool"
+			vs.Annotations[NATPolicyAnnotation] = "outbound-nat"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.NATPolicy).To(BeNil(), "NATPolicy conflicting with SNAT pool attached to virtual")
+
+			vs.Spec.SNAT = ""
+			rsCfg.Virtual.SNAT = ""
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.NATPolicy).To(Equal(&NATPolicyRef{Name: "outbound-nat", Rules: natPolicy.Spec.Rules}))
+			Expect(mockCtlr.getVirtualsForNATPolicy(natPolicy)).To(BeNil(), "VirtualServer is not in informer cache")
+
+			app := as3Application{}
+			createNATPolicyDecl(rsCfg, app)
+			policyName := rsCfg.Virtual.Name + "_nat_policy"
+			Expect(app[policyName]).To(Equal(&as3NATPolicy{
+				Class: "NAT_Policy",
+				Rules: []as3NATRule{{
+					Name:              "web_clients",
+					Protocol:          "tcp",
+					Source:            &as3FirewallRuleSource{AddressLists: []as3ResourcePointer{{Use: policyName + "_web_clients_source"}}},
+					Destination:       &as3FirewallRuleDestination{AddressLists: []as3ResourcePointer{{Use: policyName + "_web_clients_destination"}}},
+					SourceTranslation: &as3ResourcePointer{Use: policyName + "_web_clients_translation"},
+				}},
+			}))
+			Expect(app).NotTo(HaveKey(policyName+"_rules"), "NAT rules should be inline")
+			Expect(app[policyName+"_web_clients_source"]).To(Equal(&as3NetAddressList{
+				Class: "Firewall_Address_List", Addresses: []string{"10.1.0.0/16"}}))
+			Expect(app[policyName+"_web_clients_translation"]).To(Equal(&as3NATSourceTranslation{
+				Class:     "NAT_Source_Translation",
+				Type:      "static-nat",
+				Addresses: []string{"10.192.75.100"},
+			}))
+			svc := &as3Service{}
+			processCommonDecl(rsCfg, svc)
+			Expect(svc.PolicyNAT).To(Equal(&as3ResourcePointer{Use: policyName}))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with SNATPool", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			snatPool := &cisapiv1.SNATPool{}
+			snatPool.Name = "web-snat"
+			snatPool.Namespace = namespace
+			snatPool.Spec = cisapiv1.SNATPoolSpec{
+				Addresses: []string{"10.192.75.100", "invalid", "2001::100"},
+			}
+			_ = mockCtlr.comInformers[namespace].snatInformer.GetIndexer().Add(snatPool)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					SNAT: "missing-snat",
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.SNATPool).To(BeNil(), "Missing SNATPool attached to virtual")
+			Expect(rsCfg.Virtual.SNAT).To(Equal(DEFAULT_SNAT))
+			Expect(mockCtlr.setVirtualServerSNATCondition(vs)).To(BeTrue())
+			cond := meta.FindStatusCondition(vs.Status.Conditions, VSConditionSNATConfigured)
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse), "Fallback to auto snat reported as applied")
+			Expect(cond.Reason).To(Equal(VSReasonSNATPoolUnavailable))
+
+			vs.Spec.SNAT = "web-snat"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.SNATPool).To(Equal(&SNATPoolRef{
+				Name:      "web-snat",
+				Addresses: []string{"10.192.75.100", "2001::100"},
+			}))
+			Expect(mockCtlr.setVirtualServerSNATCondition(vs)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(vs.Status.Conditions, VSConditionSNATConfigured)).To(BeTrue())
+			Expect(mockCtlr.getVirtualsForSNATPool(snatPool)).To(BeNil(), "VirtualServer is not in informer cache")
+
+			app := as3Application{}
+			createSNATPoolDecl(rsCfg, app)
+			poolName := rsCfg.Virtual.Name + "_snat_pool"
+			Expect(app[poolName]).To(Equal(&as3SNATPool{
+				Class:         "SNAT_Pool",
+				SNATAddresses: []string{"10.192.75.100", "2001::100"},
+			}))
+			svc := &as3Service{}
+			processCommonDecl(rsCfg, svc)
+			Expect(svc.SNAT).To(Equal(&as3ResourcePointer{Use: poolName}))
+
+			vs.Spec.SNAT = SNATSelf
+			rsCfg.Virtual.SNATPool = nil
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.SNATPool).To(BeNil())
+			svc = &as3Service{}
+			processCommonDecl(rsCfg, svc)
+			Expect(svc.SNAT).To(Equal(SNATSelf))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with X-Forwarded-For annotation", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{XFFInsertAnnotation: XFFInsertReplace}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.XFFInsert).To(Equal(XFFInsertReplace))
+			Expect(rsCfg.Virtual.IRules).To(BeEmpty(), "XFF iRule attached before the HTTP profile is known")
+
+			// annotation is ignored for passthrough virtual server
+			rsCfg.Virtual.XFFInsert = ""
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, true, TLSPassthrough)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.XFFInsert).To(BeEmpty())
+
+			// invalid annotation value is ignored
+			vs.Annotations[XFFInsertAnnotation] = "invalid"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.XFFInsert).To(BeEmpty())
+		})
+
+		Describe("X-Forwarded-For header setting of the service", func() {
+			var app as3Application
+			var svc *as3Service
+			var profileName, xffIRule string
+			BeforeEach(func() {
+				rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+				app = as3Application{}
+				svc = &as3Service{}
+				profileName = rsCfg.Virtual.Name + "_http_profile"
+				xffIRule = getRSCfgResName(rsCfg.Virtual.Name, XFFIRuleName)
+			})
+
+			It("Inserts the header and keeps the header of the client", func() {
+				rsCfg.Virtual.XFFInsert = XFFInsertEnabled
+				createXFFHTTPProfileDecl(rsCfg, app, svc)
+				xForwardedFor := true
+				Expect(app[profileName]).To(Equal(&as3HTTPProfile{Class: "HTTP_Profile", XForwardedFor: &xForwardedFor}))
+				Expect(svc.ProfileHTTP).To(Equal(&as3ResourcePointer{Use: profileName}))
+				Expect(app).NotTo(HaveKey(xffIRule), "Header of the client removed")
+				Expect(svc.IRules).To(BeNil())
+			})
+
+			It("Replaces the header of the client", func() {
+				rsCfg.Virtual.XFFInsert = XFFInsertReplace
+				svc.IRules = []interface{}{"other_irule"}
+				createXFFHTTPProfileDecl(rsCfg, app, svc)
+				xForwardedFor := true
+				Expect(app[profileName]).To(Equal(&as3HTTPProfile{Class: "HTTP_Profile", XForwardedFor: &xForwardedFor}))
+				Expect(app[xffIRule]).To(Equal(&as3IRules{Class: "iRule", IRule: xffRemoveIRule()}))
+				Expect(svc.IRules).To(Equal([]interface{}{"other_irule", xffIRule}), "iRules of the virtual not kept")
+			})
+
+			It("Removes the header of the client without inserting it", func() {
+				rsCfg.Virtual.XFFInsert = XFFInsertDisabled
+				createXFFHTTPProfileDecl(rsCfg, app, svc)
+				xForwardedFor := false
+				Expect(app[profileName]).To(Equal(&as3HTTPProfile{Class: "HTTP_Profile", XForwardedFor: &xForwardedFor}))
+				Expect(app[xffIRule]).To(Equal(&as3IRules{Class: "iRule", IRule: xffRemoveIRule()}))
+				Expect(svc.IRules).To(Equal([]interface{}{xffIRule}))
+			})
+
+			It("Skips both the profile and the iRule when an HTTP profile is configured", func() {
+				rsCfg.Virtual.XFFInsert = XFFInsertReplace
+				svc.ProfileHTTP = &as3ResourcePointer{BigIP: "/Common/http"}
+				createXFFHTTPProfileDecl(rsCfg, app, svc)
+				Expect(svc.ProfileHTTP).To(Equal(&as3ResourcePointer{BigIP: "/Common/http"}))
+				Expect(app).To(BeEmpty(), "XFF profile or iRule declared with the configured HTTP profile")
+				Expect(svc.IRules).To(BeNil(), "XFF iRule attached with the configured HTTP profile")
+			})
+		})
+
+		It("Prepare Resource Config from a VirtualServer with rate limit annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{RateLimitRPSAnnotation: "100", RateLimitBurstAnnotation: "20"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.RateLimit).To(Equal(RateLimit{Rate: 100, Burst: 20, Unit: RateLimitUnitRPS}))
+			app := as3Application{}
+			svc := &as3Service{}
+			createRateLimitDecl(rsCfg, app, svc)
+			Expect(svc.RateLimit).To(Equal(int64(120)), "Incorrect rateLimit")
+
+			vs.Annotations = map[string]string{RateLimitRPSAnnotation: "1000000", RateLimitUnitAnnotation: RateLimitUnitBPS}
+			rsCfg.Virtual.RateLimit = RateLimit{}
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			svc = &as3Service{}
+			createRateLimitDecl(rsCfg, app, svc)
+			Expect(app[rsCfg.Virtual.Name+"_bwc_policy"]).To(Equal(&as3BandwidthControlPolicy{
+				Class: "Bandwidth_Control_Policy", MaxBandwidth: 1000000, MaxBandwidthUnit: RateLimitUnitBPS}))
+			Expect(svc.BandwidthControl).To(Equal(&as3ResourcePointer{Use: rsCfg.Virtual.Name + "_bwc_policy"}))
+
+			// invalid values are ignored
+			for _, annotations := range []map[string]string{
+				{RateLimitRPSAnnotation: "-1"},
+				{RateLimitRPSAnnotation: "abc"},
+				{RateLimitRPSAnnotation: "100", RateLimitUnitAnnotation: RateLimitUnitBPS},
+				{RateLimitRPSAnnotation: "100", RateLimitUnitAnnotation: "pps"},
+				{RateLimitRPSAnnotation: "100", RateLimitBurstAnnotation: "0"},
+			} {
+				vs.Annotations = annotations
+				rsCfg.Virtual.RateLimit = RateLimit{}
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.RateLimit).To(Equal(RateLimit{}), "Invalid rate limit should be ignored")
+			}
+		})
+
+		It("Prepare Resource Config from a VirtualServer with traffic classification", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host:                  "test.com",
+					TrafficClassification: true,
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileClassification).To(Equal(DefaultClassificationProfile))
+
+			vs.Annotations = map[string]string{ClassificationProfileAnnotation: "/Common/custom-classification"}
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileClassification).To(Equal("/Common/custom-classification"))
+
+			vs.Annotations[ClassificationProfileAnnotation] = "custom-classification"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileClassification).To(Equal(DefaultClassificationProfile), "Invalid profile path should be ignored")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with rewrite annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.MetaData.Protocol = HTTP
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				RewriteAppRootAnnotation:     "/app",
+				RewriteRequestHostAnnotation: "internal.test.local",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Rewrite).To(Equal(RewriteConfig{AppRoot: "/app/", RequestHost: "internal.test.local", Host: "test.com"}))
+
+			app := as3Application{}
+			svc := &as3Service{}
+			createRewriteProfileDecl(rsCfg, app, svc)
+			Expect(app[rsCfg.Virtual.Name+"_rewrite_profile"]).To(Equal(&as3RewriteProfile{
+				Class:       "Rewrite_Profile",
+				RewriteMode: "uri-translation",
+				URIRules: []as3RewriteURIRule{{
+					Type:   "both",
+					Client: as3RewriteURI{Scheme: HTTP, Host: "test.com", Path: "/"},
+					Server: as3RewriteURI{Scheme: HTTP, Host: "internal.test.local", Path: "/app/"},
+				}},
+			}))
+			Expect(svc.ProfileRewrite).To(Equal(&as3ResourcePointer{Use: rsCfg.Virtual.Name + "_rewrite_profile"}))
+
+			// invalid values and passthrough virtual are ignored
+			for _, annotations := range []map[string]string{
+				{RewriteAppRootAnnotation: "app"},
+				{RewriteAppRootAnnotation: "/app?query=1"},
+				{RewriteRequestHostAnnotation: "Invalid_Host"},
+			} {
+				vs.Annotations = annotations
+				rsCfg.Virtual.Rewrite = RewriteConfig{}
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.Rewrite).To(Equal(RewriteConfig{}), "Invalid rewrite should be ignored")
+			}
+			vs.Annotations = map[string]string{RewriteAppRootAnnotation: "/app"}
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, true, TLSPassthrough)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Rewrite).To(Equal(RewriteConfig{}), "Rewrite should be ignored for passthrough")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with connection multiplex annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.MetaData.Protocol = HTTP
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{ConnectionMultiplexAnnotation: "true"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Multiplex).To(Equal(&MultiplexProfile{
+				MaxConnections:     DefaultMultiplexMaxConnections,
+				MaxConnectionReuse: DefaultMultiplexMaxConnectionReuse,
+				MaxConnectionAge:   DefaultMultiplexMaxConnectionAge,
+				SourceMask:         DefaultMultiplexSourceMask,
+			}))
+
+			vs.Annotations[MultiplexMaxConnectionsAnnotation] = "500"
+			vs.Annotations[MultiplexMaxConnectionReuseAnnotation] = "100"
+			vs.Annotations[MultiplexSourceMaskAnnotation] = "255.255.255.0"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Multiplex).To(Equal(&MultiplexProfile{MaxConnections: 500, MaxConnectionReuse: 100,
+				MaxConnectionAge: DefaultMultiplexMaxConnectionAge, SourceMask: "255.255.255.0"}))
+
+			app := as3Application{}
+			svc := &as3Service{}
+			createMultiplexProfileDecl(rsCfg, app, svc)
+			Expect(app[rsCfg.Virtual.Name+"_multiplex_profile"]).To(Equal(&as3MultiplexProfile{
+				Class:              "Multiplex_Profile",
+				MaxConnections:     500,
+				MaxConnectionReuse: 100,
+				MaxConnectionAge:   DefaultMultiplexMaxConnectionAge,
+				SourceMask:         "255.255.255.0",
+			}))
+			Expect(svc.ProfileMultiplex).To(Equal(&as3ResourcePointer{Use: rsCfg.Virtual.Name + "_multiplex_profile"}))
+
+			// invalid values, BIG-IP profile reference and passthrough virtual
+			for _, annotation := range []string{MultiplexMaxConnectionsAnnotation, MultiplexSourceMaskAnnotation} {
+				vs.Annotations = map[string]string{ConnectionMultiplexAnnotation: "true", annotation: "invalid"}
+				rsCfg.Virtual.Multiplex = nil
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.Multiplex).To(BeNil(), "Invalid multiplex configuration should be ignored")
+			}
+			vs.Annotations = map[string]string{ConnectionMultiplexAnnotation: "true"}
+			vs.Spec.ProfileMultiplex = "/Common/oneconnect"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Multiplex).To(BeNil(), "profileMultiplex should take precedence")
+			Expect(rsCfg.Virtual.ProfileMultiplex).To(Equal("/Common/oneconnect"))
+			vs.Spec.ProfileMultiplex = ""
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, true, TLSPassthrough)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Multiplex).To(BeNil(), "Multiplex should be ignored for passthrough")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with OneConnect annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.MetaData.Protocol = HTTP
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				OneConnectAnnotation:                   "true",
+				OneConnectMaxConnectionsAnnotation:     "2000",
+				OneConnectMaxConnectionReuseAnnotation: "200",
+				OneConnectMaxConnectionAgeAnnotation:   "0",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Multiplex).To(Equal(&MultiplexProfile{MaxConnections: 2000, MaxConnectionReuse: 200,
+				MaxConnectionAge: 0, SourceMask: DefaultMultiplexSourceMask}))
+
+			app := as3Application{}
+			svc := &as3Service{}
+			createMultiplexProfileDecl(rsCfg, app, svc)
+			declaration, _ := json.Marshal(app[rsCfg.Virtual.Name+"_multiplex_profile"])
+			Expect(string(declaration)).To(ContainSubstring(`"maxConnectionAge":0`), "Disabled reuse should be declared")
+
+			vs.Annotations[OneConnectMaxConnectionAgeAnnotation] = "-1"
+			rsCfg.Virtual.Multiplex = nil
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Multiplex).To(BeNil(), "Invalid maximum connection age should be ignored")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with stream profile annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.MetaData.Protocol = HTTP
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{StreamProfileAnnotation: "/Common/stream"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Stream).To(Equal(StreamProfile{Profile: "/Common/stream"}))
+			app := as3Application{}
+			svc := &as3Service{}
+			createStreamProfileDecl(rsCfg, app, svc)
+			Expect(svc.ProfileStream).To(Equal(&as3ResourcePointer{BigIP: "/Common/stream"}))
+			Expect(app).To(BeEmpty(), "Stream profile should not be created without overrides")
+
+			vs.Annotations[StreamSourceAnnotation] = "http://(.*).internal"
+			vs.Annotations[StreamTargetAnnotation] = "https://$1.example"
+			vs.Annotations[StreamChunkSizeAnnotation] = "8192"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			createStreamProfileDecl(rsCfg, app, svc)
+			Expect(app[rsCfg.Virtual.Name+"_stream_profile"]).To(Equal(&as3StreamProfile{
+				Class:           "Stream_Profile",
+				ParentProfile:   &as3ResourcePointer{BigIP: "/Common/stream"},
+				Source:          "http://(.*).internal",
+				Target:          "https://$1.example",
+				ChunkingEnabled: true,
+				ChunkSize:       8192,
+			}))
+			Expect(svc.ProfileStream).To(Equal(&as3ResourcePointer{Use: rsCfg.Virtual.Name + "_stream_profile"}))
+
+			// invalid values and UDP virtual are ignored
+			for _, annotations := range []map[string]string{
+				{StreamProfileAnnotation: "stream"},
+				{StreamProfileAnnotation: "/Common/stream", StreamSourceAnnotation: "(invalid"},
+				{StreamProfileAnnotation: "/Common/stream", StreamChunkSizeAnnotation: "0"},
+			} {
+				vs.Annotations = annotations
+				rsCfg.Virtual.Stream = StreamProfile{}
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.Stream).To(Equal(StreamProfile{}), "Invalid stream profile should be ignored")
+			}
+			vs.Annotations = map[string]string{StreamProfileAnnotation: "/Common/stream"}
+			vs.Spec.Protocol = UDP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Stream).To(Equal(StreamProfile{}), "Stream profile should be ignored for UDP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with bot defense annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.MetaData.Protocol = HTTP
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{BotDefenseAnnotation: "true"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileBotDefense).To(Equal(DefaultBotDefenseProfile))
+
+			vs.Annotations[BotDefenseProfileAnnotation] = "custom-bot-defense"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileBotDefense).To(Equal("/Common/custom-bot-defense"))
+
+			app := as3Application{}
+			svc := &as3Service{}
+			app[rsCfg.Virtual.Name] = svc
+			processBotDefenseForAS3(rsCfg, app, 3.19)
+			Expect(svc.ProfileBotDefense).To(BeNil(), "Bot defense should be ignored on older AS3 versions")
+			processBotDefenseForAS3(rsCfg, app, 0)
+			Expect(svc.ProfileBotDefense).To(Equal(&as3ResourcePointer{BigIP: "/Common/custom-bot-defense"}))
+
+			// botDefense in the spec takes precedence over the annotations
+			vs.Spec.BotDefense = "/Common/spec-bot-defense"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileBotDefense).To(Equal("/Common/spec-bot-defense"), "botDefense should take precedence")
+			vs.Spec.BotDefense = ""
+
+			// not supported with TCP virtual
+			rsCfg.Virtual.ProfileBotDefense = ""
+			vs.Spec.Protocol = TCP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileBotDefense).To(BeEmpty(), "Bot defense should be ignored for TCP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with DoS profile annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.MetaData.Protocol = HTTP
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				DOSProfileAnnotation:               "app-dos",
+				DOSDetectThresholdAnnotation:       "1000",
+				DOSRateIncreaseThresholdAnnotation: "300",
+				DOSRateLimitAnnotation:             "5000",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.DOS).To(Equal(&DOSProfile{Name: "app_dos", Vector: DefaultDOSVector,
+				DetectThreshold: 1000, RateIncreaseThreshold: 300, RateLimit: 5000}))
+
+			app := as3Application{}
+			svc := &as3Service{}
+			app[rsCfg.Virtual.Name] = svc
+			processDOSProfileForAS3(rsCfg, app, true)
+			Expect(svc.ProfileDOS).To(BeNil(), "DoS profile should be ignored without AFM")
+			processDOSProfileForAS3(rsCfg, app, false)
+			Expect(svc.ProfileDOS).To(Equal(&as3ResourcePointer{Use: "app_dos"}))
+			Expect(app["app_dos"]).To(Equal(&as3DOSProfile{Class: "DOS_Profile", Network: &as3DOSNetwork{
+				Vectors: []as3DOSNetworkVector{{Type: DefaultDOSVector, State: "mitigate", ThresholdMode: "manual",
+					RateThreshold: 1000, RateIncreaseThreshold: 300, RateLimit: 5000}}}}))
+
+			// a path refers to the DoS profile on BIG-IP
+			rsCfg.Virtual.DOS = nil
+			vs.Annotations = map[string]string{DOSProfileAnnotation: "/Common/dos"}
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.DOS).To(BeNil())
+			Expect(rsCfg.Virtual.ProfileDOS).To(Equal("/Common/dos"))
+			processDOSProfileForAS3(rsCfg, app, false)
+			Expect(svc.ProfileDOS).To(Equal(&as3ResourcePointer{BigIP: "/Common/dos"}))
+
+			// dos in the spec takes precedence over the annotations
+			vs.Annotations = map[string]string{DOSProfileAnnotation: "app-dos"}
+			vs.Spec.DOS = "/Common/spec-dos"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.DOS).To(BeNil(), "dos should take precedence")
+			Expect(rsCfg.Virtual.ProfileDOS).To(Equal("/Common/spec-dos"))
+			vs.Spec.DOS = ""
+
+			rsCfg.Virtual.ProfileDOS = ""
+			for _, annotations := range []map[string]string{
+				{DOSProfileAnnotation: ""},
+				{DOSProfileAnnotation: "app-dos", DOSRateLimitAnnotation: "0"},
+				{DOSProfileAnnotation: "app-dos", DOSDetectThresholdAnnotation: "high"},
+			} {
+				vs.Annotations = annotations
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.DOS).To(BeNil(), "Invalid DoS profile should be ignored")
+			}
+		})
+
+		It("Prepare Resource Config from a VirtualServer with protocol inspection annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{ProtocolInspectionAnnotation: "true"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileProtocolInspection).To(Equal(DefaultProtocolInspectionProfile))
+
+			vs.Annotations[ProtocolInspectionProfileAnnotation] = "dns-inspection"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileProtocolInspection).To(Equal("/Common/dns-inspection"))
+
+			app := as3Application{}
+			svc := &as3Service{}
+			app[rsCfg.Virtual.Name] = svc
+			processProtocolInspectionForAS3(rsCfg, app, 0, true)
+			Expect(svc.ProfileInspection).To(BeNil(), "Protocol inspection should be ignored without AFM")
+			processProtocolInspectionForAS3(rsCfg, app, 3.15, false)
+			Expect(svc.ProfileInspection).To(BeNil(), "Protocol inspection should be ignored on older AS3 versions")
+			processProtocolInspectionForAS3(rsCfg, app, ProtocolInspectionMinAS3Version, false)
+			Expect(svc.ProfileInspection).To(Equal(&as3ResourcePointer{BigIP: "/Common/dns-inspection"}))
+
+			for _, annotations := range []map[string]string{
+				{ProtocolInspectionAnnotation: "false"},
+				{ProtocolInspectionAnnotation: "yes"},
+				{ProtocolInspectionAnnotation: "true", ProtocolInspectionProfileAnnotation: ""},
+			} {
+				rsCfg.Virtual.ProfileProtocolInspection = ""
+				vs.Annotations = annotations
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.ProfileProtocolInspection).To(BeEmpty(), "Protocol inspection should be ignored")
+			}
+		})
+
+		It("Prepare Resource Config from a VirtualServer with HTML profile annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.MetaData.Protocol = HTTP
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				HTMLProfileAnnotation:          "/Common/html",
+				HTMLContentDetectionAnnotation: "true",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.HTML).To(Equal(HTMLProfile{Profile: "/Common/html", BigIPProfile: true}))
+			app := as3Application{}
+			svc := &as3Service{}
+			app[rsCfg.Virtual.Name] = svc
+			processHTMLProfileForAS3(rsCfg, app, 0)
+			Expect(svc.ProfileHTML).To(Equal(&as3ResourcePointer{BigIP: "/Common/html"}))
+
+			vs.Annotations = map[string]string{
+				HTMLProfileAnnotation:          "coffee-html",
+				HTMLContentDetectionAnnotation: "true",
+				HTMLContentSelectionAnnotation: "text/html, text/xhtml",
+			}
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			app = as3Application{}
+			svc = &as3Service{}
+			app[rsCfg.Virtual.Name] = svc
+			processHTMLProfileForAS3(rsCfg, app, 3.19)
+			Expect(svc.ProfileHTML).To(BeNil(), "HTML profile should be ignored on older AS3 versions")
+			processHTMLProfileForAS3(rsCfg, app, 3.48)
+			Expect(app["coffee_html"]).To(Equal(&as3HTMLProfile{
+				Class:                   "HTML_Profile",
+				ContentDetectionEnabled: true,
+				ContentSelection:        []string{"text/html", "text/xhtml"},
+			}))
+			Expect(svc.ProfileHTML).To(Equal(&as3ResourcePointer{Use: "coffee_html"}))
+
+			// invalid values and TCP virtual are ignored
+			for _, annotations := range []map[string]string{
+				{HTMLProfileAnnotation: "coffee-html", HTMLContentDetectionAnnotation: "yes please"},
+				{HTMLProfileAnnotation: "coffee-html", HTMLContentSelectionAnnotation: "html"},
+			} {
+				vs.Annotations = annotations
+				rsCfg.Virtual.HTML = HTMLProfile{}
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.HTML.Profile).To(BeEmpty(), "Invalid HTML profile should be ignored")
+			}
+			vs.Annotations = map[string]string{HTMLProfileAnnotation: "/Common/html"}
+			vs.Spec.Protocol = TCP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.HTML.Profile).To(BeEmpty(), "HTML profile should be ignored for TCP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with TCP analytics annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				TCPAnalyticsAnnotation:                    "true",
+				TCPAnalyticsCollectRemoteHostIPAnnotation: "true",
+				TCPAnalyticsByteDistributionAnnotation:    "true",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.AnalyticsProfiles.TCPAnalyticsProfile).To(Equal(&TCPAnalyticsProfile{CollectRemoteHostIP: true}))
+
+			app := as3Application{}
+			svc := &as3Service{}
+			app[rsCfg.Virtual.Name] = svc
+			processTCPAnalyticsProfileForAS3(rsCfg, app, true)
+			Expect(svc.TcpAnalyticsProfile).To(BeNil(), "TCP analytics profile should be ignored without AVR license")
+			processTCPAnalyticsProfileForAS3(rsCfg, app, false)
+			profileName := rsCfg.Virtual.Name + "_tcp_analytics"
+			Expect(app[profileName]).To(Equal(&as3AnalyticsTCPProfile{
+				Class:               "Analytics_TCP_Profile",
+				CollectRemoteHostIp: true,
+			}))
+			Expect(svc.TcpAnalyticsProfile).To(Equal(&as3ResourcePointer{Use: profileName}))
+
+			// invalid values and UDP virtual are ignored
+			for _, annotations := range []map[string]string{
+				{TCPAnalyticsAnnotation: "false"},
+				{TCPAnalyticsAnnotation: "yes"},
+				{TCPAnalyticsAnnotation: "true", TCPAnalyticsCollectNexthopAnnotation: "always"},
+			} {
+				vs.Annotations = annotations
+				rsCfg.Virtual.AnalyticsProfiles = AnalyticsProfiles{}
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.AnalyticsProfiles.TCPAnalyticsProfile).To(BeNil(), "Invalid TCP analytics should be ignored")
+			}
+			vs.Annotations = map[string]string{TCPAnalyticsAnnotation: "true"}
+			vs.Spec.Protocol = UDP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.AnalyticsProfiles.TCPAnalyticsProfile).To(BeNil(), "TCP analytics should be ignored for UDP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with iFile ConfigMap", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ifiles", Namespace: namespace},
+				Data:       map[string]string{"block-list.txt": "10.1.1.1"},
+				BinaryData: map[string][]byte{"large.bin": make([]byte, MaxIFileSize+1), "key.bin": {0xff, 0x01}},
+			}
+			comInf, _ := mockCtlr.getNamespacedCommonInformer(namespace)
+			Expect(comInf.cmInformer).NotTo(BeNil(), "ConfigMap informer not created")
+			_ = comInf.cmInformer.GetIndexer().Add(cm)
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{IFileConfigMapAnnotation: "ifiles"}
+			crInf, _ := mockCtlr.getNamespacedCRInformer(namespace)
+			_ = crInf.vsInformer.GetIndexer().Add(vs)
+			Expect(mockCtlr.getVirtualServersForIFileConfigMap(cm)).To(Equal([]*cisapiv1.VirtualServer{vs}),
+				"VirtualServer of the iFile ConfigMap not found")
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.IFiles).To(Equal([]IFile{
+				{Name: "block_list_txt", Base64: "MTAuMS4xLjE="},
+				{Name: "key_bin", Base64: "/wE="},
+			}), "iFile exceeding the size limit should be skipped")
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			events, _ := mockCtlr.clientsets.KubeClient.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{})
+			Expect(events.Items).To(HaveLen(1), "Event should be created once for the iFile exceeding the size limit")
+			Expect(events.Items[0].Reason).To(Equal(IFileSizeExceededEvent))
+
+			app := as3Application{}
+			processIFilesForAS3(rsCfg, app)
+			Expect(app["key_bin"]).To(Equal(&as3IFile{Class: "iFile", IFile: as3IFileSource{Base64: "/wE="}}))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with log publisher annotation", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			mockCtlr.defaultLogPublisher = "default-syslog"
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.LogPublisher).To(Equal("/Common/default-syslog"), "Default log publisher not applied")
+
+			vs.Annotations = map[string]string{LogPublisherAnnotation: "/Shared/remote-syslog"}
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.LogPublisher).To(Equal("/Shared/remote-syslog"))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			logProfile := rsCfg.Virtual.Name + "_security_log_profile"
+			Expect(app[logProfile]).To(Equal(&as3SecurityLogProfile{
+				Class: "Security_Log_Profile",
+				Network: &as3SecurityLogProfileNetwork{
+					Publisher:           as3ResourcePointer{BigIP: "/Shared/remote-syslog"},
+					LogRuleMatchAccepts: true,
+					LogRuleMatchRejects: true,
+					LogRuleMatchDrops:   true,
+					LogIpErrors:         true,
+				},
+			}))
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.LogProfiles).To(ContainElement(as3ResourcePointer{Use: logProfile}))
+
+			rsCfg.Virtual.LogPublisher = ""
+			vs.Annotations[LogPublisherAnnotation] = "/Shared/folder/remote-syslog"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.LogPublisher).To(BeEmpty(), "Invalid log publisher should be ignored")
+			mockCtlr.defaultLogPublisher = ""
+		})
+
+		It("Prepare Resource Config from a VirtualServer with library monitors", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			library := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "monitors", Namespace: "kube-system",
+					Labels: map[string]string{MonitorLibraryLabel: "true"}},
+				Data: map[string]string{
+					"http-basic": `{"type": "http", "send": "GET / HTTP/1.1\r\n", "interval": 5, "timeout": 16}`,
+					"udp-basic":  `{"type": "udp", "interval": 5}`,
+				},
+			}
+			mockCtlr.adminInformer = mockCtlr.newAdminInformer("kube-system")
+			_ = mockCtlr.adminInformer.cmInformer.GetIndexer().Add(library)
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.VSPool{
+						{Path: "/foo", Service: "svc1", ServicePort: intstr.IntOrString{IntVal: 80}},
+						{Path: "/bar", Service: "svc2", ServicePort: intstr.IntOrString{IntVal: 80},
+							Monitor: cisapiv1.Monitor{Name: "/Common/http", Reference: BIGIP}},
+					},
+				},
+			)
+			vs.Annotations = map[string]string{MonitorRefAnnotation: "http-basic"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Pools[0].MonitorNames).To(Equal([]MonitorName{{Name: "/test/http_basic"}}))
+			Expect(rsCfg.Pools[1].MonitorNames).To(Equal([]MonitorName{{Name: "/Common/http", Reference: BIGIP}}),
+				"Pool monitor should take precedence over the library monitor")
+			Expect(rsCfg.Monitors).To(Equal([]Monitor{{Name: "http_basic", Partition: "test", Type: HTTP,
+				Send: "GET / HTTP/1.1\r\n", Interval: 5, Timeout: 16}}))
+
+			// the VirtualServers referencing the monitors of the library are processed again on its changes
+			_ = mockCtlr.crInformers[namespace].vsInformer.GetIndexer().Add(vs)
+			Expect(mockCtlr.getVirtualServersForMonitorLibrary(library)).To(Equal([]*cisapiv1.VirtualServer{vs}))
+			other := library.DeepCopy()
+			other.Namespace = namespace
+			Expect(mockCtlr.getVirtualServersForMonitorLibrary(other)).To(BeEmpty(),
+				"ConfigMaps out of the admin namespace are not in the library")
+
+			for _, monitorRef := range []string{"missing", "udp-basic"} {
+				vs.Annotations[MonitorRefAnnotation] = monitorRef
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).NotTo(BeNil(), "Invalid library monitor should not be posted")
+			}
+
+			// monitors of the ConfigMaps removed from the library are not found
+			vs.Annotations[MonitorRefAnnotation] = "http-basic"
+			library.Labels = nil
+			_ = mockCtlr.adminInformer.cmInformer.GetIndexer().Update(library)
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).NotTo(BeNil(), "Monitor of an unlabelled ConfigMap should not be found")
+			mockCtlr.adminInformer = nil
+		})
+
+		It("Prepare Resource Config from a VirtualServer with NetworkPolicy ipBlocks", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			podLabels := map[string]string{"app": "svc1"}
+			mockCtlr.addService(test.NewServicewithselectors("svc1", "1", namespace, podLabels, v1.ServiceTypeClusterIP,
+				[]v1.ServicePort{{Port: 80}}))
+			policy := &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "allow-clients", Namespace: namespace},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						From: []networkingv1.NetworkPolicyPeer{
+							{IPBlock: &networkingv1.IPBlock{CIDR: "10.1.0.0/16", Except: []string{"10.1.1.0/24"}}},
+							{IPBlock: &networkingv1.IPBlock{CIDR: "192.168.10.0/24"}},
+							{IPBlock: &networkingv1.IPBlock{CIDR: "10.1.0.0/16"}},
+						},
+					}},
+				},
+			}
+			mockCtlr.clientsets.KubeClient = k8sfake.NewSimpleClientset(policy)
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.VSPool{
+						{Path: "/foo", Service: "svc1", ServicePort: intstr.IntOrString{IntVal: 80}},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.AddressLists).To(BeEmpty(), "NetworkPolicies should be ignored without firewall")
+
+			mockCtlr.firewallEnabled = true
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.AddressLists).To(Equal([]AddressListRef{{
+				Name:      rsCfg.Virtual.Name + "_network_policy",
+				Addresses: []string{"10.1.0.0/16", "192.168.10.0/24"},
+				Action:    AddressListAllow,
+			}}))
+
+			app := as3Application{}
+			createAccessListDecl(rsCfg, app)
+			adlName := AS3NameFormatter(rsCfg.Virtual.Name+"_network_policy") + "_address_list"
+			Expect(app[adlName]).To(Equal(&as3NetAddressList{Class: "Net_Address_List",
+				Addresses: []string{"10.1.0.0/16", "192.168.10.0/24"}}))
+			policyName := getAccessListFirewallPolicyName(rsCfg.Virtual.Name)
+			Expect(app[policyName]).NotTo(BeNil(), "Firewall policy should be created")
+			rules := app[policyName+"_rules"].(*as3FirewallRuleList).Rules
+			Expect(rules).To(HaveLen(2))
+			Expect(rules[0].Action).To(Equal("accept"))
+			Expect(rules[1].Name).To(Equal("default_deny"))
+
+			// a rule without peers allows all the sources
+			rsCfg.Virtual.AddressLists = nil
+			policy.Spec.Ingress = append(policy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{})
+			mockCtlr.clientsets.KubeClient = k8sfake.NewSimpleClientset(policy)
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.AddressLists).To(BeEmpty(), "Unrestricted pods should not be firewalled")
+			mockCtlr.firewallEnabled = false
+		})
+
+		It("Prepare Resource Config from a VirtualServer with the external monitor of a service", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			svc := test.NewServicewithselectors("svc1", "1", namespace, map[string]string{"app": "svc1"},
+				v1.ServiceTypeClusterIP, []v1.ServicePort{{Port: 80}})
+			svc.Annotations = map[string]string{
+				ExternalMonitorAnnotation:            "svc1-monitor",
+				ExternalMonitorArgsAnnotation:        "-v",
+				ExternalMonitorUserDefinedAnnotation: "URI=/health, STATUS=200",
+				ExternalMonitorTimeoutAnnotation:     "30",
+			}
+			mockCtlr.addService(svc)
+			script := "#!/bin/sh\ncurl -fs http://${1#::ffff:}:${2}${URI} && echo UP\n"
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc1-monitor", Namespace: "kube-system"},
+				Data:       map[string]string{"monitor.sh": script},
+			}
+			mockCtlr.adminInformer = mockCtlr.newAdminInformer("kube-system")
+			_ = mockCtlr.adminInformer.cmInformer.GetIndexer().Add(cm)
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.VSPool{
+						{Path: "/foo", Service: "svc1", ServicePort: intstr.IntOrString{IntVal: 80}},
+					},
+				},
+			)
+			// external monitors are opt-in
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Monitors).To(BeEmpty(), "External monitors should be disabled by default")
+
+			rsCfg.Pools = nil
+			mockCtlr.externalMonitors = true
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			monitorName := formatMonitorName(namespace, "svc1", ExternalMonitorType, intstr.IntOrString{IntVal: 80}, "", "")
+			Expect(rsCfg.Pools[0].MonitorNames).To(ContainElement(MonitorName{Name: "/test/" + monitorName}))
+			Expect(rsCfg.Monitors).To(ContainElement(Monitor{Name: monitorName, Partition: "test",
+				Type: ExternalMonitorType, Timeout: 30, Script: base64.StdEncoding.EncodeToString([]byte(script)),
+				Arguments: "-v", UserDefined: "URI=/health,STATUS=200"}))
+
+			app := as3Application{}
+			createMonitorDecl(rsCfg, app)
+			Expect(app[monitorName]).To(Equal(&as3ExternalMonitor{Class: "Monitor", MonitorType: ExternalMonitorType,
+				Timeout: 30, Script: as3IFileSource{Base64: base64.StdEncoding.EncodeToString([]byte(script))},
+				Arguments: "-v", EnvironmentVariables: map[string]string{"URI": "/health", "STATUS": "200"}}))
+			Expect(mockCtlr.getServicesForExternalMonitor(cm)).To(Equal([]*v1.Service{svc}))
+
+			// scripts over the BIG-IP limit are skipped
+			rsCfg.Pools = nil
+			rsCfg.Monitors = nil
+			cm.Data = map[string]string{ExternalMonitorScriptKey: strings.Repeat("#", MaxExternalMonitorScriptSize+1)}
+			_ = mockCtlr.adminInformer.cmInformer.GetIndexer().Update(cm)
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Monitors).To(BeEmpty(), "Script over the size limit should be skipped")
+
+			// the ConfigMaps of the other namespaces are ignored
+			cm.Namespace = namespace
+			Expect(mockCtlr.getServicesForExternalMonitor(cm)).To(BeEmpty())
+			mockCtlr.externalMonitors = false
+			mockCtlr.adminInformer = nil
+		})
+
+		It("Prepare Resource Config from a VirtualServer with the LDAP monitor of a service", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			svc := test.NewServicewithselectors("svc1", "1", namespace, map[string]string{"app": "svc1"},
+				v1.ServiceTypeClusterIP, []v1.ServicePort{{Port: 389}})
+			svc.Annotations = map[string]string{
+				MonitorTypeAnnotation:         "ldap",
+				LDAPMonitorBaseAnnotation:     "ou=people,dc=example,dc=com",
+				LDAPMonitorFilterAnnotation:   "(&(objectClass=person)(uid=cis))",
+				LDAPMonitorSecurityAnnotation: "TLS",
+				LDAPMonitorUsernameAnnotation: "cn=admin,dc=example,dc=com",
+				MonitorSecretAnnotation:       "svc1-ldap",
+			}
+			mockCtlr.addService(svc)
+			secret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc1-ldap", Namespace: namespace},
+				Data:       map[string][]byte{"password": []byte("secret")},
+			}
+			mockCtlr.addSecret(secret)
+			Expect(mockCtlr.getServicesForMonitorSecret(secret)).To(Equal([]*v1.Service{svc}))
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.VSPool{
+						{Path: "/foo", Service: "svc1", ServicePort: intstr.IntOrString{IntVal: 389}},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			monitorName := formatMonitorName(namespace, "svc1", LDAPMonitorType, intstr.IntOrString{IntVal: 389}, "", "")
+			Expect(rsCfg.Pools[0].MonitorNames).To(ContainElement(MonitorName{Name: "/test/" + monitorName}))
+			password := base64.StdEncoding.EncodeToString([]byte("secret"))
+
+			app := as3Application{}
+			createMonitorDecl(rsCfg, app)
+			Expect(app[monitorName]).To(Equal(&as3LDAPMonitor{Class: "Monitor", MonitorType: LDAPMonitorType,
+				Base: "ou=people,dc=example,dc=com", Filter: "(&(objectClass=person)(uid=cis))",
+				Security: "tls", Username: "cn=admin,dc=example,dc=com",
+				Passphrase: &as3Secret{Ciphertext: password, Protected: AS3SecretProtected}}))
+
+			// monitors with an invalid filter are skipped
+			rsCfg.Pools = nil
+			rsCfg.Monitors = nil
+			svc.Annotations[LDAPMonitorFilterAnnotation] = "(uid=cis"
+			mockCtlr.addService(svc)
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Monitors).To(BeEmpty(), "LDAP monitor with an invalid filter should be skipped")
+		})
+
+		It("Validate LDAP monitor parameters", func() {
+			for _, filter := range []string{"(uid=cis)", "(cn=*)", "(cn=ci*s)", "(!(cn=a\\2ab))",
+				"(|(uid>=1)(uid<=9)(cn~=cis))", "(cn;lang-en=cis)", "(cn:dn:2.5.13.5:=cis)", "(:dn:2.5.13.5:=cis)"} {
+				Expect(validateLDAPFilter(filter)).To(Succeed(), filter)
+			}
+			for _, filter := range []string{"uid=cis", "(uid=cis", "(uid=cis))", "(&)", "(uid)", "(1a=cis)",
+				"(cn=a\\zz)", "(cn<>=cis)", "(:=cis)", "(uid=cis)(cn=cis)"} {
+				Expect(validateLDAPFilter(filter)).NotTo(Succeed(), filter)
+			}
+			for _, dn := range []string{"dc=example,dc=com", "cn=a\\,b+uid=1,dc=com", "2.5.4.3=cis"} {
+				Expect(validateLDAPDN(dn)).To(Succeed(), dn)
+			}
+			for _, dn := range []string{"example.com", "dc=example,", "=cis", "cn=cis\\"} {
+				Expect(validateLDAPDN(dn)).NotTo(Succeed(), dn)
+			}
+			monitor := Monitor{Base: "dc=example,dc=com", Filter: "(uid=cis)"}
+			Expect(validateLDAPMonitor(monitor)).To(Succeed())
+			monitor.Security = "starttls"
+			Expect(validateLDAPMonitor(monitor)).NotTo(Succeed())
+			Expect(validateLDAPMonitor(Monitor{Filter: "(uid=cis)"})).NotTo(Succeed(), "Base is required")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with connection-directed lb strategy", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.VSPool{
+						{Path: "/foo", Service: "svc1", ServicePort: intstr.IntOrString{IntVal: 80},
+							Balance: "round-robin"},
+						{Path: "/bar", Service: "svc2", ServicePort: intstr.IntOrString{IntVal: 80}},
+					},
+				},
+			)
+			vs.Annotations = map[string]string{
+				LBStrategyAnnotation:         LBStrategyConnectionDirected,
+				LBStrategyOperandsAnnotation: "http-uri/request/path, http-host/request/all",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.EndpointStrategy).To(Equal(&EndpointStrategy{MatchMethod: DefaultLBStrategyMatchMethod,
+				Operands: []string{"http-uri/request/path", "http-host/request/all"}}))
+			for _, pool := range rsCfg.Pools {
+				Expect(pool.Balance).To(Equal("fastest-app-response"))
+			}
+
+			app := as3Application{}
+			createPoliciesDecl(rsCfg, app)
+			strategyName := getEndpointStrategyName(rsCfg.Virtual.Name)
+			Expect(app[strategyName]).To(Equal(&as3EndpointStrategy{Class: "Endpoint_Strategy",
+				MatchMethod: DefaultLBStrategyMatchMethod,
+				Operands:    []string{"http-uri/request/path", "http-host/request/all"}}))
+			Expect(rsCfg.Policies).NotTo(BeEmpty())
+			ep := app[rsCfg.Policies[0].Name].(*as3EndpointPolicy)
+			Expect(ep.Strategy).To(Equal("custom"))
+			Expect(ep.CustomStrategy).To(Equal(strategyName))
+
+			rsCfg.Virtual.EndpointStrategy = nil
+			for _, value := range []map[string]string{
+				{LBStrategyAnnotation: "least-connections"},
+				{LBStrategyAnnotation: LBStrategyConnectionDirected, LBStrategyMatchMethodAnnotation: "any-match"},
+			} {
+				vs.Annotations = value
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.EndpointStrategy).To(BeNil(), "Invalid lb strategy should be ignored")
+			}
+		})
+
+		It("Prepare Resource Config from a VirtualServer with adapt profile annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				RequestAdaptProfileAnnotation:  "/Common/requestadapt",
+				ResponseAdaptProfileAnnotation: "responseadapt",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileRequestAdapt).To(Equal("/Common/requestadapt"))
+			Expect(rsCfg.Virtual.ProfileResponseAdapt).To(BeEmpty(), "Profile name without BIG-IP path should be ignored")
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.ProfileRequestAdapt).To(Equal(&as3ResourcePointer{BigIP: "/Common/requestadapt"}))
+			Expect(svc.ProfileResponseAdapt).To(BeNil())
+
+			rsCfg.Virtual.ProfileRequestAdapt = ""
+			vs.Spec.Protocol = TCP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileRequestAdapt).To(BeEmpty(), "Adapt profile should be ignored for TCP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with ICAP service annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				ICAPServiceAnnotation:     "10.10.10.5",
+				ICAPAllowHTTP10Annotation: "true",
+				ICAPServiceDownAnnotation: ICAPServiceDownDrop,
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ICAP).To(Equal(&ICAPService{Address: "10.10.10.5", Port: DefaultICAPPort,
+				AllowHTTP10: true, ServiceDownAction: "drop"}))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			prefix := rsCfg.Virtual.Name + "_icap"
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.ProfileRequestAdapt).To(Equal(&as3ResourcePointer{Use: prefix + "_request_adapt"}))
+			Expect(app[prefix+"_request_adapt"]).To(Equal(&as3AdaptProfile{Class: "Adapt_Profile", MessageType: "request",
+				InternalService: as3ResourcePointer{Use: prefix + "_service"}, AllowHTTP10: true,
+				ServiceDownAction: "drop"}))
+			Expect(app[prefix+"_service"]).To(Equal(&as3InternalService{Class: "Service_TCP", VirtualType: "internal",
+				Pool: prefix + "_pool", ProfileICAP: &as3ResourcePointer{Use: prefix + "_profile"}}))
+			Expect(app[prefix+"_pool"].(*as3Pool).Members).To(Equal([]as3PoolMember{{
+				ServerAddresses: []string{"10.10.10.5"}, ServicePort: DefaultICAPPort}}))
+
+			for _, annotations := range []map[string]string{
+				{ICAPServiceAnnotation: "icap.example.com"},
+				{ICAPServiceAnnotation: "10.10.10.5", ICAPPortAnnotation: "70000"},
+				{ICAPServiceAnnotation: "10.10.10.5", ICAPServiceDownAnnotation: "reset"},
+				{ICAPServiceAnnotation: "10.10.10.5", RequestAdaptProfileAnnotation: "/Common/requestadapt"},
+			} {
+				rsCfg.Virtual.ICAP = nil
+				rsCfg.Virtual.ProfileRequestAdapt = ""
+				vs.Annotations = annotations
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.ICAP).To(BeNil(), "Invalid ICAP service should be ignored")
+			}
+
+			vs.Annotations = map[string]string{ICAPServiceAnnotation: "10.10.10.5"}
+			vs.Spec.Protocol = TCP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ICAP).To(BeNil(), "ICAP service should be ignored for TCP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with Diameter profile annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 3868)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host:     "test.com",
+					Protocol: TCP,
+				},
+			)
+			vs.Annotations = map[string]string{
+				DiameterProfileAnnotation:       "true",
+				DiameterParentProfileAnnotation: "/Common/diameter-endpoint",
+				DiameterDestHostAnnotation:      "pcrf.example.com",
+				DiameterDestRealmAnnotation:     "example.com",
+				DiameterSupportedAppsAnnotation: "Gx, Gy",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Diameter).To(Equal(&DiameterProfile{ParentProfile: "/Common/diameter-endpoint",
+				DestHost: "pcrf.example.com", DestRealm: "example.com", SupportedApps: []string{"Gx", "Gy"}}))
+
+			app := as3Application{}
+			svc := &as3Service{}
+			app[rsCfg.Virtual.Name] = svc
+			processDiameterProfileForAS3(rsCfg, app, 3.20)
+			Expect(svc.ProfileDiameterEndpoint).To(BeNil(), "Diameter profile should be ignored with older AS3")
+			processDiameterProfileForAS3(rsCfg, app, DiameterProfileMinAS3Version)
+			profileName := rsCfg.Virtual.Name + "_diameter"
+			Expect(app[profileName]).To(Equal(&as3DiameterProfile{
+				Class:            "Enforcement_Diameter_Endpoint_Profile",
+				ParentProfile:    &as3ResourcePointer{BigIP: "/Common/diameter-endpoint"},
+				DestinationHost:  "pcrf.example.com",
+				DestinationRealm: "example.com",
+				SupportedApps:    []string{"Gx", "Gy"},
+			}))
+			Expect(svc.ProfileDiameterEndpoint).To(Equal(&as3ResourcePointer{Use: profileName}))
+
+			// invalid values and non TCP virtual are ignored
+			for _, annotations := range []map[string]string{
+				{DiameterProfileAnnotation: "yes"},
+				{DiameterProfileAnnotation: "true", DiameterParentProfileAnnotation: "diameter"},
+				{DiameterProfileAnnotation: "true", DiameterSupportedAppsAnnotation: "Rx"},
+				{DiameterProfileAnnotation: "true", DiameterSupportedAppsAnnotation: "Gx,Sd"},
+			} {
+				vs.Annotations = annotations
+				rsCfg.Virtual.Diameter = nil
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.Diameter).To(BeNil(), "Invalid Diameter profile should be ignored")
+			}
+			vs.Annotations = map[string]string{DiameterProfileAnnotation: "true"}
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Diameter.SupportedApps).To(Equal([]string{"Gx"}), "Gx should be the default application")
+			rsCfg.Virtual.Diameter = nil
+			vs.Spec.Protocol = UDP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Diameter).To(BeNil(), "Diameter profile should be ignored for UDP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with RADIUS profile annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", RadiusAuthPort)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host:                  "test.com",
+					Protocol:              UDP,
+					VirtualServerHTTPPort: RadiusAuthPort,
+				},
+			)
+			vs.Annotations = map[string]string{
+				RadiusProfileAnnotation:             "true",
+				RadiusPersistAttributeAnnotation:    "User-Name",
+				RadiusSubscriberDiscoveryAnnotation: "true",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			discovery := true
+			Expect(rsCfg.Virtual.Radius).To(Equal(&RadiusProfile{PersistAttribute: "User-Name",
+				SubscriberDiscoveryEnabled: &discovery}))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.Class).To(Equal("Service_UDP"))
+			profileName := rsCfg.Virtual.Name + "_radius_profile"
+			Expect(svc.ProfileRadius).To(Equal(&as3ResourcePointer{Use: profileName}))
+			Expect(app[profileName]).To(Equal(&as3RadiusProfile{
+				Class:                      "Radius_Profile",
+				PersistAttribute:           "User-Name",
+				SubscriberDiscoveryEnabled: &discovery,
+			}))
+			data, _ := json.Marshal(app[profileName])
+			Expect(string(data)).To(Equal(`{"class":"Radius_Profile","persistAttribute":"User-Name",` +
+				`"subscriberDiscoveryEnabled":true}`))
+
+			// numeric attribute codes are declared as numbers, the subscriber discovery is left to BIG-IP when unset
+			rsCfg.Virtual.Radius = &RadiusProfile{PersistAttribute: "26"}
+			app = as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			data, _ = json.Marshal(app[profileName])
+			Expect(string(data)).To(Equal(`{"class":"Radius_Profile","persistAttribute":26}`))
+			vs.Annotations[RadiusPersistAttributeAnnotation] = "256"
+			rsCfg.Virtual.Radius = nil
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Radius).To(BeNil(), "Invalid attribute code should be rejected")
+			vs.Annotations[RadiusPersistAttributeAnnotation] = "User-Name"
+
+			// non-standard port is allowed by the radius-ports annotation
+			vs.Spec.VirtualServerHTTPPort = 1645
+			rsCfg.Virtual.Radius = nil
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Radius).To(BeNil(), "RADIUS profile should be ignored on non-standard port")
+			vs.Annotations[RadiusPortsAnnotation] = "1645,1646"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Radius).NotTo(BeNil(), "RADIUS profile should be allowed on configured port")
+
+			vs.Spec.VirtualServerHTTPPort = RadiusAccountingPort
+			vs.Spec.Protocol = TCP
+			rsCfg.Virtual.Radius = nil
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Radius).To(BeNil(), "RADIUS profile should be ignored for TCP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with HTTP compression annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				HTTPCompressionAnnotation:                   "true",
+				HTTPCompressionGzipAnnotation:               "false",
+				HTTPCompressionContentTypeIncludeAnnotation: "text/html, application/json",
+				HTTPCompressionMinimumSizeAnnotation:        "1024",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.HTTPCompression).To(Equal(&HTTPCompressionProfile{Deflate: true,
+				ContentTypeInclude: []string{"text/html", "application/json"}, MinimumSize: 1024}))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			profileName := rsCfg.Virtual.Name + "_http_compression_profile"
+			Expect(svc.ProfileHTTPCompression).To(Equal(&as3ResourcePointer{Use: profileName}))
+			Expect(app[profileName]).To(Equal(&as3HTTPCompressProfile{
+				Class:               "HTTP_Compress",
+				PreferMethod:        "deflate",
+				ContentTypeIncludes: []string{"text/html", "application/json"},
+				MinimumSize:         1024,
+			}))
+			data, _ := json.Marshal(app[profileName])
+			Expect(string(data)).To(ContainSubstring(`"preferMethod":"deflate"`))
+			Expect(string(data)).To(ContainSubstring(`"minimumSize":1024`))
+
+			// minimum size out of the BIG-IP range is rejected
+			for _, size := range []string{"-1", "127", "131073"} {
+				vs.Annotations[HTTPCompressionMinimumSizeAnnotation] = size
+				rsCfg.Virtual.HTTPCompression = nil
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+				Expect(rsCfg.Virtual.HTTPCompression).To(BeNil(), "Invalid minimum size should be ignored")
+			}
+
+			// gzip and deflate can't both be disabled
+			vs.Annotations[HTTPCompressionMinimumSizeAnnotation] = "128"
+			vs.Annotations[HTTPCompressionDeflateAnnotation] = "false"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.HTTPCompression).To(BeNil(), "Compression without a method should be ignored")
+
+			delete(vs.Annotations, HTTPCompressionDeflateAnnotation)
+			vs.Spec.Protocol = TCP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.HTTPCompression).To(BeNil(), "HTTP compression should be ignored for TCP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with access profile annotation", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{AccessProfileAnnotation: "/Common/access"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileAccess).To(BeEmpty(), "Access profile should be ignored without APM enabled")
+
+			mockCtlr.apmEnabled = true
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileAccess).To(Equal("/Common/access"))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			processAccessProfileForAS3(rsCfg, app, true)
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.ProfileAccess).To(BeNil(), "Access profile should be skipped without APM license")
+			processAccessProfileForAS3(rsCfg, app, false)
+			Expect(svc.ProfileAccess).To(Equal(&as3ResourcePointer{BigIP: "/Common/access"}))
+
+			rsCfg.Virtual.ProfileAccess = ""
+			vs.Spec.Protocol = UDP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileAccess).To(BeEmpty(), "Access profile should be ignored for UDP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with WebSocket enabled", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host:             "test.com",
+					WebSocketEnabled: true,
+					WebSocket: &cisapiv1.WebSocket{
+						Compression: true,
+						Masking:     WebSocketMaskingAuto,
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.WebSocket).To(Equal(&WebSocketProfile{Compression: true}))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.ProfileWebSocket).To(Equal(&as3ResourcePointer{Use: rsCfg.Virtual.Name + "_websocket"}))
+			Expect(app[rsCfg.Virtual.Name+"_websocket"]).To(Equal(&as3WebSocketProfile{
+				Class:       "WebSocket_Profile",
+				Compression: true,
+			}))
+
+			rsCfg.Virtual.WebSocket = nil
+			vs.Spec.Protocol = TCP
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.WebSocket).To(BeNil(), "WebSocket should be ignored for TCP")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with protocol mqtt", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", DefaultMQTTPort)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Protocol: MQTT,
+				},
+			)
+			Expect(mockCtlr.virtualPorts(vs)).To(Equal([]portStruct{{protocol: HTTP, port: DefaultMQTTPort}}))
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Protocol).To(Equal(TCP))
+			Expect(rsCfg.Virtual.MQTT).To(BeTrue())
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.Class).To(Equal("Service_TCP"))
+			Expect(svc.MQTTEnabled).To(BeTrue())
+		})
+
+		It("Prepare Resource Config from a VirtualServer with protocol sctp", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 3868)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Protocol:              SCTP,
+					VirtualServerHTTPPort: 3868,
+					SCTP: &cisapiv1.SCTP{
+						HeartbeatInterval: 30,
+						MaxInitRetries:    4,
+						CookieExpiry:      60,
+					},
+				},
+			)
+			Expect(mockCtlr.virtualPorts(vs)).To(Equal([]portStruct{{protocol: HTTP, port: 3868}}))
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Protocol).To(Equal(SCTP))
+			Expect(rsCfg.Virtual.SCTP).To(Equal(&SCTPProfile{HeartbeatInterval: 30, MaxInitRetries: 4, CookieExpiry: 60}))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.Class).To(Equal("Service_SCTP"))
+			Expect(svc.Layer4).To(Equal(SCTP))
+			Expect(svc.ProfileSCTP).To(Equal(&as3ResourcePointer{BigIP: "/Common/cis_sctp_30_4_60"}))
+			Expect(svc.sctpProfile).To(Equal(&bigIPSCTPProfile{
+				Name:              "cis_sctp_30_4_60",
+				Partition:         CommonPartition,
+				DefaultsFrom:      DefaultSCTPProfile,
+				HeartbeatInterval: 30,
+				InitMaxRetries:    4,
+				CookieExpiration:  60,
+			}))
+
+			processSCTPServiceForAS3(rsCfg, app, 3.47)
+			Expect(app).NotTo(HaveKey(rsCfg.Virtual.Name), "SCTP service should be removed with older AS3")
+
+			// the BIG-IP SCTP profile is used without SCTP settings
+			vs.Spec.SCTP = nil
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			app = as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc = app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.ProfileSCTP).To(Equal(&as3ResourcePointer{BigIP: DefaultSCTPProfile}))
+			Expect(svc.sctpProfile).To(BeNil())
+		})
+
+		It("Prepare Resource Config from a VirtualServer with tenant settings annotations", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			vs.Annotations = map[string]string{
+				RouteDomainAnnotation:       "10",
+				OptimisticLockKeyAnnotation: "lock-key",
+			}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(*rsCfg.MetaData.routeDomain).To(Equal(10))
+			Expect(rsCfg.MetaData.optimisticLockKey).To(Equal("lock-key"))
+
+			rsCfg2 := &ResourceConfig{}
+			rsCfg2.MetaData.ResourceType = VirtualServer
+			vs.Annotations = map[string]string{
+				RouteDomainAnnotation:       "65536",
+				OptimisticLockKeyAnnotation: strings.Repeat("k", MaxOptimisticLockKeyLength+1),
+			}
+			handleVirtualServerTenantSettings(rsCfg2, vs)
+			Expect(rsCfg2.MetaData.routeDomain).To(BeNil(), "Invalid route domain should be ignored")
+			Expect(rsCfg2.MetaData.optimisticLockKey).To(BeEmpty(), "Invalid optimistic lock key should be ignored")
+
+			// the first resource of the tenant decides the settings, the global route domain is the default
+			as3PM := &AS3PostManager{defaultRouteDomain: 5}
+			tenantDecl := as3Tenant{}
+			partitionConfig := &PartitionConfig{ResourceMap: ResourceMap{"vs1": rsCfg, "vs2": rsCfg2}}
+			as3PM.setTenantSettings("test", tenantDecl, partitionConfig)
+			Expect(tenantDecl["defaultRouteDomain"]).To(Equal(10))
+			Expect(tenantDecl["optimisticLockKey"]).To(Equal("lock-key"))
+			tenantDecl = as3Tenant{}
+			as3PM.setTenantSettings("test", tenantDecl, &PartitionConfig{ResourceMap: ResourceMap{"vs2": rsCfg2}})
+			Expect(tenantDecl["defaultRouteDomain"]).To(Equal(5))
+			Expect(tenantDecl).NotTo(HaveKey("optimisticLockKey"))
+		})
+
+		It("Prepare Resource Config from a VirtualServer in a namespace with AS3 log level", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        namespace,
+				Annotations: map[string]string{AS3LogLevelAnnotation: "debug"},
+			}}
+			mockCtlr.nsAnnotationInformer = mockCtlr.newNamespaceAnnotationInformer()
+			_ = mockCtlr.nsAnnotationInformer.nsInformer.GetIndexer().Add(ns)
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.MetaData.as3LogLevel).To(Equal("debug"))
+
+			// the VirtualServers of the namespace are re-synced when the log level changes
+			crInf, _ := mockCtlr.getNamespacedCRInformer(namespace)
+			_ = crInf.vsInformer.GetIndexer().Add(vs)
+			updatedNS := ns.DeepCopy()
+			updatedNS.Annotations[AS3LogLevelAnnotation] = "error"
+			mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+				workqueue.DefaultControllerRateLimiter(), "custom-resource-controller")
+			mockCtlr.enqueueUpdatedNamespaceAnnotations(ns, ns.DeepCopy())
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(0), "VirtualServer re-synced without annotation change")
+			mockCtlr.enqueueUpdatedNamespaceAnnotations(ns, updatedNS)
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(1), "VirtualServer not re-synced on log level change")
+
+			as3PM := &AS3PostManager{}
+			tenantDecl := as3Tenant{}
+			as3PM.setTenantSettings("test", tenantDecl, &PartitionConfig{ResourceMap: ResourceMap{"vs1": rsCfg}})
+			Expect(tenantDecl["controls"]).To(Equal(map[string]interface{}{
+				"class":    "Controls",
+				"logLevel": "debug",
+			}))
+
+			// invalid log levels are ignored
+			ns.Annotations[AS3LogLevelAnnotation] = "verbose"
+			_ = mockCtlr.nsAnnotationInformer.nsInformer.GetIndexer().Update(ns)
+			rsCfg2 := &ResourceConfig{}
+			mockCtlr.handleVirtualServerAS3LogLevel(rsCfg2, vs)
+			Expect(rsCfg2.MetaData.as3LogLevel).To(BeEmpty())
+			tenantDecl = as3Tenant{}
+			as3PM.setTenantSettings("test", tenantDecl, &PartitionConfig{ResourceMap: ResourceMap{"vs2": rsCfg2}})
+			Expect(tenantDecl).NotTo(HaveKey("controls"))
+		})
+
+		It("Prepare Resource Config from a TCP VirtualServer", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Protocol: TCP,
+					Pools: []cisapiv1.VSPool{
+						{
+							Name:        "db-pool",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 5432},
+						},
+					},
+				},
+			)
+			vs.Annotations = map[string]string{TCPProfileAnnotation: "/Common/f5-tcp-lan"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Protocol).To(Equal(TCP))
+			Expect(rsCfg.Virtual.PoolName).To(Equal("db-pool"), "Default pool not set for TCP virtual")
+			Expect(rsCfg.Policies).To(BeEmpty(), "LTM policies should not be created for TCP virtual")
+			Expect(rsCfg.Virtual.TCP.Client).To(Equal("/Common/f5-tcp-lan"))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.Class).To(Equal("Service_TCP"))
+			Expect(svc.ProfileTCP).To(Equal(&as3ResourcePointer{BigIP: "/Common/f5-tcp-lan"}))
+		})
+
+		It("Prepare Resource Config from a UDP VirtualServer", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 53)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Protocol: UDP,
+					Pools: []cisapiv1.VSPool{
+						{
+							Name:        "dns-pool",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 53},
+						},
+					},
+				},
+			)
+			vs.Annotations = map[string]string{UDPProfileAnnotation: "/Common/udp_gtm_dns"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Protocol).To(Equal(UDP))
+			Expect(rsCfg.Virtual.PoolName).To(Equal("dns-pool"), "Default pool not set for UDP virtual")
+			Expect(rsCfg.Policies).To(BeEmpty(), "LTM policies should not be created for UDP virtual")
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.Class).To(Equal("Service_UDP"))
+			Expect(svc.Layer4).To(Equal(UDP))
+			Expect(svc.ProfileUDP).To(Equal(&as3ResourcePointer{BigIP: "/Common/udp_gtm_dns"}))
+		})
+
+		It("Prepare Resource Config from a SIP VirtualServer", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", DefaultSIPPort)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Protocol: SIP,
+					SIP:      &cisapiv1.SIP{Transport: TCP, DialogAware: true},
+					Pools: []cisapiv1.VSPool{
+						{
+							Name:        "sip-pool",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: DefaultSIPPort},
+						},
+					},
+				},
+			)
+			Expect(getSIPPort(vs)).To(Equal(int32(DefaultSIPPort)))
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Protocol).To(Equal(TCP))
+			Expect(rsCfg.Virtual.SIP).To(BeTrue())
+			Expect(rsCfg.Virtual.PersistenceProfile).To(Equal(SIPPersistenceMethod))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.Class).To(Equal("Service_TCP"), "SIP profile is a property of the L4 services")
+			Expect(svc.Layer4).To(Equal(TCP))
+			Expect(svc.ProfileSIP).To(Equal(&as3ResourcePointer{Use: rsCfg.Virtual.Name + "_sip_profile"}))
+			Expect(app[rsCfg.Virtual.Name+"_sip_profile"]).To(Equal(&as3SIPProfile{Class: "SIP_Profile"}))
+		})
+
+		It("Prepare Resource Config from a VirtualServer of other IP protocol", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.Virtual.Destination = "/test/10.1.1.1:80"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Pools: []cisapiv1.VSPool{
+						{
+							Name:        "gre-pool",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 80},
+						},
+					},
+				},
+			)
+			vs.Annotations = map[string]string{ProtocolAnnotation: ProtocolOther, IPProtocolNumberAnnotation: "47"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Protocol).To(Equal(ProtocolOther))
+			Expect(rsCfg.Virtual.IpProtocol).To(Equal("47"))
+			Expect(rsCfg.Virtual.PoolName).To(Equal("gre-pool"), "Default pool not set for other IP protocol virtual")
+			Expect(rsCfg.Policies).To(BeEmpty(), "LTM policies should not be created for other IP protocol virtual")
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc := app[rsCfg.Virtual.Name].(*as3Service)
+			Expect(svc.Class).To(Equal("Service_Generic"))
+			Expect(svc.Layer4).To(Equal("47"))
+			Expect(svc.ProfileIPOther).To(Equal(&as3ResourcePointer{BigIP: DefaultIPOtherProfile}))
+			Expect(svc.VirtualAddresses).To(Equal([]as3MultiTypeParam{"10.1.1.1"}))
+			Expect(svc.VirtualPort).To(BeZero(), "Virtual should listen on any port")
+
+			_, err = parseIPProtocolNumber("256")
+			Expect(err).To(HaveOccurred(), "Protocol number out of range should be rejected")
+			_, err = parseIPProtocolNumber("17")
+			Expect(err).To(HaveOccurred(), "UDP protocol number should be rejected")
+		})
+
+		It("Prepare Resource Config from a forwarding VirtualServer", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.Virtual.Destination = "/test/10.1.1.1:80"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					SNAT: "none",
+					Pools: []cisapiv1.VSPool{
+						{
+							Path:        "/",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 80},
+						},
+					},
+				},
+			)
+			vs.Annotations = map[string]string{ForwardingAnnotation: "true", ForwardingTypeAnnotation: "l2",
+				ForwardingMirroringAnnotation: "true"}
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Forwarding).To(Equal(&ForwardingConfig{Type: ForwardingTypeL2, Mirroring: true}))
+
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			Expect(app[rsCfg.Virtual.Name]).To(Equal(&as3Service{
+				Class:            "Service_Forwarding",
+				Layer4:           "any",
+				ForwardingType:   ForwardingTypeL2,
+				VirtualAddresses: []as3MultiTypeParam{"10.1.1.1"},
+				VirtualPort:      80,
+				SNAT:             "none",
+				Mirroring:        "L4",
+			}), "Forwarding service should not terminate the connections")
+
+			rsCfg.Virtual.Forwarding = nil
+			vs.Annotations = map[string]string{ForwardingAnnotation: "true", ForwardingTypeAnnotation: "l3"}
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.Forwarding).To(BeNil(), "Invalid forwarding type should be skipped")
+		})
+
+		It("Prepare Resource Config from passthrough VirtualServers with SNI routing", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 443)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.Virtual.Destination = "/test/10.1.1.1:443"
+			rsCfg.Virtual.SetVirtualAddress("10.1.1.1", 443)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			for _, host := range []string{"foo.com", "*.bar.com"} {
+				vs := test.NewVirtualServer(
+					"SampleVS",
+					namespace,
+					cisapiv1.VirtualServerSpec{
+						Host:           host,
+						TLSProfileName: "passthrough-tls",
+						Pools: []cisapiv1.VSPool{
+							{
+								Path:        "/",
+								Service:     "svc1",
+								ServicePort: intstr.IntOrString{IntVal: 443},
+							},
+						},
+					},
+				)
+				vs.Annotations = map[string]string{SNIRoutingAnnotation: "true"}
+				err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, true, TLSPassthrough)
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			}
+			Expect(rsCfg.Policies).To(HaveLen(1), "VirtualServers sharing the virtual should share the policy")
+			Expect(rsCfg.Policies[0].Rules).To(HaveLen(2))
+
+			app := as3Application{}
+			createPoliciesDecl(rsCfg, app)
+			ep := app[rsCfg.Policies[0].Name].(*as3EndpointPolicy)
+			Expect(ep.Rules).To(HaveLen(2))
+			serverNames := make(map[string]string)
+			for _, rl := range ep.Rules {
+				Expect(rl.Conditions).To(HaveLen(1))
+				Expect(rl.Conditions[0].Type).To(Equal("sslExtension"))
+				Expect(rl.Conditions[0].Event).To(Equal("ssl-client-hello"))
+				serverNames[rl.Conditions[0].ServerName.Values[0]] = rl.Conditions[0].ServerName.Operand
+				Expect(rl.Actions).To(HaveLen(1))
+				Expect(rl.Actions[0].Type).To(Equal("forward"))
+				Expect(rl.Actions[0].Event).To(Equal("ssl-client-hello"))
+				Expect(rl.Actions[0].Select.Pool).NotTo(BeNil())
+			}
+			Expect(serverNames).To(Equal(map[string]string{"foo.com": "equals", ".bar.com": "ends-with"}))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with urlMap", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.VSPool{
+						{
+							Name:        "pool1",
+							Path:        "/foo",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 80},
+						},
+					},
+					URLMap: []cisapiv1.URLRule{
+						{
+							Host:          "app.test.com",
+							Path:          "/bar",
+							Pool:          "pool1",
+							RewriteTarget: "/baz",
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(len(rsCfg.Policies)).To(Equal(1))
+			var urlMapRule *Rule
+			for _, rl := range rsCfg.Policies[0].Rules {
+				if rl.FullURI == "app.test.com/bar" {
+					urlMapRule = rl
+				}
+			}
+			Expect(urlMapRule).NotTo(BeNil(), "urlMap rule not created")
+			Expect(urlMapRule.Actions[0].Pool).To(Equal("pool1"))
+			Expect(urlMapRule.Actions[1].HTTPURI).To(BeTrue(), "rewrite action not created")
+			Expect(urlMapRule.Actions[1].Value).To(Equal("tcl:[regsub /bar [HTTP::uri] /baz ]"))
+
+			// invalid pool reference
+			rsCfg.Policies = nil
+			vs.Spec.URLMap[0].Pool = "unknown"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
+			Expect(err).NotTo(BeNil(), "urlMap with unknown pool should fail")
+		})
+
 		It("Validate Resource Config from a AB Deployment VirtualServer", func() {
 			rsCfg.MetaData.ResourceType = VirtualServer
 			rsCfg.Virtual.Enabled = true
@@ -884,6 +3129,154 @@ var _ = Describe("Resource Config Tests", func() {
 		Expect(ok).To(BeFalse(), "TLS Edge Validation Failed")
 	})
 
+	It("Validate and translate TLS Profile with OCSP/CRL checks", func() {
+		tlsEdge := test.NewTLSProfile(
+			"sampleTLS",
+			namespace,
+			cisapiv1.TLSProfileSpec{
+				TLS: cisapiv1.TLS{
+					Termination: TLSEdge,
+					ClientSSL:   "clientssl",
+				},
+				OCSPCRLProfile: &cisapiv1.CertificateValidatorConfig{CRLEnabled: true, CRLFile: "/Common/crl"},
+			},
+		)
+		Expect(validateTLSProfile(tlsEdge)).To(BeFalse(), "CRL without trustCA should be rejected")
+		tlsEdge.Spec.OCSPCRLProfile = &cisapiv1.CertificateValidatorConfig{TrustCA: "/Common/ca", CRLEnabled: true}
+		Expect(validateTLSProfile(tlsEdge)).To(BeFalse(), "CRL without crlFile should be rejected")
+		tlsEdge.Spec.OCSPCRLProfile = &cisapiv1.CertificateValidatorConfig{TrustCA: "/Common/ca", OCSPEnabled: true}
+		Expect(validateTLSProfile(tlsEdge)).To(BeFalse(), "OCSP without dnsResolver should be rejected")
+		tlsEdge.Spec.OCSPCRLProfile = &cisapiv1.CertificateValidatorConfig{RequireOCSPStatus: true}
+		Expect(validateTLSProfile(tlsEdge)).To(BeFalse(), "requireOCSPStatus without OCSP should be rejected")
+		tlsEdge.Spec.OCSPCRLProfile = &cisapiv1.CertificateValidatorConfig{TrustCA: "/Common/ca", OCSPEnabled: true,
+			DNSResolver: "/Common/resolver", RequireOCSPStatus: true}
+		Expect(validateTLSProfile(tlsEdge)).To(BeTrue(), "TLS Edge Validation Failed")
+
+		svcName := "crd_10_1_1_1_443"
+		newApp := func() as3Application {
+			return as3Application{
+				svcName + "_tls_server": &as3TLSServer{
+					Class:        "TLS_Server",
+					Certificates: []as3TLSServerCertificates{{Certificate: "cert_0"}},
+				},
+				"cert_0": &as3Certificate{Class: "Certificate"},
+			}
+		}
+		rsCfg := &ResourceConfig{}
+		rsCfg.Virtual.CertificateValidator = CertificateValidator{TrustCA: "/Common/ca", OCSPEnabled: true,
+			OCSPResponderURL: "http://ocsp.example.com", DNSResolver: "/Common/resolver", CRLEnabled: true,
+			CRLFile: "/Common/crl", RequireOCSPStatus: true}
+		app := newApp()
+		createCertificateValidatorDecl(rsCfg, app, svcName)
+		tlsServer := app[svcName+"_tls_server"].(*as3TLSServer)
+		Expect(app[svcName+"_ocsp_validator"]).To(Equal(&as3CertificateValidatorOCSP{
+			Class:        "Certificate_Validator_OCSP",
+			ResponderURL: "http://ocsp.example.com",
+			DNSResolver:  &as3ResourcePointer{BigIP: "/Common/resolver"},
+		}))
+		Expect(tlsServer.C3DOCSP).To(Equal(&as3ResourcePointer{Use: svcName + "_ocsp_validator"}))
+		Expect(tlsServer.C3DOCSPUnknownStatusAction).To(Equal("drop"))
+		Expect(tlsServer.AuthenticationMode).To(Equal("require"))
+		Expect(tlsServer.AuthenticationTrustCA).To(Equal(&as3ResourcePointer{BigIP: "/Common/ca"}))
+		Expect(tlsServer.CRLFile).To(BeNil(), "CRL should be ignored when OCSP is enabled")
+
+		rsCfg.Virtual.CertificateValidator = CertificateValidator{TrustCA: "/Common/ca", CRLEnabled: true,
+			CRLFile: "/Common/crl"}
+		app = newApp()
+		createCertificateValidatorDecl(rsCfg, app, svcName)
+		tlsServer = app[svcName+"_tls_server"].(*as3TLSServer)
+		Expect(tlsServer.CRLFile).To(Equal(&as3ResourcePointer{BigIP: "/Common/crl"}))
+		Expect(tlsServer.AuthenticationMode).To(Equal("require"))
+		Expect(tlsServer.AuthenticationTrustCA).To(Equal(&as3ResourcePointer{BigIP: "/Common/ca"}))
+		Expect(tlsServer.C3DOCSP).To(BeNil())
+		Expect(app).NotTo(HaveKey(svcName + "_ocsp_validator"))
+	})
+
+	It("Validate and translate TLS Profile with cipher group", func() {
+		tlsEdge := test.NewTLSProfile(
+			"sampleTLS",
+			namespace,
+			cisapiv1.TLSProfileSpec{
+				TLS: cisapiv1.TLS{
+					Termination: TLSEdge,
+					ClientSSL:   "clientssl",
+				},
+				CipherGroup: &cisapiv1.CipherGroupConfig{
+					Name:  "strong-ciphers",
+					Rules: []cisapiv1.CipherRule{{Name: "no-allow-or-deny"}},
+				},
+			},
+		)
+		Expect(validateTLSProfile(tlsEdge)).To(BeFalse(), "Cipher rule without allowList or denyList should be rejected")
+		tlsEdge.Spec.CipherGroup.Rules = []cisapiv1.CipherRule{
+			{Name: "ecdhe", AllowList: []string{"ECDHE"}, OrderBy: "strength"},
+			{Name: "weak", DenyList: []string{"RC4", "3DES"}, OrderBy: "speed"},
+		}
+		Expect(validateTLSProfile(tlsEdge)).To(BeFalse(), "Conflicting orderBy should be rejected")
+		tlsEdge.Spec.CipherGroup.Rules[1].OrderBy = "fastest"
+		Expect(validateTLSProfile(tlsEdge)).To(BeFalse(), "Invalid orderBy should be rejected")
+		tlsEdge.Spec.CipherGroup.Rules[1].OrderBy = ""
+		Expect(validateTLSProfile(tlsEdge)).To(BeTrue(), "TLS Edge Validation Failed")
+		tlsPst := tlsEdge.DeepCopy()
+		tlsPst.Spec.TLS = cisapiv1.TLS{Termination: TLSPassthrough}
+		Expect(validateTLSProfile(tlsPst)).To(BeFalse(), "Cipher group should be rejected for passthrough")
+
+		svcName := "crd_10_1_1_1_443"
+		app := as3Application{
+			svcName + "_tls_server": &as3TLSServer{
+				Class:        "TLS_Server",
+				Certificates: []as3TLSServerCertificates{{Certificate: "cert_0"}},
+				Ciphers:      "DEFAULT",
+			},
+		}
+		rsCfg := &ResourceConfig{}
+		rsCfg.Virtual.CipherGroup = &CipherGroup{Name: "strong-ciphers", Order: "strength", Rules: []CipherRule{
+			{Name: "ecdhe", AllowList: []string{"ECDHE"}},
+			{Name: "weak", DenyList: []string{"RC4", "3DES"}},
+		}}
+		createCipherGroupDecl(rsCfg, app, svcName)
+		Expect(app["strong_ciphers_ecdhe"]).To(Equal(&as3CipherRule{Class: "Cipher_Rule", CipherSuites: []string{"ECDHE"}}))
+		Expect(app["strong_ciphers_weak_deny"]).To(Equal(&as3CipherRule{Class: "Cipher_Rule",
+			CipherSuites: []string{"RC4", "3DES"}}))
+		Expect(app["strong_ciphers"]).To(Equal(&as3CipherGroup{
+			Class:              "Cipher_Group",
+			Order:              "strength",
+			AllowCipherRules:   []as3ResourcePointer{{Use: "strong_ciphers_ecdhe"}},
+			ExcludeCipherRules: []as3ResourcePointer{{Use: "strong_ciphers_weak_deny"}},
+		}))
+		tlsServer := app[svcName+"_tls_server"].(*as3TLSServer)
+		Expect(tlsServer.CipherGroup).To(Equal(&as3ResourcePointer{Use: "strong_ciphers"}))
+		Expect(tlsServer.Ciphers).To(BeEmpty(), "ciphers and cipherGroup are mutually exclusive")
+
+		// identical cipher groups of two virtuals are shared
+		otherSvcName := "crd_10_1_1_2_443"
+		otherApp := as3Application{
+			otherSvcName + "_tls_server": &as3TLSServer{Class: "TLS_Server", Ciphers: "DEFAULT"},
+		}
+		otherCfg := &ResourceConfig{}
+		otherCfg.Virtual.CipherGroup = &CipherGroup{Name: "same-ciphers", Order: "strength", Rules: []CipherRule{
+			{Name: "allow", AllowList: []string{"ECDHE"}},
+			{Name: "deny", DenyList: []string{"RC4", "3DES"}},
+		}}
+		createCipherGroupDecl(otherCfg, otherApp, otherSvcName)
+		sharedApp := as3Application{}
+		shareCipherGroupsForAS3(app, sharedApp, "test")
+		shareCipherGroupsForAS3(otherApp, sharedApp, "test")
+		Expect(sharedApp).To(HaveLen(3), "Identical cipher groups should be declared once")
+		Expect(app).NotTo(HaveKey("strong_ciphers"))
+		Expect(app).NotTo(HaveKey("strong_ciphers_ecdhe"))
+		Expect(otherApp).NotTo(HaveKey("same_ciphers"))
+		Expect(tlsServer.CipherGroup.Use).To(HavePrefix("/test/Shared/cipher_group_"))
+		Expect(otherApp[otherSvcName+"_tls_server"].(*as3TLSServer).CipherGroup).To(Equal(tlsServer.CipherGroup))
+		sharedName := strings.TrimPrefix(tlsServer.CipherGroup.Use, "/test/Shared/")
+		Expect(sharedApp[sharedName]).To(Equal(&as3CipherGroup{
+			Class:              "Cipher_Group",
+			Order:              "strength",
+			AllowCipherRules:   []as3ResourcePointer{{Use: sharedName + "_allow0"}},
+			ExcludeCipherRules: []as3ResourcePointer{{Use: sharedName + "_exclude0"}},
+		}))
+	})
+
 	It("Validate Multiple TLS Profiles", func() {
 		tlsRenc := test.NewTLSProfile(
 			"sampleTLS",
@@ -1556,6 +3949,7 @@ var _ = Describe("Resource Config Tests", func() {
 
 			rsCfg.Virtual.SNAT = ""
 			vs.Spec.SNAT = ""
+			rsCfg.Virtual.SNAT = ""
 			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false, "")
 			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
 			Expect(rsCfg.Virtual.SNAT).To(Equal(DEFAULT_SNAT), "Default SNAT should be set "+
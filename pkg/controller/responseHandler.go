@@ -1,13 +1,17 @@
 package controller
 
 import (
+	"context"
+	"fmt"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func (ctlr *Controller) enqueueReq(config BigIpResourceConfig, bigIpConfig cisapiv1.BigIpConfig) requestMeta {
@@ -33,6 +37,58 @@ func (ctlr *Controller) enqueueReq(config BigIpResourceConfig, bigIpConfig cisap
 	return rm
 }
 
+// setDevices records the BIG-IPs every tenant is posted to, the result of the latest post is kept for the BIG-IPs
+// the tenant was posted to before
+func (tp *tenantPosts) setDevices(deviceConfigs map[cisapiv1.BigIpConfig]BigIpResourceConfig) {
+	tp.Lock()
+	defer tp.Unlock()
+	devices := make(map[string]map[cisapiv1.BigIpConfig]bool)
+	for device, config := range deviceConfigs {
+		for tenant := range config.ltmConfig {
+			if _, ok := devices[tenant]; !ok {
+				devices[tenant] = make(map[cisapiv1.BigIpConfig]bool)
+			}
+			devices[tenant][device] = tp.devices[tenant][device]
+		}
+	}
+	tp.devices = devices
+}
+
+// posting resets the result of the tenants posted to the BIG-IP until its response is received
+func (tp *tenantPosts) posting(device cisapiv1.BigIpConfig, tenants map[string]struct{}) {
+	tp.Lock()
+	defer tp.Unlock()
+	for tenant := range tenants {
+		if _, ok := tp.devices[tenant][device]; ok {
+			tp.devices[tenant][device] = false
+		}
+	}
+}
+
+// update records the result of the tenants of the response of the BIG-IP
+func (tp *tenantPosts) update(config *agentConfig) {
+	tp.Lock()
+	defer tp.Unlock()
+	for tenant := range config.reqMeta.partitionMap {
+		if _, ok := tp.devices[tenant][config.BigIpConfig]; ok {
+			_, failed := config.as3Config.failedTenants[tenant]
+			tp.devices[tenant][config.BigIpConfig] = !failed
+		}
+	}
+}
+
+// acceptedByAll returns whether all the BIG-IPs the tenant is posted to accepted it
+func (tp *tenantPosts) acceptedByAll(tenant string) bool {
+	tp.Lock()
+	defer tp.Unlock()
+	for _, accepted := range tp.devices[tenant] {
+		if !accepted {
+			return false
+		}
+	}
+	return true
+}
+
 func (ctlr *Controller) responseHandler(respChan chan *agentConfig) {
 	// todo: update only when there is a change(success to fail or vice versa) in tenant status
 	ctlr.requestMap = &requestMap{sync.RWMutex{}, make(map[cisapiv1.BigIpConfig]requestMeta)}
@@ -40,17 +96,24 @@ func (ctlr *Controller) responseHandler(respChan chan *agentConfig) {
 	bigipLabel := BigIPLabel
 	bigipConfig := ctlr.getBIGIPConfig(bigipLabel)
 	for config := range respChan {
+		ctlr.tenantPosts.update(config)
 		ctlr.requestMap.Lock()
 		latestRequestMeta, _ := ctlr.requestMap.requestMap[config.BigIpConfig]
 		ctlr.requestMap.Unlock()
 		if len(config.as3Config.failedTenants) > 0 && latestRequestMeta.id == config.id {
 			// if the current request id is same as the failed tenant request id, then retry the failed tenants
 			ctlr.RequestHandler.PostManagers.RLock()
-			pm := ctlr.RequestHandler.PostManagers.PostManagerMap[config.BigIpConfig]
-			// Delay the retry of failed tenants
-			<-time.After(timeoutMedium)
-			pm.postChan <- *config
+			pm, ok := ctlr.RequestHandler.PostManagers.PostManagerMap[config.BigIpConfig]
 			ctlr.RequestHandler.PostManagers.RUnlock()
+			if ok {
+				// Delay the retry of failed tenants with the backoff of the post manager
+				retryDelay := config.as3Config.retryDelay
+				if retryDelay == 0 {
+					retryDelay = timeoutMedium
+				}
+				log.Debugf("[AS3] Retrying %v failed tenants in %v", len(config.as3Config.failedTenants), retryDelay)
+				pm.scheduleRetry(*config, retryDelay)
+			}
 		}
 		if latestRequestMeta.id >= config.id && len(config.as3Config.failedTenants) == 0 {
 			// Handle the network routes after successful post of tenants
@@ -64,6 +127,14 @@ func (ctlr *Controller) responseHandler(respChan chan *agentConfig) {
 					ctlr.resources.updatePartitionPriority(partition, 0, bigipConfig)
 					continue
 				}
+				// the dry-run doesn't configure the resources on BIG-IP, so their status is left unchanged
+				if ctlr.PostParams.DryRun {
+					continue
+				}
+				// a tenant posted to more than one BIG-IP is reported once all of them accepted it
+				if !ctlr.tenantPosts.acceptedByAll(partition) {
+					continue
+				}
 				for rscKey, kind := range meta {
 					if ctlr.ipamHandler != nil && (kind == VirtualServer || kind == TransportServer) {
 						ctlr.ipamHandler.RemoveUnusedIPAMEntries()
@@ -107,6 +178,11 @@ func (ctlr *Controller) responseHandler(respChan chan *agentConfig) {
 					//		}
 					//	}
 
+					case VirtualServer:
+						if _, found := config.as3Config.failedTenants[partition]; !found {
+							ctlr.updateVirtualServerConditions(rscKey, config.BigIpConfig)
+						}
+
 					case TransportServer:
 						// update status
 						crInf, ok := ctlr.getNamespacedCRInformer(ns)
@@ -177,3 +253,135 @@ func (ctlr *Controller) responseHandler(respChan chan *agentConfig) {
 		}
 	}
 }
+
+// updateVirtualServerConditions records the conditions of the VirtualServer, which are known only after
+// the declaration of its tenant is posted to BIG-IP
+func (ctlr *Controller) updateVirtualServerConditions(rscKey string, bigIpConfig cisapiv1.BigIpConfig) {
+	crInf, ok := ctlr.getNamespacedCRInformer(strings.Split(rscKey, "/")[0])
+	if !ok {
+		return
+	}
+	obj, exist, err := crInf.vsInformer.GetIndexer().GetByKey(rscKey)
+	if err != nil || !exist {
+		return
+	}
+	vs := obj.(*cisapiv1.VirtualServer)
+	vsCopy := vs.DeepCopy()
+	dosUpdated := ctlr.setVirtualServerDOSCondition(vsCopy, bigIpConfig)
+	inspectionUpdated := ctlr.setVirtualServerProtocolInspectionCondition(vsCopy, bigIpConfig)
+	snatUpdated := ctlr.setVirtualServerSNATCondition(vsCopy)
+	if !dosUpdated && !inspectionUpdated && !snatUpdated {
+		return
+	}
+	_, updateErr := ctlr.clientsets.KubeCRClient.CisV1().VirtualServers(vs.Namespace).UpdateStatus(context.TODO(), vsCopy, metav1.UpdateOptions{})
+	if nil != updateErr {
+		log.Debugf("Error while updating VirtualServer status:%v", updateErr)
+	}
+}
+
+// setVirtualServerDOSCondition records whether the DoS profile of the VirtualServer is applied on BIG-IP
+// and returns true when the condition is changed
+func (ctlr *Controller) setVirtualServerDOSCondition(vs *cisapiv1.VirtualServer, bigIpConfig cisapiv1.BigIpConfig) bool {
+	if _, ok := vs.Annotations[DOSProfileAnnotation]; !ok {
+		return false
+	}
+	status, reason, message := metav1.ConditionTrue, VSReasonDOSApplied, ""
+	rsCfg := &ResourceConfig{}
+	handleVirtualServerDOS(rsCfg, vs)
+	if rsCfg.Virtual.ProfileDOS == "" && rsCfg.Virtual.DOS == nil {
+		status, reason = metav1.ConditionFalse, VSReasonInvalidDOS
+		message = fmt.Sprintf("%v annotation is invalid or conflicts with dos", DOSProfileAnnotation)
+	} else if ctlr.isAFMUnavailable(bigIpConfig) {
+		status, reason = metav1.ConditionFalse, VSReasonDOSAFMUnavailable
+		message = "AFM module is not licensed or provisioned on BIG-IP"
+	}
+	return setVirtualServerCondition(vs, VSConditionDOSEnabled, status, reason, message)
+}
+
+// setVirtualServerProtocolInspectionCondition records whether the protocol inspection profile of the VirtualServer
+// is applied on BIG-IP and returns true when the condition is changed
+func (ctlr *Controller) setVirtualServerProtocolInspectionCondition(vs *cisapiv1.VirtualServer,
+	bigIpConfig cisapiv1.BigIpConfig) bool {
+	value, ok := vs.Annotations[ProtocolInspectionAnnotation]
+	if !ok {
+		return false
+	}
+	if enabled, err := strconv.ParseBool(value); err == nil && !enabled {
+		return false
+	}
+	status, reason, message := metav1.ConditionTrue, VSReasonProtocolInspectionApplied, ""
+	rsCfg := &ResourceConfig{}
+	handleVirtualServerProtocolInspection(rsCfg, vs)
+	if rsCfg.Virtual.ProfileProtocolInspection == "" {
+		status, reason = metav1.ConditionFalse, VSReasonInvalidProtocolInspection
+		message = fmt.Sprintf("%v or %v annotation is invalid", ProtocolInspectionAnnotation,
+			ProtocolInspectionProfileAnnotation)
+	} else if ctlr.isAFMUnavailable(bigIpConfig) {
+		status, reason = metav1.ConditionFalse, VSReasonDOSAFMUnavailable
+		message = "AFM module is not licensed or provisioned on BIG-IP"
+	} else if as3Version := ctlr.getBigIPAS3Version(bigIpConfig); as3Version != 0 &&
+		as3Version < ProtocolInspectionMinAS3Version {
+		status, reason = metav1.ConditionFalse, VSReasonProtocolInspectionAS3Unsupported
+		message = fmt.Sprintf("protocol inspection is supported from AS3 v%v onwards, BIG-IP runs AS3 v%v",
+			ProtocolInspectionMinAS3Version, as3Version)
+	}
+	return setVirtualServerCondition(vs, VSConditionProtocolInspectionEnabled, status, reason, message)
+}
+
+// setVirtualServerSNATCondition records whether the snat of the VirtualServer is applied on BIG-IP
+// and returns true when the condition is changed
+func (ctlr *Controller) setVirtualServerSNATCondition(vs *cisapiv1.VirtualServer) bool {
+	if vs.Spec.SNAT == "" {
+		return false
+	}
+	status, reason, message := metav1.ConditionTrue, VSReasonSNATApplied, fmt.Sprintf("snat %v", vs.Spec.SNAT)
+	if isSNATPoolName(vs.Spec.SNAT) {
+		if _, err := ctlr.getVirtualServerSNATPool(vs); err != nil {
+			status, reason = metav1.ConditionFalse, VSReasonSNATPoolUnavailable
+			message = fmt.Sprintf("SNATPool %v is not applied, using %v snat: %v", vs.Spec.SNAT, DEFAULT_SNAT, err)
+		}
+	}
+	return setVirtualServerCondition(vs, VSConditionSNATConfigured, status, reason, message)
+}
+
+// setVirtualServerCondition sets the condition of the VirtualServer and returns true when it's changed
+func setVirtualServerCondition(vs *cisapiv1.VirtualServer, conditionType string, status metav1.ConditionStatus,
+	reason, message string) bool {
+	cond := meta.FindStatusCondition(vs.Status.Conditions, conditionType)
+	if cond != nil && cond.Status == status && cond.Reason == reason && cond.Message == message {
+		return false
+	}
+	meta.SetStatusCondition(&vs.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: vs.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return true
+}
+
+// isAFMUnavailable checks whether the AFM module is known to be unlicensed or unprovisioned on the BIG-IP
+func (ctlr *Controller) isAFMUnavailable(bigIpConfig cisapiv1.BigIpConfig) bool {
+	if ctlr.RequestHandler == nil {
+		return false
+	}
+	ctlr.RequestHandler.PostManagers.RLock()
+	defer ctlr.RequestHandler.PostManagers.RUnlock()
+	pm, ok := ctlr.RequestHandler.PostManagers.PostManagerMap[bigIpConfig]
+	return ok && pm.AS3PostManager != nil && pm.AS3PostManager.afmUnavailable()
+}
+
+// getBigIPAS3Version returns the AS3 version of the BIG-IP, 0 when it's not known yet
+func (ctlr *Controller) getBigIPAS3Version(bigIpConfig cisapiv1.BigIpConfig) float64 {
+	if ctlr.RequestHandler == nil {
+		return 0
+	}
+	ctlr.RequestHandler.PostManagers.RLock()
+	defer ctlr.RequestHandler.PostManagers.RUnlock()
+	pm, ok := ctlr.RequestHandler.PostManagers.PostManagerMap[bigIpConfig]
+	if !ok || pm.AS3PostManager == nil {
+		return 0
+	}
+	return pm.AS3PostManager.bigIPAS3Version
+}
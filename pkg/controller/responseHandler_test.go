@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	crdfake "github.com/F5Networks/k8s-bigip-ctlr/v3/config/client/clientset/versioned/fake"
+	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/test"
+)
+
+var _ = Describe("Response Handler Tests", func() {
+	var mockCtlr *mockController
+	BeforeEach(func() {
+		mockCtlr = newMockController()
+	})
+
+	Describe("Protocol inspection condition of VirtualServer", func() {
+		It("Protocol inspection profile is reported in status condition", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{Host: "test.com"})
+			vs.Annotations = map[string]string{ProtocolInspectionAnnotation: "true"}
+			mockCtlr.clientsets.KubeCRClient = crdfake.NewSimpleClientset(vs)
+			mockCtlr.clientsets.KubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.managedResources.ManageCustomResources = true
+			mockCtlr.resourceSelectorConfig.customResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			mockCtlr.resourceSelectorConfig.nativeResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			mockCtlr.crInformers = make(map[string]*CRInformer)
+			mockCtlr.comInformers = make(map[string]*CommonInformer)
+			_ = mockCtlr.addNamespacedInformers("default", false)
+			mockCtlr.addVirtualServer(vs)
+			bigIpKey := cisapiv1.BigIpConfig{BigIpAddress: "10.8.3.11", BigIpLabel: "bigip1"}
+			mockCtlr.RequestHandler = newMockAgent("")
+			as3PostMgr := &AS3PostManager{unprovisionedModules: map[string]struct{}{ModuleAFM: {}}}
+			mockCtlr.RequestHandler.PostManagers.PostManagerMap[bigIpKey] = &PostManager{AS3PostManager: as3PostMgr}
+			vsClient := mockCtlr.clientsets.KubeCRClient.CisV1().VirtualServers("default")
+
+			mockCtlr.updateVirtualServerConditions("default/vs1", bigIpKey)
+			updated, err := vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			Expect(err).To(BeNil())
+			cond := meta.FindStatusCondition(updated.Status.Conditions, VSConditionProtocolInspectionEnabled)
+			Expect(cond).NotTo(BeNil(), "ProtocolInspectionEnabled condition not set")
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(VSReasonDOSAFMUnavailable))
+
+			as3PostMgr.unprovisionedModules = nil
+			as3PostMgr.bigIPAS3Version = 3.15
+			mockCtlr.updateVirtualServerConditions("default/vs1", bigIpKey)
+			updated, _ = vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			cond = meta.FindStatusCondition(updated.Status.Conditions, VSConditionProtocolInspectionEnabled)
+			Expect(cond.Reason).To(Equal(VSReasonProtocolInspectionAS3Unsupported))
+
+			as3PostMgr.bigIPAS3Version = 3.48
+			mockCtlr.updateVirtualServerConditions("default/vs1", bigIpKey)
+			updated, _ = vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions,
+				VSConditionProtocolInspectionEnabled)).To(BeTrue())
+		})
+	})
+
+	Describe("Tenants posted to more than one BIG-IP", func() {
+		It("Tenant is reported once all the BIG-IPs accepted it", func() {
+			bigip1 := cisapiv1.BigIpConfig{BigIpAddress: "10.8.3.11", BigIpLabel: "bigip1"}
+			bigip2 := cisapiv1.BigIpConfig{BigIpAddress: "10.8.3.12", BigIpLabel: "bigip2"}
+			tp := &mockCtlr.tenantPosts
+			tp.setDevices(map[cisapiv1.BigIpConfig]BigIpResourceConfig{
+				bigip1: {ltmConfig: LTMConfig{"tenant1": &PartitionConfig{}, "tenant2": &PartitionConfig{}}},
+				bigip2: {ltmConfig: LTMConfig{"tenant1": &PartitionConfig{}}},
+			})
+			response := func(device cisapiv1.BigIpConfig, failedTenants ...string) *agentConfig {
+				config := &agentConfig{
+					BigIpConfig: device,
+					reqMeta: requestMeta{partitionMap: map[string]map[string]string{
+						"tenant1": {}, "tenant2": {}}},
+					as3Config: as3Config{failedTenants: make(map[string]struct{})},
+				}
+				for _, tenant := range failedTenants {
+					config.as3Config.failedTenants[tenant] = struct{}{}
+				}
+				return config
+			}
+			tp.update(response(bigip1))
+			Expect(tp.acceptedByAll("tenant2")).To(BeTrue())
+			Expect(tp.acceptedByAll("tenant1")).To(BeFalse(), "Tenant reported before all BIG-IPs accepted it")
+			tp.update(response(bigip2, "tenant1"))
+			Expect(tp.acceptedByAll("tenant1")).To(BeFalse(), "Tenant rejected by a BIG-IP reported")
+			tp.update(response(bigip2))
+			Expect(tp.acceptedByAll("tenant1")).To(BeTrue())
+			Expect(tp.devices["tenant2"]).NotTo(HaveKey(bigip2), "Tenant not posted to BIG-IP recorded")
+
+			tp.posting(bigip1, map[string]struct{}{"tenant1": {}})
+			Expect(tp.acceptedByAll("tenant1")).To(BeFalse(), "Tenant reported while posting it")
+			Expect(tp.acceptedByAll("unknown")).To(BeTrue())
+		})
+	})
+})
@@ -127,6 +127,19 @@ func (ctlr *Controller) prepareVirtualServerRules(
 		}
 	}
 
+	for _, urlRule := range vs.Spec.URLMap {
+		rl, err := ctlr.createURLMapRule(vs, urlRule, rsCfg.Virtual.AllowSourceRange)
+		if nil != err {
+			log.Errorf("Error configuring urlMap rule for VirtualServer %s/%s: %v", vs.Namespace, vs.Name, err)
+			return nil
+		}
+		if strings.HasPrefix(rl.FullURI, "*.") {
+			wildcards[rl.FullURI] = rl
+		} else {
+			rlMap[rl.FullURI] = rl
+		}
+	}
+
 	if vs.Spec.RewriteAppRoot != "" && len(redirects) != 2 {
 		log.Error("AppRoot path not found for rewriting")
 		return nil
@@ -174,6 +187,113 @@ func (ctlr *Controller) prepareVirtualServerRules(
 	return &rls
 }
 
+// createURLMapRule creates the LTM policy rule for a urlMap entry of the VirtualServer
+// The referenced pool should be one of the pools defined in the VirtualServer, matched by name or service
+func (ctlr *Controller) createURLMapRule(
+	vs *cisapiv1.VirtualServer,
+	urlRule cisapiv1.URLRule,
+	allowSourceRange []string,
+) (*Rule, error) {
+	var poolName string
+	for _, pl := range vs.Spec.Pools {
+		if pl.Name != urlRule.Pool && (pl.Name != "" || pl.Service != urlRule.Pool) {
+			continue
+		}
+		poolBackends := ctlr.GetPoolBackends(&pl)
+		if len(poolBackends) == 0 {
+			break
+		}
+		poolName = ctlr.framePoolNameForVs(vs.Namespace, pl, vs.Spec.Host, poolBackends[0])
+		break
+	}
+	if poolName == "" {
+		return nil, fmt.Errorf("pool %v referenced in urlMap not found", urlRule.Pool)
+	}
+
+	host := urlRule.Host
+	if host == "" {
+		host = vs.Spec.Host
+	}
+	path := urlRule.Path
+	if path == "" {
+		path = "/"
+	}
+	ruleName := formatVirtualServerRuleName(host, vs.Spec.HostGroup, "urlmap"+path, poolName)
+	rl, err := createRule(host+path, poolName, ruleName, allowSourceRange, "", false)
+	if err != nil {
+		return nil, err
+	}
+	if urlRule.RewriteTarget != "" {
+		rewritePath := path
+		if rewritePath == "/" {
+			rewritePath = ""
+		}
+		rewriteActions, err := getRewriteActions(rewritePath, urlRule.RewriteTarget, len(rl.Actions))
+		if err != nil {
+			return nil, err
+		}
+		rl.Actions = append(rl.Actions, rewriteActions...)
+	}
+	return rl, nil
+}
+
+// prepareVirtualServerSNIRules creates the rules routing the TLS connections of the host of the passthrough
+// VirtualServer to its pool with the server name of the client hello, as the paths aren't visible before the
+// TLS termination the first pool of the VirtualServer is used
+func (ctlr *Controller) prepareVirtualServerSNIRules(
+	vs *cisapiv1.VirtualServer,
+) *Rules {
+	var poolName string
+	for _, pl := range vs.Spec.Pools {
+		// Service cannot be empty
+		if pl.Service == "" {
+			continue
+		}
+		poolBackends := ctlr.GetPoolBackends(&pl)
+		if len(poolBackends) == 0 {
+			continue
+		}
+		if poolName != "" {
+			log.Warningf("Only the first pool is used for SNI routing of VirtualServer %v/%v", vs.Namespace, vs.Name)
+			break
+		}
+		poolName = ctlr.framePoolNameForVs(vs.Namespace, pl, vs.Spec.Host, poolBackends[0])
+	}
+	if poolName == "" {
+		log.Errorf("No pool found for SNI routing of VirtualServer %v/%v", vs.Namespace, vs.Name)
+		return nil
+	}
+	ruleName := formatVirtualServerRuleName(vs.Spec.Host, vs.Spec.HostGroup, "sni", poolName)
+	return &Rules{createSNIRule(vs.Spec.Host, poolName, ruleName)}
+}
+
+// createSNIRule creates the rule forwarding the TLS connections with the server name of the host to the pool
+func createSNIRule(host, poolName, ruleName string) *Rule {
+	cond := &condition{
+		SSLExtensionClient: true,
+		Equals:             true,
+		Name:               "0",
+		Index:              0,
+		Values:             []string{host},
+	}
+	if strings.HasPrefix(host, "*.") {
+		cond.Equals = false
+		cond.EndsWith = true
+		cond.Values = []string{strings.TrimPrefix(host, "*")}
+	}
+	return &Rule{
+		Name:       ruleName,
+		FullURI:    host,
+		Conditions: []*condition{cond},
+		Actions: []*action{{
+			Forward:        true,
+			Name:           "0",
+			Pool:           poolName,
+			SSLClientHello: true,
+		}},
+	}
+}
+
 // format the rule name for VirtualServer
 func formatVirtualServerRuleName(hostname, hostGroup, path, pool string) string {
 	var rule string
@@ -516,6 +636,14 @@ func (rules Rules) Swap(i, j int) {
 	rules[i], rules[j] = rules[j], rules[i]
 }
 
+// xffRemoveIRule removes the X-Forwarded-For header sent by the client
+func xffRemoveIRule() string {
+	return `
+		when HTTP_REQUEST {
+			HTTP::header remove X-Forwarded-For
+		}`
+}
+
 // httpRedirectIRuleNoHost redirects traffic to BIG-IP https vs
 // for hostLess CRDs.
 func httpRedirectIRuleNoHost(port int32) string {
@@ -0,0 +1,281 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+)
+
+// AS3 references the SCTP profiles of the Service_SCTP but doesn't declare them, so the SCTP profiles of the
+// SCTP VirtualServers are created with the iControl REST API of the ltm/profile/sctp objects in the Common
+// partition of the BIG-IP the declaration is posted to. The profiles are named after their settings, so that the
+// virtuals with the same settings share a profile and a profile never changes once it's created. The description
+// of a profile records the CIS partition and the BIG-IP of the post manager which created it, a post manager
+// deletes only its own profiles once none of its declarations references them.
+
+// newBigIPSCTPProfile returns the BIG-IP SCTP profile of the settings, nil when the settings are the defaults
+// of the BIG-IP SCTP profile
+func newBigIPSCTPProfile(sctp *SCTPProfile) *bigIPSCTPProfile {
+	if sctp == nil || *sctp == (SCTPProfile{}) {
+		return nil
+	}
+	return &bigIPSCTPProfile{
+		Name: fmt.Sprintf("cis_sctp_%v_%v_%v", sctp.HeartbeatInterval, sctp.MaxInitRetries,
+			sctp.CookieExpiry),
+		Partition:         CommonPartition,
+		DefaultsFrom:      DefaultSCTPProfile,
+		HeartbeatInterval: sctp.HeartbeatInterval,
+		InitMaxRetries:    sctp.MaxInitRetries,
+		CookieExpiration:  sctp.CookieExpiry,
+	}
+}
+
+// getSCTPProfiles returns the SCTP profiles referenced by the services of the tenant declarations keyed by name
+func getSCTPProfiles(tenantDeclMap map[string]as3Tenant) map[string]*bigIPSCTPProfile {
+	profiles := make(map[string]*bigIPSCTPProfile)
+	for _, tenantDecl := range tenantDeclMap {
+		for _, value := range tenantDecl {
+			app, ok := value.(as3Application)
+			if !ok {
+				continue
+			}
+			for _, obj := range app {
+				if svc, ok := obj.(*as3Service); ok && svc.sctpProfile != nil {
+					profiles[svc.sctpProfile.Name] = svc.sctpProfile
+				}
+			}
+		}
+	}
+	return profiles
+}
+
+// getReferencedSCTPProfiles returns the names of the Common SCTP profiles referenced by the profileSCTP of the
+// services of the tenant declarations, the declarations restored from BIG-IP hold unmarshalled JSON objects
+func getReferencedSCTPProfiles(tenantDeclMap map[string]as3Tenant) map[string]struct{} {
+	names := make(map[string]struct{})
+	prefix := "/" + CommonPartition + "/"
+	for _, tenantDecl := range tenantDeclMap {
+		data, err := json.Marshal(tenantDecl)
+		if err != nil {
+			continue
+		}
+		var tenant map[string]interface{}
+		if err = json.Unmarshal(data, &tenant); err != nil {
+			continue
+		}
+		for _, value := range tenant {
+			app, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, obj := range app {
+				svc, ok := obj.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				pointer, _ := svc["profileSCTP"].(map[string]interface{})
+				if path, _ := pointer["bigip"].(string); strings.HasPrefix(path, prefix) {
+					names[strings.TrimPrefix(path, prefix)] = struct{}{}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// syncSCTPProfiles creates the SCTP profiles referenced by the declaration which don't exist on the BIG-IP the
+// declaration is posted to, the declaration of a tenant referencing a profile which failed to be created is
+// rejected by AS3. The profiles of the post manager are fetched from the BIG-IP with its first sync
+func (postMgr *PostManager) syncSCTPProfiles(cfg *as3Config) {
+	profiles := getSCTPProfiles(cfg.incomingTenantDeclMap)
+	if len(profiles) == 0 {
+		return
+	}
+	target := cfg.targetAddress
+	if !postMgr.DryRun && postMgr.sctpProfiles[target] == nil {
+		current, err := postMgr.getBigipSCTPProfiles(target)
+		if err != nil {
+			log.Errorf("[AS3]%v Unable to fetch the SCTP profiles from BIG-IP %v: %v", postMgr.postManagerPrefix,
+				target, err)
+			return
+		}
+		if postMgr.sctpProfiles == nil {
+			postMgr.sctpProfiles = make(map[string]map[string]struct{})
+		}
+		postMgr.sctpProfiles[target] = current
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		if _, ok := postMgr.sctpProfiles[target][name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if postMgr.DryRun {
+			log.Infof("[AS3]%v [dry-run] SCTP profile not created on BIG-IP: %+v", postMgr.postManagerPrefix,
+				*profiles[name])
+			continue
+		}
+		created, err := postMgr.createSCTPProfile(target, profiles[name])
+		if err != nil {
+			log.Errorf("[AS3]%v Unable to create SCTP profile %v on BIG-IP %v: %v", postMgr.postManagerPrefix, name,
+				target, err)
+			continue
+		}
+		// a profile of the same name not created by the post manager is used but never deleted
+		if created {
+			postMgr.sctpProfiles[target][name] = struct{}{}
+		}
+	}
+}
+
+// deleteUnreferencedSCTPProfiles deletes the SCTP profiles created by the post manager which are not referenced
+// by its declarations, a profile which fails to be deleted is deleted again after the next post
+func (postMgr *PostManager) deleteUnreferencedSCTPProfiles() {
+	if len(postMgr.sctpProfiles) == 0 {
+		return
+	}
+	referenced := getReferencedSCTPProfiles(postMgr.cachedTenantDeclMap)
+	for target, profiles := range postMgr.sctpProfiles {
+		var names []string
+		for name := range profiles {
+			if _, ok := referenced[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			code, body, err := postMgr.sctpProfileRequest(http.MethodDelete,
+				postMgr.getBigipSCTPProfileURL(target, name), nil)
+			if err == nil && code != http.StatusOK && code != http.StatusNotFound {
+				err = fmt.Errorf("status code %v: %v", code, body)
+			}
+			if err != nil {
+				log.Warningf("[AS3]%v Unable to delete SCTP profile %v on BIG-IP %v: %v", postMgr.postManagerPrefix,
+					name, target, err)
+				continue
+			}
+			log.Infof("[AS3]%v Deleted SCTP profile %v on BIG-IP %v", postMgr.postManagerPrefix,
+				JoinBigipPath(CommonPartition, name), target)
+			delete(profiles, name)
+		}
+	}
+}
+
+// getSCTPProfileDescription returns the description of the SCTP profiles created by the post manager on the BIG-IP
+func (postMgr *PostManager) getSCTPProfileDescription(target string) string {
+	return fmt.Sprintf("%v of %v on BIG-IP %v", sctpProfileDescription, postMgr.defaultPartition, target)
+}
+
+// getBigipSCTPProfiles returns the names of the SCTP profiles created by the post manager on the BIG-IP
+func (postMgr *PostManager) getBigipSCTPProfiles(target string) (map[string]struct{}, error) {
+	code, body, err := postMgr.sctpProfileRequest(http.MethodGet, postMgr.getBigipSCTPProfileURL(target, ""), nil)
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("Error response from BIGIP with status code %v", code)
+	}
+	var response struct {
+		Items []bigIPSCTPProfile `json:"items"`
+	}
+	if err = json.Unmarshal([]byte(body), &response); err != nil {
+		return nil, err
+	}
+	profiles := make(map[string]struct{})
+	for _, profile := range response.Items {
+		if profile.Partition == CommonPartition && profile.Description == postMgr.getSCTPProfileDescription(target) {
+			profiles[profile.Name] = struct{}{}
+		}
+	}
+	return profiles, nil
+}
+
+// createSCTPProfile creates the SCTP profile on the BIG-IP unless it exists, returns whether it was created
+func (postMgr *PostManager) createSCTPProfile(target string, profile *bigIPSCTPProfile) (bool, error) {
+	code, _, err := postMgr.sctpProfileRequest(http.MethodGet, postMgr.getBigipSCTPProfileURL(target, profile.Name),
+		nil)
+	if err != nil {
+		return false, err
+	}
+	switch code {
+	case http.StatusOK:
+		return false, nil
+	case http.StatusNotFound:
+	default:
+		return false, fmt.Errorf("Error response from BIGIP with status code %v", code)
+	}
+	owned := *profile
+	owned.Description = postMgr.getSCTPProfileDescription(target)
+	data, err := json.Marshal(owned)
+	if err != nil {
+		return false, err
+	}
+	code, body, err := postMgr.sctpProfileRequest(http.MethodPost, postMgr.getBigipSCTPProfileURL(target, ""), data)
+	if err != nil {
+		return false, err
+	}
+	if code != http.StatusOK {
+		return false, fmt.Errorf("status code %v: %v", code, body)
+	}
+	log.Infof("[AS3]%v Created SCTP profile %v on BIG-IP %v", postMgr.postManagerPrefix,
+		JoinBigipPath(profile.Partition, profile.Name), target)
+	return true, nil
+}
+
+// sctpProfileRequest sends the request of the SCTP profile to BIG-IP and returns the status code and the body
+// of the response
+func (postMgr *PostManager) sctpProfileRequest(method, url string, data []byte) (int, string, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return 0, "", err
+	}
+	log.Debugf("[AS3]%v Posting %v BIGIP SCTP profile request on %v", postMgr.postManagerPrefix, method, url)
+	req.Header.Add("Authorization", postMgr.tokenManager.GetToken())
+	req.Header.Add("Content-Type", "application/json")
+
+	httpResp, err := postMgr.httpClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer httpResp.Body.Close()
+	respBody, _ := io.ReadAll(httpResp.Body)
+	return httpResp.StatusCode, string(respBody), nil
+}
+
+// getBigipSCTPProfileURL returns the URL of the Common SCTP profile of the name on the BIG-IP through Central
+// Manager, the URL of all the SCTP profiles without a name
+func (postMgr *PostManager) getBigipSCTPProfileURL(target, name string) string {
+	apiURL := postMgr.tokenManager.ServerURL + "/mgmt/tm/ltm/profile/sctp"
+	if name != "" {
+		apiURL += "/~" + CommonPartition + "~" + name
+	}
+	return apiURL + "?target_address=" + target
+}
@@ -0,0 +1,97 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getTenantServiceAccount returns the ServiceAccount the resources of a namespace are authorized as, resources
+// carry no record of the user that wrote them, so a fixed identity of the namespace is used. It can not be
+// chosen by the resource, only the cluster admin binding the tenants to it decides what the namespace can write
+func getTenantServiceAccount(namespace string) string {
+	return fmt.Sprintf("system:serviceaccount:%v:%v", namespace, TenantServiceAccount)
+}
+
+// isTenantWriteAllowed checks with a SubjectAccessReview whether the ServiceAccount is granted the write verb
+// on the tenant in the cis.f5.com API group by a Role of the namespace or a ClusterRole, results are cached
+func (ctlr *Controller) isTenantWriteAllowed(namespace, serviceAccount, tenant string) (bool, error) {
+	key := tenantAccessKey{namespace: namespace, serviceAccount: serviceAccount, tenant: tenant}
+	ctlr.tenantAccessCache.Lock()
+	defer ctlr.tenantAccessCache.Unlock()
+	if result, ok := ctlr.tenantAccessCache.results[key]; ok && time.Since(result.checkedAt) < TenantAccessCacheTTL {
+		return result.allowed, nil
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   serviceAccount,
+			Groups: []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"},
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Group:     TenantAPIGroup,
+				Resource:  TenantResource,
+				Verb:      TenantWriteVerb,
+				Name:      tenant,
+			},
+		},
+	}
+	response, err := ctlr.clientsets.KubeClient.AuthorizationV1().SubjectAccessReviews().Create(context.TODO(),
+		review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	if ctlr.tenantAccessCache.results == nil {
+		ctlr.tenantAccessCache.results = make(map[tenantAccessKey]tenantAccessResult)
+	}
+	ctlr.tenantAccessCache.results[key] = tenantAccessResult{allowed: response.Status.Allowed, checkedAt: time.Now()}
+	return response.Status.Allowed, nil
+}
+
+// checkVirtualServerTenantAccess rejects the VirtualServer whose ServiceAccount is not allowed to write its tenant
+func (ctlr *Controller) checkVirtualServerTenantAccess(vs *cisapiv1.VirtualServer) bool {
+	tenant := ctlr.getCRPartition(vs.Spec.Partition)
+	serviceAccount := getTenantServiceAccount(vs.Namespace)
+	allowed, err := ctlr.isTenantWriteAllowed(vs.Namespace, serviceAccount, tenant)
+	if err != nil {
+		log.Errorf("Unable to check the access of %v to tenant %v for VirtualServer %v/%v: %v", serviceAccount,
+			tenant, vs.Namespace, vs.Name, err)
+		return false
+	}
+	if allowed {
+		return true
+	}
+	message := fmt.Sprintf("%v is not allowed to %v %v %v in the %v API group", serviceAccount, TenantWriteVerb,
+		TenantResource, tenant, TenantAPIGroup)
+	log.Warningf("VirtualServer %v/%v rejected: %v", vs.Namespace, vs.Name, message)
+	// the event is recorded only when the VirtualServer is rejected for the first time, not on every resync
+	cond := meta.FindStatusCondition(vs.Status.Conditions, VSConditionValid)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != VSReasonTenantUnauthorized ||
+		cond.Message != message {
+		ctlr.recordVirtualServerEvent(vs, v1.EventTypeWarning, VSReasonTenantUnauthorized, message)
+	}
+	ctlr.updateVirtualServerValidCondition(vs, false, VSReasonTenantUnauthorized, message)
+	return false
+}
@@ -0,0 +1,234 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+)
+
+// Tracer records a span per AS3 declaration post and exports the spans to an OpenTelemetry collector.
+// The OpenTelemetry SDK is not vendored, so this is a minimal exporter with these limitations:
+//   - only the OTLP/HTTP JSON encoding is supported, there is no gRPC, TLS client config or custom headers
+//   - every post is recorded, there is no sampling and the spans have no parent spans
+//   - the spans are exported every OTelExportInterval, they are dropped when the export fails or when
+//     OTelMaxQueuedSpans are waiting for export
+//
+// A nil Tracer records nothing.
+type Tracer struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+	lock        sync.Mutex
+	spans       []*traceSpan
+}
+
+// traceSpan is a timed operation of a trace, its trace context is propagated to BIG-IP with the
+// W3C traceparent header
+type traceSpan struct {
+	tracer     *Tracer
+	traceID    string
+	spanID     string
+	name       string
+	start      time.Time
+	end        time.Time
+	attributes map[string]string
+	err        string
+}
+
+// otlpAttribute is a key value attribute in the OTLP JSON encoding
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+// NewTracer creates a Tracer exporting the spans to the OTLP endpoint, the traces path is appended
+// when the endpoint has no path
+func NewTracer(endpoint, serviceName string) *Tracer {
+	if endpoint == "" {
+		return nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	if strings.Count(endpoint, "/") == 2 {
+		endpoint = endpoint + OTelTracesPath
+	}
+	if serviceName == "" {
+		serviceName = DefaultOTelServiceName
+	}
+	tracer := &Tracer{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: OTelExportTimeout},
+	}
+	go tracer.exporter()
+	return tracer
+}
+
+// startSpan starts the root span of a new trace
+func (t *Tracer) startSpan(name string) *traceSpan {
+	if t == nil {
+		return nil
+	}
+	return &traceSpan{
+		tracer:     t,
+		traceID:    randomHex(16),
+		spanID:     randomHex(8),
+		name:       name,
+		start:      time.Now(),
+		attributes: make(map[string]string),
+	}
+}
+
+// setAttribute records an attribute of the span
+func (s *traceSpan) setAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attributes[key] = fmt.Sprintf("%v", value)
+}
+
+// setError marks the span as failed
+func (s *traceSpan) setError(message string) {
+	if s == nil {
+		return
+	}
+	s.err = message
+}
+
+// finish ends the span and queues it for export
+func (s *traceSpan) finish() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	s.tracer.lock.Lock()
+	if len(s.tracer.spans) < OTelMaxQueuedSpans {
+		s.tracer.spans = append(s.tracer.spans, s)
+	}
+	s.tracer.lock.Unlock()
+}
+
+// traceparent returns the W3C trace context of the span
+func (s *traceSpan) traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", s.traceID, s.spanID)
+}
+
+// injectTraceContext propagates the trace context of the span to the HTTP request
+func (s *traceSpan) injectTraceContext(req *http.Request) {
+	if s == nil {
+		return
+	}
+	req.Header.Set(TraceparentHeader, s.traceparent())
+}
+
+// exporter exports the finished spans every OTelExportInterval
+func (t *Tracer) exporter() {
+	for range time.Tick(OTelExportInterval) {
+		t.flush()
+	}
+}
+
+// flush exports the finished spans to the OTLP endpoint, the spans are dropped when the export fails
+func (t *Tracer) flush() {
+	t.lock.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.lock.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+	body, err := json.Marshal(t.otlpRequest(spans))
+	if err != nil {
+		log.Errorf("[OTel] Unable to encode the spans: %v", err)
+		return
+	}
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Warningf("[OTel] Unable to export %v spans to %v: %v", len(spans), t.endpoint, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Warningf("[OTel] Export of %v spans to %v failed with status code %v", len(spans), t.endpoint,
+			resp.StatusCode)
+	}
+}
+
+// otlpRequest builds the OTLP ExportTraceServiceRequest of the spans
+func (t *Tracer) otlpRequest(spans []*traceSpan) map[string]interface{} {
+	var otlpSpans []map[string]interface{}
+	for _, span := range spans {
+		otlpSpan := map[string]interface{}{
+			"traceId":           span.traceID,
+			"spanId":            span.spanID,
+			"name":              span.name,
+			"kind":              OTelSpanKindClient,
+			"startTimeUnixNano": strconv.FormatInt(span.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.end.UnixNano(), 10),
+			"attributes":        otlpAttributes(span.attributes),
+		}
+		if span.err != "" {
+			otlpSpan["status"] = map[string]interface{}{"code": OTelStatusError, "message": span.err}
+		}
+		otlpSpans = append(otlpSpans, otlpSpan)
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]string{"service.name": t.serviceName}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": OTelScopeName},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	otlpAttrs := make([]otlpAttribute, 0, len(attributes))
+	for key, value := range attributes {
+		attr := otlpAttribute{Key: key}
+		attr.Value.StringValue = value
+		otlpAttrs = append(otlpAttrs, attr)
+	}
+	return otlpAttrs
+}
+
+func randomHex(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/tokenmanager"
 	"net/http"
 	"sync"
+	"time"
 
 	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
 
@@ -81,8 +82,106 @@ type (
 		respChan               chan *agentConfig
 		networkManager         *networkmanager.NetworkManager
 		ControllerIdentifier   string
+		topologyConfig         TopologyConfig
+		bgpAdvertise           bool
+		// tenantToDeviceMapping holds the BIG-IP address each tenant is posted to
+		tenantToDeviceMapping map[string]string
+		// deviceTenants holds the tenants of the latest request of every BIG-IP device, droppedRequests holds the
+		// devices whose latest request was dropped
+		deviceTenants   map[cisapiv1.BigIpConfig]map[string]struct{}
+		droppedRequests map[cisapiv1.BigIpConfig]struct{}
+		// bigipTargets receive the tenants matching their tenant selector as well
+		bigipTargets []BIGIPTarget
+		// tenantPosts holds whether the BIG-IPs every tenant is posted to accepted it
+		tenantPosts tenantPosts
+		// vipPool allocates the addresses of the VirtualServers without virtualServerAddress
+		vipPool *VirtualAddressPool
+		// rolloutPause pauses the rollout regardless of the rolloutPause of the global DeployConfig CR
+		rolloutPause bool
+		// manageIngress translates the Ingresses of the f5 ingress class into virtuals
+		manageIngress bool
+		// certRenewalLeadDays is the number of days before the renewal of the cert-manager Certificates
+		// labelled cis.f5.com/managed the certificates of their TLSProfiles are updated, 0 disables it
+		certRenewalLeadDays int
+		certRenewals        map[string]certRenewalState
+		// defaultLogPublisher is the BIG-IP Log Publisher of the virtuals without the log-publisher annotation
+		defaultLogPublisher string
+		// firewallEnabled allows the virtuals only from the ipBlocks of the NetworkPolicies of their pods
+		firewallEnabled bool
+		// apmEnabled allows the VirtualServers to attach APM access profiles
+		apmEnabled bool
+		// rbacEnabled restricts the tenants of the VirtualServers to the ones their ServiceAccount may write
+		rbacEnabled       bool
+		tenantAccessCache tenantAccessCache
+		// vsWarnings holds the warning events last recorded for the VirtualServers
+		vsWarnings virtualServerWarnings
+		// bigipSourceIP is the source IP of the BIG-IP traffic to the pods, the NetworkPolicies of the
+		// cross namespace services are checked against it when set
+		bigipSourceIP string
+		// resourceDependencies tracks the VirtualServers waiting for the TLSProfiles they reference to become valid
+		resourceDependencies dependencyTracker
+		// externalMonitors allows the external monitor scripts of the ConfigMaps of the admin namespace
+		externalMonitors bool
+		// adminInformer watches the ConfigMaps and the PacketFilters of the admin namespace
+		adminInformer *AdminInformer
+		// nsAnnotationInformer watches the namespaces of the VirtualServers for the annotations that apply to
+		// their tenants
+		nsAnnotationInformer *NSInformer
 		resourceContext
 	}
+	// TopologyConfig holds the zone aware pool member ratio settings
+	TopologyConfig struct {
+		Enabled     bool
+		TopologyKey string
+		BIGIPZone   string
+		ZoneWeights map[string]int
+	}
+	// tenantAccessCache caches the SubjectAccessReview results of the tenant write checks
+	tenantAccessCache struct {
+		sync.Mutex
+		results map[tenantAccessKey]tenantAccessResult
+	}
+	// virtualServerWarnings holds the message of the warnings recorded for the VirtualServers, so that a
+	// warning is recorded when its cause changes rather than on every sync of the VirtualServer
+	virtualServerWarnings struct {
+		sync.Mutex
+		messages map[virtualServerWarningKey]string
+	}
+	virtualServerWarningKey struct {
+		namespace string
+		name      string
+		reason    string
+		// object is the referenced object the warning is about, empty when the VirtualServer refers to one
+		object string
+	}
+	tenantAccessKey struct {
+		namespace      string
+		serviceAccount string
+		tenant         string
+	}
+	tenantAccessResult struct {
+		allowed   bool
+		checkedAt time.Time
+	}
+
+	// dependencyTracker maps a resource to the set of resources depending on it, the dependents are
+	// enqueued again when the resource they depend on becomes valid
+	dependencyTracker struct {
+		sync.Mutex
+		dependents map[resourceRef]map[resourceRef]struct{}
+	}
+	// VirtualAddressPool allocates virtual server addresses from the configured CIDRs in round-robin order,
+	// the allocations are saved to a ConfigMap so that they persist across restarts
+	VirtualAddressPool struct {
+		sync.Mutex
+		addresses []string
+		// next is the index of the address to try first in the next allocation
+		next int
+		// allocations maps the allocation key of the VirtualServers to the allocated address
+		allocations map[string]string
+		configMap   string
+		kubeClient  kubernetes.Interface
+	}
 	ClientSets struct {
 		KubeCRClient  versioned.Interface
 		KubeClient    kubernetes.Interface
@@ -120,10 +219,13 @@ type (
 
 	// Params defines parameters
 	Params struct {
-		Config                *rest.Config
-		ClientSets            *ClientSets
-		Namespaces            []string
+		Config     *rest.Config
+		ClientSets *ClientSets
+		Namespaces []string
+		// UserAgent is sent in the AS3 declaration with cluster/<cluster-name> go/<go-version> appended,
+		// CIS/v<CISVersion> is used when it is empty
 		UserAgent             string
+		CISVersion            string
 		UseNodeInternal       bool
 		NodePollInterval      int
 		IPAM                  bool
@@ -134,8 +236,108 @@ type (
 		CMTrustedCerts        string
 		CMSSLInsecure         bool
 		HttpAddress           string
+		DiagnosticsAddr       string
 		ManageCustomResources bool
 		httpClientMetrics     bool
+		// TopologyAwarePoolWeights assigns higher pool member ratios to endpoints
+		// in the same zone as the BIG-IP, based on the EndpointSlice topology hints.
+		// The ratios take effect only with a ratio load balancing method on the pool.
+		TopologyAwarePoolWeights bool
+		// TopologyKey is the node label holding the zone of the nodeport pool members,
+		// defaults to topology.kubernetes.io/zone
+		TopologyKey string
+		BIGIPZone   string
+		ZoneWeights map[string]int
+		// BGPAdvertise creates a LoadBalancer Service for VirtualServers annotated
+		// with cis.f5.com/bgp-advertise so that MetalLB/Calico advertises the VIP
+		BGPAdvertise bool
+		// StalenessThreshold is the duration after which a CIS managed tenant without
+		// Kubernetes resources is treated as orphaned and removed from BIG-IP
+		StalenessThreshold time.Duration
+		// CheckpointConfigMap is the namespace/name of the ConfigMap holding the
+		// last posted AS3 declaration of every BIG-IP
+		CheckpointConfigMap string
+		// WarmStart restores the AS3 declaration from the CheckpointConfigMap on startup
+		WarmStart bool
+		// TenantToDeviceMapping maps tenant names to the address of the BIG-IP they are posted to,
+		// tenants which are not mapped are posted to the default BIG-IP
+		TenantToDeviceMapping map[string]string
+		// AuditLogEnabled writes a Kubernetes audit event for every tenant posted to BIG-IP
+		AuditLogEnabled bool
+		// AuditLogPath is the file the audit events are appended to
+		AuditLogPath string
+		// DriftDetectionInterval is the interval at which the AS3 declaration stored on BIG-IP is compared
+		// with the declaration posted by CIS, disabled when zero. Only changes made through AS3 are detected,
+		// changes made to the BIG-IP objects directly do not update the stored declaration
+		DriftDetectionInterval time.Duration
+		// DriftResync posts the drifted tenants again to restore the declaration of CIS
+		DriftResync bool
+		// VirtualAddressPool is the list of CIDRs the addresses of the VirtualServers without
+		// virtualServerAddress are allocated from
+		VirtualAddressPool []string
+		// VirtualAddressPoolConfigMap is the namespace/name of the ConfigMap holding the allocations
+		VirtualAddressPoolConfigMap string
+		// ManageIngress translates the networking.k8s.io/v1 Ingresses of the f5 ingress class into virtuals
+		ManageIngress bool
+		// OpenShiftRoute translates the route.openshift.io/v1 Routes into virtuals
+		OpenShiftRoute bool
+		// CertExpiryWarningDays is the number of days before the BIG-IP management certificate
+		// expiry to warn about it
+		CertExpiryWarningDays int
+		// AS3AsyncMode posts the AS3 declarations asynchronously and polls their task until it completes,
+		// AS3AsyncPollInterval is the interval of the first poll, which doubles with every poll up to
+		// AS3AsyncPollMaxInterval, and AS3AsyncTimeout the time the task is polled for
+		AS3AsyncMode            bool
+		AS3AsyncPollInterval    time.Duration
+		AS3AsyncPollMaxInterval time.Duration
+		AS3AsyncTimeout         time.Duration
+		// ProvisionCheckInterval is the interval to refresh the provisioned BIG-IP modules, 0 fetches them once
+		ProvisionCheckInterval time.Duration
+		// AS3RetryBackoff is the backoff of the retries of the tenants which failed to post to BIG-IP
+		AS3RetryBackoff RetryBackoff
+		// OTelEndpoint is the OTLP/HTTP endpoint the traces of the reconcile cycles are exported to,
+		// tracing is disabled when it is empty. OTelServiceName is the service.name of the traces
+		OTelEndpoint    string
+		OTelServiceName string
+		// CertRenewalLeadDays is the number of days before the renewal time of the managed cert-manager
+		// Certificates to update the certificates of their TLSProfiles on BIG-IP, 0 disables the integration
+		CertRenewalLeadDays int
+		// DefaultLogPublisher is the BIG-IP Log Publisher the security events of the virtuals are logged to,
+		// when they do not reference one with the log-publisher annotation
+		DefaultLogPublisher string
+		// FirewallEnabled translates the ipBlocks of the NetworkPolicies selecting the pool pods of the virtuals
+		// into BIG-IP firewall policies allowing only those CIDRs
+		FirewallEnabled bool
+		// ClassVersions pins AS3 classes to a schema version, e.g. Service_HTTPS=3.40.0, the declarations with
+		// objects of the classes are posted with the lowest of their versions as the schemaVersion of the ADC, so
+		// that the objects are not affected by the schema changes of newer AS3 versions
+		ClassVersions map[string]string
+		// BIGIPSourceIP is the source IP of the BIG-IP traffic to the pods, the cross namespace pool members
+		// are skipped when the NetworkPolicies of their namespace do not allow it
+		BIGIPSourceIP string
+		// RBACEnabled rejects the VirtualServers whose namespace ServiceAccount cis-tenant-writer is not
+		// granted the write verb on their BIG-IP tenant in the cis.f5.com API group
+		RBACEnabled bool
+		// APMEnabled allows the VirtualServers to attach the BIG-IP APM access profiles
+		// referenced by the cis.f5.com/access-profile annotation
+		APMEnabled bool
+		// DryRun logs the declarations instead of posting them to BIG-IP
+		DryRun bool
+		// MaxDeclarationSizeBytes is the size limit of the AS3 declarations posted to BIG-IP, 0 disables the limit
+		MaxDeclarationSizeBytes int
+		// CircuitBreakerThreshold is the number of consecutive 503 responses of BIG-IP after which the posts are
+		// rejected for CircuitBreakerCooldown, 0 disables the circuit breaker
+		CircuitBreakerThreshold int
+		CircuitBreakerCooldown  time.Duration
+		// RolloutPause holds the resource updates instead of posting them to BIG-IP until the rollout is resumed
+		RolloutPause bool
+		// BIGIPTargets receive the tenants matching their tenant selector posted to the other BIG-IPs as well
+		BIGIPTargets []BIGIPTarget
+		// ExternalMonitors allows the Services to reference the external monitor scripts of the admin namespace
+		ExternalMonitors bool
+		// AdminNamespace is the namespace of the ConfigMaps of the external monitor scripts and the monitor
+		// library and of the PacketFilters, the namespace of the CIS pod when empty
+		AdminNamespace string
 	}
 
 	// CMConfig defines the Central Manager config
@@ -160,11 +362,19 @@ type (
 		stopCh           chan struct{}
 		svcInformer      cache.SharedIndexInformer
 		epsInformer      cache.SharedIndexInformer
+		epSliceInformer  cache.SharedIndexInformer
 		ednsInformer     cache.SharedIndexInformer
 		plcInformer      cache.SharedIndexInformer
+		adlInformer      cache.SharedIndexInformer
+		prtInformer      cache.SharedIndexInformer
+		natInformer      cache.SharedIndexInformer
+		snatInformer     cache.SharedIndexInformer
+		npInformer       cache.SharedIndexInformer
 		podInformer      cache.SharedIndexInformer
 		secretsInformer  cache.SharedIndexInformer
 		configCRInformer cache.SharedIndexInformer
+		ingressInformer  cache.SharedIndexInformer
+		cmInformer       cache.SharedIndexInformer
 	}
 
 	// NRInformer is informer context for Native Resources of Kubernetes/Openshift
@@ -186,6 +396,15 @@ type (
 		cluster    string
 		nsInformer cache.SharedIndexInformer
 	}
+
+	// AdminInformer is informer context for the ConfigMaps and the PacketFilters of the admin namespace,
+	// only the administrators are expected to write them
+	AdminInformer struct {
+		namespace            string
+		stopCh               chan struct{}
+		cmInformer           cache.SharedIndexInformer
+		packetFilterInformer cache.SharedIndexInformer
+	}
 	rqKey struct {
 		namespace      string
 		kind           string
@@ -206,6 +425,10 @@ type (
 		Protocol        string
 		httpTraffic     string
 		defaultPoolType string
+		// AS3 Tenant settings of the partition requested by the resource
+		routeDomain       *int
+		optimisticLockKey string
+		as3LogLevel       string
 	}
 
 	// Virtual server config
@@ -233,6 +456,7 @@ type (
 		ProfileWebSocket           string                `json:"profileWebSocket,omitempty"`
 		ProfileDOS                 string                `json:"profileDOS,omitempty"`
 		ProfileBotDefense          string                `json:"profileBotDefense,omitempty"`
+		ProfileProtocolInspection  string                `json:"profileProtocolInspection,omitempty"`
 		TCP                        ProfileTCP            `json:"tcp,omitempty"`
 		HTTP2                      ProfileHTTP2          `json:"http2,omitempty"`
 		Mode                       string                `json:"mode,omitempty"`
@@ -248,6 +472,186 @@ type (
 		AutoLastHop                string                `json:"lastHop,omitempty"`
 		AnalyticsProfiles          AnalyticsProfiles     `json:"analyticsProfiles,omitempty"`
 		MultiPoolPersistence       MultiPoolPersistence  `json:"multiPoolPersistence,omitempty"`
+		AddressLists               []AddressListRef      `json:"addressLists,omitempty"`
+		PortLists                  []PortListRef         `json:"portLists,omitempty"`
+		NATPolicy                  *NATPolicyRef         `json:"natPolicy,omitempty"`
+		SNATPool                   *SNATPoolRef          `json:"snatPool,omitempty"`
+		LogPublisher               string                `json:"logPublisher,omitempty"`
+		XFFInsert                  string                `json:"xffInsert,omitempty"`
+		RateLimit                  RateLimit             `json:"rateLimit,omitempty"`
+		ProfileClassification      string                `json:"profileClassification,omitempty"`
+		Protocol                   string                `json:"protocol,omitempty"`
+		Rewrite                    RewriteConfig         `json:"rewrite,omitempty"`
+		CertificateValidator       CertificateValidator  `json:"certificateValidator,omitempty"`
+		CipherGroup                *CipherGroup          `json:"cipherGroup,omitempty"`
+		Multiplex                  *MultiplexProfile     `json:"multiplex,omitempty"`
+		Stream                     StreamProfile         `json:"stream,omitempty"`
+		HTML                       HTMLProfile           `json:"html,omitempty"`
+		// SIP creates a TCP or UDP service with the SIP profile attached
+		SIP                  bool    `json:"sip,omitempty"`
+		IFiles               []IFile `json:"iFiles,omitempty"`
+		ProfileRequestAdapt  string  `json:"profileRequestAdapt,omitempty"`
+		ProfileResponseAdapt string  `json:"profileResponseAdapt,omitempty"`
+		// ICAP holds the ICAP server the HTTP requests of the virtual are adapted by
+		ICAP *ICAPService `json:"icap,omitempty"`
+		// DOS holds the DoS profile created for the virtual
+		DOS *DOSProfile `json:"dos,omitempty"`
+		// WebSocket holds the settings of the WebSocket profile created for the virtual
+		WebSocket *WebSocketProfile `json:"webSocket,omitempty"`
+		// ProfileAccess is the path of the BIG-IP APM access profile of the virtual
+		ProfileAccess string `json:"profileAccess,omitempty"`
+		// Diameter holds the settings of the Diameter endpoint profile created for the TCP virtual
+		Diameter *DiameterProfile `json:"diameter,omitempty"`
+		// Radius holds the settings of the RADIUS profile created for the UDP virtual
+		Radius *RadiusProfile `json:"radius,omitempty"`
+		// HTTPCompression holds the settings of the HTTP compression profile created for the virtual
+		HTTPCompression *HTTPCompressionProfile `json:"httpCompression,omitempty"`
+		// MQTT enables the MQTT protocol on the TCP virtual
+		MQTT bool `json:"mqtt,omitempty"`
+		// SCTP holds the settings of the SCTP profile of the SCTP virtual
+		SCTP *SCTPProfile `json:"sctp,omitempty"`
+		// EndpointStrategy is the custom strategy evaluating the endpoint policies of the virtual
+		EndpointStrategy *EndpointStrategy `json:"endpointStrategy,omitempty"`
+		// Forwarding creates a forwarding service routing the traffic instead of the service of the protocol
+		Forwarding *ForwardingConfig `json:"forwarding,omitempty"`
+	}
+	// ForwardingConfig holds the settings of the forwarding service of a virtual
+	ForwardingConfig struct {
+		Type      string `json:"type"`
+		Mirroring bool   `json:"mirroring,omitempty"`
+	}
+	// EndpointStrategy holds the settings of the Endpoint_Strategy created for a virtual
+	EndpointStrategy struct {
+		MatchMethod string   `json:"matchMethod,omitempty"`
+		Operands    []string `json:"operands,omitempty"`
+	}
+	// RadiusProfile holds the settings of the RADIUS profile created for a virtual
+	RadiusProfile struct {
+		PersistAttribute           string `json:"persistAttribute,omitempty"`
+		SubscriberDiscoveryEnabled *bool  `json:"subscriberDiscoveryEnabled,omitempty"`
+	}
+	// HTTPCompressionProfile holds the settings of the HTTP compression profile created for a virtual
+	HTTPCompressionProfile struct {
+		Gzip               bool     `json:"gzip,omitempty"`
+		Deflate            bool     `json:"deflate,omitempty"`
+		ContentTypeInclude []string `json:"contentTypeInclude,omitempty"`
+		MinimumSize        int      `json:"minimumSize,omitempty"`
+	}
+	// SCTPProfile holds the settings of the SCTP profile created for a virtual, the BIG-IP defaults are used for
+	// the settings which are not set
+	SCTPProfile struct {
+		HeartbeatInterval int32 `json:"heartbeatInterval,omitempty"`
+		MaxInitRetries    int32 `json:"maxInitRetries,omitempty"`
+		CookieExpiry      int32 `json:"cookieExpiry,omitempty"`
+	}
+	// DiameterProfile holds the settings of the Diameter endpoint profile created for a virtual
+	DiameterProfile struct {
+		ParentProfile string   `json:"parentProfile,omitempty"`
+		DestHost      string   `json:"destHost,omitempty"`
+		DestRealm     string   `json:"destRealm,omitempty"`
+		SupportedApps []string `json:"supportedApps,omitempty"`
+	}
+	// ICAPService holds the ICAP server and the request adapt settings of a virtual
+	ICAPService struct {
+		Address           string `json:"address,omitempty"`
+		Port              int32  `json:"port,omitempty"`
+		AllowHTTP10       bool   `json:"allowHTTP10,omitempty"`
+		ServiceDownAction string `json:"serviceDownAction,omitempty"`
+	}
+	// DOSProfile holds the name and the network vector thresholds of the DoS profile created for a virtual
+	DOSProfile struct {
+		Name                  string `json:"name,omitempty"`
+		Vector                string `json:"vector,omitempty"`
+		DetectThreshold       int64  `json:"detectThreshold,omitempty"`
+		RateIncreaseThreshold int64  `json:"rateIncreaseThreshold,omitempty"`
+		RateLimit             int64  `json:"rateLimit,omitempty"`
+	}
+	// WebSocketProfile holds the settings of the WebSocket profile created for a virtual
+	WebSocketProfile struct {
+		Compression bool   `json:"compression,omitempty"`
+		Masking     string `json:"masking,omitempty"`
+	}
+	// IFile holds the base64 encoded content of an iFile created for the iRules of a virtual
+	IFile struct {
+		Name   string `json:"name"`
+		Base64 string `json:"base64"`
+	}
+	// HTMLProfile holds the HTML profile reference or the settings of the HTML profile created for a virtual
+	HTMLProfile struct {
+		Profile                 string   `json:"profile,omitempty"`
+		BigIPProfile            bool     `json:"bigIPProfile,omitempty"`
+		ContentDetectionEnabled bool     `json:"contentDetectionEnabled,omitempty"`
+		ContentSelection        []string `json:"contentSelection,omitempty"`
+	}
+	// StreamProfile holds the stream profile reference and overrides of a virtual
+	StreamProfile struct {
+		Profile   string `json:"profile,omitempty"`
+		Source    string `json:"source,omitempty"`
+		Target    string `json:"target,omitempty"`
+		ChunkSize int64  `json:"chunkSize,omitempty"`
+	}
+	// MultiplexProfile holds the connection multiplexing settings of a virtual
+	MultiplexProfile struct {
+		MaxConnections     int64  `json:"maxConnections,omitempty"`
+		MaxConnectionReuse int64  `json:"maxConnectionReuse,omitempty"`
+		MaxConnectionAge   int64  `json:"maxConnectionAge,omitempty"`
+		SourceMask         string `json:"sourceMask,omitempty"`
+	}
+	// CertificateValidator holds the client certificate revocation checks of a virtual
+	CertificateValidator struct {
+		TrustCA           string `json:"trustCA,omitempty"`
+		OCSPEnabled       bool   `json:"ocspEnabled,omitempty"`
+		OCSPResponderURL  string `json:"ocspResponderUrl,omitempty"`
+		DNSResolver       string `json:"dnsResolver,omitempty"`
+		CRLEnabled        bool   `json:"crlEnabled,omitempty"`
+		CRLFile           string `json:"crlFile,omitempty"`
+		RequireOCSPStatus bool   `json:"requireOCSPStatus,omitempty"`
+	}
+	// CipherGroup holds the cipher group of the TLS server of a virtual
+	CipherGroup struct {
+		Name  string       `json:"name"`
+		Order string       `json:"order,omitempty"`
+		Rules []CipherRule `json:"rules,omitempty"`
+	}
+	// CipherRule holds the cipher suites allowed and denied by a rule of the cipher group
+	CipherRule struct {
+		Name      string   `json:"name"`
+		AllowList []string `json:"allowList,omitempty"`
+		DenyList  []string `json:"denyList,omitempty"`
+	}
+	// RewriteConfig holds the URL rewrite settings of a virtual
+	RewriteConfig struct {
+		AppRoot     string `json:"appRoot,omitempty"`
+		RequestHost string `json:"requestHost,omitempty"`
+		Host        string `json:"host,omitempty"`
+	}
+	// RateLimit holds the rate limiting configuration of a virtual
+	RateLimit struct {
+		Rate  int64  `json:"rate,omitempty"`
+		Burst int64  `json:"burst,omitempty"`
+		Unit  string `json:"unit,omitempty"`
+	}
+	// AddressListRef holds the addresses of an AddressList referenced by a virtual
+	AddressListRef struct {
+		Name      string   `json:"name"`
+		Addresses []string `json:"addresses"`
+		Action    string   `json:"action"`
+	}
+	// NATPolicyRef holds the rules of a NATPolicy referenced by a virtual
+	NATPolicyRef struct {
+		Name  string             `json:"name"`
+		Rules []cisapiv1.NATRule `json:"rules"`
+	}
+	// SNATPoolRef holds the addresses of a SNATPool referenced by a virtual
+	SNATPoolRef struct {
+		Name      string   `json:"name"`
+		Addresses []string `json:"addresses"`
+	}
+	// PortListRef holds the ports of a PortList referenced by a virtual
+	PortListRef struct {
+		Name       string   `json:"name"`
+		Ports      []int    `json:"ports,omitempty"`
+		PortRanges []string `json:"portRanges,omitempty"`
 	}
 	MultiPoolPersistence struct {
 		Method  string `json:"method,omitempty"`
@@ -257,7 +661,14 @@ type (
 	Virtuals []Virtual
 
 	AnalyticsProfiles struct {
-		HTTPAnalyticsProfile string `json:"http,omitempty"`
+		HTTPAnalyticsProfile string               `json:"http,omitempty"`
+		TCPAnalyticsProfile  *TCPAnalyticsProfile `json:"tcp,omitempty"`
+	}
+
+	// TCPAnalyticsProfile holds the settings of the TCP analytics profile created for a virtual
+	TCPAnalyticsProfile struct {
+		CollectRemoteHostIP bool `json:"collectRemoteHostIp,omitempty"`
+		CollectNexthop      bool `json:"collectNexthop,omitempty"`
 	}
 
 	ProfileTCP struct {
@@ -433,6 +844,9 @@ type (
 		MultiClusterServices []cisapiv1.MultiClusterServiceReference `json:"_"`
 		Cluster              string                                  `json:"-"`
 		ConnectionLimit      int32                                   `json:"-"`
+		PodSelector          string                                  `json:"-"`
+		// NetworkPolicyDenied skips the members of a cross namespace service denied by the NetworkPolicies
+		NetworkPolicyDenied bool `json:"-"`
 	}
 	CacheIPAM struct {
 		IPAM *ficV1.IPAM
@@ -470,6 +884,17 @@ type (
 		TargetPort  int32  `json:"targetPort,omitempty"`
 		Path        string `json:"path,omitempty"`
 		TimeUntilUp *int   `json:"timeUntilUp,omitempty"`
+		// Script, Arguments and UserDefined are the base64 encoded script and the parameters of the external
+		// monitor, UserDefined holds the validated NAME=VALUE variables separated by comma
+		Script      string `json:"script,omitempty"`
+		Arguments   string `json:"arguments,omitempty"`
+		UserDefined string `json:"userDefined,omitempty"`
+		// Base, Filter, Security, Username and the base64 encoded Password are the parameters of the LDAP monitor
+		Base     string `json:"base,omitempty"`
+		Filter   string `json:"filter,omitempty"`
+		Security string `json:"security,omitempty"`
+		Username string `json:"username,omitempty"`
+		Password string `json:"-"`
 	}
 	MonitorName struct {
 		Name string `json:"name"`
@@ -541,6 +966,8 @@ type (
 		Enabled   *bool  `json:"enabled,omitempty"`
 		Log       bool   `json:"log,omitempty"`
 		Message   string `json:"message,omitempty"`
+		// SSLClientHello performs the action on the TLS client hello instead of the HTTP request
+		SSLClientHello bool `json:"sslClientHello,omitempty"`
 	}
 
 	// condition config for a Rule
@@ -726,6 +1153,18 @@ type (
 		HAMode                          bool
 		PrimaryClusterHealthProbeParams PrimaryClusterHealthProbeParams
 		httpClientMetrics               bool
+		// rolloutPaused holds the requests in heldRequests instead of processing them, the latest request of
+		// every BIG-IP is processed once rolloutResumed is signalled
+		rolloutLock    sync.Mutex
+		rolloutPaused  bool
+		heldRequests   map[cisapiv1.BigIpConfig]ResourceConfigRequest
+		rolloutResumed chan struct{}
+		// lastRequests holds the latest request processed for every BIG-IP, it's processed again when the
+		// provisioned modules gating the features of the declaration change
+		lastRequests map[cisapiv1.BigIpConfig]ResourceConfigRequest
+		// partitionsFetched is signalled with the BIG-IP whose unowned partitions are fetched, the latest request
+		// of the BIG-IP held until then is processed
+		partitionsFetched chan cisapiv1.BigIpConfig
 	}
 
 	PostManager struct {
@@ -740,6 +1179,122 @@ type (
 		PostParams
 		postManagerPrefix      string
 		tenantDeclarationIDMap map[string]string
+		// tenantLastSeen holds the last time a tenant had Kubernetes resources
+		tenantLastSeen      map[string]time.Time
+		bigipTenantsFetched bool
+		// licensedModules holds the active module descriptions of the BIG-IP license
+		licensedModules  []string
+		licenseCheckedAt time.Time
+		// provisionedModules holds the names of the BIG-IP modules provisioned with a level other than none
+		provisionedModules map[string]struct{}
+		// as3Schema is the AS3 schema the dry-run validates the declarations against, the schema shipped with
+		// CIS when nil
+		as3Schema          *AS3Schema
+		provisionCheckedAt time.Time
+		// logPublishers holds the full paths of the Log Publishers found on BIG-IP
+		logPublishers map[string]struct{}
+		// certCheckedAt is the last time the BIG-IP management certificate expiry was checked
+		certCheckedAt time.Time
+		// unownedPartitions holds the BIG-IP partitions which are not managed by CIS, fetched once when the post
+		// manager starts, it's nil until fetched and guarded by unownedPartitionsLock
+		unownedPartitions     map[string]struct{}
+		unownedPartitionsLock sync.RWMutex
+		// checkpointKey is the key of the BIG-IP declaration in the checkpoint ConfigMap
+		checkpointKey string
+		// bigIpAddress is the address of the BIG-IP the last declaration was posted to
+		bigIpAddress string
+		// healthSummary is the health of the tenants after the last post, failedTenants are
+		// the tenants whose last post failed
+		healthLock    sync.RWMutex
+		healthSummary HealthSummary
+		failedTenants map[string]struct{}
+		// tenantStatus is the last known state of the posted tenants, guarded by healthLock
+		tenantStatus map[string]TenantStatus
+		// retryAttempts is the number of consecutive posts with failed tenants, reset by a successful post
+		retryAttempts int
+		// pendingRetry holds the failed tenants of the latest request, the postManager posts them again once
+		// retryTimer signals retryReady, guarded by retryLock
+		retryLock    sync.Mutex
+		pendingRetry *agentConfig
+		retryTimer   *time.Timer
+		retryReady   chan struct{}
+		// pendingCheckpoint holds the tenant declarations to write to the checkpoint ConfigMap once
+		// checkpointTimer fires, guarded by checkpointLock
+		checkpointLock      sync.Mutex
+		pendingCheckpoint   map[string]as3Tenant
+		checkpointTimer     *time.Timer
+		checkpointWriteLock sync.Mutex
+		// unavailableResponses is the number of consecutive 503 responses of BIG-IP, the circuit breaker
+		// rejects the posts until circuitOpenUntil once it reaches the threshold
+		unavailableResponses int
+		circuitOpenUntil     time.Time
+		// packetFilters holds the packet filters applied by CIS on BIG-IP
+		packetFilterLock sync.Mutex
+		packetFilters    map[string]bigIPPacketFilter
+		// sctpProfiles holds the names of the SCTP profiles created by the post manager per BIG-IP address
+		sctpProfiles map[string]map[string]struct{}
+	}
+
+	// bigIPSCTPProfile maps to a SCTP profile of BIG-IP
+	bigIPSCTPProfile struct {
+		Name              string `json:"name"`
+		Partition         string `json:"partition"`
+		DefaultsFrom      string `json:"defaultsFrom"`
+		Description       string `json:"description"`
+		HeartbeatInterval int32  `json:"heartbeatInterval,omitempty"`
+		InitMaxRetries    int32  `json:"initMaxRetries,omitempty"`
+		CookieExpiration  int32  `json:"cookieExpiration,omitempty"`
+	}
+
+	// bigIPPacketFilter maps to a packet filter rule of BIG-IP
+	bigIPPacketFilter struct {
+		Name        string `json:"name"`
+		Partition   string `json:"partition"`
+		Order       int    `json:"order"`
+		Action      string `json:"action"`
+		Rule        string `json:"rule"`
+		Description string `json:"description"`
+	}
+
+	// RetryBackoff is the exponential backoff of the retries of the failed AS3 posts, the delay of the
+	// n-th consecutive retry is BaseDelay * Multiplier^n capped at MaxDelay
+	RetryBackoff struct {
+		BaseDelay  time.Duration
+		Multiplier float64
+		MaxDelay   time.Duration
+	}
+
+	// tenantPosts holds the BIG-IPs every tenant is posted to and whether the latest post of the tenant to
+	// the BIG-IP succeeded
+	tenantPosts struct {
+		sync.Mutex
+		devices map[string]map[cisapiv1.BigIpConfig]bool
+	}
+
+	// BIGIPTarget is a BIG-IP of the DeployConfig which receives the tenants of TenantSelector posted to the other
+	// BIG-IPs as well, e.g. the standby device of an HA pair, a target without TenantSelector receives all the
+	// tenants. A tenant is reported as posted only when all the BIG-IPs it's posted to accepted it
+	BIGIPTarget struct {
+		Address        string
+		TenantSelector []string
+	}
+
+	// HealthSummary is the health of the AS3 tenants managed on a BIG-IP
+	HealthSummary struct {
+		TotalTenants    int       `json:"totalTenants"`
+		HealthyTenants  int       `json:"healthyTenants"`
+		FailedTenants   int       `json:"failedTenants"`
+		LastSyncTime    time.Time `json:"lastSyncTime"`
+		LastSuccessTime time.Time `json:"lastSuccessTime"`
+	}
+
+	// TenantStatus is the last known state of an AS3 tenant posted to a BIG-IP
+	TenantStatus struct {
+		LastPostTime     time.Time `json:"lastPostTime"`
+		LastResponseCode int       `json:"lastResponseCode"`
+		// DeclarationHash is the SHA-256 of the last posted declaration of the tenant
+		DeclarationHash string `json:"declarationHash"`
+		Failed          bool   `json:"failed"`
 	}
 
 	PostManagers struct {
@@ -752,6 +1307,18 @@ type (
 		bigIPAS3Version float64
 		firstPost       bool
 		bigipLabel      string
+		// defaultRouteDomain is the route domain of the tenants without a route domain annotation
+		defaultRouteDomain int
+		// avrUnlicensed is set when the BIG-IP license is known to lack the AVR module
+		avrUnlicensed bool
+		// apmUnlicensed is set when the BIG-IP license is known to lack the APM module
+		apmUnlicensed bool
+		// afmUnlicensed is set when the BIG-IP license is known to lack the AFM module
+		afmUnlicensed bool
+		// unprovisionedModules holds the modules known not to be provisioned on BIG-IP, their features are skipped
+		unprovisionedModules map[string]struct{}
+		// classVersions pins the schemaVersion of the declarations with the AS3 objects of a class
+		classVersions map[string]string
 	}
 
 	PrimaryClusterHealthProbeParams struct {
@@ -765,11 +1332,82 @@ type (
 	}
 
 	PostParams struct {
-		HTTPClientMetrics bool
-		httpClient        *http.Client
-		AS3Config         cisapiv1.AS3Config
-		tokenManager      *tokenmanager.TokenManager
-		UserAgent         string
+		HTTPClientMetrics   bool
+		httpClient          *http.Client
+		AS3Config           cisapiv1.AS3Config
+		tokenManager        *tokenmanager.TokenManager
+		UserAgent           string
+		StalenessThreshold  time.Duration
+		CheckpointConfigMap string
+		WarmStart           bool
+		kubeClient          kubernetes.Interface
+		DefaultRouteDomain  int
+		AuditLogEnabled     bool
+		AuditLogPath        string
+		auditUser           string
+		// DriftDetectionInterval and DriftResync configure the detection of AS3 declaration drift
+		DriftDetectionInterval time.Duration
+		DriftResync            bool
+		// CertExpiryWarningDays is the number of days before the BIG-IP management certificate
+		// expiry to warn about it, 0 disables the check
+		CertExpiryWarningDays int
+		// ClassVersions pins the declarations with the objects of the AS3 classes to a schema version
+		ClassVersions map[string]string
+		// AsyncMode posts the declarations with async=true and polls the AS3 task until it completes or
+		// AsyncTimeout expires, the poll interval starts at AsyncPollInterval and doubles up to AsyncPollMaxInterval
+		AsyncMode            bool
+		AsyncPollInterval    time.Duration
+		AsyncPollMaxInterval time.Duration
+		AsyncTimeout         time.Duration
+		// ProvisionCheckInterval is the interval to refresh the provisioned BIG-IP modules, 0 fetches them once
+		ProvisionCheckInterval time.Duration
+		// RetryBackoff is the backoff of the retries of the failed tenants
+		RetryBackoff RetryBackoff
+		// DryRun checks the tenants and the classes of the declarations against the AS3 schema and logs them without
+		// posting them, the valid tenants are handled as posted
+		DryRun bool
+		// MaxDeclarationSize is the size limit of the declarations, the larger ones are not posted
+		MaxDeclarationSize int
+		// CircuitBreakerThreshold is the number of consecutive 503 responses opening the circuit breaker, which
+		// rejects the posts for CircuitBreakerCooldown before a post probes BIG-IP again, 0 disables it
+		CircuitBreakerThreshold int
+		CircuitBreakerCooldown  time.Duration
+		// tracer records the spans of the declaration posts, nil when tracing is disabled
+		tracer *Tracer
+		// podName and podNamespace identify the CIS pod the warning events are created on
+		podName      string
+		podNamespace string
+	}
+
+	// auditEvent is the subset of the audit.k8s.io/v1 Event written for every tenant posted to BIG-IP
+	auditEvent struct {
+		Kind                     string            `json:"kind"`
+		APIVersion               string            `json:"apiVersion"`
+		Level                    string            `json:"level"`
+		AuditID                  string            `json:"auditID"`
+		Stage                    string            `json:"stage"`
+		RequestURI               string            `json:"requestURI"`
+		Verb                     string            `json:"verb"`
+		User                     auditUserInfo     `json:"user"`
+		ObjectRef                auditObjectRef    `json:"objectRef"`
+		ResponseStatus           *auditStatus      `json:"responseStatus,omitempty"`
+		RequestReceivedTimestamp metav1.MicroTime  `json:"requestReceivedTimestamp"`
+		StageTimestamp           metav1.MicroTime  `json:"stageTimestamp"`
+		Annotations              map[string]string `json:"annotations,omitempty"`
+	}
+
+	auditUserInfo struct {
+		Username string `json:"username"`
+	}
+
+	auditObjectRef struct {
+		Resource string `json:"resource"`
+		Name     string `json:"name"`
+		APIGroup string `json:"apiGroup"`
+	}
+
+	auditStatus struct {
+		Code int `json:"code"`
 	}
 
 	tenantResponse struct {
@@ -796,6 +1434,20 @@ type (
 		failedTenants         map[string]struct{}
 		incomingTenantDeclMap map[string]as3Tenant
 		deleted               bool
+		// span is the span of the declaration post, nil until the declaration is posted
+		span *traceSpan
+		// retryDelay is the delay before the failed tenants of the declaration are posted again
+		retryDelay time.Duration
+	}
+
+	// TenantDiff is the change of a tenant between two AS3 declarations, Keys are the paths of the keys
+	// changed in a modified tenant and Before and After the JSON of the changed part of the tenant
+	TenantDiff struct {
+		Tenant string
+		Change TenantChangeType
+		Keys   []string
+		Before string
+		After  string
 	}
 
 	//TODO L3Config to put into post channel. Handle with L3Postmanager implementation
@@ -828,9 +1480,17 @@ type (
 
 	// as3EndpointPolicy maps to Endpoint_Policy in AS3 Resources
 	as3EndpointPolicy struct {
-		Class    string     `json:"class,omitempty"`
-		Rules    []*as3Rule `json:"rules,omitempty"`
-		Strategy string     `json:"strategy,omitempty"`
+		Class          string     `json:"class,omitempty"`
+		Rules          []*as3Rule `json:"rules,omitempty"`
+		Strategy       string     `json:"strategy,omitempty"`
+		CustomStrategy string     `json:"customStrategy,omitempty"`
+	}
+
+	// as3EndpointStrategy maps to Endpoint_Strategy in AS3 Resources
+	as3EndpointStrategy struct {
+		Class       string   `json:"class,omitempty"`
+		MatchMethod string   `json:"matchMethod,omitempty"`
+		Operands    []string `json:"operands,omitempty"`
 	}
 
 	// as3Rule maps to Endpoint_Policy_Rule in AS3 Resources
@@ -925,6 +1585,7 @@ type (
 		ShareNodes       bool     `json:"shareNodes,omitempty"`
 		AdminState       string   `json:"adminState,omitempty"`
 		ConnectionLimit  int32    `json:"connectionLimit,omitempty"`
+		Ratio            int      `json:"ratio,omitempty"`
 	}
 
 	// as3ResourcePointer maps to following in AS3 Resources
@@ -957,18 +1618,289 @@ type (
 		IRules           as3MultiTypeParam   `json:"iRules,omitempty"`
 		Redirect80       *bool               `json:"redirect80,omitempty"`
 		//Pool                 *as3ResourcePointer  `json:"pool,omitempty"`
-		Pool                 interface{}          `json:"pool,omitempty"`
-		WAF                  as3MultiTypeParam    `json:"policyWAF,omitempty"`
-		Firewall             as3MultiTypeParam    `json:"policyFirewallEnforced,omitempty"`
-		LogProfiles          []as3ResourcePointer `json:"securityLogProfiles,omitempty"`
-		ProfileL4            as3MultiTypeParam    `json:"profileL4,omitempty"`
-		PersistenceMethods   *[]as3MultiTypeParam `json:"persistenceMethods,omitempty"`
-		ProfileTCP           as3MultiTypeParam    `json:"profileTCP,omitempty"`
-		ProfileUDP           as3MultiTypeParam    `json:"profileUDP,omitempty"`
-		ProfileHTTP          as3MultiTypeParam    `json:"profileHTTP,omitempty"`
-		ProfileHTTP2         as3MultiTypeParam    `json:"profileHTTP2,omitempty"`
-		ProfileMultiplex     as3MultiTypeParam    `json:"profileMultiplex,omitempty"`
-		HttpAnalyticsProfile *as3ResourcePointer  `json:"profileAnalytics,omitempty"`
+		Pool                    interface{}          `json:"pool,omitempty"`
+		WAF                     as3MultiTypeParam    `json:"policyWAF,omitempty"`
+		Firewall                as3MultiTypeParam    `json:"policyFirewallEnforced,omitempty"`
+		LogProfiles             []as3ResourcePointer `json:"securityLogProfiles,omitempty"`
+		ProfileL4               as3MultiTypeParam    `json:"profileL4,omitempty"`
+		PersistenceMethods      *[]as3MultiTypeParam `json:"persistenceMethods,omitempty"`
+		ProfileTCP              as3MultiTypeParam    `json:"profileTCP,omitempty"`
+		ProfileUDP              as3MultiTypeParam    `json:"profileUDP,omitempty"`
+		ProfileHTTP             as3MultiTypeParam    `json:"profileHTTP,omitempty"`
+		ProfileHTTP2            as3MultiTypeParam    `json:"profileHTTP2,omitempty"`
+		ProfileMultiplex        as3MultiTypeParam    `json:"profileMultiplex,omitempty"`
+		HttpAnalyticsProfile    *as3ResourcePointer  `json:"profileAnalytics,omitempty"`
+		TcpAnalyticsProfile     *as3ResourcePointer  `json:"profileAnalyticsTcp,omitempty"`
+		RateLimit               int64                `json:"rateLimit,omitempty"`
+		BandwidthControl        *as3ResourcePointer  `json:"policyBandwidthControl,omitempty"`
+		ProfileClassification   *as3ResourcePointer  `json:"profileClassification,omitempty"`
+		ProfileRewrite          *as3ResourcePointer  `json:"profileRewrite,omitempty"`
+		ProfileStream           *as3ResourcePointer  `json:"profileStream,omitempty"`
+		ProfileBotDefense       *as3ResourcePointer  `json:"profileBotDefense,omitempty"`
+		ProfileDOS              *as3ResourcePointer  `json:"profileDOS,omitempty"`
+		ProfileInspection       *as3ResourcePointer  `json:"profileProtocolInspection,omitempty"`
+		ProfileHTML             *as3ResourcePointer  `json:"profileHTML,omitempty"`
+		ProfileRequestAdapt     *as3ResourcePointer  `json:"profileRequestAdapt,omitempty"`
+		ProfileResponseAdapt    *as3ResourcePointer  `json:"profileResponseAdapt,omitempty"`
+		ProfileWebSocket        *as3ResourcePointer  `json:"profileWebSocket,omitempty"`
+		ProfileAccess           *as3ResourcePointer  `json:"profileAccess,omitempty"`
+		ProfileDiameterEndpoint *as3ResourcePointer  `json:"profileDiameterEndpoint,omitempty"`
+		ProfileIPOther          *as3ResourcePointer  `json:"profileIPOther,omitempty"`
+		ProfileSIP              *as3ResourcePointer  `json:"profileSIP,omitempty"`
+		MQTTEnabled             bool                 `json:"mqttEnabled,omitempty"`
+		ProfileSCTP             *as3ResourcePointer  `json:"profileSCTP,omitempty"`
+		ProfileRadius           *as3ResourcePointer  `json:"profileRADIUS,omitempty"`
+		ProfileHTTPCompression  *as3ResourcePointer  `json:"profileHTTPCompression,omitempty"`
+		PolicyNAT               *as3ResourcePointer  `json:"policyNAT,omitempty"`
+		ForwardingType          string               `json:"forwardingType,omitempty"`
+		// sctpProfile is the SCTP profile referenced by profileSCTP, which is created on BIG-IP before the post
+		// as AS3 doesn't declare SCTP profiles
+		sctpProfile *bigIPSCTPProfile
+	}
+
+	// as3SIPProfile maps to SIP_Profile in AS3 Resources
+	as3SIPProfile struct {
+		Class string `json:"class"`
+	}
+
+	// as3RadiusProfile maps to Radius_Profile in AS3 Resources, persistAttribute is the name or the numeric code
+	// of the RADIUS attribute
+	as3RadiusProfile struct {
+		Class                      string      `json:"class"`
+		PersistAttribute           interface{} `json:"persistAttribute,omitempty"`
+		SubscriberDiscoveryEnabled *bool       `json:"subscriberDiscoveryEnabled,omitempty"`
+	}
+
+	// as3HTTPCompressProfile maps to HTTP_Compress in AS3 Resources
+	as3HTTPCompressProfile struct {
+		Class               string   `json:"class"`
+		PreferMethod        string   `json:"preferMethod,omitempty"`
+		ContentTypeIncludes []string `json:"contentTypeIncludes,omitempty"`
+		MinimumSize         int      `json:"minimumSize,omitempty"`
+	}
+
+	// as3HTTPProfile maps to HTTP_Profile in AS3 Resources
+	as3HTTPProfile struct {
+		Class         string `json:"class"`
+		XForwardedFor *bool  `json:"xForwardedFor,omitempty"`
+	}
+
+	// as3MultiplexProfile maps to Multiplex_Profile in AS3 Resources
+	as3MultiplexProfile struct {
+		Class              string `json:"class,omitempty"`
+		MaxConnections     int64  `json:"maxConnections,omitempty"`
+		MaxConnectionReuse int64  `json:"maxConnectionReuse,omitempty"`
+		MaxConnectionAge   int64  `json:"maxConnectionAge"`
+		SourceMask         string `json:"sourceMask,omitempty"`
+	}
+
+	// as3StreamProfile maps to Stream_Profile in AS3 Resources
+	as3StreamProfile struct {
+		Class           string              `json:"class"`
+		ParentProfile   *as3ResourcePointer `json:"parentProfile,omitempty"`
+		Source          string              `json:"source,omitempty"`
+		Target          string              `json:"target,omitempty"`
+		ChunkingEnabled bool                `json:"chunkingEnabled,omitempty"`
+		ChunkSize       int64               `json:"chunkSize,omitempty"`
+	}
+
+	// as3HTMLProfile maps to HTML_Profile in AS3 Resources
+	as3HTMLProfile struct {
+		Class                   string   `json:"class"`
+		ContentDetectionEnabled bool     `json:"contentDetectionEnabled"`
+		ContentSelection        []string `json:"contentSelection,omitempty"`
+	}
+
+	// as3DiameterProfile maps to Enforcement_Diameter_Endpoint_Profile in AS3 Resources
+	as3DiameterProfile struct {
+		Class            string              `json:"class"`
+		ParentProfile    *as3ResourcePointer `json:"parentProfile,omitempty"`
+		DestinationHost  string              `json:"destinationHost,omitempty"`
+		DestinationRealm string              `json:"destinationRealm,omitempty"`
+		SupportedApps    []string            `json:"supportedApps"`
+	}
+
+	// as3ICAPProfile maps to ICAP_Profile in AS3 Resources
+	as3ICAPProfile struct {
+		Class string `json:"class"`
+		URI   string `json:"uri,omitempty"`
+	}
+
+	// as3AdaptProfile maps to Adapt_Profile in AS3 Resources
+	as3AdaptProfile struct {
+		Class             string             `json:"class"`
+		MessageType       string             `json:"messageType"`
+		InternalService   as3ResourcePointer `json:"internalService"`
+		AllowHTTP10       bool               `json:"allowHTTP10,omitempty"`
+		ServiceDownAction string             `json:"serviceDownAction,omitempty"`
+	}
+
+	// as3InternalService maps to the internal Service_TCP forwarding the adapted traffic to the ICAP server
+	as3InternalService struct {
+		Class       string              `json:"class"`
+		VirtualType string              `json:"virtualType"`
+		Pool        string              `json:"pool"`
+		ProfileICAP *as3ResourcePointer `json:"profileICAP,omitempty"`
+	}
+
+	// as3DOSProfile maps to DOS_Profile in AS3 Resources
+	as3DOSProfile struct {
+		Class   string         `json:"class"`
+		Network *as3DOSNetwork `json:"network,omitempty"`
+	}
+
+	// as3DOSNetwork maps to the network DoS vectors of DOS_Profile
+	as3DOSNetwork struct {
+		Vectors []as3DOSNetworkVector `json:"vectors"`
+	}
+
+	// as3DOSNetworkVector maps to DOS_Network_Vector in AS3 Resources
+	as3DOSNetworkVector struct {
+		Type                  string `json:"type"`
+		State                 string `json:"state"`
+		ThresholdMode         string `json:"thresholdMode"`
+		RateThreshold         int64  `json:"rateThreshold,omitempty"`
+		RateIncreaseThreshold int64  `json:"rateIncreaseThreshold,omitempty"`
+		RateLimit             int64  `json:"rateLimit,omitempty"`
+	}
+
+	// as3WebSocketProfile maps to WebSocket_Profile in AS3 Resources
+	as3WebSocketProfile struct {
+		Class       string `json:"class"`
+		Compression bool   `json:"compression"`
+		Masking     string `json:"masking,omitempty"`
+	}
+
+	// as3IFile maps to iFile in AS3 Resources
+	as3IFile struct {
+		Class string         `json:"class"`
+		IFile as3IFileSource `json:"iFile"`
+	}
+
+	// as3IFileSource holds the base64 encoded content of the iFile
+	as3IFileSource struct {
+		Base64 string `json:"base64"`
+	}
+
+	// as3AnalyticsTCPProfile maps to Analytics_TCP_Profile in AS3 Resources
+	as3AnalyticsTCPProfile struct {
+		Class               string `json:"class"`
+		CollectRemoteHostIp bool   `json:"collectRemoteHostIp,omitempty"`
+		CollectNexthop      bool   `json:"collectNexthop,omitempty"`
+	}
+
+	// as3RewriteProfile maps to Rewrite_Profile in AS3 Resources
+	as3RewriteProfile struct {
+		Class       string              `json:"class"`
+		RewriteMode string              `json:"rewriteMode"`
+		URIRules    []as3RewriteURIRule `json:"uriRules"`
+	}
+
+	// as3RewriteURIRule maps to Rewrite_Profile_Uri_Rule in AS3 Resources
+	as3RewriteURIRule struct {
+		Type   string        `json:"type"`
+		Client as3RewriteURI `json:"client"`
+		Server as3RewriteURI `json:"server"`
+	}
+
+	as3RewriteURI struct {
+		Scheme string `json:"scheme,omitempty"`
+		Host   string `json:"host,omitempty"`
+		Path   string `json:"path"`
+	}
+
+	// as3BandwidthControlPolicy maps to Bandwidth_Control_Policy in AS3 Resources
+	as3BandwidthControlPolicy struct {
+		Class            string `json:"class"`
+		MaxBandwidth     int64  `json:"maxBandwidth"`
+		MaxBandwidthUnit string `json:"maxBandwidthUnit"`
+	}
+
+	// as3NetAddressList maps to Net_Address_List and Firewall_Address_List in AS3 Resources
+	as3NetAddressList struct {
+		Class     string   `json:"class"`
+		Addresses []string `json:"addresses"`
+	}
+
+	// as3NetPortList maps to Net_Port_List in AS3 Resources
+	as3NetPortList struct {
+		Class string              `json:"class"`
+		Ports []as3MultiTypeParam `json:"ports"`
+	}
+
+	// as3FirewallPolicy maps to Firewall_Policy in AS3 Resources
+	as3FirewallPolicy struct {
+		Class string               `json:"class"`
+		Rules []as3ResourcePointer `json:"rules"`
+	}
+
+	// as3FirewallRuleList maps to Firewall_Rule_List in AS3 Resources
+	as3FirewallRuleList struct {
+		Class string            `json:"class"`
+		Rules []as3FirewallRule `json:"rules"`
+	}
+
+	// as3FirewallRule maps to Firewall_Rule in AS3 Resources
+	as3FirewallRule struct {
+		Name        string                      `json:"name"`
+		Action      string                      `json:"action"`
+		Protocol    string                      `json:"protocol"`
+		Source      *as3FirewallRuleSource      `json:"source,omitempty"`
+		Destination *as3FirewallRuleDestination `json:"destination,omitempty"`
+	}
+
+	// as3FirewallRuleSource maps to the source of Firewall_Rule in AS3 Resources
+	as3FirewallRuleSource struct {
+		AddressLists []as3ResourcePointer `json:"addressLists,omitempty"`
+	}
+
+	// as3FirewallRuleDestination maps to the destination of Firewall_Rule and NAT_Rule in AS3 Resources
+	as3FirewallRuleDestination struct {
+		AddressLists []as3ResourcePointer `json:"addressLists,omitempty"`
+		PortLists    []as3ResourcePointer `json:"portLists,omitempty"`
+	}
+
+	// as3SecurityLogProfile maps to Security_Log_Profile in AS3 Resources
+	as3SecurityLogProfile struct {
+		Class   string                        `json:"class"`
+		Network *as3SecurityLogProfileNetwork `json:"network,omitempty"`
+	}
+
+	// as3SecurityLogProfileNetwork maps to the network settings of Security_Log_Profile in AS3 Resources
+	as3SecurityLogProfileNetwork struct {
+		Publisher           as3ResourcePointer `json:"publisher"`
+		LogRuleMatchAccepts bool               `json:"logRuleMatchAccepts"`
+		LogRuleMatchRejects bool               `json:"logRuleMatchRejects"`
+		LogRuleMatchDrops   bool               `json:"logRuleMatchDrops"`
+		LogIpErrors         bool               `json:"logIpErrors"`
+	}
+
+	// as3SNATPool maps to SNAT_Pool in AS3 Resources
+	as3SNATPool struct {
+		Class         string   `json:"class"`
+		SNATAddresses []string `json:"snatAddresses"`
+	}
+
+	// as3NATPolicy maps to NAT_Policy in AS3 Resources
+	as3NATPolicy struct {
+		Class string       `json:"class"`
+		Rules []as3NATRule `json:"rules,omitempty"`
+	}
+
+	// as3NATRule maps to NAT_Rule in AS3 Resources
+	as3NATRule struct {
+		Name              string                      `json:"name"`
+		Protocol          string                      `json:"protocol"`
+		Source            *as3FirewallRuleSource      `json:"source,omitempty"`
+		Destination       *as3FirewallRuleDestination `json:"destination,omitempty"`
+		SourceTranslation *as3ResourcePointer         `json:"sourceTranslation,omitempty"`
+	}
+
+	// as3NATSourceTranslation maps to NAT_Source_Translation in AS3 Resources
+	as3NATSourceTranslation struct {
+		Class     string   `json:"class"`
+		Type      string   `json:"type"`
+		Addresses []string `json:"addresses"`
 	}
 
 	// as3ServiceAddress maps to VirtualAddress in AS3 Resources
@@ -998,6 +1930,36 @@ type (
 		Ciphers           string `json:"ciphers,omitempty"`
 	}
 
+	// as3ExternalMonitor maps to Monitor_External in AS3 Resources
+	as3ExternalMonitor struct {
+		Class                string            `json:"class"`
+		MonitorType          string            `json:"monitorType"`
+		Interval             int               `json:"interval,omitempty"`
+		Timeout              int               `json:"timeout,omitempty"`
+		Script               as3IFileSource    `json:"script"`
+		Arguments            string            `json:"arguments,omitempty"`
+		EnvironmentVariables map[string]string `json:"environmentVariables,omitempty"`
+	}
+
+	// as3LDAPMonitor maps to Monitor_LDAP in AS3 Resources
+	as3LDAPMonitor struct {
+		Class       string     `json:"class"`
+		MonitorType string     `json:"monitorType"`
+		Interval    int        `json:"interval,omitempty"`
+		Timeout     int        `json:"timeout,omitempty"`
+		Base        string     `json:"base"`
+		Filter      string     `json:"filter"`
+		Security    string     `json:"security,omitempty"`
+		Username    string     `json:"username,omitempty"`
+		Passphrase  *as3Secret `json:"passphrase,omitempty"`
+	}
+
+	// as3Secret maps to Secret in AS3 Resources
+	as3Secret struct {
+		Ciphertext string `json:"ciphertext"`
+		Protected  string `json:"protected"`
+	}
+
 	// as3CABundle maps to CA_Bundle in AS3 Resources
 	as3CABundle struct {
 		Class  string `json:"class,omitempty"`
@@ -1014,11 +1976,37 @@ type (
 
 	// as3TLSServer maps to TLS_Server in AS3 Resources
 	as3TLSServer struct {
-		Class         string                     `json:"class,omitempty"`
-		Certificates  []as3TLSServerCertificates `json:"certificates,omitempty"`
-		Ciphers       string                     `json:"ciphers,omitempty"`
-		CipherGroup   *as3ResourcePointer        `json:"cipherGroup,omitempty"`
-		TLS1_3Enabled bool                       `json:"tls1_3Enabled,omitempty"`
+		Class                      string                     `json:"class,omitempty"`
+		Certificates               []as3TLSServerCertificates `json:"certificates,omitempty"`
+		Ciphers                    string                     `json:"ciphers,omitempty"`
+		CipherGroup                *as3ResourcePointer        `json:"cipherGroup,omitempty"`
+		TLS1_3Enabled              bool                       `json:"tls1_3Enabled,omitempty"`
+		CRLFile                    *as3ResourcePointer        `json:"crlFile,omitempty"`
+		AuthenticationMode         string                     `json:"authenticationMode,omitempty"`
+		AuthenticationTrustCA      *as3ResourcePointer        `json:"authenticationTrustCA,omitempty"`
+		C3DOCSP                    *as3ResourcePointer        `json:"c3dOCSP,omitempty"`
+		C3DOCSPUnknownStatusAction string                     `json:"c3dOCSPUnknownStatusAction,omitempty"`
+	}
+
+	// as3CipherRule maps to Cipher_Rule in AS3 Resources
+	as3CipherRule struct {
+		Class        string   `json:"class,omitempty"`
+		CipherSuites []string `json:"cipherSuites,omitempty"`
+	}
+
+	// as3CipherGroup maps to Cipher_Group in AS3 Resources
+	as3CipherGroup struct {
+		Class              string               `json:"class,omitempty"`
+		Order              string               `json:"order,omitempty"`
+		AllowCipherRules   []as3ResourcePointer `json:"allowCipherRules,omitempty"`
+		ExcludeCipherRules []as3ResourcePointer `json:"excludeCipherRules,omitempty"`
+	}
+
+	// as3CertificateValidatorOCSP maps to Certificate_Validator_OCSP in AS3 Resources
+	as3CertificateValidatorOCSP struct {
+		Class        string              `json:"class,omitempty"`
+		ResponderURL string              `json:"responderUrl,omitempty"`
+		DNSResolver  *as3ResourcePointer `json:"dnsResolver,omitempty"`
 	}
 
 	// as3TLSServerCertificates maps to TLS_Server_certificates in AS3 Resources
@@ -1064,6 +2052,8 @@ type (
 		Session         string `json:"session,omitempty"`
 		AdminState      string `json:"adminState,omitempty"`
 		ConnectionLimit int32  `json:"connectionLimit,omitempty"`
+		Ratio           int    `json:"ratio,omitempty"`
+		Zone            string `json:"zone,omitempty"`
 	}
 )
 
@@ -1191,6 +2181,9 @@ type AnnotationsUsed struct {
 
 type TLSVersion string
 
+// TenantChangeType is the change of a tenant between two AS3 declarations
+type TenantChangeType string
+
 type (
 	PoolIdentifier struct {
 		poolName   string
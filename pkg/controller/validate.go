@@ -19,8 +19,12 @@ package controller
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -43,18 +47,127 @@ func (ctlr *Controller) checkValidVirtualServer(
 		log.Infof("VirtualServer %s is invalid", vsName)
 		return false
 	}
+	// Check if the VS may write its tenant
+	if ctlr.rbacEnabled && !ctlr.checkVirtualServerTenantAccess(vsResource) {
+		return false
+	}
 	// Check if HTTPTraffic is set for insecure VS
 	if vsResource.Spec.TLSProfileName == "" && vsResource.Spec.HTTPTraffic != "" {
 		log.Warningf("HTTPTraffic not allowed to be set for insecure VirtualServer: %v", vsName)
 		return false
 	}
+	// Check if TLS is set for TCP VS
+	if vsResource.Spec.Protocol == TCP && vsResource.Spec.TLSProfileName != "" {
+		log.Warningf("TLSProfile not allowed to be set for TCP VirtualServer: %v", vsName)
+		return false
+	}
+	// Check the configurations not supported by AS3 for UDP VS and surface them in the status
+	if vsResource.Spec.Protocol == UDP {
+		if violations := getUDPVirtualServerViolations(vsResource); len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			log.Warningf("Invalid UDP VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonUnsupportedInUDP, message)
+			return false
+		}
+		ctlr.updateVirtualServerValidCondition(vsResource, true, VSReasonValid, "")
+	}
+	// Check the SIP transport and warn about secure SIP without TLS profile
+	if vsResource.Spec.Protocol == SIP {
+		if violations := getSIPVirtualServerViolations(vsResource); len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			log.Warningf("Invalid SIP VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidSIP, message)
+			return false
+		}
+		if vsResource.Spec.SIP != nil && vsResource.Spec.SIP.Secure && vsResource.Spec.TLSProfileName == "" {
+			message := "secure SIP is set but no tlsProfileName is referenced, SIP traffic is not TLS terminated"
+			log.Warningf("SIP VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, true, VSReasonSIPWithoutTLS, message)
+		} else {
+			ctlr.updateVirtualServerValidCondition(vsResource, true, VSReasonValid, "")
+		}
+	}
+	// Check the configurations not supported by the MQTT VS
+	if vsResource.Spec.Protocol == MQTT {
+		if violations := getMQTTVirtualServerViolations(vsResource); len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			log.Warningf("Invalid MQTT VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidMQTT, message)
+			return false
+		}
+		ctlr.updateVirtualServerValidCondition(vsResource, true, VSReasonValid, "")
+	}
+	// Check the SCTP settings and the HTTP configurations conflicting with the SCTP VS
+	if vsResource.Spec.Protocol == SCTP || vsResource.Spec.SCTP != nil {
+		if conflicts := getSCTPVirtualServerConflicts(vsResource); len(conflicts) > 0 {
+			message := strings.Join(conflicts, "; ")
+			log.Warningf("Invalid SCTP VirtualServer %v: %v", vsName, message)
+			ctlr.recordVirtualServerEvent(vsResource, v1.EventTypeWarning, SCTPProfileConflictEvent, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidSCTP, message)
+			return false
+		}
+		if violations := getSCTPVirtualServerViolations(vsResource); len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			log.Warningf("Invalid SCTP VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidSCTP, message)
+			return false
+		}
+		ctlr.updateVirtualServerValidCondition(vsResource, true, VSReasonValid, "")
+	}
+	// Check the WebSocket settings and the protocol of the WebSocket VS
+	if vsResource.Spec.WebSocketEnabled {
+		if violations := getWebSocketVirtualServerViolations(vsResource); len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			log.Warningf("Invalid WebSocket VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidWebSocket, message)
+			return false
+		}
+	}
+	// Check the protocol number and the configurations not supported for other IP protocol VS
+	if _, ok := vsResource.Annotations[ProtocolAnnotation]; ok {
+		if violations := getIPOtherVirtualServerViolations(vsResource); len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			log.Warningf("Invalid other IP protocol VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidIPProtocol, message)
+			return false
+		}
+		ctlr.updateVirtualServerValidCondition(vsResource, true, VSReasonValid, "")
+	}
+	// Check the forwarding settings and the configurations not supported for forwarding VS
+	if _, ok := vsResource.Annotations[ForwardingAnnotation]; ok {
+		if violations := getForwardingVirtualServerViolations(vsResource); len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			log.Warningf("Invalid forwarding VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidForwarding, message)
+			return false
+		}
+	}
+	// Check the SNI routing is used with the passthrough termination
+	if _, ok := vsResource.Annotations[SNIRoutingAnnotation]; ok {
+		if violations := ctlr.getSNIRoutingVirtualServerViolations(vsResource); len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			log.Warningf("Invalid SNI routing VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidSNIRouting, message)
+			return false
+		}
+	}
+	// Check the SNATPool referenced by snat exists in the namespace of the VirtualServer
+	if isSNATPoolName(vsResource.Spec.SNAT) {
+		if _, err := ctlr.getSNATPool(vsResource.Namespace, vsResource.Spec.SNAT); err != nil {
+			message := fmt.Sprintf("SNATPool %v not found in namespace %v", vsResource.Spec.SNAT, vsResource.Namespace)
+			log.Warningf("Invalid snat of VirtualServer %v: %v", vsName, message)
+			ctlr.updateVirtualServerValidCondition(vsResource, false, VSReasonInvalidSNAT, message)
+			return false
+		}
+	}
 
 	bindAddr := vsResource.Spec.VirtualServerAddress
 	if ctlr.ipamHandler == nil {
 
 		// This ensures that pool-only mode only logs the message below the first
 		// time we see a config.
-		if bindAddr == "" {
+		// The address of the virtual server is allocated from the virtual address pool when configured
+		if bindAddr == "" && ctlr.vipPool == nil {
 			log.Infof("No IP was specified for the virtual server %s", vsName)
 			return false
 		}
@@ -82,6 +195,201 @@ func (ctlr *Controller) checkValidVirtualServer(
 	return true
 }
 
+// getUDPVirtualServerViolations returns the TLS, HTTP and persistence configurations of a UDP VirtualServer
+func getUDPVirtualServerViolations(vs *cisapiv1.VirtualServer) []string {
+	var violations []string
+	if vs.Spec.TLSProfileName != "" {
+		violations = append(violations, "tlsProfileName is not supported")
+	}
+	if vs.Spec.Profiles.HTTP2 != (cisapiv1.ProfileHTTP2{}) || vs.Spec.ProfileMultiplex != "" {
+		violations = append(violations, "HTTP profiles are not supported")
+	}
+	if vs.Spec.PersistenceProfile != "" {
+		violations = append(violations, "persistenceProfile is not supported")
+	}
+	return violations
+}
+
+// getSIPVirtualServerViolations returns the invalid transport and the HTTP configurations of a SIP VirtualServer
+func getSIPVirtualServerViolations(vs *cisapiv1.VirtualServer) []string {
+	var violations []string
+	if sip := vs.Spec.SIP; sip != nil {
+		if sip.Transport != "" && sip.Transport != UDP && sip.Transport != TCP {
+			violations = append(violations, fmt.Sprintf("transport %v is not supported, use udp or tcp", sip.Transport))
+		}
+		if sip.Secure && sip.Transport == UDP {
+			violations = append(violations, "secure SIP requires tcp transport")
+		}
+		if sip.Port < 0 || sip.Port > MaxPortNumber {
+			violations = append(violations, fmt.Sprintf("port %v is not in range 1-%v", sip.Port, MaxPortNumber))
+		}
+	}
+	if vs.Spec.Profiles.HTTP2 != (cisapiv1.ProfileHTTP2{}) || vs.Spec.ProfileMultiplex != "" {
+		violations = append(violations, "HTTP profiles are not supported")
+	}
+	return violations
+}
+
+// getMQTTVirtualServerViolations returns the configurations not supported by an MQTT VirtualServer
+func getMQTTVirtualServerViolations(vs *cisapiv1.VirtualServer) []string {
+	var violations []string
+	if vs.Spec.TLSProfileName != "" {
+		violations = append(violations, "tlsProfileName is not supported")
+	}
+	if vs.Spec.Profiles.HTTP2 != (cisapiv1.ProfileHTTP2{}) || vs.Spec.ProfileMultiplex != "" {
+		violations = append(violations, "HTTP profiles are not supported")
+	}
+	return violations
+}
+
+// getSCTPVirtualServerViolations returns the invalid SCTP settings of an SCTP VirtualServer, SCTP profiles are
+// applicable only to the virtuals of protocol sctp
+func getSCTPVirtualServerViolations(vs *cisapiv1.VirtualServer) []string {
+	var violations []string
+	if vs.Spec.Protocol != SCTP {
+		violations = append(violations, fmt.Sprintf("sctp is not supported with protocol %v, use protocol sctp",
+			vs.Spec.Protocol))
+	}
+	if sctp := vs.Spec.SCTP; sctp != nil {
+		if sctp.HeartbeatInterval < 0 {
+			violations = append(violations, fmt.Sprintf("heartbeatInterval %v should be positive", sctp.HeartbeatInterval))
+		}
+		if sctp.MaxInitRetries < 0 {
+			violations = append(violations, fmt.Sprintf("maxInitRetries %v should be positive", sctp.MaxInitRetries))
+		}
+		if sctp.CookieExpiry < 0 {
+			violations = append(violations, fmt.Sprintf("cookieExpiry %v should be positive", sctp.CookieExpiry))
+		}
+	}
+	if vs.Spec.VirtualServerHTTPPort == 0 {
+		violations = append(violations, "virtualServerHTTPPort is required for the SCTP port")
+	}
+	return violations
+}
+
+// getSCTPVirtualServerConflicts returns the HTTP configurations of an SCTP VirtualServer, which can't be
+// attached to the SCTP virtual
+func getSCTPVirtualServerConflicts(vs *cisapiv1.VirtualServer) []string {
+	var conflicts []string
+	if vs.Spec.TLSProfileName != "" {
+		conflicts = append(conflicts, "tlsProfileName conflicts with the SCTP profile")
+	}
+	if vs.Spec.Profiles.HTTP2 != (cisapiv1.ProfileHTTP2{}) || vs.Spec.ProfileMultiplex != "" {
+		conflicts = append(conflicts, "HTTP profiles conflict with the SCTP profile")
+	}
+	if vs.Spec.WebSocketEnabled {
+		conflicts = append(conflicts, "webSocketEnabled conflicts with the SCTP profile")
+	}
+	if vs.Spec.WAF != "" {
+		conflicts = append(conflicts, "waf conflicts with the SCTP profile")
+	}
+	if vs.Spec.RewriteAppRoot != "" || len(vs.Spec.URLMap) > 0 {
+		conflicts = append(conflicts, "HTTP rewrites conflict with the SCTP profile")
+	}
+	return conflicts
+}
+
+// getWebSocketVirtualServerViolations returns the invalid WebSocket settings and the protocols not supported
+// by a WebSocket VirtualServer
+func getWebSocketVirtualServerViolations(vs *cisapiv1.VirtualServer) []string {
+	var violations []string
+	if vs.Spec.Protocol != "" && vs.Spec.Protocol != HTTP {
+		violations = append(violations, fmt.Sprintf("WebSocket is not supported with protocol %v", vs.Spec.Protocol))
+	}
+	if _, ok := vs.Annotations[ProtocolAnnotation]; ok {
+		violations = append(violations, "WebSocket is not supported with other IP protocols")
+	}
+	if ws := vs.Spec.WebSocket; ws != nil {
+		switch ws.Masking {
+		case "", WebSocketMaskingAuto, WebSocketMaskingUnmask, WebSocketMaskingSelective:
+		default:
+			violations = append(violations, fmt.Sprintf("masking %v is not supported, use auto, unmask or selective",
+				ws.Masking))
+		}
+	}
+	return violations
+}
+
+// getIPOtherVirtualServerViolations returns the invalid protocol settings and the TLS and HTTP configurations
+// of a VirtualServer for the other IP protocols
+func getIPOtherVirtualServerViolations(vs *cisapiv1.VirtualServer) []string {
+	var violations []string
+	if protocol := vs.Annotations[ProtocolAnnotation]; protocol != ProtocolOther {
+		violations = append(violations, fmt.Sprintf("%v annotation supports only %v, use protocol for tcp and udp",
+			ProtocolAnnotation, ProtocolOther))
+	}
+	if vs.Spec.Protocol != "" {
+		violations = append(violations, fmt.Sprintf("protocol %v conflicts with %v annotation", vs.Spec.Protocol,
+			ProtocolAnnotation))
+	}
+	if _, err := parseIPProtocolNumber(vs.Annotations[IPProtocolNumberAnnotation]); err != nil {
+		violations = append(violations, err.Error())
+	}
+	if vs.Spec.TLSProfileName != "" {
+		violations = append(violations, "tlsProfileName is not supported")
+	}
+	if vs.Spec.Profiles.HTTP2 != (cisapiv1.ProfileHTTP2{}) || vs.Spec.ProfileMultiplex != "" {
+		violations = append(violations, "HTTP profiles are not supported")
+	}
+	return violations
+}
+
+// getForwardingVirtualServerViolations returns the invalid forwarding settings and the persistence and HTTP
+// configurations of a forwarding VirtualServer, which routes the traffic without terminating the connections
+func getForwardingVirtualServerViolations(vs *cisapiv1.VirtualServer) []string {
+	var violations []string
+	if _, err := strconv.ParseBool(vs.Annotations[ForwardingAnnotation]); err != nil {
+		violations = append(violations, fmt.Sprintf("%v annotation should be true or false", ForwardingAnnotation))
+	}
+	if _, err := parseForwardingType(vs.Annotations[ForwardingTypeAnnotation]); err != nil {
+		violations = append(violations, err.Error())
+	}
+	if value, ok := vs.Annotations[ForwardingMirroringAnnotation]; ok {
+		if _, err := strconv.ParseBool(value); err != nil {
+			violations = append(violations, fmt.Sprintf("%v annotation should be true or false",
+				ForwardingMirroringAnnotation))
+		}
+	}
+	if forwarding, _ := strconv.ParseBool(vs.Annotations[ForwardingAnnotation]); !forwarding {
+		return violations
+	}
+	if vs.Spec.PersistenceProfile != "" {
+		violations = append(violations, "persistenceProfile is not supported")
+	}
+	if vs.Spec.TLSProfileName != "" {
+		violations = append(violations, "tlsProfileName is not supported")
+	}
+	if vs.Spec.Profiles.HTTP2 != (cisapiv1.ProfileHTTP2{}) || vs.Spec.ProfileMultiplex != "" {
+		violations = append(violations, "HTTP profiles are not supported")
+	}
+	return violations
+}
+
+// getSNIRoutingVirtualServerViolations returns the invalid SNI routing settings of a VirtualServer, the TLS
+// connections are routed with the server name of the client hello only for the passthrough termination
+func (ctlr *Controller) getSNIRoutingVirtualServerViolations(vs *cisapiv1.VirtualServer) []string {
+	sniRouting, err := strconv.ParseBool(vs.Annotations[SNIRoutingAnnotation])
+	if err != nil {
+		return []string{fmt.Sprintf("%v annotation should be true or false", SNIRoutingAnnotation)}
+	}
+	if !sniRouting {
+		return nil
+	}
+	var violations []string
+	if vs.Spec.Host == "" {
+		violations = append(violations, "host is required")
+	}
+	if vs.Spec.TLSProfileName == "" {
+		return append(violations, "tlsProfileName with passthrough termination is required")
+	}
+	tlsProfile, err := ctlr.getTLSProfile(vs.Spec.TLSProfileName, vs.Namespace)
+	if err == nil && tlsProfile.Spec.TLS.Termination != TLSPassthrough {
+		violations = append(violations, fmt.Sprintf("TLSProfile %v termination should be %v", vs.Spec.TLSProfileName,
+			TLSPassthrough))
+	}
+	return violations
+}
+
 func (ctlr *Controller) checkValidTransportServer(
 	tsResource *cisapiv1.TransportServer,
 ) bool {
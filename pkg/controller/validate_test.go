@@ -1,14 +1,26 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"net"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v3/config/apis/cis/v1"
+	crdfake "github.com/F5Networks/k8s-bigip-ctlr/v3/config/client/clientset/versioned/fake"
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/clustermanager"
+	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/test"
 )
 
 var _ = Describe("Validation Tests", func() {
@@ -17,6 +29,371 @@ var _ = Describe("Validation Tests", func() {
 		mockCtlr = newMockController()
 	})
 
+	Describe("Validating UDP VirtualServer", func() {
+		It("Unsupported configurations are reported in status condition", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{Protocol: UDP})
+			Expect(getUDPVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Spec.TLSProfileName = "tls-profile"
+			vs.Spec.PersistenceProfile = "source-address"
+			vs.Spec.ProfileMultiplex = "/Common/oneconnect"
+			violations := getUDPVirtualServerViolations(vs)
+			Expect(violations).To(HaveLen(3))
+
+			mockCtlr.clientsets.KubeCRClient = crdfake.NewSimpleClientset(vs)
+			vsClient := mockCtlr.clientsets.KubeCRClient.CisV1().VirtualServers("default")
+			mockCtlr.updateVirtualServerValidCondition(vs, false, VSReasonUnsupportedInUDP, violations[0])
+			updated, err := vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			Expect(err).To(BeNil())
+			cond := meta.FindStatusCondition(updated.Status.Conditions, VSConditionValid)
+			Expect(cond).NotTo(BeNil(), "Valid condition not set")
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(VSReasonUnsupportedInUDP))
+
+			mockCtlr.updateVirtualServerValidCondition(updated, true, VSReasonValid, "")
+			updated, _ = vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, VSConditionValid)).To(BeTrue())
+		})
+	})
+
+	Describe("Validating SIP VirtualServer", func() {
+		It("Invalid SIP transport is reported", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{Protocol: SIP})
+			Expect(getSIPVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Spec.SIP = &cisapiv1.SIP{Transport: TCP, Secure: true, Port: 5061}
+			Expect(getSIPVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Spec.SIP.Transport = "sctp"
+			Expect(getSIPVirtualServerViolations(vs)).To(HaveLen(1))
+			vs.Spec.SIP.Transport = UDP
+			vs.Spec.ProfileMultiplex = "/Common/oneconnect"
+			// secure SIP over UDP and HTTP profiles
+			Expect(getSIPVirtualServerViolations(vs)).To(HaveLen(2))
+		})
+	})
+
+	Describe("Validating MQTT VirtualServer", func() {
+		It("Unsupported MQTT configurations are reported", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{Protocol: MQTT})
+			Expect(getMQTTVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Spec.TLSProfileName = "tls"
+			vs.Spec.ProfileMultiplex = "/Common/oneconnect"
+			// TLS and HTTP profiles
+			Expect(getMQTTVirtualServerViolations(vs)).To(HaveLen(2))
+		})
+	})
+
+	Describe("Validating SCTP VirtualServer", func() {
+		It("Invalid SCTP settings and HTTP conflicts are reported", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{Protocol: SCTP,
+				VirtualServerHTTPPort: 3868})
+			Expect(getSCTPVirtualServerViolations(vs)).To(BeEmpty())
+			Expect(getSCTPVirtualServerConflicts(vs)).To(BeEmpty())
+			vs.Spec.SCTP = &cisapiv1.SCTP{HeartbeatInterval: -1, MaxInitRetries: -1, CookieExpiry: 60}
+			Expect(getSCTPVirtualServerViolations(vs)).To(HaveLen(2))
+			vs.Spec.SCTP = &cisapiv1.SCTP{HeartbeatInterval: 30}
+			vs.Spec.Protocol = TCP
+			vs.Spec.VirtualServerHTTPPort = 0
+			// sctp settings on TCP without port
+			Expect(getSCTPVirtualServerViolations(vs)).To(HaveLen(2))
+			vs.Spec.TLSProfileName = "tls"
+			vs.Spec.WebSocketEnabled = true
+			vs.Spec.WAF = "/Common/WAF_Policy"
+			vs.Spec.RewriteAppRoot = "/home"
+			Expect(getSCTPVirtualServerConflicts(vs)).To(HaveLen(4))
+		})
+	})
+
+	Describe("Validating WebSocket VirtualServer", func() {
+		It("Invalid WebSocket settings and protocols are reported", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{WebSocketEnabled: true})
+			Expect(getWebSocketVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Spec.WebSocket = &cisapiv1.WebSocket{Masking: WebSocketMaskingSelective}
+			Expect(getWebSocketVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Spec.WebSocket = &cisapiv1.WebSocket{Masking: "mask"}
+			Expect(getWebSocketVirtualServerViolations(vs)).To(HaveLen(1))
+			vs.Spec.WebSocket = nil
+			vs.Spec.Protocol = UDP
+			Expect(getWebSocketVirtualServerViolations(vs)).To(HaveLen(1))
+		})
+	})
+
+	Describe("Validating tenant access of VirtualServer", func() {
+		It("VirtualServer is rejected without write access to its tenant", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{Partition: "dev"})
+			kubeClient := k8sfake.NewSimpleClientset()
+			reviews := 0
+			kubeClient.PrependReactor("create", "subjectaccessreviews",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					reviews++
+					review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+					attributes := review.Spec.ResourceAttributes
+					review.Status.Allowed = review.Spec.User == "system:serviceaccount:default:"+TenantServiceAccount &&
+						attributes.Group == TenantAPIGroup && attributes.Resource == TenantResource &&
+						attributes.Verb == TenantWriteVerb && attributes.Name == "prod"
+					return true, review, nil
+				})
+			mockCtlr.clientsets.KubeClient = kubeClient
+			mockCtlr.clientsets.KubeCRClient = crdfake.NewSimpleClientset(vs)
+
+			Expect(mockCtlr.checkVirtualServerTenantAccess(vs)).To(BeFalse(), "Unauthorized tenant should be rejected")
+			updated, _ := mockCtlr.clientsets.KubeCRClient.CisV1().VirtualServers("default").Get(context.TODO(), "vs1",
+				metav1.GetOptions{})
+			cond := meta.FindStatusCondition(updated.Status.Conditions, VSConditionValid)
+			Expect(cond).NotTo(BeNil(), "Valid condition not set")
+			Expect(cond.Reason).To(Equal(VSReasonTenantUnauthorized))
+			events, _ := kubeClient.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+			Expect(events.Items).To(HaveLen(1), "Rejection event not created")
+
+			// resync of the rejected VirtualServer
+			Expect(mockCtlr.checkVirtualServerTenantAccess(updated)).To(BeFalse())
+			events, _ = kubeClient.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+			Expect(events.Items).To(HaveLen(1), "Rejection event should not be repeated")
+
+			vs.Spec.Partition = "prod"
+			Expect(mockCtlr.checkVirtualServerTenantAccess(vs)).To(BeTrue(), "Authorized tenant should be accepted")
+			Expect(mockCtlr.checkVirtualServerTenantAccess(vs)).To(BeTrue())
+			Expect(reviews).To(Equal(2), "Access review results should be cached")
+		})
+	})
+
+	Describe("Validating cross namespace services of VirtualServer", func() {
+		It("Cross namespace services are parsed from the annotation", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{})
+			Expect(getCrossNamespaceServices(vs)).To(BeEmpty())
+			vs.Annotations = map[string]string{CrossNamespaceServiceAnnotation: "tea/svc-2, invalid,coffee/svc-3"}
+			Expect(getCrossNamespaceServices(vs)).To(Equal(map[string]string{"svc-2": "tea", "svc-3": "coffee"}))
+		})
+
+		It("NetworkPolicies are checked for the BIG-IP source IP", func() {
+			podLabels := map[string]string{"app": "tea"}
+			sourceIP := net.ParseIP("10.1.10.5")
+			Expect(isSourceAllowedByNetworkPolicies(nil, podLabels, sourceIP)).To(BeTrue(),
+				"Pods without NetworkPolicies should not be isolated")
+
+			denyAll := networkingv1.NetworkPolicy{Spec: networkingv1.NetworkPolicySpec{
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			}}
+			otherPods := networkingv1.NetworkPolicy{Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "coffee"}},
+				Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+			}}
+			Expect(isSourceAllowedByNetworkPolicies([]networkingv1.NetworkPolicy{denyAll, otherPods}, podLabels,
+				sourceIP)).To(BeFalse(), "Isolated pods should not be reachable")
+
+			allowBIGIP := networkingv1.NetworkPolicy{Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					From: []networkingv1.NetworkPolicyPeer{{
+						IPBlock: &networkingv1.IPBlock{CIDR: "10.1.10.0/24", Except: []string{"10.1.10.128/25"}},
+					}},
+				}},
+			}}
+			Expect(isSourceAllowedByNetworkPolicies([]networkingv1.NetworkPolicy{denyAll, allowBIGIP}, podLabels,
+				sourceIP)).To(BeTrue(), "Source IP in allowed IP block should be reachable")
+			Expect(isSourceAllowedByNetworkPolicies([]networkingv1.NetworkPolicy{denyAll, allowBIGIP}, podLabels,
+				net.ParseIP("10.1.10.200"))).To(BeFalse(), "Source IP in excluded IP block should not be reachable")
+		})
+
+		It("Denied cross namespace services are reported once", func() {
+			mockCtlr.bigipSourceIP = "10.1.10.5"
+			mockCtlr.managedResources.ManageCustomResources = true
+			mockCtlr.clientsets.KubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.clientsets.KubeCRClient = crdfake.NewSimpleClientset()
+			mockCtlr.crInformers = make(map[string]*CRInformer)
+			mockCtlr.comInformers = make(map[string]*CommonInformer)
+			_ = mockCtlr.addNamespacedInformers("default", false)
+			_ = mockCtlr.addNamespacedInformers("tea", false)
+			comInf, _ := mockCtlr.getNamespacedCommonInformer("tea")
+			Expect(comInf.npInformer).NotTo(BeNil(), "NetworkPolicy informer not created for the BIG-IP source IP")
+			svc := test.NewService("svc-2", "1", "tea", v1.ServiceTypeClusterIP, nil)
+			svc.Spec.Selector = map[string]string{"app": "tea"}
+			_ = comInf.svcInformer.GetIndexer().Add(svc)
+			denyAll := &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "deny-all", Namespace: "tea"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+				},
+			}
+			_ = comInf.npInformer.GetIndexer().Add(denyAll)
+
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{
+				Pools: []cisapiv1.VSPool{{Service: "svc-2", ServicePort: intstr.IntOrString{IntVal: 80}}},
+			})
+			vs.Annotations = map[string]string{CrossNamespaceServiceAnnotation: "tea/svc-2"}
+			crInf, _ := mockCtlr.getNamespacedCRInformer("default")
+			_ = crInf.vsInformer.GetIndexer().Add(vs)
+			Expect(mockCtlr.getCrossNamespaceVirtualServers("tea")).To(Equal([]*cisapiv1.VirtualServer{vs}),
+				"VirtualServer of the cross namespace service not found")
+			Expect(mockCtlr.getCrossNamespaceVirtualServers("default")).To(BeEmpty())
+
+			Expect(mockCtlr.checkCrossNamespaceService(vs, "tea", "svc-2")).To(BeFalse())
+			Expect(mockCtlr.checkCrossNamespaceService(vs, "tea", "svc-2")).To(BeFalse())
+			events, _ := mockCtlr.clientsets.KubeClient.CoreV1().Events("default").List(context.TODO(),
+				metav1.ListOptions{})
+			Expect(events.Items).To(HaveLen(1), "Denied service should be reported once")
+
+			// the warning is cleared once a policy allows the traffic, and reported again when it's removed
+			allowAll := &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "allow-all", Namespace: "tea"},
+				Spec: networkingv1.NetworkPolicySpec{
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{}},
+				},
+			}
+			_ = comInf.npInformer.GetIndexer().Add(allowAll)
+			Expect(mockCtlr.checkCrossNamespaceService(vs, "tea", "svc-2")).To(BeTrue())
+			Expect(mockCtlr.vsWarnings.messages).To(BeEmpty(), "Warning not cleared for the allowed service")
+			_ = comInf.npInformer.GetIndexer().Delete(allowAll)
+			Expect(mockCtlr.checkCrossNamespaceService(vs, "tea", "svc-2")).To(BeFalse())
+			Expect(mockCtlr.vsWarnings.messages).To(HaveKey(virtualServerWarningKey{namespace: "default",
+				name: "vs1", reason: CrossNamespaceServiceDeniedEvent, object: "tea/svc-2"}),
+				"Denied service should be reported again")
+		})
+	})
+
+	Describe("Validating DoS profile of VirtualServer", func() {
+		It("DoS profile is reported in status condition", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{Host: "test.com"})
+			vs.Annotations = map[string]string{DOSProfileAnnotation: "/Common/dos"}
+			mockCtlr.clientsets.KubeCRClient = crdfake.NewSimpleClientset(vs)
+			mockCtlr.clientsets.KubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.managedResources.ManageCustomResources = true
+			mockCtlr.resourceSelectorConfig.customResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			mockCtlr.resourceSelectorConfig.nativeResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			mockCtlr.crInformers = make(map[string]*CRInformer)
+			mockCtlr.comInformers = make(map[string]*CommonInformer)
+			_ = mockCtlr.addNamespacedInformers("default", false)
+			mockCtlr.addVirtualServer(vs)
+			bigIpKey := cisapiv1.BigIpConfig{BigIpAddress: "10.8.3.11", BigIpLabel: "bigip1"}
+			mockCtlr.RequestHandler = newMockAgent("")
+			mockCtlr.RequestHandler.PostManagers.PostManagerMap[bigIpKey] = &PostManager{
+				AS3PostManager: &AS3PostManager{afmUnlicensed: true},
+			}
+			vsClient := mockCtlr.clientsets.KubeCRClient.CisV1().VirtualServers("default")
+
+			mockCtlr.updateVirtualServerConditions("default/vs1", bigIpKey)
+			updated, err := vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			Expect(err).To(BeNil())
+			cond := meta.FindStatusCondition(updated.Status.Conditions, VSConditionDOSEnabled)
+			Expect(cond).NotTo(BeNil(), "DOSEnabled condition not set")
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(VSReasonDOSAFMUnavailable))
+
+			mockCtlr.RequestHandler.PostManagers.PostManagerMap[bigIpKey].AS3PostManager.afmUnlicensed = false
+			mockCtlr.updateVirtualServerConditions("default/vs1", bigIpKey)
+			updated, _ = vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, VSConditionDOSEnabled)).To(BeTrue())
+		})
+	})
+
+	Describe("Validating SNAT of VirtualServer", func() {
+		It("SNATPool is validated and SNAT is reported in status condition", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{
+				Host:                 "test.com",
+				VirtualServerAddress: "10.8.3.11",
+				SNAT:                 "web-snat",
+			})
+			mockCtlr.clientsets.KubeCRClient = crdfake.NewSimpleClientset(vs)
+			mockCtlr.clientsets.KubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.managedResources.ManageCustomResources = true
+			mockCtlr.resourceSelectorConfig.customResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			mockCtlr.resourceSelectorConfig.nativeResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			mockCtlr.crInformers = make(map[string]*CRInformer)
+			mockCtlr.comInformers = make(map[string]*CommonInformer)
+			_ = mockCtlr.addNamespacedInformers("default", false)
+			mockCtlr.addVirtualServer(vs)
+			vsClient := mockCtlr.clientsets.KubeCRClient.CisV1().VirtualServers("default")
+
+			Expect(mockCtlr.checkValidVirtualServer(vs)).To(BeFalse(), "VirtualServer with missing SNATPool is valid")
+			updated, _ := vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			cond := meta.FindStatusCondition(updated.Status.Conditions, VSConditionValid)
+			Expect(cond).NotTo(BeNil(), "Valid condition not set")
+			Expect(cond.Reason).To(Equal(VSReasonInvalidSNAT))
+
+			snatPool := &cisapiv1.SNATPool{}
+			snatPool.Name = "web-snat"
+			snatPool.Namespace = "default"
+			snatPool.Spec.Addresses = []string{"10.192.75.100"}
+			_ = mockCtlr.comInformers["default"].snatInformer.GetIndexer().Add(snatPool)
+			Expect(mockCtlr.checkValidVirtualServer(vs)).To(BeTrue(), "VirtualServer with SNATPool is invalid")
+
+			mockCtlr.updateVirtualServerConditions("default/vs1", cisapiv1.BigIpConfig{})
+			updated, _ = vsClient.Get(context.TODO(), "vs1", metav1.GetOptions{})
+			cond = meta.FindStatusCondition(updated.Status.Conditions, VSConditionSNATConfigured)
+			Expect(cond).NotTo(BeNil(), "SNATConfigured condition not set")
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(VSReasonSNATApplied))
+		})
+	})
+
+	Describe("Validating other IP protocol VirtualServer", func() {
+		It("Invalid protocol settings and unsupported configurations are reported", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{})
+			vs.Annotations = map[string]string{ProtocolAnnotation: ProtocolOther, IPProtocolNumberAnnotation: "47"}
+			Expect(getIPOtherVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Annotations[IPProtocolNumberAnnotation] = "6"
+			vs.Spec.TLSProfileName = "tls-profile"
+			Expect(getIPOtherVirtualServerViolations(vs)).To(HaveLen(2))
+			vs.Annotations = map[string]string{ProtocolAnnotation: TCP}
+			vs.Spec.TLSProfileName = ""
+			vs.Spec.Protocol = TCP
+			// unsupported protocol value, conflicting protocol and missing protocol number
+			Expect(getIPOtherVirtualServerViolations(vs)).To(HaveLen(3))
+		})
+	})
+
+	Describe("Validating forwarding VirtualServer", func() {
+		It("Invalid forwarding settings and unsupported configurations are reported", func() {
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{})
+			vs.Annotations = map[string]string{ForwardingAnnotation: "true", ForwardingTypeAnnotation: ForwardingTypeL2,
+				ForwardingMirroringAnnotation: "false"}
+			Expect(getForwardingVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Spec.PersistenceProfile = "cookie"
+			vs.Spec.ProfileMultiplex = "/Common/oneconnect"
+			// persistence and HTTP profiles are not supported
+			Expect(getForwardingVirtualServerViolations(vs)).To(HaveLen(2))
+			vs.Annotations = map[string]string{ForwardingAnnotation: "yes", ForwardingTypeAnnotation: "l3",
+				ForwardingMirroringAnnotation: "on"}
+			// invalid annotation values, the configurations of a virtual not forwarding are not checked
+			Expect(getForwardingVirtualServerViolations(vs)).To(HaveLen(3))
+		})
+	})
+
+	Describe("Validating SNI routing VirtualServer", func() {
+		It("SNI routing is only allowed with passthrough termination", func() {
+			mockCtlr.clientsets.KubeCRClient = crdfake.NewSimpleClientset()
+			mockCtlr.clientsets.KubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.managedResources.ManageCustomResources = true
+			mockCtlr.crInformers = make(map[string]*CRInformer)
+			mockCtlr.comInformers = make(map[string]*CommonInformer)
+			mockCtlr.resourceSelectorConfig.customResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			mockCtlr.resourceSelectorConfig.nativeResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			_ = mockCtlr.addNamespacedInformers("default", false)
+			tlsProfile := test.NewTLSProfile("passthrough-tls", "default", cisapiv1.TLSProfileSpec{
+				TLS: cisapiv1.TLS{Termination: TLSPassthrough},
+			})
+			mockCtlr.addTLSProfile(tlsProfile)
+			mockCtlr.addTLSProfile(test.NewTLSProfile("edge-tls", "default", cisapiv1.TLSProfileSpec{
+				TLS: cisapiv1.TLS{Termination: TLSEdge},
+			}))
+
+			vs := test.NewVirtualServer("vs1", "default", cisapiv1.VirtualServerSpec{
+				Host:           "foo.com",
+				TLSProfileName: "passthrough-tls",
+			})
+			vs.Annotations = map[string]string{SNIRoutingAnnotation: "true"}
+			Expect(mockCtlr.getSNIRoutingVirtualServerViolations(vs)).To(BeEmpty())
+			vs.Spec.TLSProfileName = "edge-tls"
+			Expect(mockCtlr.getSNIRoutingVirtualServerViolations(vs)).To(HaveLen(1),
+				"SNI routing with edge termination should be rejected")
+			vs.Spec.TLSProfileName = ""
+			vs.Spec.Host = ""
+			Expect(mockCtlr.getSNIRoutingVirtualServerViolations(vs)).To(HaveLen(2))
+			vs.Annotations[SNIRoutingAnnotation] = "yes"
+			Expect(mockCtlr.getSNIRoutingVirtualServerViolations(vs)).To(HaveLen(1))
+			vs.Annotations[SNIRoutingAnnotation] = "false"
+			Expect(mockCtlr.getSNIRoutingVirtualServerViolations(vs)).To(BeEmpty())
+		})
+	})
+
 	Describe("Validating ExtendedServiceReference", func() {
 		BeforeEach(func() {
 			mockCtlr.multiClusterMode = PrimaryCIS
@@ -32,6 +32,7 @@ import (
 	"reflect"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,9 +43,14 @@ import (
 	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
 	routeapi "github.com/openshift/api/route/v1"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 )
 
 // nextGenResourceWorker starts the Custom Resource Worker.
@@ -134,6 +140,12 @@ func (ctlr *Controller) setInitialResourceCount() {
 		if !found {
 			continue
 		}
+		if comInf.ingressInformer != nil {
+			ingresses, err := comInf.ingressInformer.GetIndexer().ByIndex("namespace", ns)
+			if err == nil {
+				rscCount += len(ingresses)
+			}
+		}
 		services, err := comInf.svcInformer.GetIndexer().ByIndex("namespace", ns)
 		if err != nil {
 			continue
@@ -178,9 +190,10 @@ func (ctlr *Controller) processResources() bool {
 	rKey := key.(*rqKey)
 	log.Debugf("Processing Key: %v", rKey)
 	// During Init time, just process all the resources
-	if ctlr.initState && rKey.kind != Namespace {
+	// the packet filters are not part of the resources, so they are processed as well
+	if ctlr.initState && rKey.kind != Namespace && rKey.kind != PacketFilter {
 		if rKey.kind == VirtualServer || rKey.kind == TransportServer || rKey.kind == Service ||
-			rKey.kind == IngressLink || rKey.kind == Route || rKey.kind == ExternalDNS {
+			rKey.kind == IngressLink || rKey.kind == Route || rKey.kind == ExternalDNS || rKey.kind == Ingress {
 			if rKey.kind == Service {
 				//if svc, ok := rKey.rsc.(*v1.Service); ok {
 				//	if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
@@ -282,6 +295,9 @@ func (ctlr *Controller) processResources() bool {
 			// update the poolMem cache, clusterSvcResource & resource-svc maps
 			ctlr.deleteResourceExternalClusterSvcRouteReference(rscRefKey)
 		}
+		if rscDelete {
+			ctlr.forgetVirtualServerWarnings(virtual)
+		}
 
 		err := ctlr.processVirtualServers(virtual, rscDelete)
 		if err != nil {
@@ -297,6 +313,8 @@ func (ctlr *Controller) processResources() bool {
 			break
 		}
 		tlsProfile := rKey.rsc.(*cisapiv1.TLSProfile)
+		// the VirtualServers waiting for the TLSProfile are enqueued once its Valid condition becomes true
+		ctlr.updateTLSProfileValidCondition(tlsProfile, validateTLSProfile(tlsProfile))
 		virtuals := ctlr.getVirtualsForTLSProfile(tlsProfile)
 		// No Virtuals are effected with the change in TLSProfile.
 		if nil == virtuals {
@@ -321,6 +339,17 @@ func (ctlr *Controller) processResources() bool {
 			}
 			break
 		}
+		// Re-sync the pools of the services whose monitor reads the password from the Secret
+		for _, svc := range ctlr.getServicesForMonitorSecret(secret) {
+			ctlr.resourceQueue.Add(&rqKey{
+				namespace:      svc.Namespace,
+				kind:           Service,
+				rscName:        svc.Name,
+				rsc:            svc,
+				event:          Update,
+				svcPortUpdated: true,
+			})
+		}
 		if ctlr.managedResources.ManageRoutes {
 			routeGroup := ctlr.getRouteGroupForSecret(secret)
 			if routeGroup != "" {
@@ -411,6 +440,75 @@ func (ctlr *Controller) processResources() bool {
 		ipam := rKey.rsc.(*ficV1.IPAM)
 		ctlr.processIPAM(ipam)
 
+	case AddressList:
+		if !ctlr.managedResources.ManageCustomResources {
+			break
+		}
+		adl := rKey.rsc.(*cisapiv1.AddressList)
+		virtuals := ctlr.getVirtualsForAddressList(adl)
+		for _, virtual := range virtuals {
+			err := ctlr.processVirtualServers(virtual, false)
+			if err != nil {
+				// TODO
+				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				isRetryableError = true
+			}
+		}
+
+	case PortList:
+		if !ctlr.managedResources.ManageCustomResources {
+			break
+		}
+		prt := rKey.rsc.(*cisapiv1.PortList)
+		virtuals := ctlr.getVirtualsForPortList(prt)
+		for _, virtual := range virtuals {
+			err := ctlr.processVirtualServers(virtual, false)
+			if err != nil {
+				// TODO
+				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				isRetryableError = true
+			}
+		}
+
+	case NATPolicy:
+		if !ctlr.managedResources.ManageCustomResources {
+			break
+		}
+		nat := rKey.rsc.(*cisapiv1.NATPolicy)
+		virtuals := ctlr.getVirtualsForNATPolicy(nat)
+		for _, virtual := range virtuals {
+			err := ctlr.processVirtualServers(virtual, false)
+			if err != nil {
+				// TODO
+				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				isRetryableError = true
+			}
+		}
+
+	case PacketFilter:
+		if !ctlr.managedResources.ManageCustomResources {
+			break
+		}
+		if err := ctlr.processPacketFilters(); err != nil {
+			utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+			isRetryableError = true
+		}
+
+	case SNATPool:
+		if !ctlr.managedResources.ManageCustomResources {
+			break
+		}
+		snat := rKey.rsc.(*cisapiv1.SNATPool)
+		virtuals := ctlr.getVirtualsForSNATPool(snat)
+		for _, virtual := range virtuals {
+			err := ctlr.processVirtualServers(virtual, false)
+			if err != nil {
+				// TODO
+				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				isRetryableError = true
+			}
+		}
+
 	case CustomPolicy:
 		cp := rKey.rsc.(*cisapiv1.Policy)
 		if ctlr.managedResources.ManageRoutes {
@@ -519,6 +617,66 @@ func (ctlr *Controller) processResources() bool {
 		// Just update the endpoints instead of processing them entirely
 		ctlr.updatePoolMembersForService(svcKey, false)
 
+	case Ingress:
+		ing := rKey.rsc.(*networkingv1.Ingress)
+		if rKey.event != Create {
+			// update the poolMem cache, clusterSvcResource & resource-svc maps
+			ctlr.deleteResourceExternalClusterSvcRouteReference(resourceRef{
+				kind:      Ingress,
+				name:      ing.Name,
+				namespace: ing.Namespace,
+			})
+		}
+		err := ctlr.processIngress(ing, rscDelete)
+		if err != nil {
+			utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+			isRetryableError = true
+		}
+
+	case ConfigMap:
+		cm := rKey.rsc.(*v1.ConfigMap)
+		// Re-sync the pools of the services monitored with the script of the ConfigMap
+		for _, svc := range ctlr.getServicesForExternalMonitor(cm) {
+			ctlr.resourceQueue.Add(&rqKey{
+				namespace:      svc.Namespace,
+				kind:           Service,
+				rscName:        svc.Name,
+				rsc:            svc,
+				event:          Update,
+				svcPortUpdated: true,
+			})
+		}
+		// Re-sync the VirtualServers referencing the library monitors of the ConfigMap
+		for _, virtual := range ctlr.getVirtualServersForMonitorLibrary(cm) {
+			ctlr.resyncVirtualServer(virtual)
+		}
+		// Re-sync the VirtualServers creating the iFiles of the ConfigMap
+		if ctlr.managedResources.ManageCustomResources {
+			for _, virtual := range ctlr.getVirtualServersForIFileConfigMap(cm) {
+				ctlr.resyncVirtualServer(virtual)
+			}
+		}
+
+	case NetworkPolicy:
+		if !ctlr.managedResources.ManageCustomResources {
+			break
+		}
+		np := rKey.rsc.(*networkingv1.NetworkPolicy)
+		// The ipBlocks of the policy apply to the pods of its namespace, re-sync the virtuals of the namespace
+		for _, virtual := range ctlr.getAllVirtualServers(np.Namespace) {
+			err := ctlr.processVirtualServers(virtual, false)
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				isRetryableError = true
+			}
+		}
+		// The pools of the other namespaces are checked against the policies of the service namespace
+		if ctlr.bigipSourceIP != "" {
+			for _, virtual := range ctlr.getCrossNamespaceVirtualServers(np.Namespace) {
+				ctlr.resyncVirtualServer(virtual)
+			}
+		}
+
 	case Pod:
 		pod := rKey.rsc.(*v1.Pod)
 		_ = ctlr.processPod(pod, rscDelete)
@@ -666,18 +824,8 @@ func (ctlr *Controller) processResources() bool {
 		}
 		// set prometheus resource metrics
 		ctlr.setPrometheusResourceCount()
-		// Put each BIGIPConfig per bigip  pair into specific requestChannel
-		for bigip, bigipConfig := range ctlr.resources.bigIpMap {
-			if (!reflect.DeepEqual(bigipConfig.ltmConfig, LTMConfig{}) || !reflect.DeepEqual(bigipConfig.gtmConfig, GTMConfig{})) && ctlr.resources.isConfigUpdated(bigip) {
-				config := ResourceConfigRequest{
-					bigIpConfig:         bigip,
-					bigIpResourceConfig: bigipConfig,
-					poolMemberType:      ctlr.PoolMemberType,
-				}
-				config.reqMeta = ctlr.enqueueReq(bigipConfig, bigip)
-				ctlr.RequestHandler.EnqueueRequestConfig(config)
-			}
-		}
+		// Put the config of every BIG-IP device into specific requestChannel
+		ctlr.enqueueDeviceConfigs()
 		ctlr.initState = false
 		ctlr.resources.updateCaches()
 
@@ -762,6 +910,115 @@ func (ctlr *Controller) getVirtualsForCustomPolicy(plc *cisapiv1.Policy) []*cisa
 	return plcVSs
 }
 
+// getVirtualsForAddressList gets all VirtualServers referring the AddressList via annotations
+func (ctlr *Controller) getVirtualsForAddressList(adl *cisapiv1.AddressList) []*cisapiv1.VirtualServer {
+	nsVirtuals := ctlr.getAllVirtualServers(adl.Namespace)
+	if nil == nsVirtuals {
+		log.Infof("No VirtualServers found in namespace %s",
+			adl.Namespace)
+		return nil
+	}
+
+	var adlVSs []*cisapiv1.VirtualServer
+	var adlVSNames []string
+	for _, vs := range nsVirtuals {
+		for _, annotation := range []string{AllowAddressListAnnotation, DenyAddressListAnnotation} {
+			if names, found := vs.Annotations[annotation]; found && containsListName(names, adl.Name) {
+				adlVSs = append(adlVSs, vs)
+				adlVSNames = append(adlVSNames, vs.Name)
+				break
+			}
+		}
+	}
+
+	log.Debugf("VirtualServers %v are affected with AddressList %s: ",
+		adlVSNames, adl.Name)
+
+	return adlVSs
+}
+
+// getVirtualsForPortList gets all VirtualServers referring the PortList via annotation
+func (ctlr *Controller) getVirtualsForPortList(prt *cisapiv1.PortList) []*cisapiv1.VirtualServer {
+	nsVirtuals := ctlr.getAllVirtualServers(prt.Namespace)
+	if nil == nsVirtuals {
+		log.Infof("No VirtualServers found in namespace %s",
+			prt.Namespace)
+		return nil
+	}
+
+	var prtVSs []*cisapiv1.VirtualServer
+	var prtVSNames []string
+	for _, vs := range nsVirtuals {
+		if names, found := vs.Annotations[AllowPortListAnnotation]; found && containsListName(names, prt.Name) {
+			prtVSs = append(prtVSs, vs)
+			prtVSNames = append(prtVSNames, vs.Name)
+		}
+	}
+
+	log.Debugf("VirtualServers %v are affected with PortList %s: ",
+		prtVSNames, prt.Name)
+
+	return prtVSs
+}
+
+// getVirtualsForNATPolicy gets all VirtualServers referring the NATPolicy via annotation
+func (ctlr *Controller) getVirtualsForNATPolicy(nat *cisapiv1.NATPolicy) []*cisapiv1.VirtualServer {
+	nsVirtuals := ctlr.getAllVirtualServers(nat.Namespace)
+	if nil == nsVirtuals {
+		log.Infof("No VirtualServers found in namespace %s",
+			nat.Namespace)
+		return nil
+	}
+
+	var natVSs []*cisapiv1.VirtualServer
+	var natVSNames []string
+	for _, vs := range nsVirtuals {
+		if vs.Annotations[NATPolicyAnnotation] == nat.Name {
+			natVSs = append(natVSs, vs)
+			natVSNames = append(natVSNames, vs.Name)
+		}
+	}
+
+	log.Debugf("VirtualServers %v are affected with NATPolicy %s: ",
+		natVSNames, nat.Name)
+
+	return natVSs
+}
+
+// getVirtualsForSNATPool gets all VirtualServers referring the SNATPool via snat
+func (ctlr *Controller) getVirtualsForSNATPool(snat *cisapiv1.SNATPool) []*cisapiv1.VirtualServer {
+	nsVirtuals := ctlr.getAllVirtualServers(snat.Namespace)
+	if nil == nsVirtuals {
+		log.Infof("No VirtualServers found in namespace %s",
+			snat.Namespace)
+		return nil
+	}
+
+	var snatVSs []*cisapiv1.VirtualServer
+	var snatVSNames []string
+	for _, vs := range nsVirtuals {
+		if vs.Spec.SNAT == snat.Name {
+			snatVSs = append(snatVSs, vs)
+			snatVSNames = append(snatVSNames, vs.Name)
+		}
+	}
+
+	log.Debugf("VirtualServers %v are affected with SNATPool %s: ",
+		snatVSNames, snat.Name)
+
+	return snatVSs
+}
+
+// containsListName checks whether the comma separated annotation value refers the given address or port list
+func containsListName(names, name string) bool {
+	for _, n := range strings.Split(names, ",") {
+		if strings.TrimSpace(n) == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (ctlr *Controller) getTransportServersForCustomPolicy(plc *cisapiv1.Policy) []*cisapiv1.TransportServer {
 	nsVirtuals := ctlr.getAllTransportServers(plc.Namespace)
 	if nil == nsVirtuals {
@@ -918,17 +1175,27 @@ func (ctlr *Controller) getTLSProfileForVirtualServer(vs *cisapiv1.VirtualServer
 		log.Errorf("Common Informer not found for namespace: %v", namespace)
 		return nil
 	}
+	// The VirtualServer waits for the TLSProfile to become valid and is enqueued again once it is valid
+	tlsRef := resourceRef{kind: TLSProfile, namespace: namespace, name: tlsName}
+	vsRef := resourceRef{kind: VirtualServer, namespace: namespace, name: vs.Name}
 	// TODO: Create Internal Structure to hold TLSProfiles. Make API call only for a new TLSProfile
 	// Check if the TLSProfile exists and valid for us.
 	tlsProfile, err := ctlr.getTLSProfile(tlsName, namespace)
 	if err != nil {
 		log.Errorf("Error fetching TLSProfile %s: %v", tlsName, err)
+		ctlr.resourceDependencies.addDependency(tlsRef, vsRef)
 		return nil
 	}
 
-	// validate TLSProfile
+	// validate TLSProfile, the status condition is not set yet for the TLSProfiles not processed by CIS
+	if isTLSProfileInvalid(tlsProfile) {
+		log.Warningf("TLSProfile %s is not valid, waiting to process VirtualServer %s", tlsName, vsKey)
+		ctlr.resourceDependencies.addDependency(tlsRef, vsRef)
+		return nil
+	}
 	validation := validateTLSProfile(tlsProfile)
 	if validation == false {
+		ctlr.resourceDependencies.addDependency(tlsRef, vsRef)
 		return nil
 	}
 
@@ -1067,6 +1334,7 @@ func (ctlr *Controller) processVirtualServers(
 
 	var ip string
 	var status int
+	ipSource := VSAddressSourceSpec
 	partition := ctlr.getCRPartition(virtual.Spec.Partition)
 	if ctlr.ipamHandler != nil {
 		resRef := ipmanager.ResourceRef{
@@ -1089,6 +1357,7 @@ func (ctlr *Controller) processVirtualServers(
 			ip = virtual.Spec.VirtualServerAddress
 		} else {
 			ipamLabel := getIPAMLabel(virtuals)
+			ipSource = VSAddressSourceIPAM
 			if virtual.Spec.HostGroup != "" {
 				//hg is unique across namepsaces
 				key := virtual.Spec.HostGroup + "_hg"
@@ -1114,10 +1383,36 @@ func (ctlr *Controller) processVirtualServers(
 		}
 	} else {
 		if virtual.Spec.HostGroup == "" {
-			if virtual.Spec.VirtualServerAddress == "" {
-				return fmt.Errorf("No VirtualServer address or IPAM found.")
+			// In cluster mode prefer the address already assigned to a LoadBalancer service of the pools
+			if ctlr.PoolMemberType == Cluster {
+				if ip = ctlr.getLBServiceIngressIP(virtual); ip != "" {
+					ipSource = VSAddressSourceLBStatus
+				}
+			}
+			// the address allocated before the virtual switched to an explicit address is returned to the pool
+			ctlr.releaseExplicitVirtualAddress(virtual, virtuals)
+			if ip == "" && virtual.Spec.VirtualServerAddress == "" && ctlr.vipPool != nil {
+				// virtuals with the same host share the address allocated from the pool
+				key := getVirtualAddressPoolKey(virtual)
+				if isVSDeleted && len(virtuals) == 0 {
+					ip = ctlr.vipPool.release(key)
+				} else {
+					var err error
+					if ip, err = ctlr.vipPool.allocate(key); err != nil {
+						return err
+					}
+				}
+				if ip == "" {
+					return nil
+				}
+				ipSource = VSAddressSourcePool
+			}
+			if ip == "" {
+				if virtual.Spec.VirtualServerAddress == "" {
+					return fmt.Errorf("No VirtualServer address or IPAM found.")
+				}
+				ip = virtual.Spec.VirtualServerAddress
 			}
-			ip = virtual.Spec.VirtualServerAddress
 		} else {
 			var err error
 			ip, err = getVirtualServerAddress(virtuals)
@@ -1221,6 +1516,7 @@ func (ctlr *Controller) processVirtualServers(
 		for _, vrt := range virtuals {
 			// Updating the virtual server IP Address status for all associated virtuals
 			vrt.Status.VSAddress = ip
+			vrt.Status.VSAddressSource = ipSource
 			passthroughVS := false
 			var tlsProf *cisapiv1.TLSProfile
 			var tlsTermination string
@@ -1311,11 +1607,92 @@ func (ctlr *Controller) processVirtualServers(
 		if len(hostnames) > 0 {
 			ctlr.ProcessAssociatedExternalDNS(hostnames)
 		}
+		if ctlr.bgpAdvertise {
+			ctlr.handleBGPAdvertiseService(virtual, ip, portStructs, isVSDeleted)
+		}
 	}
 
 	return nil
 }
 
+// handleBGPAdvertiseService creates, updates or deletes the LoadBalancer Service used to
+// advertise the VirtualServer address via BGP (MetalLB/Calico)
+func (ctlr *Controller) handleBGPAdvertiseService(
+	virtual *cisapiv1.VirtualServer,
+	ip string,
+	portStructs []portStruct,
+	isVSDeleted bool,
+) {
+	svcName := virtual.Name + BGPAdvertiseServiceSuffix
+	svcClient := ctlr.clientsets.KubeClient.CoreV1().Services(virtual.Namespace)
+	existing, err := svcClient.Get(context.TODO(), svcName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Errorf("Unable to fetch BGP advertise service %s/%s: %v", virtual.Namespace, svcName, err)
+		return
+	}
+	if err != nil {
+		existing = nil
+	}
+
+	if isVSDeleted || ip == "" || virtual.Annotations[BGPAdvertiseAnnotation] != "true" {
+		if existing == nil {
+			return
+		}
+		err = svcClient.Delete(context.TODO(), svcName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.Errorf("Unable to delete BGP advertise service %s/%s: %v", virtual.Namespace, svcName, err)
+			return
+		}
+		log.Debugf("Deleted BGP advertise service %s/%s", virtual.Namespace, svcName)
+		return
+	}
+
+	var ports []v1.ServicePort
+	for _, portS := range portStructs {
+		ports = append(ports, v1.ServicePort{
+			Name:     portS.protocol,
+			Protocol: v1.ProtocolTCP,
+			Port:     portS.port,
+		})
+	}
+
+	if existing != nil {
+		if existing.Spec.LoadBalancerIP == ip && reflect.DeepEqual(existing.Spec.Ports, ports) {
+			return
+		}
+		svc := existing.DeepCopy()
+		svc.Spec.LoadBalancerIP = ip
+		svc.Spec.Ports = ports
+		if _, err = svcClient.Update(context.TODO(), svc, metav1.UpdateOptions{}); err != nil {
+			log.Errorf("Unable to update BGP advertise service %s/%s: %v", virtual.Namespace, svcName, err)
+			return
+		}
+		log.Debugf("Updated BGP advertise service %s/%s with IP %s", virtual.Namespace, svcName, ip)
+		return
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svcName,
+			Namespace: virtual.Namespace,
+			Labels:    map[string]string{BGPAdvertiseLabel: virtual.Name},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(virtual, cisapiv1.SchemeGroupVersion.WithKind(VirtualServer)),
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Type:           v1.ServiceTypeLoadBalancer,
+			LoadBalancerIP: ip,
+			Ports:          ports,
+		},
+	}
+	if _, err = svcClient.Create(context.TODO(), svc, metav1.CreateOptions{}); err != nil {
+		log.Errorf("Unable to create BGP advertise service %s/%s: %v", virtual.Namespace, svcName, err)
+		return
+	}
+	log.Debugf("Created BGP advertise service %s/%s with IP %s", virtual.Namespace, svcName, ip)
+}
+
 // getEffectiveHTTPPort returns the final HTTP port considered for virtual server
 func getEffectiveHTTPSPort(vrt *cisapiv1.VirtualServer) int32 {
 	effectiveHTTPSPort := DEFAULT_HTTPS_PORT
@@ -1662,6 +2039,82 @@ func (ctlr *Controller) getPolicy(ns string, plcName string) (*cisapiv1.Policy,
 	return obj.(*cisapiv1.Policy), nil
 }
 
+// getAddressList fetches the AddressList CR
+func (ctlr *Controller) getAddressList(ns string, name string) (*cisapiv1.AddressList, error) {
+	comInf, ok := ctlr.getNamespacedCommonInformer(ns)
+	if !ok || comInf.adlInformer == nil {
+		return nil, fmt.Errorf("Informer not found for namespace: %v", ns)
+	}
+	key := ns + "/" + name
+
+	obj, exist, err := comInf.adlInformer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching AddressList: %v: %v", key, err)
+	}
+
+	if !exist {
+		return nil, fmt.Errorf("AddressList Not Found: %v", key)
+	}
+	return obj.(*cisapiv1.AddressList), nil
+}
+
+// getPortList fetches the PortList CR
+func (ctlr *Controller) getPortList(ns string, name string) (*cisapiv1.PortList, error) {
+	comInf, ok := ctlr.getNamespacedCommonInformer(ns)
+	if !ok || comInf.prtInformer == nil {
+		return nil, fmt.Errorf("Informer not found for namespace: %v", ns)
+	}
+	key := ns + "/" + name
+
+	obj, exist, err := comInf.prtInformer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching PortList: %v: %v", key, err)
+	}
+
+	if !exist {
+		return nil, fmt.Errorf("PortList Not Found: %v", key)
+	}
+	return obj.(*cisapiv1.PortList), nil
+}
+
+// getSNATPool fetches the SNATPool CR
+func (ctlr *Controller) getSNATPool(ns string, name string) (*cisapiv1.SNATPool, error) {
+	comInf, ok := ctlr.getNamespacedCommonInformer(ns)
+	if !ok || comInf.snatInformer == nil {
+		return nil, fmt.Errorf("Informer not found for namespace: %v", ns)
+	}
+	key := ns + "/" + name
+
+	obj, exist, err := comInf.snatInformer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching SNATPool: %v: %v", key, err)
+	}
+
+	if !exist {
+		return nil, fmt.Errorf("SNATPool Not Found: %v", key)
+	}
+	return obj.(*cisapiv1.SNATPool), nil
+}
+
+// getNATPolicy fetches the NATPolicy CR
+func (ctlr *Controller) getNATPolicy(ns string, name string) (*cisapiv1.NATPolicy, error) {
+	comInf, ok := ctlr.getNamespacedCommonInformer(ns)
+	if !ok || comInf.natInformer == nil {
+		return nil, fmt.Errorf("Informer not found for namespace: %v", ns)
+	}
+	key := ns + "/" + name
+
+	obj, exist, err := comInf.natInformer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching NATPolicy: %v: %v", key, err)
+	}
+
+	if !exist {
+		return nil, fmt.Errorf("NATPolicy Not Found: %v", key)
+	}
+	return obj.(*cisapiv1.NATPolicy), nil
+}
+
 func getIPAMLabel(virtuals []*cisapiv1.VirtualServer) string {
 	for _, vrt := range virtuals {
 		if vrt.Spec.IPAMLabel != "" {
@@ -1735,10 +2188,12 @@ func (ctlr *Controller) updatePoolMembersForService(svcKey MultiClusterServiceKe
 									}
 								case VirtualServer:
 									var item interface{}
-									inf, _ := ctlr.getNamespacedCRInformer(poolId.rsKey.namespace)
-									item, _, _ = inf.vsInformer.GetIndexer().GetByKey(poolId.rsKey.namespace + "/" + poolId.rsKey.name)
+									inf, ok := ctlr.getNamespacedCRInformer(poolId.rsKey.namespace)
+									if ok && inf.vsInformer != nil {
+										item, _, _ = inf.vsInformer.GetIndexer().GetByKey(poolId.rsKey.namespace + "/" + poolId.rsKey.name)
+									}
 									if item == nil {
-										// This case won't arise
+										// the VirtualServer is deleted, its pools are removed with its delete event
 										continue
 									}
 									virtual, found := item.(*cisapiv1.VirtualServer)
@@ -1751,7 +2206,7 @@ func (ctlr *Controller) updatePoolMembersForService(svcKey MultiClusterServiceKe
 									inf, _ := ctlr.getNamespacedCRInformer(poolId.rsKey.namespace)
 									item, _, _ = inf.tsInformer.GetIndexer().GetByKey(poolId.rsKey.namespace + "/" + poolId.rsKey.name)
 									if item == nil {
-										// This case won't arise
+										// the TransportServer is deleted, its pools are removed with its delete event
 										continue
 									}
 									virtual, found := item.(*cisapiv1.TransportServer)
@@ -1764,7 +2219,7 @@ func (ctlr *Controller) updatePoolMembersForService(svcKey MultiClusterServiceKe
 									inf, _ := ctlr.getNamespacedCRInformer(poolId.rsKey.namespace)
 									item, _, _ = inf.ilInformer.GetIndexer().GetByKey(poolId.rsKey.namespace + "/" + poolId.rsKey.name)
 									if item == nil {
-										// This case won't arise
+										// the IngressLink is deleted, its pools are removed with its delete event
 										continue
 									}
 									il, found := item.(*cisapiv1.IngressLink)
@@ -1772,6 +2227,21 @@ func (ctlr *Controller) updatePoolMembersForService(svcKey MultiClusterServiceKe
 										_ = ctlr.processIngressLink(il, false)
 									}
 									return
+								case Ingress:
+									var item interface{}
+									comInf, ok := ctlr.getNamespacedCommonInformer(poolId.rsKey.namespace)
+									if ok && comInf.ingressInformer != nil {
+										item, _, _ = comInf.ingressInformer.GetIndexer().GetByKey(poolId.rsKey.namespace + "/" + poolId.rsKey.name)
+									}
+									if item == nil {
+										// the Ingress is deleted, its pools are removed with its delete event
+										continue
+									}
+									ing, found := item.(*networkingv1.Ingress)
+									if found {
+										_ = ctlr.processIngress(ing, false)
+									}
+									return
 								}
 							}
 							ctlr.updatePoolMembersForResources(&pool)
@@ -1791,6 +2261,33 @@ func (ctlr *Controller) updatePoolMembersForService(svcKey MultiClusterServiceKe
 	}
 }
 
+// getLBServiceIngressIP returns the ingress IP from the status of the first LoadBalancer
+// service referenced by the pools of the virtual, skipping services managed by CIS itself
+func (ctlr *Controller) getLBServiceIngressIP(virtual *cisapiv1.VirtualServer) string {
+	for _, pl := range virtual.Spec.Pools {
+		svcNamespace := virtual.Namespace
+		if pl.ServiceNamespace != "" {
+			svcNamespace = pl.ServiceNamespace
+		}
+		err, svc := ctlr.fetchService(MultiClusterServiceKey{serviceName: pl.Service, namespace: svcNamespace})
+		if err != nil || svc == nil || svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+			continue
+		}
+		if _, ok := svc.Annotations[LBServiceIPAnnotation]; ok {
+			continue
+		}
+		if _, ok := svc.Annotations[LBServiceIPAMLabelAnnotation]; ok {
+			continue
+		}
+		if len(svc.Status.LoadBalancer.Ingress) > 0 && svc.Status.LoadBalancer.Ingress[0].IP != "" {
+			log.Debugf("Using LoadBalancer status IP %v of service %v/%v for VirtualServer %v/%v",
+				svc.Status.LoadBalancer.Ingress[0].IP, svc.Namespace, svc.Name, virtual.Namespace, virtual.Name)
+			return svc.Status.LoadBalancer.Ingress[0].IP
+		}
+	}
+	return ""
+}
+
 func (ctlr *Controller) fetchService(svcKey MultiClusterServiceKey) (error, *v1.Service) {
 	var svc *v1.Service
 	if svcKey.clusterName == "" {
@@ -1826,14 +2323,51 @@ func (ctlr *Controller) fetchService(svcKey MultiClusterServiceKey) (error, *v1.
 	return nil, svc
 }
 
+// updatePoolSlowRampTime sets the slowRampTime of the pool using the service annotation
+func (ctlr *Controller) updatePoolSlowRampTime(pool *Pool) {
+	_, svc := ctlr.fetchService(MultiClusterServiceKey{
+		serviceName: pool.ServiceName,
+		namespace:   pool.ServiceNamespace,
+		clusterName: pool.Cluster,
+	})
+	if svc == nil {
+		return
+	}
+	if slowRampTime, ok := getSlowRampTime(svc); ok {
+		pool.SlowRampTime = slowRampTime
+	}
+}
+
+// getSlowRampTime returns the validated slowRampTime from the service annotation
+func getSlowRampTime(svc *v1.Service) (int32, bool) {
+	val, ok := svc.Annotations[SlowRampTimeAnnotation]
+	if !ok {
+		return 0, false
+	}
+	slowRampTime, err := strconv.Atoi(val)
+	if err != nil || slowRampTime < 0 || slowRampTime > MaxSlowRampTime {
+		log.Errorf("Invalid value %v for %v annotation in service %v/%v, it should be an integer between 0 and %v",
+			val, SlowRampTimeAnnotation, svc.Namespace, svc.Name, MaxSlowRampTime)
+		return 0, false
+	}
+	return int32(slowRampTime), true
+}
+
 // updatePoolMembersForResources updates the pool members for service present in the provided Pool
 func (ctlr *Controller) updatePoolMembersForResources(pool *Pool) {
 	var poolMembers []PoolMember
+	// update the slowRampTime from the service annotation
+	ctlr.updatePoolSlowRampTime(pool)
+	// order the pool members by topology once they are updated
+	defer ctlr.sortPoolMembersByTopology(pool)
 	// for local cluster
 	if pool.Cluster == "" {
-		poolMembers = append(poolMembers,
-			ctlr.fetchPoolMembersForService(pool.ServiceName, pool.ServiceNamespace, pool.ServicePort,
-				pool.NodeMemberLabel, "", pool.ConnectionLimit)...)
+		// skip the members of the cross namespace service denied by the NetworkPolicies
+		if !pool.NetworkPolicyDenied {
+			poolMembers = append(poolMembers,
+				ctlr.fetchPoolMembersForService(pool.ServiceName, pool.ServiceNamespace, pool.ServicePort,
+					pool.NodeMemberLabel, "", pool.ConnectionLimit, pool.PodSelector)...)
+		}
 		if len(ctlr.clusterRatio) > 0 {
 			pool.Members = poolMembers
 			return
@@ -1844,7 +2378,7 @@ func (ctlr *Controller) updatePoolMembersForResources(pool *Pool) {
 	if ctlr.haModeType == Active && ctlr.multiClusterConfigs.HAPairClusterName != "" {
 		poolMembers = append(poolMembers,
 			ctlr.fetchPoolMembersForService(pool.ServiceName, pool.ServiceNamespace, pool.ServicePort,
-				pool.NodeMemberLabel, ctlr.multiClusterConfigs.HAPairClusterName, pool.ConnectionLimit)...)
+				pool.NodeMemberLabel, ctlr.multiClusterConfigs.HAPairClusterName, pool.ConnectionLimit, pool.PodSelector)...)
 	}
 
 	// In case of ratio mode unique pools are created for each service so only update the pool members for this backend
@@ -1852,7 +2386,7 @@ func (ctlr *Controller) updatePoolMembersForResources(pool *Pool) {
 	if len(ctlr.clusterRatio) > 0 {
 		poolMembers = append(poolMembers,
 			ctlr.fetchPoolMembersForService(pool.ServiceName, pool.ServiceNamespace, pool.ServicePort,
-				pool.NodeMemberLabel, pool.Cluster, pool.ConnectionLimit)...)
+				pool.NodeMemberLabel, pool.Cluster, pool.ConnectionLimit, pool.PodSelector)...)
 		pool.Members = poolMembers
 		return
 	}
@@ -1870,7 +2404,7 @@ func (ctlr *Controller) updatePoolMembersForResources(pool *Pool) {
 		if _, ok := ctlr.multiClusterPoolInformers[mcs.ClusterName]; ok && ctlr.multiClusterConfigs.HAPairClusterName != mcs.ClusterName {
 			poolMembers = append(poolMembers,
 				ctlr.fetchPoolMembersForService(mcs.SvcName, mcs.Namespace, mcs.ServicePort,
-					pool.NodeMemberLabel, mcs.ClusterName, pool.ConnectionLimit)...)
+					pool.NodeMemberLabel, mcs.ClusterName, pool.ConnectionLimit, pool.PodSelector)...)
 		}
 	}
 	pool.Members = poolMembers
@@ -1878,7 +2412,7 @@ func (ctlr *Controller) updatePoolMembersForResources(pool *Pool) {
 
 // fetchPoolMembersForService returns pool members associated with a service created in specified cluster
 func (ctlr *Controller) fetchPoolMembersForService(serviceName string, serviceNamespace string,
-	servicePort intstr.IntOrString, nodeMemberLabel string, clusterName string, podConnections int32) []PoolMember {
+	servicePort intstr.IntOrString, nodeMemberLabel string, clusterName string, podConnections int32, podSelector string) []PoolMember {
 	svcKey := MultiClusterServiceKey{
 		serviceName: serviceName,
 		namespace:   serviceNamespace,
@@ -1905,6 +2439,9 @@ func (ctlr *Controller) fetchPoolMembersForService(serviceName string, serviceNa
 			}
 		}
 		poolMembers = append(poolMembers, ctlr.getPoolMembersForService(svcKey, servicePort, nodeMemberLabel)...)
+		if podSelector != "" {
+			poolMembers = ctlr.filterPoolMembersByPodSelector(poolMembers, svcKey, podSelector)
+		}
 	}
 	// Update the cluster admin state for pool members if multi cluster mode is enabled
 	ctlr.updatePoolMembersConfig(&poolMembers, clusterName, podConnections)
@@ -1912,6 +2449,87 @@ func (ctlr *Controller) fetchPoolMembersForService(serviceName string, serviceNa
 	return poolMembers
 }
 
+// filterPoolMembersByPodSelector returns the pool members backed by the pods of the service namespace
+// matching the pod selector
+func (ctlr *Controller) filterPoolMembersByPodSelector(poolMembers []PoolMember, svcKey MultiClusterServiceKey,
+	podSelector string) []PoolMember {
+	selector, err := labels.Parse(podSelector)
+	if err != nil {
+		log.Errorf("Invalid pod selector %v for service %v: %v", podSelector, svcKey, err)
+		return poolMembers
+	}
+	pods := ctlr.getPodsWithSelector(svcKey.namespace, svcKey.clusterName, selector)
+	// pod IPs are the pool members in cluster mode, the node IP and port of the pod are in nodeportlocal mode
+	selected := make(map[string]struct{})
+	for _, pod := range pods {
+		for _, podIP := range pod.Status.PodIPs {
+			selected[podIP.IP] = struct{}{}
+		}
+		for _, annotation := range ctlr.resources.nplStore[pod.Namespace+"/"+pod.Name] {
+			selected[fmt.Sprintf("%v:%v", annotation.NodeIP, annotation.NodePort)] = struct{}{}
+		}
+	}
+	var members []PoolMember
+	for _, member := range poolMembers {
+		if member.MemberType == NodePort {
+			log.Warningf("Pod selector %v is not supported for NodePort pool members of service %v", podSelector, svcKey)
+			return poolMembers
+		}
+		_, podIPFound := selected[member.Address]
+		_, nplFound := selected[fmt.Sprintf("%v:%v", member.Address, member.Port)]
+		if podIPFound || nplFound {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// getPodsWithSelector returns the pods of the namespace matching the selector in the given cluster, pods are
+// listed from the pod informer if it's running or else from the API server
+func (ctlr *Controller) getPodsWithSelector(namespace, clusterName string, selector labels.Selector) []*v1.Pod {
+	var podInformer cache.SharedIndexInformer
+	var kubeClient kubernetes.Interface
+	if clusterName == "" {
+		if comInf, ok := ctlr.getNamespacedCommonInformer(namespace); ok {
+			podInformer = comInf.podInformer
+		}
+		if ctlr.clientsets != nil {
+			kubeClient = ctlr.clientsets.KubeClient
+		}
+	} else {
+		if poolInf, ok := ctlr.multiClusterPoolInformers[clusterName][""]; ok {
+			podInformer = poolInf.podInformer
+		} else if poolInf, ok = ctlr.multiClusterPoolInformers[clusterName][namespace]; ok {
+			podInformer = poolInf.podInformer
+		}
+		if config, ok := ctlr.multiClusterConfigs.ClusterConfigs[clusterName]; ok {
+			kubeClient = config.KubeClient
+		}
+	}
+	if podInformer != nil {
+		pods, err := listerscorev1.NewPodLister(podInformer.GetIndexer()).Pods(namespace).List(selector)
+		if err != nil {
+			log.Debugf("Got error while listing Pods with selector %v: %v", selector, err)
+		}
+		return pods
+	}
+	if kubeClient == nil {
+		log.Errorf("Unable to list pods with selector %v in namespace %v %v", selector, namespace, getClusterLog(clusterName))
+		return nil
+	}
+	podList, err := kubeClient.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		log.Errorf("Unable to list pods with selector %v in namespace %v %v: %v", selector, namespace,
+			getClusterLog(clusterName), err)
+		return nil
+	}
+	var pods []*v1.Pod
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+	return pods
+}
+
 func (ctlr *Controller) getPoolMembersForEndpoints(mSvcKey MultiClusterServiceKey, servicePort intstr.IntOrString) []PoolMember {
 	var poolMembers []PoolMember
 	poolMemInfo, ok := ctlr.resources.poolMemCache[mSvcKey]
@@ -2008,11 +2626,16 @@ func (ctlr *Controller) getEndpointsForNodePort(
 	}
 	var members []PoolMember
 	for _, v := range nodes {
+		zone := v.Labels[ctlr.getTopologyKey()]
 		member := PoolMember{
 			MemberType: NodePort,
 			Address:    v.Addr,
 			Port:       nodePort,
 			Session:    "user-enabled",
+			Ratio:      ctlr.getZoneRatio(zone),
+		}
+		if ctlr.topologyConfig.Enabled {
+			member.Zone = zone
 		}
 		members = append(members, member)
 	}
@@ -2061,6 +2684,82 @@ func (ctlr *Controller) getEndpointsForNPL(
 	return members
 }
 
+// getTopologyKey returns the node label which holds the topology zone.
+func (ctlr *Controller) getTopologyKey() string {
+	if ctlr.topologyConfig.TopologyKey != "" {
+		return ctlr.topologyConfig.TopologyKey
+	}
+	return TopologyZoneLabel
+}
+
+// getEndpointHintZones returns the zone hinted for each endpoint address of the service
+// by the topology hints of its EndpointSlices. The BIG-IP zone is preferred when an
+// endpoint is hinted for several zones.
+func (ctlr *Controller) getEndpointHintZones(comInf *CommonInformer, svc *v1.Service) map[string]string {
+	if !ctlr.topologyConfig.Enabled || comInf.epSliceInformer == nil {
+		return nil
+	}
+	hintZones := make(map[string]string)
+	objs, _ := comInf.epSliceInformer.GetIndexer().ByIndex(cache.NamespaceIndex, svc.Namespace)
+	for _, obj := range objs {
+		slice := obj.(*discoveryv1.EndpointSlice)
+		if slice.Labels[discoveryv1.LabelServiceName] != svc.Name {
+			continue
+		}
+		for _, ep := range slice.Endpoints {
+			if ep.Hints == nil || len(ep.Hints.ForZones) == 0 {
+				continue
+			}
+			zone := ep.Hints.ForZones[0].Name
+			for _, forZone := range ep.Hints.ForZones {
+				if forZone.Name == ctlr.topologyConfig.BIGIPZone {
+					zone = forZone.Name
+				}
+			}
+			for _, addr := range ep.Addresses {
+				hintZones[addr] = zone
+			}
+		}
+	}
+	return hintZones
+}
+
+// sortPoolMembersByTopology places the members in the BIG-IP zone at the top of the pool
+// followed by the remaining members in the order of their ratio.
+func (ctlr *Controller) sortPoolMembersByTopology(pool *Pool) {
+	if !ctlr.topologyConfig.Enabled {
+		return
+	}
+	if !strings.HasPrefix(pool.Balance, "ratio-") {
+		log.Warningf("Pool %v uses load balancing method %q, the topology aware pool member ratios take effect only with a ratio method",
+			pool.Name, pool.Balance)
+	}
+	isLocal := func(member PoolMember) bool {
+		return member.Zone != "" && member.Zone == ctlr.topologyConfig.BIGIPZone
+	}
+	sort.SliceStable(pool.Members, func(i, j int) bool {
+		if isLocal(pool.Members[i]) != isLocal(pool.Members[j]) {
+			return isLocal(pool.Members[i])
+		}
+		return pool.Members[i].Ratio > pool.Members[j].Ratio
+	})
+}
+
+// getZoneRatio returns the pool member ratio for an endpoint in the given zone.
+// Returns 0 when topology aware pool weights are disabled.
+func (ctlr *Controller) getZoneRatio(zone string) int {
+	if !ctlr.topologyConfig.Enabled {
+		return 0
+	}
+	if ratio, ok := ctlr.topologyConfig.ZoneWeights[zone]; ok {
+		return ratio
+	}
+	if zone != "" && zone == ctlr.topologyConfig.BIGIPZone {
+		return DefaultLocalZoneRatio
+	}
+	return DefaultRemoteZoneRatio
+}
+
 // containsNode returns true for a valid node.
 func containsNode(nodes []Node, name string) bool {
 	for _, node := range nodes {
@@ -2490,12 +3189,14 @@ func (ctlr *Controller) processService(
 	pmi.svcType = svc.Spec.Type
 	nodes := ctlr.getNodesFromCache(svcKey.clusterName)
 	var eps *v1.Endpoints
+	var hintZones map[string]string
 	if clusterName == "" {
 		comInf, ok := ctlr.getNamespacedCommonInformer(namespace)
 		if !ok {
 			log.Errorf("Informer not found for namespace: %v %v", namespace, getClusterLog(clusterName))
 			return fmt.Errorf("unable to process Service: %v %v", svcKey, getClusterLog(clusterName))
 		}
+		hintZones = ctlr.getEndpointHintZones(comInf, svc)
 		if comInf.epsInformer != nil {
 			item, found, _ := comInf.epsInformer.GetIndexer().GetByKey(svc.Namespace + "/" + svc.Name)
 			if !found {
@@ -2543,6 +3244,10 @@ func (ctlr *Controller) processService(
 							Port:    p.Port,
 							Session: "user-enabled",
 						}
+						if ctlr.topologyConfig.Enabled {
+							member.Zone = hintZones[addr.IP]
+							member.Ratio = ctlr.getZoneRatio(member.Zone)
+						}
 						members = append(members, member)
 					}
 				}
@@ -3615,6 +4320,128 @@ func (ctlr *Controller) updateResourceStatus(rscType string, obj interface{}, ip
 	}
 }
 
+// updateVirtualServerValidCondition records the validation result of the VirtualServer as a status condition
+func (ctlr *Controller) updateVirtualServerValidCondition(vs *cisapiv1.VirtualServer, valid bool, reason, message string) {
+	status := metav1.ConditionTrue
+	if !valid {
+		status = metav1.ConditionFalse
+	}
+	cond := meta.FindStatusCondition(vs.Status.Conditions, VSConditionValid)
+	// skip the update when the condition is unchanged or a valid VirtualServer without warning never had a condition
+	if (cond == nil && valid && message == "") || (cond != nil && cond.Status == status && cond.Message == message) {
+		return
+	}
+	vsCopy := vs.DeepCopy()
+	meta.SetStatusCondition(&vsCopy.Status.Conditions, metav1.Condition{
+		Type:               VSConditionValid,
+		Status:             status,
+		ObservedGeneration: vs.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	_, updateErr := ctlr.clientsets.KubeCRClient.CisV1().VirtualServers(vs.Namespace).UpdateStatus(context.TODO(), vsCopy, metav1.UpdateOptions{})
+	if nil != updateErr {
+		log.Debugf("Error while updating VirtualServer status:%v", updateErr)
+	}
+}
+
+// recordVirtualServerWarning records a warning event of the VirtualServer about the referenced object unless the
+// same warning was recorded by an earlier sync, the warning is recorded again after clearVirtualServerWarning once
+// its cause is resolved
+func (ctlr *Controller) recordVirtualServerWarning(vs *cisapiv1.VirtualServer, reason, object, message string) {
+	key := virtualServerWarningKey{namespace: vs.Namespace, name: vs.Name, reason: reason, object: object}
+	ctlr.vsWarnings.Lock()
+	if ctlr.vsWarnings.messages == nil {
+		ctlr.vsWarnings.messages = make(map[virtualServerWarningKey]string)
+	}
+	if last, ok := ctlr.vsWarnings.messages[key]; ok && last == message {
+		ctlr.vsWarnings.Unlock()
+		return
+	}
+	ctlr.vsWarnings.messages[key] = message
+	ctlr.vsWarnings.Unlock()
+	ctlr.recordVirtualServerEvent(vs, v1.EventTypeWarning, reason, message)
+}
+
+// clearVirtualServerWarning forgets the warning of the VirtualServer once its cause is resolved
+func (ctlr *Controller) clearVirtualServerWarning(vs *cisapiv1.VirtualServer, reason, object string) {
+	ctlr.vsWarnings.Lock()
+	defer ctlr.vsWarnings.Unlock()
+	delete(ctlr.vsWarnings.messages, virtualServerWarningKey{namespace: vs.Namespace, name: vs.Name, reason: reason,
+		object: object})
+}
+
+// forgetVirtualServerWarnings forgets all the warnings of the deleted VirtualServer
+func (ctlr *Controller) forgetVirtualServerWarnings(vs *cisapiv1.VirtualServer) {
+	ctlr.vsWarnings.Lock()
+	defer ctlr.vsWarnings.Unlock()
+	for key := range ctlr.vsWarnings.messages {
+		if key.namespace == vs.Namespace && key.name == vs.Name {
+			delete(ctlr.vsWarnings.messages, key)
+		}
+	}
+}
+
+// recordVirtualServerEvent creates an event of the VirtualServer
+func (ctlr *Controller) recordVirtualServerEvent(vs *cisapiv1.VirtualServer, eventType, reason, message string) {
+	now := metav1.Now()
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: vs.Name + ".",
+			Namespace:    vs.Namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:            VirtualServer,
+			APIVersion:      cisapiv1.SchemeGroupVersion.String(),
+			Namespace:       vs.Namespace,
+			Name:            vs.Name,
+			UID:             vs.UID,
+			ResourceVersion: vs.ResourceVersion,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         v1.EventSource{Component: "k8s-bigip-ctlr"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := ctlr.clientsets.KubeClient.CoreV1().Events(vs.Namespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	if err != nil {
+		log.Debugf("Error while creating VirtualServer event:%v", err)
+	}
+}
+
+// isTLSProfileInvalid checks whether the Valid condition of the TLSProfile is set to false
+func isTLSProfileInvalid(tls *cisapiv1.TLSProfile) bool {
+	cond := meta.FindStatusCondition(tls.Status.Conditions, TLSProfileConditionValid)
+	return cond != nil && cond.Status != metav1.ConditionTrue
+}
+
+// updateTLSProfileValidCondition sets the Valid condition of the TLSProfile
+func (ctlr *Controller) updateTLSProfileValidCondition(tls *cisapiv1.TLSProfile, valid bool) {
+	status, reason, message := metav1.ConditionTrue, TLSProfileReasonValid, ""
+	if !valid {
+		status, reason, message = metav1.ConditionFalse, TLSProfileReasonInvalid, "invalid TLS configuration for the termination type"
+	}
+	cond := meta.FindStatusCondition(tls.Status.Conditions, TLSProfileConditionValid)
+	if cond != nil && cond.Status == status && cond.ObservedGeneration == tls.Generation {
+		return
+	}
+	tlsCopy := tls.DeepCopy()
+	meta.SetStatusCondition(&tlsCopy.Status.Conditions, metav1.Condition{
+		Type:               TLSProfileConditionValid,
+		Status:             status,
+		ObservedGeneration: tls.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	_, updateErr := ctlr.clientsets.KubeCRClient.CisV1().TLSProfiles(tls.Namespace).UpdateStatus(context.TODO(), tlsCopy, metav1.UpdateOptions{})
+	if nil != updateErr {
+		log.Debugf("Error while updating TLSProfile status:%v", updateErr)
+	}
+}
+
 // returns service obj with servicename
 func (ctlr *Controller) GetService(namespace, serviceName string) *v1.Service {
 	svcKey := namespace + "/" + serviceName
@@ -3810,6 +4637,10 @@ func (ctlr *Controller) processConfigCR(configCR *cisapiv1.DeployConfig, isDelet
 		}
 
 	}()
+	if ctlr.isGlobalExtendedCR(configCR) && ctlr.RequestHandler != nil {
+		// the rollout is paused by the rollout-pause flag or the rolloutPause of the global DeployConfig CR
+		ctlr.RequestHandler.SetRolloutPause(ctlr.rolloutPause || (!isDelete && configCR.Spec.AS3Config.RolloutPause))
+	}
 	// get bigIpConfig and start/stop agent if needed
 	bigipconfig := configCR.Spec.BigIpConfig
 	ctlr.handleBigipConfigUpdates(bigipconfig)
@@ -4241,11 +5072,166 @@ func (ctlr *Controller) handleBigipConfigUpdates(config []cisapiv1.BigIpConfig)
 				ctlr.RequestHandler.startPostManager(newConfig)
 				//update bigipMap with new bigipconfig
 				ctlr.bigIpConfigMap[newConfig] = BigIpResourceConfig{ltmConfig: make(LTMConfig), gtmConfig: make(GTMConfig)}
+				// apply the packet filters to the new bigip
+				if ctlr.adminInformer != nil && ctlr.adminInformer.packetFilterInformer != nil {
+					ctlr.resourceQueue.Add(&rqKey{kind: PacketFilter, event: Update})
+				}
 			}
 		}
 	}
 }
 
+// enqueueDeviceConfigs puts one request per BIG-IP device with all the tenants posted to the device into the
+// request channel, a device which lost tenants to another device gets a request as well, so that its post
+// manager deletes them. The devices whose request is dropped are requested again with the next update
+func (ctlr *Controller) enqueueDeviceConfigs() {
+	deviceConfigs, updated := ctlr.assignTenantsToDevices()
+	ctlr.tenantPosts.setDevices(deviceConfigs)
+	for device := range ctlr.deviceTenants {
+		if _, ok := deviceConfigs[device]; !ok {
+			deviceConfigs[device] = BigIpResourceConfig{ltmConfig: make(LTMConfig), gtmConfig: make(GTMConfig)}
+		}
+	}
+	for device := range ctlr.droppedRequests {
+		updated[device] = true
+	}
+	deviceTenants := make(map[cisapiv1.BigIpConfig]map[string]struct{}, len(deviceConfigs))
+	droppedRequests := make(map[cisapiv1.BigIpConfig]struct{})
+	for device, deviceConfig := range deviceConfigs {
+		tenants := make(map[string]struct{}, len(deviceConfig.ltmConfig))
+		for tenant := range deviceConfig.ltmConfig {
+			tenants[tenant] = struct{}{}
+		}
+		if !reflect.DeepEqual(tenants, ctlr.deviceTenants[device]) {
+			// the device gained or lost tenants of another device
+			updated[device] = true
+		}
+		if !updated[device] || (len(tenants) == 0 && len(deviceConfig.gtmConfig) == 0 && len(ctlr.deviceTenants[device]) == 0) {
+			deviceTenants[device] = tenants
+			continue
+		}
+		config := ResourceConfigRequest{
+			bigIpConfig:         device,
+			bigIpResourceConfig: deviceConfig,
+			poolMemberType:      ctlr.PoolMemberType,
+		}
+		config.reqMeta = ctlr.enqueueReq(deviceConfig, device)
+		if !ctlr.RequestHandler.EnqueueRequestConfig(config) {
+			log.Warningf("Request of BIG-IP %v dropped, requesting it again with the next update", device.BigIpAddress)
+			// the tenants of the previous request are kept, so that the tenants deleted meanwhile are detected
+			deviceTenants[device] = ctlr.deviceTenants[device]
+			droppedRequests[device] = struct{}{}
+			continue
+		}
+		ctlr.tenantPosts.posting(device, tenants)
+		deviceTenants[device] = tenants
+	}
+	ctlr.deviceTenants = deviceTenants
+	ctlr.droppedRequests = droppedRequests
+}
+
+// assignTenantsToDevices merges the tenants of all the BIG-IP configs into one config per BIG-IP device based on
+// the tenant to device mapping, tenants which are not mapped stay with their BIG-IP config and the GTM config
+// stays with its BIG-IP config. The BIG-IP targets receive the tenants matching their tenant selector as well.
+// It returns the configs and whether a BIG-IP config of every device is updated
+func (ctlr *Controller) assignTenantsToDevices() (map[cisapiv1.BigIpConfig]BigIpResourceConfig, map[cisapiv1.BigIpConfig]bool) {
+	deviceConfigs := make(map[cisapiv1.BigIpConfig]BigIpResourceConfig, len(ctlr.resources.bigIpMap))
+	updated := make(map[cisapiv1.BigIpConfig]bool, len(ctlr.resources.bigIpMap))
+	if len(ctlr.tenantToDeviceMapping) == 0 && len(ctlr.bigipTargets) == 0 {
+		for bigip, config := range ctlr.resources.bigIpMap {
+			deviceConfigs[bigip] = config
+			updated[bigip] = ctlr.resources.isConfigUpdated(bigip)
+		}
+		return deviceConfigs, updated
+	}
+	getDeviceConfig := func(device cisapiv1.BigIpConfig) BigIpResourceConfig {
+		if _, ok := deviceConfigs[device]; !ok {
+			deviceConfigs[device] = BigIpResourceConfig{ltmConfig: make(LTMConfig), gtmConfig: make(GTMConfig)}
+		}
+		return deviceConfigs[device]
+	}
+	for bigip, config := range ctlr.resources.bigIpMap {
+		isUpdated := ctlr.resources.isConfigUpdated(bigip)
+		bigipConfig := getDeviceConfig(bigip)
+		bigipConfig.shareNodes = config.shareNodes
+		for partition, gtmPartitionConfig := range config.gtmConfig {
+			bigipConfig.gtmConfig[partition] = gtmPartitionConfig
+		}
+		deviceConfigs[bigip] = bigipConfig
+		updated[bigip] = updated[bigip] || isUpdated
+		for tenant, partitionConfig := range config.ltmConfig {
+			device := ctlr.getTenantDevice(tenant, bigip)
+			getDeviceConfig(device).ltmConfig[tenant] = partitionConfig
+			updated[device] = updated[device] || isUpdated
+			for _, target := range ctlr.getTenantTargets(tenant, device) {
+				getDeviceConfig(target).ltmConfig[tenant] = partitionConfig
+				updated[target] = updated[target] || isUpdated
+			}
+		}
+	}
+	return deviceConfigs, updated
+}
+
+// getTenantTargets returns the BIG-IP targets other than the device which receive the tenant, the targets
+// which are not configured are skipped
+func (ctlr *Controller) getTenantTargets(tenant string, device cisapiv1.BigIpConfig) []cisapiv1.BigIpConfig {
+	var targets []cisapiv1.BigIpConfig
+	for _, target := range ctlr.bigipTargets {
+		if !target.matchesTenant(tenant) {
+			continue
+		}
+		targetDevice, found := ctlr.getBIGIPConfigByAddress(target.Address)
+		if !found {
+			log.Warningf("BIG-IP target %v is not configured, skipping the tenant %v", target.Address, tenant)
+			continue
+		}
+		if targetDevice != device {
+			targets = append(targets, targetDevice)
+		}
+	}
+	return targets
+}
+
+// matchesTenant returns whether the tenant is posted to the target, a target without a tenant selector
+// receives all the tenants
+func (target BIGIPTarget) matchesTenant(tenant string) bool {
+	if len(target.TenantSelector) == 0 {
+		return true
+	}
+	for _, selected := range target.TenantSelector {
+		if selected == tenant {
+			return true
+		}
+	}
+	return false
+}
+
+// getTenantDevice returns the BIG-IP the tenant of the BIG-IP config is posted to based on the tenant to device
+// mapping, the tenant stays with the BIG-IP config when it's not mapped or mapped to an unknown BIG-IP
+func (ctlr *Controller) getTenantDevice(tenant string, bigip cisapiv1.BigIpConfig) cisapiv1.BigIpConfig {
+	address, ok := ctlr.tenantToDeviceMapping[tenant]
+	if !ok {
+		return bigip
+	}
+	if device, found := ctlr.getBIGIPConfigByAddress(address); found {
+		return device
+	}
+	log.Warningf("BIG-IP %v mapped to tenant %v is not configured, posting the tenant to BIG-IP %v",
+		address, tenant, bigip.BigIpAddress)
+	return bigip
+}
+
+// getBIGIPConfigByAddress returns the BIG-IP config with the given BIG-IP address
+func (ctlr *Controller) getBIGIPConfigByAddress(address string) (cisapiv1.BigIpConfig, bool) {
+	address = strings.TrimSuffix(address, "/")
+	for bigipconfig := range ctlr.bigIpConfigMap {
+		if strings.TrimSuffix(bigipconfig.BigIpAddress, "/") == address {
+			return bigipconfig, true
+		}
+	}
+	return cisapiv1.BigIpConfig{}, false
+}
+
 func (ctlr *Controller) getPartitionForBIGIP(bigipLabel string) string {
 	//get partition from bigip
 	for bigipconfig, _ := range ctlr.bigIpConfigMap {
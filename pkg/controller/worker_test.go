@@ -10,6 +10,7 @@ import (
 	routeapi "github.com/openshift/api/route/v1"
 	fakeRouteClient "github.com/openshift/client-go/route/clientset/versioned/fake"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/workqueue"
 	"net/http"
@@ -31,7 +32,10 @@ import (
 	"github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/test"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -901,6 +905,221 @@ var _ = Describe("Worker Tests", func() {
 			Expect(len(mems)).To(Equal(0), "Wrong set of Endpoints for NodePort")
 		})
 
+		It("Pool slowRampTime from service annotation", func() {
+			svc := test.NewService("foo", "1", namespace, v1.ServiceTypeClusterIP, nil)
+			_, ok := getSlowRampTime(svc)
+			Expect(ok).To(BeFalse(), "slowRampTime should not be set without annotation")
+			svc.Annotations = map[string]string{SlowRampTimeAnnotation: "300"}
+			slowRampTime, ok := getSlowRampTime(svc)
+			Expect(ok).To(BeTrue())
+			Expect(slowRampTime).To(Equal(int32(300)), "Incorrect slowRampTime")
+			svc.Annotations[SlowRampTimeAnnotation] = "901"
+			_, ok = getSlowRampTime(svc)
+			Expect(ok).To(BeFalse(), "slowRampTime out of range should be rejected")
+			svc.Annotations[SlowRampTimeAnnotation] = "abc"
+			_, ok = getSlowRampTime(svc)
+			Expect(ok).To(BeFalse(), "non integer slowRampTime should be rejected")
+		})
+
+		It("BGP advertise service for VirtualServer", func() {
+			mockCtlr.clientsets.KubeClient = k8sfake.NewSimpleClientset()
+			vs := test.NewVirtualServer("vs1", namespace, cisapiv1.VirtualServerSpec{VirtualServerAddress: "10.1.1.1"})
+			ports := []portStruct{{protocol: HTTP, port: DEFAULT_HTTP_PORT}}
+			svcName := "vs1" + BGPAdvertiseServiceSuffix
+			svcClient := mockCtlr.clientsets.KubeClient.CoreV1().Services(namespace)
+
+			mockCtlr.handleBGPAdvertiseService(vs, "10.1.1.1", ports, false)
+			_, err := svcClient.Get(context.TODO(), svcName, metav1.GetOptions{})
+			Expect(err).To(HaveOccurred(), "Service should not be created without annotation")
+
+			vs.Annotations = map[string]string{BGPAdvertiseAnnotation: "true"}
+			mockCtlr.handleBGPAdvertiseService(vs, "10.1.1.1", ports, false)
+			svc, err := svcClient.Get(context.TODO(), svcName, metav1.GetOptions{})
+			Expect(err).To(BeNil(), "Service should be created")
+			Expect(svc.Spec.Type).To(Equal(v1.ServiceTypeLoadBalancer))
+			Expect(svc.Spec.LoadBalancerIP).To(Equal("10.1.1.1"))
+			Expect(len(svc.Spec.Ports)).To(Equal(1))
+
+			mockCtlr.handleBGPAdvertiseService(vs, "10.1.1.2", ports, false)
+			svc, _ = svcClient.Get(context.TODO(), svcName, metav1.GetOptions{})
+			Expect(svc.Spec.LoadBalancerIP).To(Equal("10.1.1.2"), "Service IP should be updated")
+
+			mockCtlr.handleBGPAdvertiseService(vs, "10.1.1.2", ports, true)
+			_, err = svcClient.Get(context.TODO(), svcName, metav1.GetOptions{})
+			Expect(err).To(HaveOccurred(), "Service should be deleted with VirtualServer")
+		})
+
+		It("VirtualServer address from LoadBalancer service status", func() {
+			vs := test.NewVirtualServer("vs1", namespace, cisapiv1.VirtualServerSpec{
+				Pools: []cisapiv1.VSPool{{Service: "svc1", ServicePort: intstr.FromInt(80)}},
+			})
+			Expect(mockCtlr.getLBServiceIngressIP(vs)).To(BeEmpty(), "IP should be empty without service")
+
+			svc := test.NewService("svc1", "1", namespace, v1.ServiceTypeLoadBalancer, nil)
+			mockCtlr.addService(svc)
+			Expect(mockCtlr.getLBServiceIngressIP(vs)).To(BeEmpty(), "IP should be empty without LoadBalancer status")
+
+			svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "10.2.2.2"}}
+			mockCtlr.updateService(svc)
+			Expect(mockCtlr.getLBServiceIngressIP(vs)).To(Equal("10.2.2.2"), "Incorrect LoadBalancer status IP")
+
+			svc.Annotations = map[string]string{LBServiceIPAnnotation: "10.2.2.2"}
+			mockCtlr.updateService(svc)
+			Expect(mockCtlr.getLBServiceIngressIP(vs)).To(BeEmpty(), "CIS managed LoadBalancer service should be skipped")
+		})
+
+		It("Assign tenants to BIG-IP devices", func() {
+			bigip2 := cisapiv1.BigIpConfig{BigIpLabel: "bigip2", DefaultPartition: "test", BigIpAddress: "10.8.3.12"}
+			mockCtlr.bigIpConfigMap[bigip2] = BigIpResourceConfig{ltmConfig: make(LTMConfig), gtmConfig: make(GTMConfig)}
+			partition := func() *PartitionConfig {
+				priority := 0
+				return &PartitionConfig{ResourceMap: ResourceMap{"vs": &ResourceConfig{}}, Priority: &priority}
+			}
+			mockCtlr.resources.bigIpMap[bigipConfig] = BigIpResourceConfig{
+				ltmConfig: LTMConfig{"tenant1": partition(), "tenant2": partition(), "tenant3": partition()},
+				gtmConfig: GTMConfig{DEFAULT_GTM_PARTITION: GTMPartitionConfig{WideIPs: make(map[string]WideIP)}},
+			}
+			mockCtlr.resources.bigIpMap[bigip2] = BigIpResourceConfig{ltmConfig: LTMConfig{"tenant4": partition()},
+				gtmConfig: make(GTMConfig)}
+			reqChan := make(chan ResourceConfigRequest, 4)
+			mockCtlr.RequestHandler.reqChan = reqChan
+			getRequests := func() map[cisapiv1.BigIpConfig]BigIpResourceConfig {
+				requests := make(map[cisapiv1.BigIpConfig]BigIpResourceConfig)
+				for len(reqChan) > 0 {
+					req := <-reqChan
+					Expect(requests).NotTo(HaveKey(req.bigIpConfig), "More than one request for a BIG-IP")
+					requests[req.bigIpConfig] = req.bigIpResourceConfig
+				}
+				return requests
+			}
+
+			mockCtlr.tenantToDeviceMapping = map[string]string{"tenant2": "10.8.3.12/", "tenant3": "10.8.3.13"}
+			mockCtlr.enqueueDeviceConfigs()
+			requests := getRequests()
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[bigip2].ltmConfig).To(HaveLen(2))
+			Expect(requests[bigip2].ltmConfig).To(HaveKey("tenant2"), "Mapped tenant not assigned to BIG-IP")
+			Expect(requests[bigip2].ltmConfig).To(HaveKey("tenant4"), "Tenant of the BIG-IP not merged")
+			Expect(requests[bigip2].gtmConfig).To(BeEmpty(), "GTM config should remain with the default BIG-IP")
+			Expect(requests[bigipConfig].ltmConfig).To(HaveLen(2))
+			Expect(requests[bigipConfig].ltmConfig).To(HaveKey("tenant1"))
+			Expect(requests[bigipConfig].ltmConfig).To(HaveKey("tenant3"), "Tenant mapped to unknown BIG-IP should remain")
+			Expect(requests[bigipConfig].gtmConfig).To(HaveKey(DEFAULT_GTM_PARTITION))
+
+			mockCtlr.resources.updateCaches()
+			mockCtlr.enqueueDeviceConfigs()
+			Expect(reqChan).To(BeEmpty(), "BIG-IPs requested without updates")
+
+			delete(mockCtlr.tenantToDeviceMapping, "tenant2")
+			mockCtlr.enqueueDeviceConfigs()
+			requests = getRequests()
+			Expect(requests).To(HaveLen(2), "BIG-IP losing a tenant not requested")
+			Expect(requests[bigip2].ltmConfig).NotTo(HaveKey("tenant2"), "Remapped tenant not deleted")
+			Expect(requests[bigipConfig].ltmConfig).To(HaveKey("tenant2"), "Remapped tenant not posted")
+
+			mockCtlr.RequestHandler.reqChan = make(chan ResourceConfigRequest)
+			mockCtlr.tenantToDeviceMapping["tenant1"] = "10.8.3.12"
+			mockCtlr.enqueueDeviceConfigs()
+			Expect(mockCtlr.droppedRequests).To(HaveLen(2))
+			mockCtlr.RequestHandler.reqChan = reqChan
+			mockCtlr.enqueueDeviceConfigs()
+			requests = getRequests()
+			Expect(requests).To(HaveLen(2), "Dropped requests not requested again")
+			Expect(requests[bigip2].ltmConfig).To(HaveKey("tenant1"))
+			Expect(requests[bigipConfig].ltmConfig).NotTo(HaveKey("tenant1"))
+
+			bigip3 := cisapiv1.BigIpConfig{BigIpLabel: "bigip3", DefaultPartition: "test", BigIpAddress: "10.8.3.13"}
+			mockCtlr.bigIpConfigMap[bigip3] = BigIpResourceConfig{ltmConfig: make(LTMConfig), gtmConfig: make(GTMConfig)}
+			mockCtlr.tenantToDeviceMapping = nil
+			mockCtlr.bigipTargets = []BIGIPTarget{{Address: "10.8.3.13"}, {Address: "10.8.3.12", TenantSelector: []string{"tenant2"}}}
+			mockCtlr.enqueueDeviceConfigs()
+			requests = getRequests()
+			Expect(requests).To(HaveLen(3))
+			Expect(requests[bigip3].ltmConfig).To(HaveLen(4), "Tenants not posted to the BIG-IP target")
+			Expect(requests[bigip2].ltmConfig).To(HaveLen(2))
+			Expect(requests[bigip2].ltmConfig).To(HaveKey("tenant2"), "Selected tenant not posted to the BIG-IP target")
+			Expect(requests[bigip2].ltmConfig).NotTo(HaveKey("tenant1"), "Unselected tenant posted to the BIG-IP target")
+			Expect(requests[bigipConfig].ltmConfig).To(HaveLen(3))
+			Expect(mockCtlr.tenantPosts.devices["tenant1"]).To(HaveLen(2))
+			Expect(mockCtlr.tenantPosts.devices["tenant2"]).To(HaveLen(3))
+		})
+
+		It("NodePort with topology aware pool weights", func() {
+			mockCtlr.multiClusterNodeInformers[""].oldNodes[0].Labels[TopologyZoneLabel] = "zone-a"
+			mockCtlr.multiClusterNodeInformers[""].oldNodes[1].Labels[TopologyZoneLabel] = "zone-b"
+			mockCtlr.topologyConfig = TopologyConfig{Enabled: true, BIGIPZone: "zone-a"}
+			mems := mockCtlr.getEndpointsForNodePort(30000, "worker=true", "")
+			Expect(len(mems)).To(Equal(2), "Wrong set of Endpoints for NodePort")
+			Expect(mems[0].Ratio).To(Equal(DefaultLocalZoneRatio), "Wrong ratio for local zone member")
+			Expect(mems[1].Ratio).To(Equal(DefaultRemoteZoneRatio), "Wrong ratio for remote zone member")
+
+			mockCtlr.topologyConfig.ZoneWeights = map[string]int{"zone-b": 5}
+			mems = mockCtlr.getEndpointsForNodePort(30000, "worker=true", "")
+			Expect(mems[1].Ratio).To(Equal(5), "Wrong ratio for configured zone weight")
+
+			mockCtlr.topologyConfig = TopologyConfig{}
+			mems = mockCtlr.getEndpointsForNodePort(30000, "worker=true", "")
+			Expect(mems[0].Ratio).To(Equal(0), "Ratio should not be set when disabled")
+		})
+
+		It("Pool members ordered by topology", func() {
+			mockCtlr.multiClusterNodeInformers[""].oldNodes[0].Labels["custom/zone"] = "zone-b"
+			mockCtlr.multiClusterNodeInformers[""].oldNodes[1].Labels["custom/zone"] = "zone-a"
+			mockCtlr.topologyConfig = TopologyConfig{Enabled: true, TopologyKey: "custom/zone", BIGIPZone: "zone-a"}
+			pool := &Pool{Members: mockCtlr.getEndpointsForNodePort(30000, "worker=true", "")}
+			Expect(len(pool.Members)).To(Equal(2), "Wrong set of Endpoints for NodePort")
+			Expect(pool.Members[0].Zone).To(Equal("zone-b"))
+			mockCtlr.sortPoolMembersByTopology(pool)
+			Expect(pool.Members[0].Zone).To(Equal("zone-a"), "Local zone member should be placed first")
+			Expect(pool.Members[0].Ratio).To(Equal(DefaultLocalZoneRatio))
+			Expect(pool.Members[1].Zone).To(Equal("zone-b"))
+			mockCtlr.topologyConfig = TopologyConfig{}
+		})
+
+		It("Cluster endpoints with topology hints", func() {
+			mockCtlr.topologyConfig = TopologyConfig{Enabled: true, BIGIPZone: "zone-a"}
+			defer func() { mockCtlr.topologyConfig = TopologyConfig{} }()
+			mockCtlr.comInformers[namespace] = mockCtlr.newNamespacedCommonResourceInformer(namespace)
+			Expect(mockCtlr.comInformers[namespace].epSliceInformer).ToNot(BeNil(), "EndpointSlice informer not created")
+
+			svcPorts := []v1.ServicePort{{Name: "http", Port: 80}}
+			svc := test.NewService("svc", "1", namespace, v1.ServiceTypeClusterIP, svcPorts)
+			mockCtlr.addEndpoints(test.NewEndpoints("svc", "1", "worker1", namespace,
+				[]string{"10.1.1.1", "10.1.1.2", "10.1.1.3"}, []string{}, convertSvcPortsToEndpointPorts(svcPorts)))
+			_ = mockCtlr.comInformers[namespace].epSliceInformer.GetIndexer().Add(&discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc-abc",
+					Namespace: namespace,
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "svc"},
+				},
+				Endpoints: []discoveryv1.Endpoint{
+					{
+						Addresses: []string{"10.1.1.1"},
+						Hints:     &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: "zone-a"}}},
+					},
+					{
+						Addresses: []string{"10.1.1.2"},
+						Hints:     &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: "zone-b"}}},
+					},
+					{
+						Addresses: []string{"10.1.1.3"},
+					},
+				},
+			})
+			svcKey := MultiClusterServiceKey{serviceName: "svc", namespace: namespace}
+			mockCtlr.resources.poolMemCache[svcKey] = &poolMembersInfo{memberMap: make(map[portRef][]PoolMember)}
+
+			Expect(mockCtlr.processService(svc, "")).To(BeNil())
+			mems := mockCtlr.resources.poolMemCache[svcKey].memberMap[portRef{name: "http", port: 80}]
+			Expect(len(mems)).To(Equal(3), "Wrong set of Endpoints")
+			Expect(mems[0].Zone).To(Equal("zone-a"))
+			Expect(mems[0].Ratio).To(Equal(DefaultLocalZoneRatio), "Wrong ratio for endpoint hinted for the BIG-IP zone")
+			Expect(mems[1].Zone).To(Equal("zone-b"))
+			Expect(mems[1].Ratio).To(Equal(DefaultRemoteZoneRatio), "Wrong ratio for endpoint hinted for another zone")
+			Expect(mems[2].Zone).To(BeEmpty(), "Endpoint without hints should not have a zone")
+			Expect(mems[2].Ratio).To(Equal(DefaultRemoteZoneRatio))
+		})
+
 	})
 
 	Describe("Processing Resources", func() {
@@ -1021,6 +1240,67 @@ var _ = Describe("Worker Tests", func() {
 			Expect(len(gtmConfig)).To(Equal(0))
 		})
 
+		It("Processing cert-manager Certificate renewal", func() {
+			renewalTime := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+			certificates := func(revision int) string {
+				return fmt.Sprintf(`{"apiVersion": "cert-manager.io/v1", "kind": "CertificateList", "items": [{
+					"apiVersion": "cert-manager.io/v1", "kind": "Certificate",
+					"metadata": {"name": "tea-cert", "namespace": "default"},
+					"spec": {"secretName": "tea-secret", "issuerRef": {"name": "letsencrypt"}},
+					"status": {"notAfter": "2026-12-01T00:00:00Z", "renewalTime": "%s", "revision": %d}}]}`,
+					renewalTime, revision)
+			}
+			body := certificates(1)
+			status := http.StatusOK
+			server := ghttp.NewServer()
+			defer server.Close()
+			server.RouteToHandler(http.MethodGet, CertManagerCertificatesPath, ghttp.CombineHandlers(
+				ghttp.VerifyFormKV("labelSelector", CertManagerManagedLabel+"=true"),
+				ghttp.RespondWithPtr(&status, &body),
+			))
+			client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL()})
+			Expect(err).NotTo(HaveOccurred())
+			mockCtlr.clientsets.KubeClient = client
+
+			tlsProfile := test.NewTLSProfile("edge-tls", namespace, cisapiv1.TLSProfileSpec{
+				Hosts: []string{"tea.example.com"},
+				TLS: cisapiv1.TLS{
+					Termination: TLSEdge,
+					ClientSSL:   "tea-secret",
+					Reference:   Secret,
+				},
+			})
+			_, _ = mockCtlr.clientsets.KubeCRClient.CisV1().TLSProfiles(namespace).Create(context.TODO(), tlsProfile,
+				metav1.CreateOptions{})
+			_ = mockCtlr.crInformers["default"].tlsInformer.GetIndexer().Add(tlsProfile)
+			mockCtlr.certRenewalLeadDays = 1
+
+			// renewal is not within the lead days
+			mockCtlr.checkCertificateRenewals()
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(0), "TLSProfile enqueued before the lead days")
+			tls, _ := mockCtlr.clientsets.KubeCRClient.CisV1().TLSProfiles(namespace).Get(context.TODO(), "edge-tls",
+				metav1.GetOptions{})
+			Expect(tls.Status.CertExpiry).To(Equal("2026-12-01T00:00:00Z"))
+			Expect(tls.Status.CertIssuer).To(Equal("letsencrypt"))
+
+			// renewal within the lead days is synced once
+			mockCtlr.certRenewalLeadDays = 7
+			mockCtlr.checkCertificateRenewals()
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(1), "TLSProfile not enqueued within the lead days")
+			mockCtlr.checkCertificateRenewals()
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(1), "TLSProfile enqueued again for the same revision")
+
+			// renewed certificate is synced
+			body = certificates(2)
+			mockCtlr.checkCertificateRenewals()
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(2), "TLSProfile not enqueued for the renewed certificate")
+
+			// cert-manager not installed
+			status = http.StatusNotFound
+			mockCtlr.checkCertificateRenewals()
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(2))
+		})
+
 		It("Processing IngressLink", func() {
 			// Creation of IngressLink
 			fooPorts := []v1.ServicePort{
@@ -1078,6 +1358,35 @@ var _ = Describe("Worker Tests", func() {
 		Expect(int(np)).To(Equal(30000))
 	})
 
+	It("Filter pool members by pod selector", func() {
+		vs := test.NewVirtualServer("SampleVS", namespace, cisapiv1.VirtualServerSpec{Host: "test.com"})
+		vs.Annotations = map[string]string{PoolPodSelectorAnnotation: `{"matchLabels": {"version": "canary"}}`}
+		Expect(getPoolPodSelector(vs)).To(Equal("version=canary"))
+		vs.Annotations[PoolPodSelectorAnnotation] = `version=canary`
+		Expect(getPoolPodSelector(vs)).To(BeEmpty(), "Invalid pod selector should be ignored")
+
+		mockCtlr.PoolMemberType = Cluster
+		stable := test.NewPod("stable", namespace, 8080, map[string]string{"app": "svc", "version": "stable"})
+		stable.Status.PodIPs = []v1.PodIP{{IP: "10.244.0.10"}}
+		canary := test.NewPod("canary", namespace, 8080, map[string]string{"app": "svc", "version": "canary"})
+		canary.Status.PodIPs = []v1.PodIP{{IP: "10.244.0.11"}}
+		for _, pod := range []*v1.Pod{stable, canary} {
+			_, err := mockCtlr.clientsets.KubeClient.CoreV1().Pods(namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			Expect(err).To(BeNil())
+		}
+		members := []PoolMember{
+			{Address: "10.244.0.10", Port: 8080, Session: "user-enabled"},
+			{Address: "10.244.0.11", Port: 8080, Session: "user-enabled"},
+		}
+		svcKey := MultiClusterServiceKey{serviceName: "svc", namespace: namespace}
+		Expect(mockCtlr.filterPoolMembersByPodSelector(members, svcKey, "version=canary")).To(
+			Equal([]PoolMember{{Address: "10.244.0.11", Port: 8080, Session: "user-enabled"}}))
+
+		// pod selector is not applied to NodePort members
+		nodeMembers := []PoolMember{{Address: "10.10.10.1", Port: 30000, MemberType: NodePort}}
+		Expect(mockCtlr.filterPoolMembersByPodSelector(nodeMembers, svcKey, "version=canary")).To(Equal(nodeMembers))
+	})
+
 	Describe("Test NodeportLocal", func() {
 		var nplsvc *v1.Service
 		var selectors map[string]string
@@ -2849,6 +3158,183 @@ var _ = Describe("Worker Tests", func() {
 
 			})
 		})
+
+		Describe("Processing Ingress", func() {
+			var ing *networkingv1.Ingress
+
+			BeforeEach(func() {
+				pathType := networkingv1.PathTypePrefix
+				ing = &networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ing1",
+						Namespace: namespace,
+						Annotations: map[string]string{
+							IngressClassAnnotation: F5IngressClass,
+							F5VsBindAddrAnnotation: "10.8.3.20",
+						},
+					},
+					Spec: networkingv1.IngressSpec{
+						TLS: []networkingv1.IngressTLS{{Hosts: []string{"foo.com"}, SecretName: "foo-secret"}},
+						Rules: []networkingv1.IngressRule{
+							{
+								Host: "foo.com",
+								IngressRuleValue: networkingv1.IngressRuleValue{
+									HTTP: &networkingv1.HTTPIngressRuleValue{
+										Paths: []networkingv1.HTTPIngressPath{
+											{
+												Path:     "/foo",
+												PathType: &pathType,
+												Backend: networkingv1.IngressBackend{
+													Service: &networkingv1.IngressServiceBackend{
+														Name: "svc1",
+														Port: networkingv1.ServiceBackendPort{Number: 80},
+													},
+												},
+											},
+											{
+												Path:     "/bar",
+												PathType: &pathType,
+												Backend: networkingv1.IngressBackend{
+													Service: &networkingv1.IngressServiceBackend{
+														Name: "svc2",
+														Port: networkingv1.ServiceBackendPort{Name: "http"},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+							{
+								Host: "bar.com",
+								IngressRuleValue: networkingv1.IngressRuleValue{
+									HTTP: &networkingv1.HTTPIngressRuleValue{
+										Paths: []networkingv1.HTTPIngressPath{
+											{
+												PathType: &pathType,
+												Backend: networkingv1.IngressBackend{
+													Service: &networkingv1.IngressServiceBackend{
+														Name: "svc3",
+														Port: networkingv1.ServiceBackendPort{Number: 8080},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+			})
+
+			It("Translate Ingress", func() {
+				virtuals, tlsProfiles := translateIngress(ing)
+				Expect(len(virtuals)).To(Equal(2), "Invalid VirtualServer count")
+				Expect(virtuals[0].Spec.Host).To(Equal("foo.com"))
+				Expect(virtuals[0].Spec.TLSProfileName).To(Equal("foo-secret"))
+				Expect(virtuals[0].Spec.Pools).To(Equal([]cisapiv1.VSPool{
+					{Path: "/foo", Service: "svc1", ServicePort: intstr.FromInt(80)},
+					{Path: "/bar", Service: "svc2", ServicePort: intstr.FromString("http")},
+				}))
+				Expect(virtuals[1].Spec.Host).To(Equal("bar.com"))
+				Expect(virtuals[1].Spec.TLSProfileName).To(BeEmpty())
+				Expect(virtuals[1].Spec.Pools[0].Path).To(Equal("/"), "Path should default to /")
+				Expect(len(tlsProfiles)).To(Equal(1), "Invalid TLSProfile count")
+				Expect(tlsProfiles["foo.com"].Spec.TLS).To(Equal(cisapiv1.TLS{
+					Termination: TLSEdge,
+					ClientSSL:   "foo-secret",
+					Reference:   Secret,
+				}))
+				Expect(virtuals[0].Spec.DefaultPool).To(BeZero(), "Default pool without default backend")
+
+				defaultPool := cisapiv1.DefaultPool{Service: "svc4", ServicePort: intstr.FromInt(80), Reference: ServiceRef}
+				ing.Spec.DefaultBackend = &networkingv1.IngressBackend{
+					Service: &networkingv1.IngressServiceBackend{
+						Name: "svc4",
+						Port: networkingv1.ServiceBackendPort{Number: 80},
+					},
+				}
+				virtuals, _ = translateIngress(ing)
+				Expect(virtuals[0].Spec.DefaultPool).To(Equal(defaultPool))
+				Expect(virtuals[1].Spec.DefaultPool).To(Equal(defaultPool))
+
+				// Ingress with the default backend only
+				ing.Spec.Rules = nil
+				virtuals, _ = translateIngress(ing)
+				Expect(len(virtuals)).To(Equal(1), "Invalid VirtualServer count")
+				Expect(virtuals[0].Spec.Host).To(BeEmpty())
+				Expect(virtuals[0].Spec.Pools).To(BeEmpty())
+				Expect(virtuals[0].Spec.DefaultPool).To(Equal(defaultPool))
+			})
+
+			It("Process Ingress", func() {
+				bigipConfig := mockCtlr.getBIGIPConfig(BigIPLabel)
+				partition := mockCtlr.getPartitionForBIGIP("")
+				// The TLS is ignored as the secrets are not managed
+				mockCtlr.managedResources.ManageSecrets = false
+				Expect(mockCtlr.processIngress(ing, false)).To(BeNil())
+				rsMap := mockCtlr.resources.getPartitionResourceMap(partition, bigipConfig)
+				Expect(len(rsMap)).To(Equal(1), "Invalid virtual count")
+				rsCfg := rsMap["ingress_default_ing1_80"]
+				Expect(rsCfg).NotTo(BeNil(), "HTTP virtual not created")
+				Expect(rsCfg.Virtual.Destination).To(Equal("/test/10.8.3.20:80"))
+				Expect(len(rsCfg.Pools)).To(Equal(3), "Invalid pool count")
+				Expect(rsCfg.MetaData.hosts).To(ConsistOf("foo.com", "bar.com"))
+				// the pools refer the Ingress, so that it is processed on the service updates
+				for _, svcPorts := range mockCtlr.multiClusterResources.clusterSvcMap[""] {
+					for _, poolIds := range svcPorts {
+						for poolId := range poolIds {
+							Expect(poolId.rsKey).To(Equal(resourceRef{kind: Ingress, name: "ing1", namespace: namespace}))
+						}
+					}
+				}
+
+				ing.Spec.DefaultBackend = &networkingv1.IngressBackend{
+					Service: &networkingv1.IngressServiceBackend{
+						Name: "svc4",
+						Port: networkingv1.ServiceBackendPort{Number: 80},
+					},
+				}
+				Expect(mockCtlr.processIngress(ing, false)).To(BeNil())
+				rsCfg = mockCtlr.resources.getPartitionResourceMap(partition, bigipConfig)["ingress_default_ing1_80"]
+				Expect(len(rsCfg.Pools)).To(Equal(4), "Default pool not created")
+				Expect(rsCfg.Virtual.PoolName).NotTo(BeEmpty(), "Default pool not set on the virtual")
+
+				// Ingresses of other classes are not processed
+				ing.Annotations[IngressClassAnnotation] = "nginx"
+				Expect(mockCtlr.processIngress(ing, false)).To(BeNil())
+				Expect(len(mockCtlr.resources.getPartitionResourceMap(partition, bigipConfig))).To(BeZero())
+
+				ing.Annotations[IngressClassAnnotation] = F5IngressClass
+				Expect(mockCtlr.processIngress(ing, false)).To(BeNil())
+				Expect(mockCtlr.processIngress(ing, true)).To(BeNil())
+				Expect(len(mockCtlr.resources.getPartitionResourceMap(partition, bigipConfig))).To(BeZero())
+			})
+
+			It("Ingress partition update", func() {
+				mockCtlr.managedResources.ManageSecrets = false
+				bigipConfig := mockCtlr.getBIGIPConfig(BigIPLabel)
+				partition := mockCtlr.getPartitionForBIGIP("")
+				mockCtlr.enqueueIngress(ing, Create)
+				mockCtlr.processResources()
+				Expect(len(mockCtlr.resources.getPartitionResourceMap(partition, bigipConfig))).To(Equal(1))
+
+				// status updates are skipped
+				mockCtlr.enqueueUpdatedIngress(ing, ing.DeepCopy())
+				Expect(mockCtlr.resourceQueue.Len()).To(BeZero(), "Ingress status update should be skipped")
+
+				newIng := ing.DeepCopy()
+				newIng.Annotations[F5VsPartitionAnnotation] = "dev"
+				mockCtlr.enqueueUpdatedIngress(ing, newIng)
+				Expect(mockCtlr.resourceQueue.Len()).To(Equal(2), "Ingress should be deleted from the old partition")
+				mockCtlr.processResources()
+				mockCtlr.processResources()
+				Expect(len(mockCtlr.resources.getPartitionResourceMap("dev", bigipConfig))).To(Equal(1))
+				Expect(len(mockCtlr.resources.getPartitionResourceMap(partition, bigipConfig))).To(BeZero(),
+					"Virtual of the old partition not deleted")
+			})
+		})
 	})
 
 	Describe("Processing Native Resources", func() {
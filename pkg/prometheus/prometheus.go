@@ -1,7 +1,6 @@
 package prometheus
 
 import (
-	log "github.com/F5Networks/k8s-bigip-ctlr/v3/pkg/vlogger"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -37,6 +36,103 @@ var MonitoredNodes = prometheus.NewGaugeVec(
 	[]string{"nodeselector"},
 )
 
+var DeclarationSize = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "k8s_bigip_ctlr_as3_declaration_size_bytes",
+		Help: "The size of the last AS3 declaration posted to the BIG-IP.",
+	},
+	[]string{"bigip"},
+)
+
+var DeclarationBytesSent = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k8s_bigip_ctlr_as3_declaration_bytes_sent_total",
+		Help: "The total number of AS3 declaration bytes sent to the BIG-IP since startup.",
+	},
+	[]string{"bigip"},
+)
+
+var DriftEvents = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k8s_bigip_ctlr_bigip_drift_events_total",
+		Help: "The total number of times the AS3 declaration stored on BIG-IP was found to differ from the declaration posted by CIS.",
+	},
+	[]string{"bigip"},
+)
+
+var AsyncTaskPolls = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k8s_bigip_ctlr_bigip_async_task_polls_total",
+		Help: "The total number of polls of the asynchronous AS3 tasks on the BIG-IP.",
+	},
+	[]string{"bigip"},
+)
+
+var AS3Errors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k8s_bigip_ctlr_as3_errors_total",
+		Help: "The total number of failed AS3 posts by the error code.",
+	},
+	[]string{"bigip", "code"},
+)
+
+var AS3ValidationSkipped = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k8s_bigip_ctlr_as3_validation_skipped_total",
+		Help: "The total number of dry-run declarations not validated as the AS3 schema failed to load.",
+	},
+	[]string{"bigip"},
+)
+
+var BigIPCertDaysUntilExpiry = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "k8s_bigip_ctlr_bigip_cert_days_until_expiry",
+		Help: "The number of days until the BIG-IP management certificate expires.",
+	},
+	[]string{"bigip"},
+)
+
+var ManagedTenants = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "k8s_bigip_ctlr_as3_tenants",
+		Help: "The number of AS3 tenants managed on BIG-IP by the status of their last post.",
+	},
+	[]string{"bigip", "status"},
+)
+
+var LastSuccessfulSync = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "k8s_bigip_ctlr_as3_last_successful_sync_timestamp_seconds",
+		Help: "The time of the last AS3 post without failed tenants.",
+	},
+	[]string{"bigip"},
+)
+
+var DeclarationTenants = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "k8s_bigip_ctlr_as3_declaration_tenants",
+		Help: "The number of tenants in the last AS3 declaration posted to the BIG-IP.",
+	},
+	[]string{"bigip"},
+)
+
+var TenantPostLatency = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "k8s_bigip_ctlr_as3_tenant_post_duration_seconds",
+		Help:    "The time from the AS3 post of a tenant until its final response from the BIG-IP.",
+		Buckets: []float64{.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	},
+	[]string{"tenant", "endpoint"},
+)
+
+var TenantPostResponses = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k8s_bigip_ctlr_as3_tenant_post_responses_total",
+		Help: "The total number of AS3 post responses of the tenants by the response code.",
+	},
+	[]string{"tenant", "response_code"},
+)
+
 var ClientInFlightGauge = prometheus.NewGauge(prometheus.GaugeOpts{
 	Name: "k8s_bigip_ctlr_http_client_in_flight_requests",
 	Help: "Total count of in-flight requests for the wrapped http client.",
@@ -93,29 +189,35 @@ var ClientTrace = &promhttp.InstrumentTrace{
 }
 
 // further metrics? todo think about
-// RegisterMetrics registers all Prometheus metrics defined above
-func RegisterMetrics(httpClientMetrics bool, cmAddress string) {
-	log.Infof("Registered http metrics for central manager %v", cmAddress)
+// RegisterMetrics registers the CIS metrics defined above with the registry, the AS3 tenant post
+// metrics and the metrics of the instrumented http client are registered only with httpClientMetrics
+func RegisterMetrics(registry prometheus.Registerer, httpClientMetrics bool) {
+	registry.MustRegister(
+		ManagedServices,
+		ManagedTransportServers,
+		ConfigurationWarnings,
+		AgentCount,
+		MonitoredNodes,
+		DeclarationSize,
+		DeclarationBytesSent,
+		DriftEvents,
+		AsyncTaskPolls,
+		AS3Errors,
+		AS3ValidationSkipped,
+		BigIPCertDaysUntilExpiry,
+		ManagedTenants,
+		LastSuccessfulSync,
+	)
 	if httpClientMetrics {
-		prometheus.MustRegister(
-			ManagedServices,
-			ManagedTransportServers,
-			ConfigurationWarnings,
-			AgentCount,
-			MonitoredNodes,
+		registry.MustRegister(
+			DeclarationTenants,
+			TenantPostLatency,
+			TenantPostResponses,
 			ClientInFlightGauge,
 			ClientAPIRequestsCounter,
 			ClientDNSLatencyVec,
 			ClientTLSLatencyVec,
 			ClientHistVec,
 		)
-	} else {
-		prometheus.MustRegister(
-			ManagedServices,
-			ManagedTransportServers,
-			ConfigurationWarnings,
-			AgentCount,
-			MonitoredNodes,
-		)
 	}
 }